@@ -2,29 +2,52 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"os/signal"
 	"strings"
 
 	"github.com/jeeftor/caddy-dns-sync/internal/tui"
 
 	"github.com/jeeftor/caddy-dns-sync/internal/api"
+	runtimeapp "github.com/jeeftor/caddy-dns-sync/internal/app"
 	"github.com/jeeftor/caddy-dns-sync/internal/config"
 	"github.com/jeeftor/caddy-dns-sync/internal/logging"
+	"github.com/jeeftor/caddy-dns-sync/internal/syncplan"
 	"github.com/spf13/cobra"
 )
 
 // applyCmd represents the apply command
 var applyCmd = &cobra.Command{
-	Use:   "apply",
-	Short: "Apply pending DNS changes",
+	Use:   "apply [plan.json]",
+	Short: "Apply pending DNS changes or a saved sync plan",
 	Long: `Apply pending DNS changes to Unbound DNS.
 
 This command applies any pending changes to Unbound DNS. Changes made with
 the add, edit, or delete commands are not applied immediately. You must use
-this command to apply the changes.`,
+this command to apply the changes.
+
+Given a plan file written by 'sync plan -o plan.json', it instead re-reads
+the live state of Caddy and every DNS service, refuses to run if that state
+no longer matches the plan's fingerprint, and otherwise applies exactly the
+saved actions. With --atomic, a failed action reverts the changes already
+made for the same hostname (--atomic hostname) or for the whole plan
+(--atomic run). The sync policy applies as it does to 'sync': protected
+hostnames are skipped and a mass delete needs --allow-mass-delete.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: runApply,
 }
 
+var (
+	applyCaddyServerIP   string
+	applyCaddyServerPort int
+	applyAtomic          string
+	applyAllowMassDelete bool
+)
+
 func runApply(cmd *cobra.Command, args []string) error {
+	if len(args) == 1 {
+		return runApplySavedPlan(cmd, args[0])
+	}
 	applyUI := newApplyUI()
 
 	cfg, err := config.LoadConfig()
@@ -45,6 +68,67 @@ func runApply(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// runApplySavedPlan applies a plan file after checking that the state it was
+// built from is unchanged.
+func runApplySavedPlan(cmd *cobra.Command, path string) error {
+	saved, err := syncplan.ReadSavedPlan(path)
+	if err != nil {
+		return err
+	}
+	atomic, err := syncplan.ParseAtomicScope(applyAtomic)
+	if err != nil {
+		return err
+	}
+
+	releaseLock, err := acquireSyncLockWithWait()
+	if err != nil {
+		return err
+	}
+	defer releaseLock()
+
+	caddyServerIP := applyCaddyServerIP
+	if caddyServerIP == "" {
+		caddyServerIP = saved.CaddyServerIP
+	}
+	runtime, err := loadSavedPlanRuntime(caddyServerIP, applyCaddyServerPort)
+	if err != nil {
+		return err
+	}
+	defer closePiholeSession(runtime)
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintln(out, StyleMuted.Render(fmt.Sprintf("Re-reading live state for plan %s…", path)))
+	entries, err := loadSavedPlanEntries(ctx, runtime, saved.CaddyServerIP, savedPlanTargets(saved), out)
+	if err != nil {
+		return err
+	}
+	if err := saved.Verify(entries); err != nil {
+		return fmt.Errorf("refusing to apply %s: %w; run 'sync plan' again and review the new plan", path, err)
+	}
+
+	if saved.Plan.Actions, err = enforceSyncPolicy(out, saved.Plan.Actions, entries, applyAllowMassDelete); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "%s  state unchanged, applying %d saved changes\n", SymOK, len(saved.Plan.Actions))
+	if len(saved.Plan.Actions) == 0 {
+		return nil
+	}
+	printSyncActions(out, saved.Plan.Actions)
+	result := syncplan.Apply(ctx, syncplan.NewClients(runtime.Clients), saved.Plan, syncplan.ApplyOptions{
+		Journal: syncplan.NewJournal(syncplan.DefaultJournalDir()),
+		Atomic:  atomic,
+	})
+	printApplyResult(out, result)
+	if !result.Success {
+		return fmt.Errorf("plan %s applied with %d error(s)", path, len(result.Errors))
+	}
+	return nil
+}
+
 type applyUI struct {
 	Styles tui.StyleConfig
 }
@@ -73,6 +157,20 @@ func (ui *applyUI) RenderApplyingMessage() string {
 	return ui.Styles.Info.Render(" 💾 Applying DNS changes... ")
 }
 
+// savedPlanTargets returns the targets a saved plan depends on: those it was
+// built for and those it has actions for.
+func savedPlanTargets(saved syncplan.SavedPlan) []string {
+	targets := append([]string(nil), saved.Targets...)
+	for _, action := range saved.Plan.Actions {
+		targets = append(targets, action.Service)
+	}
+	return targets
+}
+
 func init() {
 	rootCmd.AddCommand(applyCmd)
+	applyCmd.Flags().StringVar(&applyCaddyServerIP, "caddy-ip", "", "Caddy server IP (default: the one recorded in the plan)")
+	applyCmd.Flags().IntVar(&applyCaddyServerPort, "caddy-port", runtimeapp.DefaultCaddyServerPort, "Caddy admin API port")
+	applyCmd.Flags().StringVar(&applyAtomic, "atomic", "", "Roll back applied changes when one fails: hostname or run")
+	applyCmd.Flags().BoolVar(&applyAllowMassDelete, "allow-mass-delete", false, "Allow the plan to delete more records than sync_policy permits")
 }
@@ -23,6 +23,7 @@ var (
 	cpCFExcludeHostnames []string
 	cpCFDirectHostSuffix string
 	cpCFVerbose          bool
+	cpCFAllowMassDelete  bool
 )
 
 var caddyPushCloudflareCmd = &cobra.Command{
@@ -32,8 +33,13 @@ var caddyPushCloudflareCmd = &cobra.Command{
 Cloudflare tunnel as ingress rules, creating or updating the corresponding DNS CNAME
 records in the Cloudflare zone.
 
+With caddy.source set to "traefik" (and no --caddy-ip), hostnames are read from
+the Traefik API instead.
+
 Hostnames found in other tunnels in the same account are skipped (reported only).
-Hostnames in the default tunnel that are no longer in Caddy are removed.
+Hostnames in the default tunnel that are no longer in Caddy are removed. The
+sync_policy limits and protected globs apply to these removals; pass
+--allow-mass-delete to lift the limits for one run.
 
 Configuration is loaded from ~/.caddy-dns-sync.json (cloudflare section) or environment
 variables (CF_API_TOKEN, CF_ACCOUNT_ID, CF_ZONE_ID, CF_TUNNEL_ID, CF_CADDY_SERVICE_URL).`,
@@ -83,7 +89,23 @@ func runCaddyPushCloudflare(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("error creating Cloudflare client: %w", err)
 	}
 
-	caddyClient := api.NewCaddyClient(caddyIP, caddyPort)
+	var caddyClient api.HostnameMapSource = api.NewCaddyClient(caddyIP, caddyPort)
+	source := fmt.Sprintf("Caddy at %s:%d", caddyIP, caddyPort)
+	if cpCFCaddyIP == "" {
+		caddyCfg, err := config.LoadCaddyConfig()
+		if err != nil {
+			return fmt.Errorf("error loading Caddy configuration: %w", err)
+		}
+		if caddyCfg.Source == config.CaddySourceTraefik {
+			caddyClient = api.NewTraefikClient(caddyCfg.Traefik)
+			source = "Traefik at " + caddyCfg.Traefik.BaseURL
+		}
+	}
+
+	policy, err := loadSyncPolicy(cpCFAllowMassDelete)
+	if err != nil {
+		return err
+	}
 
 	options := sync2.CaddyToCloudflareSyncOptions{
 		DryRun:           cpCFDryRun,
@@ -92,12 +114,13 @@ func runCaddyPushCloudflare(cmd *cobra.Command, args []string) error {
 		ExcludeHostnames: cpCFExcludeHostnames,
 		DirectHostSuffix: cpCFDirectHostSuffix,
 		Verbose:          cpCFVerbose,
+		Policy:           policy,
 	}
 
 	if cpCFDryRun {
 		fmt.Fprintln(cmd.OutOrStdout(), "DRY RUN - no changes will be applied")
 	}
-	fmt.Fprintf(cmd.OutOrStdout(), "Fetching hostnames from Caddy at %s:%d...\n", caddyIP, caddyPort)
+	fmt.Fprintf(cmd.OutOrStdout(), "Fetching hostnames from %s...\n", source)
 
 	// Use signal-aware context so Ctrl+C cancels pending API calls.
 	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
@@ -212,4 +235,6 @@ func init() {
 			"Suffix for direct-to-service sibling Cloudflare hosts; set empty to disable")
 	caddyPushCloudflareCmd.Flags().
 		BoolVar(&cpCFVerbose, "verbose", false, "Show additional detail including skipped hostnames")
+	caddyPushCloudflareCmd.Flags().
+		BoolVar(&cpCFAllowMassDelete, "allow-mass-delete", false, "Allow a run to delete more records than sync_policy permits")
 }
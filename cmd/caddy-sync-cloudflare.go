@@ -21,6 +21,7 @@ var (
 	cfDirectOnly         bool
 	cfCaddyOnly          bool
 	cfPrompt             bool
+	cfAllowMassDelete    bool
 )
 
 // caddySyncCloudflareCmd represents the caddy-sync-cloudflare command
@@ -39,7 +40,10 @@ and creates DNS entries with two modes:
 2. Caddy Mode (service.caddy.example.com): Points to Caddy server for reverse proxy access
 
 This enables flexible routing where services can be accessed either directly or through Caddy,
-supporting both LAN optimization and external Cloudflare tunnel access patterns.`,
+supporting both LAN optimization and external Cloudflare tunnel access patterns.
+
+Removals follow the sync_policy limits and protected globs; pass
+--allow-mass-delete to lift the limits for one run.`,
 	RunE: runCaddySyncCloudflare,
 }
 
@@ -59,6 +63,11 @@ func runCaddySyncCloudflare(cmd *cobra.Command, args []string) error {
 		unboundClient.Prompt = true
 	}
 
+	policy, err := loadSyncPolicy(cfAllowMassDelete)
+	if err != nil {
+		return err
+	}
+
 	syncUI := sync2.NewSyncUI()
 	syncDirect := !cfCaddyOnly
 	syncCaddy := !cfDirectOnly
@@ -71,6 +80,7 @@ func runCaddySyncCloudflare(cmd *cobra.Command, args []string) error {
 			EntryDescription:   cfEntryDescription,
 			LegacyDescriptions: cfLegacyDescriptions,
 			Verbose:            verbose,
+			Policy:             policy,
 		},
 		DirectSubdomain: cfDirectSubdomain,
 		CaddySubdomain:  cfCaddySubdomain,
@@ -141,4 +151,6 @@ func init() {
 		BoolVar(&cfCaddyOnly, "caddy-only", false, "Sync only Caddy proxy entries (skip direct access entries)")
 	caddySyncCloudflareCmd.Flags().
 		BoolVar(&cfPrompt, "prompt", false, "Prompt before each API call (useful for debugging)")
+	caddySyncCloudflareCmd.Flags().
+		BoolVar(&cfAllowMassDelete, "allow-mass-delete", false, "Allow a run to delete more records than sync_policy permits")
 }
@@ -9,7 +9,10 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/jeeftor/caddy-dns-sync/internal/api"
+	runtimeapp "github.com/jeeftor/caddy-dns-sync/internal/app"
 	"github.com/jeeftor/caddy-dns-sync/internal/caddyeditor"
+	"github.com/jeeftor/caddy-dns-sync/internal/config"
 	"github.com/spf13/cobra"
 )
 
@@ -17,7 +20,7 @@ var caddyEditorCheckCmd = &cobra.Command{
 	Use:           "doctor",
 	Aliases:       []string{"caddy-editor-check"},
 	Short:         "Check config, paths, git, and commands — no files written",
-	Long:          `Verifies the caddy_editor config is correct and all paths/commands work.\nRuns validation and checks git connectivity, but makes NO edits, commits, or deploys.`,
+	Long:          `Verifies the caddy_editor config is correct and all paths/commands work.\nChecks the Caddy admin API (TCP, unix socket or mTLS), runs validation and checks\ngit connectivity, but makes NO edits, commits, or deploys.`,
 	RunE:          runCaddyEditorCheck,
 	SilenceUsage:  true,
 	SilenceErrors: true,
@@ -168,6 +171,29 @@ func runCaddyEditorCheck(cmd *cobra.Command, args []string) error {
 	kv("git_remote", or(cfg.GitRemote, "origin"), true)
 	kv("git_branch", or(cfg.GitBranch, "(auto-detect)"), true)
 
+	// ── Caddy admin API ───────────────────────────────────────────────────────
+	section("Caddy admin API (read-only)")
+	fmt.Fprintln(out)
+
+	caddyCfg, caddyErr := config.LoadCaddyConfig()
+	check("caddy config valid", caddyErr == nil, errStr(caddyErr))
+	if caddyErr == nil && caddyCfg.Source != "" && caddyCfg.Source != config.CaddySourceAdminAPI {
+		info("routes are read from " + StyleCode.Render(caddyCfg.Source) + "; admin API not checked")
+	} else if caddyErr == nil {
+		endpoint := runtimeapp.ResolveCaddyEndpoint(caddyCfg.ServerIP, caddyCfg.ServerPort, caddyCfg.Admin)
+		kv("endpoint", endpoint.String(), true)
+		if endpoint.Admin.UsesTLS() {
+			kv("client_cert", endpoint.Admin.ClientCert, true)
+			kv("ca_cert", or(endpoint.Admin.CACert, "(system roots)"), true)
+		}
+		client, clientErr := api.NewCaddyAdminClient(endpoint.ServerIP, endpoint.ServerPort, endpoint.Admin)
+		check("caddy admin client configured", clientErr == nil, errStr(clientErr))
+		if clientErr == nil {
+			_, getErr := client.GetConfig()
+			check("caddy admin API reachable", getErr == nil, errStr(getErr))
+		}
+	}
+
 	if cfg.RepoPath == "" {
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, docSummaryFail.Render("  Cannot continue without repo_path  "))
@@ -36,6 +36,8 @@ UnboundDNS (OPNSense):
   CADDY_DNS_SYNC_API_SECRET - API secret for OPNSense
   CADDY_DNS_SYNC_BASE_URL   - Base URL for OPNSense (e.g., https://10.0.0.1)
   CADDY_DNS_SYNC_INSECURE   - Set to "true" or "1" to skip SSL verification
+  CADDY_DNS_SYNC_DHCP_BACKEND - DHCP server for leases and reservations: "dnsmasq" (default) or "kea"
+  CADDY_DNS_SYNC_DNSMASQ_HOSTS - Set to "true" or "1" to sync Dnsmasq host overrides
 
   (Deprecated but still supported: UNBOUND_CLI_API_KEY, UNBOUND_CLI_API_SECRET,
    UNBOUND_CLI_BASE_URL, UNBOUND_CLI_INSECURE)
@@ -45,7 +47,150 @@ AdguardHome:
   ADGUARD_USERNAME       - Username for AdguardHome
   ADGUARD_PASSWORD       - Password for AdguardHome
   ADGUARD_BASE_URL       - Base URL for AdguardHome (e.g., http://10.0.0.10:3000)
-  ADGUARD_INSECURE       - Set to "true" or "1" to skip SSL verification`,
+  ADGUARD_INSECURE       - Set to "true" or "1" to skip SSL verification
+
+Pi-hole (v6):
+  PIHOLE_ENABLED         - Set to "true" to enable Pi-hole integration
+  PIHOLE_PASSWORD        - Web or application password (optional if none is set)
+  PIHOLE_BASE_URL        - Base URL for Pi-hole (e.g., http://10.0.0.11)
+  PIHOLE_INSECURE        - Set to "true" or "1" to skip SSL verification
+
+RFC 2136 dynamic updates (BIND, Knot, PowerDNS, Technitium):
+  RFC2136_ENABLED        - Set to "true" to enable RFC 2136 updates
+  RFC2136_SERVER         - Primary server address (e.g., 10.0.0.53 or 10.0.0.53:53)
+  RFC2136_ZONE           - Zone to update (e.g., home.example.com)
+  RFC2136_TSIG_KEY       - TSIG key name
+  RFC2136_TSIG_SECRET    - Base64 TSIG secret
+  RFC2136_TSIG_ALGORITHM - TSIG algorithm (default hmac-sha256)
+
+Docker label discovery (config file: "docker" section):
+  DOCKER_DISCOVERY_ENABLED - Set to true to add hostnames from container labels
+                             ("caddy=", "traefik.http.routers.*.rule") that Caddy
+                             does not serve yet
+  DOCKER_HOST              - Docker Engine address (default unix:///var/run/docker.sock)
+  DOCKER_DISCOVERY_NETWORK - Container network whose IP is used as the upstream
+
+Kubernetes discovery (config file: "kubernetes" section):
+  KUBERNETES_DISCOVERY_ENABLED - Set to true to add Ingress and HTTPRoute hostnames;
+                                 their DNS records point at the controller's
+                                 LoadBalancer IP
+  KUBECONFIG                   - kubeconfig file (default ~/.kube/config)
+  KUBERNETES_CONTEXT           - kubeconfig context (default current-context)
+  KUBERNETES_NAMESPACE         - Limit discovery to one namespace
+
+Static host manifest (config file: "manifest"):
+  CADDY_DNS_SYNC_MANIFEST - YAML or JSON file of hostnames Caddy never serves
+                            (printers, NAS, IPMI), each with a fixed "ip" or a
+                            "mac" whose DHCP lease is followed. They are synced
+                            and kept out of stale-record cleanup:
+                              hosts:
+                                - hostname: printer.home.example.com
+                                  ip: 10.0.0.50
+
+Sync safety policy (config file: "sync_policy" section):
+  CADDY_DNS_SYNC_MAX_DELETES        - Deletes one run may make (default 10, -1 disables)
+  CADDY_DNS_SYNC_MAX_DELETE_PERCENT - Share of a target's records one run may
+                                      delete (default 50, -1 disables)
+  CADDY_DNS_SYNC_PROTECTED_HOSTS    - Comma-separated hostname globs that sync
+                                      never updates or deletes
+                                      (e.g., "*.prod.example.com,router.lan")
+  A run over either limit is refused unless --allow-mass-delete is passed.
+
+Caddy route source (config file: "caddy.source" / "caddy.caddyfile" / "caddy.traefik"):
+  CADDY_SOURCE           - "admin_api" (default), "caddyfile" to plan from a
+                           Caddyfile without a running Caddy, or "traefik" to
+                           read routers and services from a Traefik API
+  CADDY_CADDYFILE        - Caddyfile to read (defaults to the caddy_editor one);
+                           run through 'caddy adapt' when caddy is on PATH
+  CADDY_TRAEFIK_URL      - Traefik API base URL (e.g., http://traefik.lan:8080)
+  CADDY_TRAEFIK_USERNAME - Basic auth username for the Traefik API (optional)
+  CADDY_TRAEFIK_PASSWORD - Basic auth password for the Traefik API (optional)
+
+Caddy IPv6 (config file: "caddy.server_ipv6"):
+  CADDY_SERVER_IPV6      - IPv6 address of the main Caddy server; its hostnames
+                           get AAAA records next to their A records in Unbound
+                           and AdGuard Home
+
+Caddy admin API transport (config file: "caddy.admin"):
+  CADDY_ADMIN_SOCKET      - Unix socket the admin API listens on
+                            (e.g., unix:///run/caddy/admin.sock); replaces
+                            server_ip:server_port for admin requests only
+  CADDY_ADMIN_CLIENT_CERT - Client certificate for Caddy's admin.remote (HTTPS)
+  CADDY_ADMIN_CLIENT_KEY  - Key for the client certificate
+  CADDY_ADMIN_CA_CERT     - CA that signed admin.remote's certificate
+                            (default: system roots)
+
+Additional Caddy servers (e.g. a DMZ Caddy) are listed under "caddy.servers";
+their hostnames are merged with the main server's, and each hostname's DNS
+records point at the "serve_ip" (default: server_ip) of the Caddy owning it:
+  "caddy": {
+    "server_ip": "10.0.0.15",
+    "servers": [{"name": "dmz", "server_ip": "10.50.0.2", "server_port": 2019, "serve_ip": "10.50.0.15"}]
+  }
+A hostname defined on more than one server is reported as a conflict.
+
+Additional Unbound instances (HA pairs, per-site firewalls) are configured
+only in the config file, as a list under "unbound_instances":
+  "unbound_instances": [
+    {"name": "fw-b", "api_key": "...", "api_secret": "...", "base_url": "https://10.0.0.2"},
+    {"name": "site-2", "api_key": "...", "api_secret": "...", "base_url": "https://10.2.0.1", "target_ip": "10.2.0.15"}
+  ]
+Each instance is synced as its own target ("unbound:<name>"); target_ip
+overrides the Caddy server IP for that instance's host overrides.
+
+DNS answer rules (split-horizon and bypass-Caddy hosts) are configured only
+in the config file, as a list under "answer_rules":
+  "answer_rules": [
+    {"hostname": "*.games.example.com", "answer": "upstream"},
+    {"handler": "layer4", "answer": "upstream"},
+    {"hostname": "nas.example.com", "answer": "192.168.20.5", "targets": ["unbound:iot"]}
+  ]
+A rule matches by hostname glob, Caddy handler type, or both. "answer" is
+"caddy" (the default answer), "upstream" (the reverse_proxy upstream IP) or
+a literal IP; "targets" limits the rule to the named sync targets. The first
+matching rule wins.
+
+Alias mode (config file: "alias_mode" section):
+  CADDY_DNS_SYNC_ALIAS_CANONICAL - Canonical hostname (e.g., caddy.lan) that gets
+                                   the Caddy server's A record; hostnames on the
+                                   main Caddy server become Unbound host aliases
+                                   and AdGuard CNAME-style rewrites pointing at
+                                   it, so a new Caddy IP rewrites one record.
+                                   Other targets keep per-hostname A records.
+
+Unbound domain overrides (query forwarders) are configured only in the config
+file, as a list under "domain_overrides":
+  "domain_overrides": [
+    {"domain": "corp.example.com", "servers": ["10.1.0.10", "10.1.0.11"]}
+  ]
+'sync unbound-domains' forwards each domain to each of its servers and
+removes the forwarders it created that are no longer listed.
+
+Inbound webhooks for the web UI are listed under "webhooks". A push signed
+with the hook's secret (GitHub and Gitea HMAC-SHA256 signatures) to
+POST /api/hooks/<name> pulls and deploys the Caddy editor repo ("deploy")
+and/or syncs DNS for "service" ("sync"); "branch" ignores other branches:
+  "webhooks": [
+    {"name": "gitea", "secret": "...", "deploy": true, "sync": true, "branch": "main"},
+    {"name": "caddy-reload", "secret": "...", "sync": true, "service": "unbound"}
+  ]
+Deliveries must be sent within 5 minutes of their timestamp (GitHub's
+repository.pushed_at, Gitea's repository.updated_at, or "timestamp" in other
+payloads) and are accepted once. Recent deliveries, their outcome and output
+are listed at GET /api/hooks, which requires the web session token.
+
+AdGuard Home replicas are listed under "adguard.instances" in the same way;
+"answer_override" replaces the Caddy server IP as the rewrite answer, on the
+primary or on any replica:
+  "adguard": {
+    "enabled": true, "base_url": "http://10.0.0.3", "username": "...", "password": "...",
+    "instances": [
+      {"name": "secondary", "base_url": "http://10.0.0.4", "username": "...", "password": "..."},
+      {"name": "iot", "base_url": "http://10.20.0.3", "username": "...", "password": "...", "answer_override": "10.20.0.15"}
+    ]
+  }
+Each replica is synced as "adguard:<name>"; 'status' and 'sync adguard'
+report hostnames the replicas disagree on.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Create UI component
 		configUI := newConfigUI()
@@ -255,6 +400,9 @@ AdguardHome:
 			Password: adguardPassword,
 			BaseURL:  adguardBaseURL,
 			Insecure: adguardInsecure,
+			// Replicas and answer overrides are only edited in the config file.
+			AnswerOverride: existingExtended.Adguard.AnswerOverride,
+			Instances:      existingExtended.Adguard.Instances,
 		}
 		extendedConfig.Cloudflare = config.CloudflareConfig{
 			Enabled:         cfEnabled,
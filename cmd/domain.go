@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/api"
+	"github.com/jeeftor/caddy-dns-sync/internal/config"
+	"github.com/jeeftor/caddy-dns-sync/internal/logging"
+	"github.com/jeeftor/caddy-dns-sync/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	domainPort        string
+	domainDescription string
+	domainDisabled    bool
+	domainServer      string
+	domainForce       bool
+)
+
+// addDomainCmd represents the add-domain command
+var addDomainCmd = &cobra.Command{
+	Use:   "add-domain <domain> <server>",
+	Short: "Add an Unbound domain override",
+	Long: `Add a domain override (query forwarder) to Unbound DNS.
+
+Queries for the domain and its subdomains are forwarded to the given DNS
+server instead of being resolved, e.g. an internal Active Directory zone.
+To have sync keep forwarders in place, list them under "domain_overrides"
+in the config file and run 'sync unbound-domains' instead.`,
+	Example: `  caddy-dns-sync add-domain corp.example.com 10.1.0.10
+  caddy-dns-sync add-domain corp.example.com 10.1.0.11 --port 5353 -D "DC2"`,
+	Args: cobra.ExactArgs(2),
+	RunE: runAddDomain,
+}
+
+// deleteDomainCmd represents the delete-domain command
+var deleteDomainCmd = &cobra.Command{
+	Use:     "delete-domain <domain|uuid>",
+	Short:   "Delete Unbound domain overrides",
+	Aliases: []string{"del-domain", "rm-domain"},
+	Long: `Delete domain overrides (query forwarders) from Unbound DNS.
+
+Pass the UUID of one override, or a domain to delete every override for it.
+Use --server to delete only the override forwarding to that server. Use
+'list unbound-domains' to find UUIDs.`,
+	Example: `  caddy-dns-sync delete-domain corp.example.com --server 10.1.0.11
+  caddy-dns-sync delete-domain 5c2e1f0a-0000-4000-8000-000000000000 --force`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDeleteDomain,
+}
+
+func runAddDomain(cmd *cobra.Command, args []string) error {
+	domainUI := newDomainUI()
+	override := api.DomainOverride{
+		Enabled:     "1",
+		Type:        api.DomainOverrideTypeForward,
+		Domain:      strings.ToLower(strings.TrimSuffix(args[0], ".")),
+		Server:      args[1],
+		Port:        domainPort,
+		Description: domainDescription,
+	}
+	if domainDisabled {
+		override.Enabled = "0"
+	}
+	if err := config.ValidateDomainOverrides([]config.DomainOverride{{Domain: override.Domain, Servers: []string{override.Server}}}); err != nil {
+		return err
+	}
+
+	client, err := newDomainClient()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(cmd.OutOrStdout(), domainUI.RenderInfo(fmt.Sprintf("Adding domain override for %s via %s...", override.Domain, override.Server)))
+	uuid, err := client.AddDomainOverride(override)
+	if err != nil {
+		logging.Error("Error adding domain override", "error", err)
+		return fmt.Errorf("error adding domain override: %w", err)
+	}
+
+	fmt.Fprintln(cmd.OutOrStdout(), domainUI.RenderInfo("Applying configuration..."))
+	if err := client.ApplyChanges(); err != nil {
+		logging.Error("Error applying changes", "error", err)
+		return fmt.Errorf("error applying changes: %w\nThe domain override was added but changes were not applied", err)
+	}
+
+	fmt.Fprintln(cmd.OutOrStdout(), domainUI.RenderSuccess(fmt.Sprintf("Domain override added successfully with UUID: %s", uuid)))
+	return nil
+}
+
+func runDeleteDomain(cmd *cobra.Command, args []string) error {
+	domainUI := newDomainUI()
+	client, err := newDomainClient()
+	if err != nil {
+		return err
+	}
+
+	overrides, err := client.GetDomainOverrides()
+	if err != nil {
+		logging.Error("Error fetching domain overrides", "error", err)
+		return fmt.Errorf("error fetching domain overrides: %w", err)
+	}
+	matches := filterDomainOverrides(overrides, args[0], domainServer)
+	if len(matches) == 0 {
+		return fmt.Errorf("no domain override found matching %s", args[0])
+	}
+
+	if !domainForce {
+		for _, override := range matches {
+			fmt.Fprintln(cmd.OutOrStdout(), domainUI.RenderInfo(fmt.Sprintf("%s -> %s (UUID: %s)", override.Domain, override.Server, override.UUID)))
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Delete %d domain override(s)? (y/N): ", len(matches))
+		var confirm string
+		if _, err := fmt.Fscanln(cmd.InOrStdin(), &confirm); err != nil {
+			fmt.Fprintln(cmd.OutOrStdout(), domainUI.RenderWarning("Deletion cancelled (no input)"))
+			return nil
+		}
+		if confirm != "y" && confirm != "Y" {
+			fmt.Fprintln(cmd.OutOrStdout(), domainUI.RenderWarning("Deletion cancelled"))
+			return nil
+		}
+	}
+
+	for _, override := range matches {
+		fmt.Fprintln(cmd.OutOrStdout(), domainUI.RenderInfo(fmt.Sprintf("Deleting domain override with UUID: %s", override.UUID)))
+		if err := client.DeleteDomainOverride(override.UUID); err != nil {
+			logging.Error("Error deleting domain override", "error", err, "uuid", override.UUID)
+			return fmt.Errorf("error deleting domain override: %w", err)
+		}
+	}
+
+	fmt.Fprintln(cmd.OutOrStdout(), domainUI.RenderInfo("Applying configuration..."))
+	if err := client.ApplyChanges(); err != nil {
+		logging.Error("Error applying changes", "error", err)
+		return fmt.Errorf("error applying changes: %w\nThe domain overrides were deleted but changes were not applied", err)
+	}
+
+	fmt.Fprintln(cmd.OutOrStdout(), domainUI.RenderSuccess(fmt.Sprintf("Deleted %d domain override(s)", len(matches))))
+	return nil
+}
+
+// filterDomainOverrides returns the override with UUID target, or else the
+// overrides for domain target, optionally only those forwarding to server.
+func filterDomainOverrides(overrides []api.DomainOverride, target, server string) []api.DomainOverride {
+	for _, override := range overrides {
+		if override.UUID == target {
+			return []api.DomainOverride{override}
+		}
+	}
+	domain := strings.TrimSuffix(target, ".")
+	var matches []api.DomainOverride
+	for _, override := range overrides {
+		if !strings.EqualFold(override.Domain, domain) {
+			continue
+		}
+		if server != "" && override.Server != server {
+			continue
+		}
+		matches = append(matches, override)
+	}
+	return matches
+}
+
+func newDomainClient() (*api.Client, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		logging.Error("Error loading configuration", "error", err)
+		return nil, fmt.Errorf("error loading configuration: %w\nPlease run 'config' command to set up API access", err)
+	}
+	return api.NewClient(cfg), nil
+}
+
+type domainUI struct {
+	*ui.BaseUI
+}
+
+func newDomainUI() *domainUI {
+	return &domainUI{ui.NewBaseUI()}
+}
+
+func init() {
+	rootCmd.AddCommand(addDomainCmd)
+	rootCmd.AddCommand(deleteDomainCmd)
+
+	addDomainCmd.Flags().StringVar(&domainPort, "port", "", "Port of the DNS server (default 53)")
+	addDomainCmd.Flags().StringVarP(&domainDescription, "description", "D", "", "Description")
+	addDomainCmd.Flags().BoolVar(&domainDisabled, "disabled", false, "Disable this domain override")
+	deleteDomainCmd.Flags().StringVar(&domainServer, "server", "", "Delete only the override forwarding to this server")
+	deleteDomainCmd.Flags().BoolVarP(&domainForce, "force", "f", false, "Force deletion without confirmation")
+}
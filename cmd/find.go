@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/jeeftor/caddy-dns-sync/internal/api"
+	"github.com/jeeftor/caddy-dns-sync/internal/commands"
 	"github.com/jeeftor/caddy-dns-sync/internal/config"
 	"github.com/jeeftor/caddy-dns-sync/internal/logging"
 	"github.com/jeeftor/caddy-dns-sync/internal/ui"
@@ -22,11 +23,12 @@ var (
 // findCmd represents the find command
 var findCmd = &cobra.Command{
 	Use:   "find",
-	Short: "Find DNS overrides by host, domain, or both",
-	Long: `Find DNS overrides by host, domain, or both.
+	Short: "Find DNS overrides and aliases by host, domain, or both",
+	Long: `Find DNS overrides and host aliases by host, domain, or both.
 
-This command searches for DNS overrides based on the specified criteria.
-It can be used to find the UUID of an entry for use in other commands.
+This command searches for DNS overrides, and the host aliases under them, based
+on the specified criteria. It can be used to find the UUID of an entry for use
+in other commands.
 
 Examples:
   caddy-dns-sync find --host test
@@ -64,11 +66,21 @@ func runFind(cmd *cobra.Command, args []string) error {
 		}
 		return fmt.Errorf("error fetching overrides: %w", err)
 	}
+	aliases, err := client.GetHostAliases()
+	if err != nil {
+		if logging.GetLogLevel() == logging.LogLevelDebug {
+			logging.Error("Error fetching host aliases", "error", err)
+		}
+		return fmt.Errorf("error fetching host aliases: %w", err)
+	}
 
-	matches := filterOverrides(overrides, findHost, findDomain)
-	if len(matches) == 0 {
+	matches := commands.UnboundListing{
+		Overrides: filterOverrides(overrides, findHost, findDomain),
+		Aliases:   filterAliases(aliases, findHost, findDomain),
+	}
+	if len(matches.Overrides) == 0 && len(matches.Aliases) == 0 {
 		if findJsonOutput {
-			fmt.Fprintln(cmd.OutOrStdout(), "[]")
+			fmt.Fprintln(cmd.OutOrStdout(), findUI.RenderJSON(matches))
 			return nil
 		}
 		if scriptOutput {
@@ -83,13 +95,16 @@ func runFind(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 	if scriptOutput {
-		for _, override := range matches {
+		for _, override := range matches.Overrides {
 			fmt.Fprintln(cmd.OutOrStdout(), override.UUID)
 		}
+		for _, alias := range matches.Aliases {
+			fmt.Fprintln(cmd.OutOrStdout(), alias.UUID)
+		}
 		return nil
 	}
 
-	fmt.Fprintln(cmd.OutOrStdout(), findUI.RenderMatches(matches))
+	fmt.Fprintln(cmd.OutOrStdout(), findUI.RenderMatches(matches, overrides))
 	return nil
 }
 
@@ -114,6 +129,20 @@ func filterOverrides(overrides []api.DNSOverride, host, domain string) []api.DNS
 	return matches
 }
 
+func filterAliases(aliases []api.HostAlias, host, domain string) []api.HostAlias {
+	var matches []api.HostAlias
+	for _, alias := range aliases {
+		if host != "" && !strings.EqualFold(alias.Hostname, host) {
+			continue
+		}
+		if domain != "" && !strings.EqualFold(alias.Domain, domain) {
+			continue
+		}
+		matches = append(matches, alias)
+	}
+	return matches
+}
+
 type findUI struct {
 	*ui.BaseUI
 }
@@ -127,18 +156,32 @@ func (ui *findUI) RenderSearchingMessage() string {
 }
 
 func (ui *findUI) RenderNoMatches() string {
-	return ui.RenderWarning("No matching DNS overrides found.")
+	return ui.RenderWarning("No matching DNS overrides or aliases found.")
 }
 
-func (ui *findUI) RenderMatches(overrides []api.DNSOverride) string {
-	var sb strings.Builder
+// RenderMatches lists matching overrides, then matching aliases with the
+// hostname of the override they belong to, looked up in overrides.
+func (ui *findUI) RenderMatches(matches commands.UnboundListing, overrides []api.DNSOverride) string {
+	parents := make(map[string]string, len(overrides))
 	for _, o := range overrides {
+		parents[o.UUID] = o.Host + "." + o.Domain
+	}
+
+	var sb strings.Builder
+	for _, o := range matches.Overrides {
 		sb.WriteString(fmt.Sprintf("%s.%s -> %s (UUID: %s)\n", o.Host, o.Domain, o.Server, o.UUID))
 	}
+	for _, a := range matches.Aliases {
+		parent := parents[a.Host]
+		if parent == "" {
+			parent = a.Host
+		}
+		sb.WriteString(fmt.Sprintf("%s.%s -> alias of %s (UUID: %s)\n", a.Hostname, a.Domain, parent, a.UUID))
+	}
 	return sb.String()
 }
 
-func (ui *findUI) RenderJSON(matches []api.DNSOverride) string {
+func (ui *findUI) RenderJSON(matches commands.UnboundListing) string {
 	data, err := json.MarshalIndent(matches, "", "  ")
 	if err != nil {
 		return ui.RenderError(fmt.Errorf("failed to marshal JSON: %w", err))
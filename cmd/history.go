@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/syncplan"
+	"github.com/spf13/cobra"
+)
+
+var undoDryRun bool
+
+// historyCmd lists journaled sync runs
+var historyCmd = &cobra.Command{
+	Use:   "history [run-id]",
+	Short: "List applied sync runs, or show the changes made by one",
+	Long: `List the sync runs recorded in the run journal, newest first.
+
+Every apply records each action and the record it replaced before touching a
+DNS service, so a run can later be reverted with 'undo <run-id>'. Pass a run
+ID to see its individual changes.`,
+	Example: `  caddy-dns-sync history
+  caddy-dns-sync history 20260101T120000Z-1a2b3c4d`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runHistory,
+}
+
+// undoCmd reverts a journaled sync run
+var undoCmd = &cobra.Command{
+	Use:   "undo <run-id>",
+	Short: "Revert the changes made by a sync run",
+	Long: `Revert a sync run recorded in the run journal.
+
+Each applied change is inverted, last change first: added records are
+deleted, updated records get their previous value back, and deleted records
+and Cloudflare ingress rules are re-created. DHCP reservations and Pi-hole
+CNAME records cannot be restored and are reported instead, as are records
+changed since the run, which are left alone. The undo is itself journaled,
+and a run can only be undone once; an undo that fails part-way can be run
+again to revert the rest.`,
+	Example: `  caddy-dns-sync undo 20260101T120000Z-1a2b3c4d --dry-run
+  caddy-dns-sync undo 20260101T120000Z-1a2b3c4d`,
+	Args: cobra.ExactArgs(1),
+	RunE: runUndo,
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	journal := syncplan.NewJournal(syncplan.DefaultJournalDir())
+	out := cmd.OutOrStdout()
+	if len(args) == 1 {
+		run, err := journal.Read(args[0])
+		if err != nil {
+			return err
+		}
+		printRunSteps(out, run)
+		return nil
+	}
+
+	runs, err := journal.List()
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		fmt.Fprintln(out, StyleMuted.Render("No sync runs recorded yet."))
+		return nil
+	}
+	for _, run := range runs {
+		applied, failed := countRunSteps(run)
+		line := fmt.Sprintf("%s  %s  %d applied", run.ID, run.StartedAt.Local().Format(time.DateTime), applied)
+		if failed > 0 {
+			line += fmt.Sprintf(", %d failed", failed)
+		}
+		switch {
+		case run.UndoOf != "":
+			line += StyleMuted.Render("  undo of " + run.UndoOf)
+		case run.UndoneBy != "":
+			line += StyleMuted.Render("  undone by " + run.UndoneBy)
+		}
+		fmt.Fprintln(out, line)
+	}
+	return nil
+}
+
+func runUndo(cmd *cobra.Command, args []string) error {
+	journal := syncplan.NewJournal(syncplan.DefaultJournalDir())
+	run, err := journal.Read(args[0])
+	if err != nil {
+		return err
+	}
+	plan, warnings, err := syncplan.UndoPlan(run)
+	if err != nil {
+		return err
+	}
+
+	if !undoDryRun {
+		releaseLock, err := acquireSyncLockWithWait()
+		if err != nil {
+			return err
+		}
+		defer releaseLock()
+	}
+
+	runtime, err := loadSavedPlanRuntime("", 0)
+	if err != nil {
+		return err
+	}
+	defer closePiholeSession(runtime)
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+
+	clients := syncplan.NewClients(runtime.Clients)
+	plan, skipped, err := syncplan.CheckUndo(ctx, clients, plan)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	for _, warning := range warnings {
+		fmt.Fprintf(out, "  %s  cannot undo %s\n", SymWarn, warning)
+	}
+	for _, warning := range skipped {
+		fmt.Fprintf(out, "  %s  skipping %s\n", SymWarn, warning)
+	}
+	fmt.Fprintf(out, "%s  %d changes to revert run %s\n", SymOK, len(plan.Actions), run.ID)
+	if len(plan.Actions) == 0 {
+		return nil
+	}
+	printSyncActions(out, plan.Actions)
+	if undoDryRun {
+		fmt.Fprintln(out, StyleWarn.Render("Dry run: no changes applied"))
+		return nil
+	}
+
+	result := syncplan.Apply(ctx, clients, plan, syncplan.ApplyOptions{
+		Journal: journal,
+		UndoOf:  run.ID,
+	})
+	printApplyResult(out, result)
+	if !result.Success {
+		return fmt.Errorf("undo of %s finished with %d error(s); run it again to retry the rest", run.ID, len(result.Errors))
+	}
+	if result.RunID != "" {
+		if err := journal.MarkUndone(run.ID, result.RunID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printApplyResult reports the outcome of a journaled apply and how to
+// revert it.
+func printApplyResult(out io.Writer, result *syncplan.Result) {
+	for _, msg := range result.Errors {
+		fmt.Fprintf(out, "  %s  %s\n", SymFail, msg)
+	}
+	for _, rollback := range result.Rollbacks {
+		scope := "run"
+		if rollback.Hostname != "" {
+			scope = rollback.Hostname
+		}
+		icon := SymWarn
+		if !rollback.Success {
+			icon = SymFail
+		}
+		fmt.Fprintf(out, "  %s  rolled back %d change(s) for %s\n", icon, len(rollback.Actions), scope)
+	}
+	fmt.Fprintf(out, "Added: %d  Updated: %d  Deleted: %d\n", result.ItemsAdded, result.ItemsUpdated, result.ItemsDeleted)
+	if result.RunID != "" {
+		fmt.Fprintln(out, StyleMuted.Render(fmt.Sprintf("Recorded as run %s (revert with: caddy-dns-sync undo %s)", result.RunID, result.RunID)))
+	}
+}
+
+func printRunSteps(out io.Writer, run syncplan.Run) {
+	fmt.Fprintf(out, "Run %s started %s\n", run.ID, run.StartedAt.Local().Format(time.DateTime))
+	if run.UndoOf != "" {
+		fmt.Fprintf(out, "Undo of run %s\n", run.UndoOf)
+	}
+	if run.UndoneBy != "" {
+		fmt.Fprintf(out, "Undone by run %s\n", run.UndoneBy)
+	}
+	for _, step := range run.Steps {
+		icon := SymOK
+		switch {
+		case step.Error != "":
+			icon = SymFail
+		case !step.Applied:
+			icon = SymWarn
+		}
+		line := fmt.Sprintf("%-6s %s", step.Action.Type, step.Action.Hostname)
+		switch {
+		case step.Prior.Exists && step.Prior.Service != "":
+			line += fmt.Sprintf(" (was %s)", step.Prior.Service)
+		case step.Prior.Exists:
+			line += fmt.Sprintf(" (was %s)", step.Prior.IP)
+		}
+		if step.Error != "" {
+			line += ": " + step.Error
+		}
+		fmt.Fprintf(out, "  %s  %s  %s\n", icon, StyleInfo.Render(step.Action.Service), line)
+	}
+}
+
+func countRunSteps(run syncplan.Run) (applied, failed int) {
+	for _, step := range run.Steps {
+		switch {
+		case step.Applied:
+			applied++
+		case step.Error != "":
+			failed++
+		}
+	}
+	return applied, failed
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(undoCmd)
+	undoCmd.Flags().BoolVar(&undoDryRun, "dry-run", false, "Show the changes that would be reverted without applying them")
+}
@@ -7,18 +7,20 @@ import (
 	"path/filepath"
 	"strings"
 	"text/template"
+	"time"
 
+	"github.com/jeeftor/caddy-dns-sync/internal/watch"
 	"github.com/spf13/cobra"
 )
 
 const unitTemplate = `[Unit]
-Description=CaddySync Web UI
+Description={{.Description}}
 After=network.target
 
 [Service]
 Type=simple
 Environment=HOME={{.HomeDir}}
-ExecStart={{.BinPath}} web --host {{.Host}} --port {{.Port}}{{if .Origin}} --origin {{.Origin}}{{end}}
+ExecStart={{.BinPath}} {{.Args}}
 Restart=on-failure
 RestartSec=5
 
@@ -26,26 +28,49 @@ RestartSec=5
 WantedBy=multi-user.target
 `
 
-const unitPath = "/etc/systemd/system/caddy-sync.service"
+const (
+	serviceName      = "caddy-sync"
+	watchServiceName = "caddy-sync-watch"
+)
 
 var (
-	installHost   string
-	installPort   int
-	installOrigin string
-	installStart  bool
+	installHost            string
+	installPort            int
+	installOrigin          string
+	installStart           bool
+	installWatch           bool
+	installInterval        time.Duration
+	installResync          time.Duration
+	installMaxBackoff      time.Duration
+	installSyncService     string
+	installAllowMassDelete bool
+	uninstallWatch         bool
 )
 
+// unitPath returns the unit file of the named service.
+func unitPath(name string) string {
+	return "/etc/systemd/system/" + name + ".service"
+}
+
 var installServiceCmd = &cobra.Command{
 	Use:   "install-service",
 	Short: "Install caddy-sync as a systemd service",
-	Long:  `Writes a systemd unit file and enables caddy-sync web as a service.`,
-	RunE:  runInstallService,
+	Long: `Writes a systemd unit file and enables caddy-sync web as a service.
+
+With --watch, installs 'watch' as the separate caddy-sync-watch service
+instead, keeping DNS in sync whenever Caddy's config changes. The --interval,
+--resync, --max-backoff, --service and --allow-mass-delete flags are passed
+through to it.`,
+	Example: `  caddy-dns-sync install-service --host 0.0.0.0 --start
+  caddy-dns-sync install-service --watch --interval 15s --resync 30m --start
+  caddy-dns-sync install-service --watch --service unbound --max-backoff 10m`,
+	RunE: runInstallService,
 }
 
 var uninstallServiceCmd = &cobra.Command{
 	Use:   "uninstall-service",
 	Short: "Remove the caddy-sync systemd service",
-	Long:  `Stops, disables, and removes the caddy-sync systemd unit file.`,
+	Long:  `Stops, disables, and removes the caddy-sync systemd unit file, or the caddy-sync-watch unit with --watch.`,
 	RunE:  runUninstallService,
 }
 
@@ -57,6 +82,23 @@ func init() {
 	installServiceCmd.Flags().IntVar(&installPort, "port", 8080, "port for the web server")
 	installServiceCmd.Flags().StringVar(&installOrigin, "origin", "", "allowed Origin for browser mutations (e.g. https://caddy-sync.example.com)")
 	installServiceCmd.Flags().BoolVar(&installStart, "start", false, "start the service immediately after installing")
+	installServiceCmd.Flags().BoolVar(&installWatch, "watch", false, "install the 'watch' service instead of the web UI")
+	installServiceCmd.Flags().DurationVar(&installInterval, "interval", watch.DefaultPollInterval, "with --watch, how often to check Caddy for config changes")
+	installServiceCmd.Flags().DurationVar(&installResync, "resync", watch.DefaultResyncInterval, "with --watch, how often to run a full resync")
+	installServiceCmd.Flags().DurationVar(&installMaxBackoff, "max-backoff", watch.DefaultMaxBackoff, "with --watch, longest wait between retries of a failing run")
+	installServiceCmd.Flags().StringVar(&installSyncService, "service", "all", "with --watch, service to sync (all, unbound, adguard, pihole, ...)")
+	installServiceCmd.Flags().BoolVar(&installAllowMassDelete, "allow-mass-delete", false, "with --watch, allow runs to delete more records than sync_policy permits")
+	uninstallServiceCmd.Flags().BoolVar(&uninstallWatch, "watch", false, "remove the 'watch' service instead of the web UI")
+}
+
+// watchExecArgs returns the arguments the watch service runs with.
+func watchExecArgs() string {
+	args := fmt.Sprintf("watch --interval %s --resync %s --max-backoff %s --service %s",
+		installInterval, installResync, installMaxBackoff, installSyncService)
+	if installAllowMassDelete {
+		args += " --allow-mass-delete"
+	}
+	return args
 }
 
 func runInstallService(cmd *cobra.Command, args []string) error {
@@ -74,7 +116,20 @@ func runInstallService(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("parse unit template: %w", err)
 	}
 
-	f, err := os.OpenFile(unitPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	name := serviceName
+	description := "CaddySync Web UI"
+	execArgs := fmt.Sprintf("web --host %s --port %d", installHost, installPort)
+	if installOrigin != "" {
+		execArgs += " --origin " + installOrigin
+	}
+	if installWatch {
+		name = watchServiceName
+		description = "CaddySync DNS watcher"
+		execArgs = watchExecArgs()
+	}
+	path := unitPath(name)
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
 	if err != nil {
 		return fmt.Errorf("write unit file (are you root?): %w", err)
 	}
@@ -82,20 +137,19 @@ func runInstallService(cmd *cobra.Command, args []string) error {
 
 	homeDir, _ := os.UserHomeDir()
 	if err := tmpl.Execute(f, struct {
-		BinPath string
-		Host    string
-		Port    int
-		Origin  string
-		HomeDir string
-	}{binPath, installHost, installPort, installOrigin, homeDir}); err != nil {
+		Description string
+		BinPath     string
+		Args        string
+		HomeDir     string
+	}{description, binPath, execArgs, homeDir}); err != nil {
 		return fmt.Errorf("render unit file: %w", err)
 	}
 	o := cmd.OutOrStdout()
-	fmt.Fprintf(o, "  %s  Wrote %s\n", SymOK, StyleCode.Render(unitPath))
+	fmt.Fprintf(o, "  %s  Wrote %s\n", SymOK, StyleCode.Render(path))
 
 	for _, sc := range [][]string{
 		{"systemctl", "daemon-reload"},
-		{"systemctl", "enable", "caddy-sync"},
+		{"systemctl", "enable", name},
 	} {
 		if out, err := exec.Command(sc[0], sc[1:]...).CombinedOutput(); err != nil {
 			return fmt.Errorf("run %v: %w\n%s", sc, err, out)
@@ -105,22 +159,28 @@ func runInstallService(cmd *cobra.Command, args []string) error {
 
 	if installStart {
 		// Use restart so the new unit file is always picked up, even if already running
-		if out, err := exec.Command("systemctl", "restart", "caddy-sync").CombinedOutput(); err != nil {
+		if out, err := exec.Command("systemctl", "restart", name).CombinedOutput(); err != nil {
 			return fmt.Errorf("restart service: %w\n%s", err, out)
 		}
-		fmt.Fprintf(o, "  %s  %s\n", SymOK, StyleMuted.Render("systemctl restart caddy-sync"))
+		fmt.Fprintf(o, "  %s  %s\n", SymOK, StyleMuted.Render("systemctl restart "+name))
 	}
 
 	fmt.Fprintf(o, "\n  %s  Service installed.  To start: %s\n",
-		SymOK, StyleCode.Render("systemctl start caddy-sync"))
+		SymOK, StyleCode.Render("systemctl start "+name))
 	return nil
 }
 
 func runUninstallService(cmd *cobra.Command, args []string) error {
+	name := serviceName
+	if uninstallWatch {
+		name = watchServiceName
+	}
+	path := unitPath(name)
+
 	o := cmd.OutOrStdout()
 	for _, sc := range [][]string{
-		{"systemctl", "stop", "caddy-sync"},
-		{"systemctl", "disable", "caddy-sync"},
+		{"systemctl", "stop", name},
+		{"systemctl", "disable", name},
 	} {
 		out, err := exec.Command(sc[0], sc[1:]...).CombinedOutput()
 		if err != nil {
@@ -130,10 +190,10 @@ func runUninstallService(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	if err := os.Remove(unitPath); err != nil && !os.IsNotExist(err) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("remove unit file: %w", err)
 	}
-	fmt.Fprintf(o, "  %s  Removed %s\n", SymOK, StyleCode.Render(unitPath))
+	fmt.Fprintf(o, "  %s  Removed %s\n", SymOK, StyleCode.Render(path))
 
 	if out, err := exec.Command("systemctl", "daemon-reload").CombinedOutput(); err != nil {
 		return fmt.Errorf("daemon-reload: %w\n%s", err, out)
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWatchExecArgsPassesWatchFlagsThrough(t *testing.T) {
+	previousInterval, previousResync, previousMaxBackoff := installInterval, installResync, installMaxBackoff
+	previousService, previousAllowMassDelete := installSyncService, installAllowMassDelete
+	t.Cleanup(func() {
+		installInterval, installResync, installMaxBackoff = previousInterval, previousResync, previousMaxBackoff
+		installSyncService, installAllowMassDelete = previousService, previousAllowMassDelete
+	})
+	installInterval, installResync, installMaxBackoff = 15*time.Second, 30*time.Minute, 10*time.Minute
+	installSyncService, installAllowMassDelete = "unbound", true
+
+	want := "watch --interval 15s --resync 30m0s --max-backoff 10m0s --service unbound --allow-mass-delete"
+	if got := watchExecArgs(); got != want {
+		t.Fatalf("watchExecArgs() = %q, want %q", got, want)
+	}
+}
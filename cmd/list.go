@@ -23,8 +23,11 @@ var listCmd = &cobra.Command{
 
 Available subcommands:
   all      - Show 3-way sync status across all services
-  unbound  - List UnboundDNS host overrides
+  unbound  - List UnboundDNS host overrides and aliases
+  unbound-domains - List UnboundDNS domain overrides (query forwarders)
   adguard  - List AdguardHome DNS rewrites
+  pihole   - List Pi-hole local DNS records
+  rfc2136  - List A records in the RFC 2136 zone
   dhcp     - List DNSMasq DHCP leases
   caddy    - List Caddy reverse proxy routes`,
 }
@@ -58,12 +61,13 @@ interactive dashboard but outputs a static table for quick status checks.`,
 var unboundCmd = &cobra.Command{
 	Use:     "unbound",
 	Aliases: []string{"u"},
-	Short:   "List UnboundDNS host overrides",
-	Long: `List all DNS host overrides from UnboundDNS.
+	Short:   "List UnboundDNS host overrides and aliases",
+	Long: `List all DNS host overrides and host aliases from UnboundDNS.
 
-This command retrieves all host override entries from the OPNSense UnboundDNS API
-and displays them in a table format. You can also output the results in JSON format
-using the --json flag.`,
+This command retrieves all host override entries, and the aliases that answer with
+an override's records, from the OPNSense UnboundDNS API and displays them in a table
+format. You can also output the results in JSON format using the --json flag, which
+prints an object with "overrides" and "aliases" lists.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		source := commands.NewUnboundDataSource()
 		runner := commands.NewListCommandRunner(source)
@@ -79,6 +83,30 @@ using the --json flag.`,
 	},
 }
 
+// unboundDomainsCmd lists Unbound domain overrides
+var unboundDomainsCmd = &cobra.Command{
+	Use:   "unbound-domains",
+	Short: "List UnboundDNS domain overrides",
+	Long: `List all domain overrides (query forwarders) from UnboundDNS.
+
+This command retrieves the domains OPNSense UnboundDNS forwards to other DNS
+servers and displays them in a table format. You can also output the results
+in JSON format using the --json flag.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		source := commands.NewUnboundDomainDataSource()
+		runner := commands.NewListCommandRunner(source)
+		runner.SetJSONOutput(listJsonOutput)
+		runner.SetQuietMode(listQuietMode)
+
+		if err := runner.Run(); err != nil {
+			logging.Error("Error listing Unbound domain overrides", "error", err)
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return err
+		}
+		return nil
+	},
+}
+
 // adguardCmd lists AdguardHome DNS rewrites
 var adguardCmd = &cobra.Command{
 	Use:     "adguard",
@@ -106,6 +134,57 @@ Note: AdguardHome must be enabled in the configuration for this command to work.
 	},
 }
 
+// piholeCmd lists Pi-hole local DNS records
+var piholeCmd = &cobra.Command{
+	Use:     "pihole",
+	Aliases: []string{"p"},
+	Short:   "List Pi-hole local DNS records",
+	Long: `List all local DNS host and CNAME records from Pi-hole.
+
+This command retrieves the dns.hosts and dns.cnameRecords settings from the
+Pi-hole v6 API and displays them in a table format. You can also output the
+results in JSON format using the --json flag.
+
+Note: Pi-hole must be enabled in the configuration for this command to work.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		source := commands.NewPiholeDataSource()
+		runner := commands.NewListCommandRunner(source)
+		runner.SetJSONOutput(listJsonOutput)
+		runner.SetQuietMode(listQuietMode)
+
+		if err := runner.Run(); err != nil {
+			logging.Error("Error listing Pi-hole records", "error", err)
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return err
+		}
+		return nil
+	},
+}
+
+// rfc2136Cmd lists A records in the RFC 2136 zone
+var rfc2136Cmd = &cobra.Command{
+	Use:   "rfc2136",
+	Short: "List A records in the RFC 2136 zone",
+	Long: `List all A records in the configured RFC 2136 zone.
+
+This command transfers the zone (AXFR) from the primary using the configured TSIG
+key and shows each A record along with whether it carries caddy-dns-sync's TXT
+ownership marker. You can also output the results in JSON format using the --json flag.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		source := commands.NewRFC2136DataSource()
+		runner := commands.NewListCommandRunner(source)
+		runner.SetJSONOutput(listJsonOutput)
+		runner.SetQuietMode(listQuietMode)
+
+		if err := runner.Run(); err != nil {
+			logging.Error("Error listing RFC 2136 records", "error", err)
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return err
+		}
+		return nil
+	},
+}
+
 // dhcpCmd lists DNSMasq DHCP leases
 var dhcpCmd = &cobra.Command{
 	Use:     "dhcp",
@@ -164,7 +243,10 @@ func init() {
 	// Add subcommands
 	listCmd.AddCommand(allCmd)
 	listCmd.AddCommand(unboundCmd)
+	listCmd.AddCommand(unboundDomainsCmd)
 	listCmd.AddCommand(adguardCmd)
+	listCmd.AddCommand(piholeCmd)
+	listCmd.AddCommand(rfc2136Cmd)
 	listCmd.AddCommand(dhcpCmd)
 	listCmd.AddCommand(caddyCmd)
 
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/jeeftor/caddy-dns-sync/internal/api"
 	runtimeapp "github.com/jeeftor/caddy-dns-sync/internal/app"
 	"github.com/spf13/cobra"
 )
@@ -55,7 +56,11 @@ func runQuery(cmd *cobra.Command, args []string) error {
 
 	// ── Caddy ──────────────────────────────────────────────────────────────────
 	if all || want["caddy"] {
-		details, err := runtime.Clients.Caddy.GetHostnameDetails()
+		var source api.HostnameSource = runtime.Clients.Caddy
+		if runtime.Clients.CaddySource != nil {
+			source = runtime.Clients.CaddySource
+		}
+		details, err := source.GetHostnameDetails()
 		if err != nil {
 			result["caddy"] = map[string]any{"error": err.Error()}
 		} else if queryHostname != "" {
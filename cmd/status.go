@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"sort"
 	"strings"
 	"text/tabwriter"
 
@@ -11,6 +13,7 @@ import (
 	"github.com/jeeftor/caddy-dns-sync/internal/logging"
 	"github.com/jeeftor/caddy-dns-sync/internal/models"
 	"github.com/jeeftor/caddy-dns-sync/internal/status"
+	"github.com/jeeftor/caddy-dns-sync/internal/syncplan"
 	"github.com/spf13/cobra"
 )
 
@@ -55,7 +58,8 @@ func runStatus(cmd *cobra.Command, _ []string) error {
 	defer stop()
 
 	entries, report, err := status.LoadEntries(ctx, runtime.Clients, status.Options{
-		CaddyServerIP: runtime.CaddyEndpoint.ServerIP,
+		CaddyServerIP:   runtime.CaddyEndpoint.ServerIP,
+		CaddyServerIPv6: runtime.CaddyEndpoint.ServerIPv6,
 	})
 	if err != nil {
 		return fmt.Errorf("error loading data: %w", err)
@@ -115,12 +119,29 @@ func runStatus(cmd *cobra.Command, _ []string) error {
 	fmt.Fprintln(out, StyleSection.Render("── Entries ──────────────────────────────────────────────────"))
 	fmt.Fprintln(out)
 
+	// One extra column per additional Unbound instance, after UNBOUND, and
+	// per AdGuard replica, after ADGUARD.
+	instanceNames := make([]string, 0, len(runtime.Clients.UnboundInstances))
+	instanceHeaders := ""
+	for _, instance := range runtime.Clients.UnboundInstances {
+		instanceNames = append(instanceNames, syncplan.UnboundInstancePrefix+instance.Name)
+		instanceHeaders += StyleMuted.Render(strings.ToUpper(instance.Name)) + "\t"
+	}
+	adguardNames := make([]string, 0, len(runtime.Clients.AdguardInstances))
+	adguardHeaders := ""
+	for _, instance := range runtime.Clients.AdguardInstances {
+		adguardNames = append(adguardNames, syncplan.AdguardInstancePrefix+instance.Name)
+		adguardHeaders += StyleMuted.Render(strings.ToUpper(instance.Name)) + "\t"
+	}
+
 	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
-	fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+	fmt.Fprintf(tw, "%s\t%s\t%s\t%s%s\t%s%s\t%s\n",
 		StyleMuted.Render("HOSTNAME"),
 		StyleMuted.Render("STATUS"),
 		StyleMuted.Render("UNBOUND"),
+		instanceHeaders,
 		StyleMuted.Render("ADGUARD"),
+		adguardHeaders,
 		StyleMuted.Render("DHCP"),
 		StyleMuted.Render("CLOUDFLARE"),
 	)
@@ -137,11 +158,24 @@ func runStatus(cmd *cobra.Command, _ []string) error {
 			hostname = StyleWarn.Render(hostname)
 		}
 
-		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+		instanceCells := ""
+		for _, name := range instanceNames {
+			instanceStatus := e.TargetStatus[name]
+			instanceCells += statusRenderSvc(instanceStatus) + "\t"
+		}
+		adguardCells := ""
+		for _, name := range adguardNames {
+			instanceStatus := e.TargetStatus[name]
+			adguardCells += statusRenderSvc(instanceStatus) + "\t"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s%s\t%s%s\t%s\n",
 			hostname,
 			statusRenderSync(e.OverallStatus),
-			statusRenderSvc(e.UnboundStatus.Configured, e.UnboundStatus.InSync),
-			statusRenderSvc(e.AdguardStatus.Configured, e.AdguardStatus.InSync),
+			statusRenderSvc(e.UnboundStatus),
+			instanceCells,
+			statusRenderSvc(e.AdguardStatus),
+			adguardCells,
 			statusRenderDHCP(e.DHCPStatus),
 			statusRenderCF(e.CloudflareStatus),
 		)
@@ -155,6 +189,9 @@ func runStatus(cmd *cobra.Command, _ []string) error {
 	}
 	fmt.Fprintln(out)
 
+	// ── Replica drift ──────────────────────────────────────────────────────────
+	printReplicaDrift(out, report.Drift)
+
 	// ── Issues ─────────────────────────────────────────────────────────────────
 	if len(issueEntries) > 0 {
 		fmt.Fprintln(out, StyleSection.Render(fmt.Sprintf("── Issues (%d) ───────────────────────────────────────────────", len(issueEntries))))
@@ -188,6 +225,9 @@ func statusEntryHasIssue(e *models.Entry) bool {
 	if e.NeedsHTTPHostHeader() {
 		return true
 	}
+	if e.HasCaddyConflict() {
+		return true
+	}
 	return false
 }
 
@@ -205,6 +245,10 @@ func statusIssueMessages(e *models.Entry) []string {
 	if e.NeedsHTTPHostHeader() {
 		msgs = append(msgs, "Cloudflare tunnel missing HTTP Host header — Caddy routing will break")
 	}
+	if e.HasCaddyConflict() {
+		msgs = append(msgs, fmt.Sprintf("Claimed by several Caddy servers (%s) — DNS points at %s (%s)",
+			strings.Join(e.CaddyConflicts, ", "), e.CaddyServer, e.CaddyServerIP))
+	}
 	return msgs
 }
 
@@ -225,11 +269,24 @@ func statusRenderSync(s models.SyncStatus) string {
 	}
 }
 
-func statusRenderSvc(configured, inSync bool) string {
-	if !configured {
+// statusRenderSvc renders the A record state, followed by the AAAA record
+// state for hostnames that have or need one. Aliases are marked as such.
+func statusRenderSvc(s models.ServiceStatus) string {
+	if !s.Configured {
 		return StyleMuted.Render("─")
 	}
-	if inSync {
+	cell := statusRenderMark(s.InSync)
+	if s.IsAlias() {
+		cell += StyleMuted.Render(" alias")
+	}
+	if s.IPv6 != "" || s.IPv6Mismatch {
+		cell += StyleMuted.Render(" v6") + statusRenderMark(!s.IPv6Mismatch)
+	}
+	return cell
+}
+
+func statusRenderMark(ok bool) string {
+	if ok {
 		return StyleOK.Render("✓")
 	}
 	return StyleFail.Render("✗")
@@ -271,3 +328,26 @@ func init() {
 	statusCmd.Flags().StringVar(&statusHostnameFilter, "hostname", "", "Filter by hostname (partial match)")
 	statusCmd.Flags().BoolVar(&statusCompact, "compact", false, "Show one-line summary only")
 }
+
+// printReplicaDrift lists hostnames whose replicas disagree, with each
+// replica's state.
+func printReplicaDrift(out io.Writer, drifts []status.ReplicaDrift) {
+	if len(drifts) == 0 {
+		return
+	}
+	fmt.Fprintln(out, StyleSection.Render(fmt.Sprintf("── Replica drift (%d) ────────────────────────────────────────", len(drifts))))
+	fmt.Fprintln(out)
+	for _, drift := range drifts {
+		replicas := make([]string, 0, len(drift.States))
+		for name := range drift.States {
+			replicas = append(replicas, name)
+		}
+		sort.Strings(replicas)
+		parts := make([]string, 0, len(replicas))
+		for _, name := range replicas {
+			parts = append(parts, name+"="+drift.States[name])
+		}
+		fmt.Fprintf(out, "  %s  %s\n      %s\n", SymWarn, StyleBold.Render(drift.Hostname), StyleMuted.Render(strings.Join(parts, "  ")))
+	}
+	fmt.Fprintln(out)
+}
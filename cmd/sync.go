@@ -2,13 +2,13 @@ package cmd
 
 import (
 	"fmt"
-	"strings"
+	"io"
 
-	"github.com/jeeftor/caddy-dns-sync/internal/api"
 	runtimeapp "github.com/jeeftor/caddy-dns-sync/internal/app"
-	execsync "github.com/jeeftor/caddy-dns-sync/internal/exec/sync"
+	"github.com/jeeftor/caddy-dns-sync/internal/config"
 	"github.com/jeeftor/caddy-dns-sync/internal/logging"
-	"github.com/jeeftor/caddy-dns-sync/internal/sync"
+	"github.com/jeeftor/caddy-dns-sync/internal/models"
+	"github.com/jeeftor/caddy-dns-sync/internal/syncplan"
 	"github.com/spf13/cobra"
 )
 
@@ -21,18 +21,31 @@ var (
 	syncUnboundOnly        bool
 	syncAdguardOnly        bool
 	syncPrompt             bool
+	syncUnboundInstances   []string
+	syncAllowMassDelete    bool
 )
 
 // syncCmd is the parent command for sync operations
 var syncCmd = &cobra.Command{
 	Use:   "sync",
 	Short: "Synchronize Caddy routes to DNS services",
-	Long: `Sync Caddy reverse proxy routes to Unbound, Adguard, or both.
+	Long: `Sync Caddy reverse proxy routes to Unbound, Dnsmasq, Adguard, Pi-hole, an RFC 2136 zone, or several at once.
 
 Available subcommands:
   all      - Sync to both Unbound and Adguard
   unbound  - Sync to Unbound only
-  adguard  - Sync to Adguard only`,
+  unbound-domains - Sync the configured Unbound domain overrides
+  dnsmasq  - Sync to OPNsense Dnsmasq host overrides only
+  adguard  - Sync to Adguard only
+  pihole   - Sync to Pi-hole only
+  rfc2136  - Sync to an authoritative zone via RFC 2136 dynamic updates
+  dhcp     - Create static DHCP reservations for Caddy upstreams
+  watch    - Keep syncing whenever Caddy's config changes
+
+A run that would delete more than sync_policy.max_deletes records (default
+10), or more than sync_policy.max_delete_percent of a service's records
+(default 50), is refused unless --allow-mass-delete is given. Hostnames
+matching a sync_policy.protected glob are never updated or deleted.`,
 }
 
 // syncAllCmd syncs to all DNS services
@@ -58,7 +71,12 @@ var syncUnboundCmd = &cobra.Command{
 
 This command queries the Caddy server for its configuration, extracts all
 hostnames from the routes, and ensures that corresponding DNS host override
-entries exist in UnboundDNS pointing to the Caddy server.`,
+entries exist in UnboundDNS pointing to the Caddy server.
+
+Additional Unbound endpoints listed under "unbound_instances" in the config
+file (HA pairs, per-site firewalls) are synced afterwards, each on its own so
+one unreachable firewall does not block the others. Use --instance to sync
+only the named instance(s).`,
 	RunE: runSyncUnbound,
 }
 
@@ -74,27 +92,31 @@ entries exist in AdguardHome pointing to the Caddy server.`,
 	RunE: runSyncAdguard,
 }
 
-// buildSyncOptions creates SyncOptions from command flags
-func buildSyncOptions() *sync.SyncOptions {
-	opts := sync.DefaultSyncOptions()
-	opts.DryRun = syncDryRun
-	opts.CaddyServerIP = syncCaddyServerIP
-	opts.CaddyServerPort = syncCaddyServerPort
-	opts.EntryDescription = syncEntryDescription
-	opts.Verbose = verbose
-	opts.UnboundOnly = syncUnboundOnly
-	opts.AdguardOnly = syncAdguardOnly
-
-	// Parse legacy descriptions
-	if syncLegacyDescriptions != "" {
-		opts.LegacyDescriptions = strings.Split(syncLegacyDescriptions, ",")
-		// Trim whitespace from each description
-		for i := range opts.LegacyDescriptions {
-			opts.LegacyDescriptions[i] = strings.TrimSpace(opts.LegacyDescriptions[i])
-		}
+// loadSyncPolicy builds the sync policy from the "sync_policy" config
+// section.
+func loadSyncPolicy(allowMassDelete bool) (syncplan.Policy, error) {
+	cfg, err := config.LoadSyncPolicyConfig()
+	if err != nil {
+		return syncplan.Policy{}, fmt.Errorf("error loading sync policy: %w", err)
 	}
+	return syncplan.NewPolicy(cfg, allowMassDelete), nil
+}
 
-	return opts
+// enforceSyncPolicy drops protected hostnames from actions, reporting each
+// one, and refuses a mass delete.
+func enforceSyncPolicy(out io.Writer, actions []syncplan.Action, entries []*models.Entry, allowMassDelete bool) ([]syncplan.Action, error) {
+	policy, err := loadSyncPolicy(allowMassDelete)
+	if err != nil {
+		return nil, err
+	}
+	allowed, protected, err := policy.Enforce(actions, syncplan.ExistingRecords(entries))
+	for _, action := range protected {
+		fmt.Fprintf(out, "  %s  %s %s for %s skipped: hostname is protected\n", SymWarn, action.Type, action.Service, action.Hostname)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return allowed, nil
 }
 
 func runSyncAll(cmd *cobra.Command, args []string) error {
@@ -110,12 +132,9 @@ func runSyncAll(cmd *cobra.Command, args []string) error {
 	}
 	defer releaseLock()
 
-	opts := buildSyncOptions()
-	syncUI := execsync.NewSyncUI()
-
 	runtime, err := runtimeapp.LoadRuntime(runtimeapp.RuntimeOptions{
-		CaddyServerIP:   opts.CaddyServerIP,
-		CaddyServerPort: opts.CaddyServerPort,
+		CaddyServerIP:   syncCaddyServerIP,
+		CaddyServerPort: syncCaddyServerPort,
 		IncludeUnbound:  !syncAdguardOnly,
 		IncludeAdguard:  !syncUnboundOnly,
 		RequireAdguard:  syncAdguardOnly,
@@ -124,71 +143,29 @@ func runSyncAll(cmd *cobra.Command, args []string) error {
 		logging.Error("Error loading sync runtime", "error", err)
 		return fmt.Errorf("error loading sync runtime: %w", err)
 	}
+	return syncAll(cmd, runtime)
+}
 
-	// Check what systems we'll sync to
-	syncToUnbound := !syncAdguardOnly
-	syncToAdguard := !syncUnboundOnly && runtime.Clients.Adguard != nil
-
-	// Validate that we have at least one target
-	if !syncToUnbound && !syncToAdguard {
-		fmt.Println("No sync targets available:")
-		if syncAdguardOnly {
-			fmt.Println("  - AdguardHome sync was requested but AdguardHome is not enabled")
-			fmt.Println("  - Set ADGUARD_ENABLED=true and configure credentials")
-		} else if runtime.Clients.Adguard == nil {
-			fmt.Println("  - UnboundDNS: Disabled by --adguard-only flag")
-			fmt.Println("  - AdguardHome: Not enabled (set ADGUARD_ENABLED=true)")
+// syncAll syncs Unbound and AdguardHome, with their instances, as one plan
+// built from the same entries as 'sync plan', so manifest, Docker and
+// additional Caddy server hostnames, answer rules and alias mode all apply.
+func syncAll(cmd *cobra.Command, runtime *runtimeapp.Runtime) error {
+	var services []string
+	if !syncAdguardOnly {
+		unbound, err := unboundServices(runtime, nil)
+		if err != nil {
+			return err
 		}
-		return fmt.Errorf("no sync targets available")
-	}
-
-	// Create executor and set clients
-	executor := sync.NewSyncExecutor(opts)
-	unboundClient, adguardClient := syncCommandClients(runtime, !syncAdguardOnly, !syncUnboundOnly && runtime.Clients.Adguard != nil)
-	executor.SetClients(runtime.Clients.Caddy, unboundClient, adguardClient)
-
-	// Print header
-	fmt.Print(syncUI.RenderUnifiedHeader(syncToUnbound, syncToAdguard))
-	fmt.Print(syncUI.RenderSyncTargets(syncToUnbound, syncToAdguard))
-	fmt.Println(syncUI.RenderFetchingMessage(opts.CaddyServerIP, opts.CaddyServerPort))
-
-	// Perform unified sync
-	result, err := executor.SyncAll()
-	if err != nil {
-		logging.Error("Error during unified sync operation", "error", err)
-		return fmt.Errorf("error during unified sync operation: %w", err)
+		services = append(services, unbound...)
 	}
-	if result == nil {
-		fmt.Println(syncUI.RenderWarning("No hostnames found in Caddy config"))
-		return nil
-	}
-
-	if len(result.HostnameMap) == 0 {
-		fmt.Println(syncUI.RenderWarning("No hostnames found in Caddy config"))
-		return nil
-	}
-
-	// Display results
-	fmt.Print(syncUI.RenderHostnameCount(len(result.HostnameMap)))
-
-	if verbose {
-		fmt.Println()
-		hostnames := make([]string, 0, len(result.HostnameMap))
-		for hostname := range result.HostnameMap {
-			hostnames = append(hostnames, hostname)
-		}
-		fmt.Print(syncUI.RenderHostnameList(hostnames))
+	if !syncUnboundOnly && runtime.Clients.Adguard != nil {
+		services = append(services, adguardServices(runtime)...)
 	}
-
-	fmt.Print(syncUI.RenderUnifiedSummary(result))
-
-	if syncDryRun {
-		fmt.Print(syncUI.RenderUnifiedDryRunOutput(result, opts.EntryDescription))
-	} else {
-		fmt.Print(syncUI.RenderUnifiedChanges(result, opts.EntryDescription))
+	if len(services) == 0 {
+		return fmt.Errorf("no sync targets available: AdguardHome is not enabled (set ADGUARD_ENABLED=true)")
 	}
-
-	return nil
+	enableSyncPrompt(runtime)
+	return runSyncplanTargets(cmd, runtime, services...)
 }
 
 func runSyncUnbound(cmd *cobra.Command, args []string) error {
@@ -198,12 +175,9 @@ func runSyncUnbound(cmd *cobra.Command, args []string) error {
 	}
 	defer releaseLock()
 
-	opts := buildSyncOptions()
-	syncUI := execsync.NewSyncUI()
-
 	runtime, err := runtimeapp.LoadRuntime(runtimeapp.RuntimeOptions{
-		CaddyServerIP:   opts.CaddyServerIP,
-		CaddyServerPort: opts.CaddyServerPort,
+		CaddyServerIP:   syncCaddyServerIP,
+		CaddyServerPort: syncCaddyServerPort,
 		IncludeUnbound:  true,
 	})
 	if err != nil {
@@ -211,48 +185,12 @@ func runSyncUnbound(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("error loading configuration: %w\nPlease run 'config' command to set up API access", err)
 	}
 
-	// Create executor
-	executor := sync.NewSyncExecutor(opts)
-	unboundClient, _ := syncCommandClients(runtime, true, false)
-	executor.SetClients(runtime.Clients.Caddy, unboundClient, nil)
-
-	// Print header
-	fmt.Print(syncUI.RenderHeader())
-	fmt.Println(syncUI.RenderFetchingMessage(opts.CaddyServerIP, opts.CaddyServerPort))
-
-	// Perform sync
-	result, err := executor.SyncToUnbound()
+	services, err := unboundServices(runtime, syncUnboundInstances)
 	if err != nil {
-		logging.Error("Error during Unbound sync", "error", err)
-		return fmt.Errorf("error during Unbound sync: %w", err)
-	}
-
-	if len(result.HostnameMap) == 0 {
-		fmt.Println(syncUI.RenderWarning("No hostnames found in Caddy config"))
-		return nil
-	}
-
-	// Display results
-	fmt.Print(syncUI.RenderHostnameCount(len(result.HostnameMap)))
-
-	if verbose {
-		fmt.Println()
-		hostnames := make([]string, 0, len(result.HostnameMap))
-		for hostname := range result.HostnameMap {
-			hostnames = append(hostnames, hostname)
-		}
-		fmt.Print(syncUI.RenderHostnameList(hostnames))
-	}
-
-	fmt.Print(syncUI.RenderSummary(result))
-
-	if syncDryRun {
-		fmt.Print(syncUI.RenderDryRunOutput(result, opts.EntryDescription))
-	} else {
-		fmt.Print(syncUI.RenderChanges(result, opts.EntryDescription))
+		return err
 	}
-
-	return nil
+	enableSyncPrompt(runtime)
+	return runSyncplanTargets(cmd, runtime, services...)
 }
 
 func runSyncAdguard(cmd *cobra.Command, args []string) error {
@@ -262,12 +200,9 @@ func runSyncAdguard(cmd *cobra.Command, args []string) error {
 	}
 	defer releaseLock()
 
-	opts := buildSyncOptions()
-	syncUI := execsync.NewSyncUI()
-
 	runtime, err := runtimeapp.LoadRuntime(runtimeapp.RuntimeOptions{
-		CaddyServerIP:   opts.CaddyServerIP,
-		CaddyServerPort: opts.CaddyServerPort,
+		CaddyServerIP:   syncCaddyServerIP,
+		CaddyServerPort: syncCaddyServerPort,
 		IncludeAdguard:  true,
 		RequireAdguard:  true,
 	})
@@ -276,70 +211,63 @@ func runSyncAdguard(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("error loading AdguardHome runtime: %w", err)
 	}
 
-	// Create executor
-	executor := sync.NewSyncExecutor(opts)
-	_, adguardClient := syncCommandClients(runtime, false, true)
-	executor.SetClients(runtime.Clients.Caddy, nil, adguardClient)
-
-	// Print header
-	fmt.Print(syncUI.RenderHeader())
-	fmt.Println(syncUI.RenderFetchingMessage(opts.CaddyServerIP, opts.CaddyServerPort))
+	enableSyncPrompt(runtime)
+	return runSyncplanTargets(cmd, runtime, adguardServices(runtime)...)
+}
 
-	// Perform sync
-	result, err := executor.SyncToAdguard()
-	if err != nil {
-		logging.Error("Error during Adguard sync", "error", err)
-		return fmt.Errorf("error during Adguard sync: %w", err)
+// unboundServices returns the sync targets for the named Unbound instances,
+// or for the main Unbound and every configured instance when names is empty.
+func unboundServices(runtime *runtimeapp.Runtime, names []string) ([]string, error) {
+	configured := make(map[string]bool, len(runtime.Clients.UnboundInstances))
+	for _, instance := range runtime.Clients.UnboundInstances {
+		configured[instance.Name] = true
 	}
-
-	if len(result.HostnameMap) == 0 {
-		fmt.Println(syncUI.RenderWarning("No hostnames found in Caddy config"))
-		return nil
+	if len(names) == 0 {
+		services := []string{"unbound"}
+		for _, instance := range runtime.Clients.UnboundInstances {
+			services = append(services, syncplan.UnboundInstancePrefix+instance.Name)
+		}
+		return services, nil
 	}
 
-	// Display results
-	fmt.Print(syncUI.RenderHostnameCount(len(result.HostnameMap)))
-
-	if verbose {
-		fmt.Println()
-		hostnames := make([]string, 0, len(result.HostnameMap))
-		for hostname := range result.HostnameMap {
-			hostnames = append(hostnames, hostname)
+	services := make([]string, 0, len(names))
+	for _, name := range names {
+		if !configured[name] {
+			return nil, fmt.Errorf("unknown Unbound instance %q (configure it under unbound_instances)", name)
 		}
-		fmt.Print(syncUI.RenderHostnameList(hostnames))
+		services = append(services, syncplan.UnboundInstancePrefix+name)
 	}
+	return services, nil
+}
 
-	fmt.Print(syncUI.RenderAdguardSummary(result))
-
-	if syncDryRun {
-		fmt.Print(syncUI.RenderAdguardDryRunOutput(result, opts.EntryDescription))
-	} else {
-		fmt.Print(syncUI.RenderAdguardChanges(result, opts.EntryDescription))
+// adguardServices returns the sync targets for the primary AdguardHome and
+// every replica from adguard.instances.
+func adguardServices(runtime *runtimeapp.Runtime) []string {
+	services := []string{"adguard"}
+	for _, instance := range runtime.Clients.AdguardInstances {
+		services = append(services, syncplan.AdguardInstancePrefix+instance.Name)
 	}
-
-	return nil
+	return services
 }
 
-func syncCommandClients(runtime *runtimeapp.Runtime, useUnbound, useAdguard bool) (*api.Client, *api.AdguardClient) {
-	var unboundClient *api.Client
-	if useUnbound {
-		unboundClient = runtime.Clients.Unbound
-		if syncPrompt && runtime.Clients.Unbound != nil {
-			unboundClient = api.NewClient(runtime.UnboundConfig)
-			unboundClient.Prompt = true
-		}
+// enableSyncPrompt makes the Unbound and AdguardHome clients ask before each
+// API call when --prompt is given.
+func enableSyncPrompt(runtime *runtimeapp.Runtime) {
+	if !syncPrompt {
+		return
 	}
-
-	var adguardClient *api.AdguardClient
-	if useAdguard {
-		adguardClient = runtime.Clients.Adguard
-		if syncPrompt && runtime.Clients.Adguard != nil {
-			adguardClient = api.NewAdguardClient(runtime.AdguardConfig.GetAdguardAPIConfig())
-			adguardClient.Prompt = true
-		}
+	if runtime.Clients.Unbound != nil {
+		runtime.Clients.Unbound.Prompt = true
+	}
+	for _, instance := range runtime.Clients.UnboundInstances {
+		instance.Client.Prompt = true
+	}
+	if runtime.Clients.Adguard != nil {
+		runtime.Clients.Adguard.Prompt = true
+	}
+	for _, instance := range runtime.Clients.AdguardInstances {
+		instance.Client.Prompt = true
 	}
-
-	return unboundClient, adguardClient
 }
 
 func init() {
@@ -348,17 +276,25 @@ func init() {
 	// Add subcommands
 	syncCmd.AddCommand(syncAllCmd)
 	syncCmd.AddCommand(syncUnboundCmd)
+	syncCmd.AddCommand(syncDNSMasqCmd)
 	syncCmd.AddCommand(syncAdguardCmd)
+	syncCmd.AddCommand(syncPiholeCmd)
+	syncCmd.AddCommand(syncRFC2136Cmd)
+	syncCmd.AddCommand(syncDHCPCmd)
 
 	// Shared flags for all sync commands
 	syncCmd.PersistentFlags().BoolVar(&syncDryRun, "dry-run", false, "Show what would be changed without applying")
 	syncCmd.PersistentFlags().StringVar(&syncCaddyServerIP, "caddy-ip", runtimeapp.DefaultCaddyServerIP, "Caddy server IP")
 	syncCmd.PersistentFlags().IntVar(&syncCaddyServerPort, "caddy-port", runtimeapp.DefaultCaddyServerPort, "Caddy admin API port")
 	syncCmd.PersistentFlags().StringVar(&syncEntryDescription, "description", runtimeapp.CurrentUnboundDescription, "Description for DNS entries")
-	syncCmd.PersistentFlags().StringVar(&syncLegacyDescriptions, "legacy-desc", "Entry created by unboundCLI caddy-sync-all,Entry created by unboundCLI sync,Entry created by unboundCLI caddy-sync-unbound,Entry created by unboundCLI caddy-sync-cloudflare,Entry created by CaddySync,Route via Caddy", "Comma-separated legacy descriptions")
+	syncCmd.PersistentFlags().StringVar(&syncLegacyDescriptions, "legacy-desc", "", "Comma-separated legacy descriptions")
+	_ = syncCmd.PersistentFlags().MarkDeprecated("description", "synced records always carry the managed description")
+	_ = syncCmd.PersistentFlags().MarkDeprecated("legacy-desc", "legacy descriptions are migrated to the managed description automatically")
 	syncCmd.PersistentFlags().BoolVar(&syncPrompt, "prompt", false, "Prompt before each API call")
+	syncCmd.PersistentFlags().BoolVar(&syncAllowMassDelete, "allow-mass-delete", false, "Allow a run to delete more records than sync_policy permits")
 
 	// Target selection flags (only for 'all' subcommand)
 	syncAllCmd.Flags().BoolVar(&syncUnboundOnly, "unbound-only", false, "Sync to Unbound only")
 	syncAllCmd.Flags().BoolVar(&syncAdguardOnly, "adguard-only", false, "Sync to Adguard only")
+	syncUnboundCmd.Flags().StringSliceVar(&syncUnboundInstances, "instance", nil, "Sync only the named Unbound instance(s) from unbound_instances")
 }
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+
+	runtimeapp "github.com/jeeftor/caddy-dns-sync/internal/app"
+	"github.com/jeeftor/caddy-dns-sync/internal/logging"
+	"github.com/spf13/cobra"
+)
+
+// syncDHCPCmd pins dynamic DHCP leases behind Caddy upstreams
+var syncDHCPCmd = &cobra.Command{
+	Use:   "dhcp",
+	Short: "Create static DHCP reservations for Caddy upstreams",
+	Long: `Create static DHCP reservations for devices that back a Caddy upstream.
+
+This command finds Caddy upstreams whose IP address is currently a dynamic
+DHCP lease and creates a static reservation for the lease's MAC address, so
+the upstream IP cannot drift out from under Caddy. Existing reservations for
+the same MAC and IP are left alone.
+
+Reservations are created in OPNsense Dnsmasq by default. Set
+CADDY_DNS_SYNC_DHCP_BACKEND=kea (or "dhcp_backend": "kea" in the config file)
+to use Kea DHCPv4 instead. Use --dry-run to preview the reservations.`,
+	RunE: runSyncDHCP,
+}
+
+func runSyncDHCP(cmd *cobra.Command, args []string) error {
+	releaseLock, err := acquireSyncLockWithWait()
+	if err != nil {
+		return err
+	}
+	defer releaseLock()
+
+	runtime, err := runtimeapp.LoadRuntime(runtimeapp.RuntimeOptions{
+		CaddyServerIP:   syncCaddyServerIP,
+		CaddyServerPort: syncCaddyServerPort,
+		IncludeDNSMasq:  true,
+	})
+	if err != nil {
+		logging.Error("Error loading DHCP runtime", "error", err)
+		return fmt.Errorf("error loading DHCP runtime: %w", err)
+	}
+
+	return runSyncplanTargets(cmd, runtime, "dhcp")
+}
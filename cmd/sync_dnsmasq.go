@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+
+	runtimeapp "github.com/jeeftor/caddy-dns-sync/internal/app"
+	"github.com/jeeftor/caddy-dns-sync/internal/config"
+	"github.com/jeeftor/caddy-dns-sync/internal/logging"
+	"github.com/spf13/cobra"
+)
+
+// syncDNSMasqCmd syncs to OPNsense Dnsmasq host overrides only
+var syncDNSMasqCmd = &cobra.Command{
+	Use:   "dnsmasq",
+	Short: "Sync Caddy routes to OPNsense Dnsmasq",
+	Long: `Synchronize host overrides in OPNsense Dnsmasq with hostnames from Caddy.
+
+This command queries the Caddy server for its configuration, extracts all
+hostnames from the routes, and ensures that corresponding host entries exist
+in Dnsmasq (Services > Dnsmasq DNS & DHCP > Hosts) pointing to the Caddy
+server. Dnsmasq is reconfigured once after all changes are applied.
+
+Dnsmasq uses the same OPNsense API credentials as Unbound. Host overrides
+are only synced (and shown in status) when "dnsmasq_hosts" is true in the
+config file or CADDY_DNS_SYNC_DNSMASQ_HOSTS=true. Once enabled they are part
+of plans for every service ('sync plan', 'watch', the web UI); 'sync all'
+still covers only Unbound and AdguardHome.`,
+	RunE: runSyncDNSMasq,
+}
+
+func runSyncDNSMasq(cmd *cobra.Command, args []string) error {
+	releaseLock, err := acquireSyncLockWithWait()
+	if err != nil {
+		return err
+	}
+	defer releaseLock()
+
+	runtime, err := runtimeapp.LoadRuntime(runtimeapp.RuntimeOptions{
+		CaddyServerIP:   syncCaddyServerIP,
+		CaddyServerPort: syncCaddyServerPort,
+		IncludeDNSMasq:  true,
+	})
+	if err != nil {
+		logging.Error("Error loading Dnsmasq runtime", "error", err)
+		return fmt.Errorf("error loading Dnsmasq runtime: %w", err)
+	}
+	if !runtime.Clients.DNSMasqHosts {
+		return fmt.Errorf("Dnsmasq host overrides are not enabled; set \"dnsmasq_hosts\": true in the config file or %s=true", config.EnvDNSMasqHosts)
+	}
+
+	return runSyncplanTargets(cmd, runtime, "dnsmasq")
+}
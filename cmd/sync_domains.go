@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/api"
+	"github.com/jeeftor/caddy-dns-sync/internal/config"
+	"github.com/jeeftor/caddy-dns-sync/internal/logging"
+	"github.com/jeeftor/caddy-dns-sync/internal/syncplan"
+	"github.com/spf13/cobra"
+)
+
+// syncDomainsCmd reconciles Unbound domain overrides with the config file
+var syncDomainsCmd = &cobra.Command{
+	Use:   "unbound-domains",
+	Short: "Sync the configured Unbound domain overrides",
+	Long: `Make Unbound's domain overrides (query forwarders) match the
+"domain_overrides" section of the config file.
+
+Every listed domain is forwarded to each of its servers. Forwarders this
+command created earlier are deleted once their domain or server is removed
+from the list; forwarders added by hand are never touched. Changes are
+applied and journaled like any other sync, so a run can be reverted with
+'undo <run-id>', and the sync policy's delete limits and protected globs
+apply to domains as they do to hostnames.`,
+	Example: `  caddy-dns-sync sync unbound-domains --dry-run
+  caddy-dns-sync sync unbound-domains`,
+	RunE: runSyncDomains,
+}
+
+func runSyncDomains(cmd *cobra.Command, args []string) error {
+	desired, err := config.LoadDomainOverrides()
+	if err != nil {
+		return fmt.Errorf("error loading domain overrides: %w", err)
+	}
+
+	releaseLock, err := acquireSyncLockWithWait()
+	if err != nil {
+		return err
+	}
+	defer releaseLock()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		logging.Error("Error loading configuration", "error", err)
+		return fmt.Errorf("error loading configuration: %w\nPlease run 'config' command to set up API access", err)
+	}
+	client := api.NewClient(cfg)
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+
+	existing, err := client.WithContext(ctx).GetDomainOverrides()
+	if err != nil {
+		return fmt.Errorf("error fetching domain overrides: %w", err)
+	}
+	plan := syncplan.PlanDomainOverrides(desired, existing)
+
+	out := cmd.OutOrStdout()
+	policy, err := loadSyncPolicy(syncAllowMassDelete)
+	if err != nil {
+		return err
+	}
+	allowed, protected, err := policy.Enforce(plan.Actions, map[string]int{
+		syncplan.UnboundDomainsService: syncplan.OwnedDomainOverrides(existing),
+	})
+	for _, action := range protected {
+		fmt.Fprintf(out, "  %s  %s %s for %s skipped: domain is protected\n", SymWarn, action.Type, action.Service, action.Hostname)
+	}
+	if err != nil {
+		return err
+	}
+	plan.Actions = allowed
+
+	fmt.Fprintf(out, "%s  %d domains, %d changes\n", SymOK, len(desired), len(plan.Actions))
+	if len(plan.Actions) == 0 {
+		return nil
+	}
+	printSyncActions(out, plan.Actions)
+	if syncDryRun {
+		fmt.Fprintln(out, StyleWarn.Render("Dry run: no changes applied"))
+		return nil
+	}
+
+	result := syncplan.Apply(ctx, syncplan.Clients{Unbound: client}, plan, syncplan.ApplyOptions{
+		Journal: syncplan.NewJournal(syncplan.DefaultJournalDir()),
+	})
+	printApplyResult(out, result)
+	if !result.Success {
+		return fmt.Errorf("domain override sync finished with %d error(s)", len(result.Errors))
+	}
+	return nil
+}
+
+func init() {
+	syncCmd.AddCommand(syncDomainsCmd)
+}
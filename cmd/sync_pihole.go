@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	runtimeapp "github.com/jeeftor/caddy-dns-sync/internal/app"
+	"github.com/jeeftor/caddy-dns-sync/internal/logging"
+	"github.com/jeeftor/caddy-dns-sync/internal/status"
+	"github.com/jeeftor/caddy-dns-sync/internal/syncplan"
+	"github.com/spf13/cobra"
+)
+
+// syncPiholeCmd syncs to Pi-hole only
+var syncPiholeCmd = &cobra.Command{
+	Use:   "pihole",
+	Short: "Sync Caddy routes to Pi-hole",
+	Long: `Synchronize local DNS records in Pi-hole v6 with hostnames from Caddy.
+
+This command queries the Caddy server for its configuration, extracts all
+hostnames from the routes, and ensures that corresponding local DNS host
+entries (dns.hosts) exist in Pi-hole pointing to the Caddy server. Hostnames
+that are currently CNAME records in Pi-hole are replaced with host entries.
+
+Pi-hole must be enabled with PIHOLE_ENABLED=true and PIHOLE_BASE_URL (or the
+"pihole" section of the config file). PIHOLE_PASSWORD may be the web password
+or an application password.`,
+	RunE: runSyncPihole,
+}
+
+func runSyncPihole(cmd *cobra.Command, args []string) error {
+	releaseLock, err := acquireSyncLockWithWait()
+	if err != nil {
+		return err
+	}
+	defer releaseLock()
+
+	runtime, err := runtimeapp.LoadRuntime(runtimeapp.RuntimeOptions{
+		CaddyServerIP:   syncCaddyServerIP,
+		CaddyServerPort: syncCaddyServerPort,
+		IncludePihole:   true,
+		RequirePihole:   true,
+	})
+	if err != nil {
+		logging.Error("Error loading Pi-hole runtime", "error", err)
+		return fmt.Errorf("error loading Pi-hole runtime: %w", err)
+	}
+	defer func() {
+		if err := runtime.Clients.Pihole.Logout(); err != nil {
+			logging.Debug("Failed to end Pi-hole session", "error", err)
+		}
+	}()
+
+	return runSyncplanTargets(cmd, runtime, "pihole")
+}
+
+// runSyncplanTargets plans and applies the sync actions for one or more
+// registered syncplan targets, printing each action as it goes. Entries are
+// loaded once; a target whose records failed to load is skipped so it does not
+// block the others.
+func runSyncplanTargets(cmd *cobra.Command, runtime *runtimeapp.Runtime, services ...string) error {
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintln(out, StyleMuted.Render(fmt.Sprintf("Fetching Caddy config from %s…", runtime.CaddyEndpoint)))
+
+	entries, report, err := status.LoadEntries(ctx, runtime.Clients, status.Options{
+		CaddyServerIP:   runtime.CaddyEndpoint.ServerIP,
+		CaddyServerIPv6: runtime.CaddyEndpoint.ServerIPv6,
+	})
+	if err != nil {
+		return fmt.Errorf("error loading data: %w", err)
+	}
+
+	clients := syncplan.NewClients(runtime.Clients)
+	registry := clients.Registry()
+	var plan syncplan.Plan
+	var loadErrors []string
+	for _, service := range services {
+		if serviceReport, ok := report.Services[status.ServiceName(service)]; ok && serviceReport.Error != "" {
+			loadErrors = append(loadErrors, fmt.Sprintf("error loading %s records: %s", service, serviceReport.Error))
+			continue
+		}
+		servicePlan := syncplan.BuildPlan(entries, syncplan.Options{
+			Service:       service,
+			CaddyServerIP: runtime.CaddyEndpoint.ServerIP,
+			Targets:       registry,
+		})
+		plan.Actions = append(plan.Actions, servicePlan.Actions...)
+	}
+	if len(loadErrors) == len(services) {
+		return errors.New(strings.Join(loadErrors, "; "))
+	}
+	for _, msg := range loadErrors {
+		fmt.Fprintf(out, "  %s  %s\n", SymFail, msg)
+	}
+	printReplicaDrift(out, replicaDriftFor(report.Drift, services))
+
+	if plan.Actions, err = enforceSyncPolicy(out, plan.Actions, entries, syncAllowMassDelete); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "%s  %d hostnames, %d changes\n", SymOK, len(entries), len(plan.Actions))
+	if len(plan.Actions) > 0 {
+		printSyncActions(out, plan.Actions)
+	}
+
+	result := &syncplan.Result{Success: true}
+	switch {
+	case len(plan.Actions) == 0:
+	case syncDryRun:
+		fmt.Fprintln(out, StyleWarn.Render("Dry run: no changes applied"))
+	default:
+		result = syncplan.Apply(ctx, clients, plan, syncplan.ApplyOptions{
+			Journal: syncplan.NewJournal(syncplan.DefaultJournalDir()),
+		})
+		printApplyResult(out, result)
+	}
+	if !result.Success || len(loadErrors) > 0 {
+		return fmt.Errorf("%s sync finished with %d error(s)", strings.Join(services, ", "), len(result.Errors)+len(loadErrors))
+	}
+	return nil
+}
+
+// replicaDriftFor keeps the drift between replicas of the synced services,
+// e.g. "adguard" drift when syncing "adguard" or "adguard:secondary".
+func replicaDriftFor(drifts []status.ReplicaDrift, services []string) []status.ReplicaDrift {
+	synced := make(map[string]bool, len(services))
+	for _, service := range services {
+		base, _, _ := strings.Cut(service, ":")
+		synced[base] = true
+	}
+	var kept []status.ReplicaDrift
+	for _, drift := range drifts {
+		if synced[drift.Service] {
+			kept = append(kept, drift)
+		}
+	}
+	return kept
+}
+
+func printSyncActions(out io.Writer, actions []syncplan.Action) {
+	for _, action := range actions {
+		line := fmt.Sprintf("%-6s %s", action.Type, action.Hostname)
+		switch {
+		case action.OldIP != "" && action.NewIP != "":
+			line += fmt.Sprintf(" %s -> %s", action.OldIP, action.NewIP)
+		case action.NewIP != "":
+			line += " -> " + action.NewIP
+		case action.OldIP != "":
+			line += " (" + action.OldIP + ")"
+		}
+		if action.MAC != "" {
+			line += " [MAC " + action.MAC + "]"
+		}
+		fmt.Fprintf(out, "  %s  %s\n", StyleInfo.Render(action.Service), line)
+	}
+}
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+
+	runtimeapp "github.com/jeeftor/caddy-dns-sync/internal/app"
+	"github.com/jeeftor/caddy-dns-sync/internal/config"
+	"github.com/jeeftor/caddy-dns-sync/internal/logging"
+	"github.com/jeeftor/caddy-dns-sync/internal/models"
+	"github.com/jeeftor/caddy-dns-sync/internal/status"
+	"github.com/jeeftor/caddy-dns-sync/internal/syncplan"
+	"github.com/spf13/cobra"
+)
+
+var (
+	syncPlanOut     string
+	syncPlanService string
+)
+
+// syncPlanCmd saves a reviewed plan for 'apply plan.json'
+var syncPlanCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Save a sync plan for review and later 'apply'",
+	Long: `Plan the DNS changes needed to match Caddy and write them to a file.
+
+The plan file records every action together with a fingerprint of the state
+observed in Caddy and each DNS service. Review it, then run
+
+  caddy-dns-sync apply plan.json
+
+which re-reads live state, refuses to run if anything has changed since the
+plan was written, and otherwise applies exactly the saved actions.`,
+	Example: `  caddy-dns-sync sync plan -o plan.json
+  caddy-dns-sync sync plan --service unbound -o unbound-plan.json`,
+	RunE: runSyncPlan,
+}
+
+func runSyncPlan(cmd *cobra.Command, args []string) error {
+	runtime, err := loadSavedPlanRuntime(syncCaddyServerIP, syncCaddyServerPort)
+	if err != nil {
+		return err
+	}
+	defer closePiholeSession(runtime)
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintln(out, StyleMuted.Render(fmt.Sprintf("Fetching Caddy config from %s…", runtime.CaddyEndpoint)))
+	options := syncplan.Options{
+		Service:         syncPlanService,
+		CaddyServerIP:   runtime.CaddyEndpoint.ServerIP,
+		CaddyServiceURL: runtime.CaddyServiceURL,
+		Targets:         syncplan.NewClients(runtime.Clients).Registry(),
+	}
+	targets := targetNames(options)
+	if len(targets) == 0 {
+		return fmt.Errorf("no configured sync target for service %q", syncPlanService)
+	}
+	entries, err := loadSavedPlanEntries(ctx, runtime, runtime.CaddyEndpoint.ServerIP, targets, out)
+	if err != nil {
+		return err
+	}
+	plan := syncplan.BuildPlan(entries, options)
+	saved := syncplan.NewSavedPlan(plan, entries, options)
+
+	fmt.Fprintf(out, "%s  %d hostnames, %d changes\n", SymOK, len(entries), len(plan.Actions))
+	if len(plan.Actions) > 0 {
+		printSyncActions(out, plan.Actions)
+	}
+	if err := syncplan.WriteSavedPlan(syncPlanOut, saved); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Plan saved to %s (state %.12s)\n", syncPlanOut, saved.Fingerprint)
+	fmt.Fprintln(out, StyleMuted.Render(fmt.Sprintf("Apply it with: caddy-dns-sync apply %s", syncPlanOut)))
+	return nil
+}
+
+// loadSavedPlanRuntime builds every client a saved plan may touch. Optional
+// services that are not configured are simply left out.
+func loadSavedPlanRuntime(caddyServerIP string, caddyServerPort int) (*runtimeapp.Runtime, error) {
+	runtime, err := runtimeapp.LoadRuntime(runtimeapp.RuntimeOptions{
+		CaddyServerIP:     caddyServerIP,
+		CaddyServerPort:   caddyServerPort,
+		IncludeUnbound:    true,
+		IncludeDNSMasq:    true,
+		IncludeAdguard:    true,
+		IncludePihole:     true,
+		IncludeRFC2136:    true,
+		IncludeCloudflare: true,
+	})
+	if err != nil {
+		logging.Error("Error loading plan runtime", "error", err)
+		return nil, fmt.Errorf("error loading plan runtime: %w", err)
+	}
+	return runtime, nil
+}
+
+// closePiholeSession ends the Pi-hole API session, if a client was built.
+func closePiholeSession(runtime *runtimeapp.Runtime) {
+	if runtime.Clients.Pihole == nil {
+		return
+	}
+	if err := runtime.Clients.Pihole.Logout(); err != nil {
+		logging.Debug("Failed to end Pi-hole session", "error", err)
+	}
+}
+
+// loadSavedPlanEntries loads entries for planning or verifying a saved plan.
+// A service that failed to load would make its records look absent, so a
+// failure aborts when the plan depends on that service: a hostname source,
+// one of targets, or DHCP when manifest hosts are looked up by MAC. Other
+// failures are only reported. Nil targets means the plan depends on every
+// service.
+func loadSavedPlanEntries(ctx context.Context, runtime *runtimeapp.Runtime, caddyServerIP string, targets []string, out io.Writer) ([]*models.Entry, error) {
+	entries, report, err := status.LoadEntries(ctx, runtime.Clients, status.Options{
+		CaddyServerIP:   caddyServerIP,
+		CaddyServerIPv6: runtime.CaddyEndpoint.ServerIPv6,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error loading data: %w", err)
+	}
+	failed, warnings := splitLoadFailures(report, planServices(targets, runtime.Clients.Manifest))
+	for _, warning := range warnings {
+		fmt.Fprintf(out, "%s  %s\n", SymWarn, StyleWarn.Render("ignoring service that failed to load: "+warning))
+	}
+	if len(failed) > 0 {
+		return nil, fmt.Errorf("cannot plan with services that failed to load: %s", strings.Join(failed, "; "))
+	}
+	return entries, nil
+}
+
+// planServices returns the services a plan for targets depends on, or nil
+// when targets is empty and the plan depends on all of them.
+func planServices(targets []string, manifest []config.ManifestHost) map[status.ServiceName]bool {
+	if len(targets) == 0 {
+		return nil
+	}
+	services := map[status.ServiceName]bool{
+		status.ServiceCaddy:      true,
+		status.ServiceDocker:     true,
+		status.ServiceKubernetes: true,
+	}
+	for _, target := range targets {
+		if target == syncplan.UnboundDomainsService {
+			target = string(status.ServiceUnbound)
+		}
+		services[status.ServiceName(target)] = true
+	}
+	for _, host := range manifest {
+		if host.IP == "" && host.MAC != "" {
+			services[status.ServiceDHCP] = true
+		}
+	}
+	return services
+}
+
+// splitLoadFailures sorts the services that failed to load into those in
+// services, which are fatal, and the rest. DNS resolution is never fatal.
+func splitLoadFailures(report status.LoadReport, services map[status.ServiceName]bool) (failed, warnings []string) {
+	for name, serviceReport := range report.Services {
+		if name == status.ServiceDNS || serviceReport.Status != status.ServiceFailed {
+			continue
+		}
+		failure := fmt.Sprintf("%s: %s", name, serviceReport.Error)
+		if services == nil || services[name] {
+			failed = append(failed, failure)
+		} else {
+			warnings = append(warnings, failure)
+		}
+	}
+	sort.Strings(failed)
+	sort.Strings(warnings)
+	return failed, warnings
+}
+
+// targetNames returns the names of the targets options selects.
+func targetNames(options syncplan.Options) []string {
+	targets := options.Targets.ForService(options)
+	names := make([]string, 0, len(targets))
+	for _, target := range targets {
+		names = append(names, target.Name())
+	}
+	return names
+}
+
+func init() {
+	syncCmd.AddCommand(syncPlanCmd)
+	syncPlanCmd.Flags().StringVarP(&syncPlanOut, "out", "o", "plan.json", "File to write the plan to")
+	syncPlanCmd.Flags().StringVar(&syncPlanService, "service", "all", "Service to plan for (all, unbound, adguard, pihole, rfc2136, dnsmasq, cloudflare, ...)")
+}
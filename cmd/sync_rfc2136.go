@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+
+	runtimeapp "github.com/jeeftor/caddy-dns-sync/internal/app"
+	"github.com/jeeftor/caddy-dns-sync/internal/logging"
+	"github.com/spf13/cobra"
+)
+
+// syncRFC2136Cmd syncs to an authoritative zone via RFC 2136
+var syncRFC2136Cmd = &cobra.Command{
+	Use:   "rfc2136",
+	Short: "Sync Caddy routes to an RFC 2136 zone",
+	Long: `Synchronize A records in an authoritative zone with hostnames from Caddy.
+
+This command transfers the configured zone (AXFR), compares it with the
+hostnames from Caddy, and sends TSIG-signed RFC 2136 UPDATE messages to the
+primary. Every name caddy-dns-sync creates gets a TXT ownership marker
+("heritage=caddy-dns-sync" by default) on its "_owner." name, e.g.
+_owner.app.example.com; names without the marker are never updated or
+deleted.
+
+The primary must allow both updates and zone transfers for the TSIG key.`,
+	RunE: runSyncRFC2136,
+}
+
+func runSyncRFC2136(cmd *cobra.Command, args []string) error {
+	releaseLock, err := acquireSyncLockWithWait()
+	if err != nil {
+		return err
+	}
+	defer releaseLock()
+
+	runtime, err := runtimeapp.LoadRuntime(runtimeapp.RuntimeOptions{
+		CaddyServerIP:   syncCaddyServerIP,
+		CaddyServerPort: syncCaddyServerPort,
+		IncludeRFC2136:  true,
+		RequireRFC2136:  true,
+	})
+	if err != nil {
+		logging.Error("Error loading RFC 2136 runtime", "error", err)
+		return fmt.Errorf("error loading RFC 2136 runtime: %w", err)
+	}
+
+	return runSyncplanTargets(cmd, runtime, "rfc2136")
+}
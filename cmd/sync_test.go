@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	gosync "sync"
+	"testing"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/api"
+	runtimeapp "github.com/jeeftor/caddy-dns-sync/internal/app"
+	"github.com/jeeftor/caddy-dns-sync/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// syncTestOPNsense fakes the Unbound API with fixed host overrides and
+// records every change request made against it.
+type syncTestOPNsense struct {
+	*httptest.Server
+	mu    gosync.Mutex
+	calls []string
+}
+
+func newSyncTestOPNsense(t *testing.T, overrides string) *syncTestOPNsense {
+	t.Helper()
+	fake := &syncTestOPNsense{}
+	fake.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/api/unbound/settings/searchHostOverride":
+			fmt.Fprintf(w, `{"rows":[%s]}`, overrides)
+		case r.URL.Path == "/api/unbound/settings/searchHostAlias":
+			fmt.Fprint(w, `{"rows":[]}`)
+		case r.URL.Path == "/api/unbound/service/reconfigure", r.URL.Path == "/api/core/firmware/backup":
+			fmt.Fprint(w, `{"status":"ok"}`)
+		default:
+			fake.mu.Lock()
+			fake.calls = append(fake.calls, strings.TrimPrefix(r.URL.Path, "/api/unbound/settings/"))
+			fake.mu.Unlock()
+			if strings.Contains(r.URL.Path, "/del") {
+				fmt.Fprint(w, `{"result":"deleted"}`)
+				return
+			}
+			fmt.Fprint(w, `{"result":"saved","uuid":"new-uuid"}`)
+		}
+	}))
+	t.Cleanup(fake.Close)
+	return fake
+}
+
+func (f *syncTestOPNsense) client() *api.Client {
+	return api.NewClient(api.Config{
+		APIKey:    "fixture-key",
+		APISecret: "fixture-secret",
+		BaseURL:   f.URL,
+		Insecure:  true,
+	})
+}
+
+func (f *syncTestOPNsense) changes() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return strings.Join(f.calls, " ")
+}
+
+// runSyncAllForTest runs 'sync all' against runtime with the Unbound target
+// only, returning its output.
+func runSyncAllForTest(t *testing.T, runtime *runtimeapp.Runtime) string {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	previousUnboundOnly, previousDryRun := syncUnboundOnly, syncDryRun
+	t.Cleanup(func() { syncUnboundOnly, syncDryRun = previousUnboundOnly, previousDryRun })
+	syncUnboundOnly, syncDryRun = true, false
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+	cmd.SetContext(context.Background())
+	if err := syncAll(cmd, runtime); err != nil {
+		t.Fatalf("sync all failed: %v\n%s", err, out.String())
+	}
+	return out.String()
+}
+
+func TestSyncAllKeepsManifestHosts(t *testing.T) {
+	managed := runtimeapp.CurrentUnboundDescription
+	opnsense := newSyncTestOPNsense(t, fmt.Sprintf(
+		`{"uuid":"app-uuid","hostname":"app","domain":"example.test","server":"10.0.0.1","description":%q},`+
+			`{"uuid":"nas-uuid","hostname":"nas","domain":"example.test","server":"10.0.0.9","description":%q},`+
+			`{"uuid":"old-uuid","hostname":"old","domain":"example.test","server":"10.0.0.1","description":%q}`,
+		managed, managed, managed))
+
+	out := runSyncAllForTest(t, &runtimeapp.Runtime{
+		CaddyEndpoint: runtimeapp.CaddyEndpoint{ServerIP: "10.0.0.1"},
+		Clients: runtimeapp.ClientSet{
+			CaddySource: watchTestSource{"app.example.test": {Upstream: "10.0.0.5:8080"}},
+			Manifest:    []config.ManifestHost{{Hostname: "nas.example.test", IP: "10.0.0.9"}},
+			Unbound:     opnsense.client(),
+		},
+	})
+
+	if changes := opnsense.changes(); changes != "delHostOverride/old-uuid" {
+		t.Fatalf("expected only the stale override to be deleted, got %q\n%s", changes, out)
+	}
+}
+
+func TestSyncAllHonoursAliasMode(t *testing.T) {
+	managed := runtimeapp.CurrentUnboundDescription
+	opnsense := newSyncTestOPNsense(t, fmt.Sprintf(
+		`{"uuid":"caddy-uuid","hostname":"caddy","domain":"example.test","server":"10.0.0.1","description":%q},`+
+			`{"uuid":"app-uuid","hostname":"app","domain":"example.test","server":"10.0.0.1","description":%q}`,
+		managed, managed))
+
+	out := runSyncAllForTest(t, &runtimeapp.Runtime{
+		CaddyEndpoint: runtimeapp.CaddyEndpoint{ServerIP: "10.0.0.1"},
+		Clients: runtimeapp.ClientSet{
+			CaddySource:    watchTestSource{"app.example.test": {Upstream: "10.0.0.5:8080"}},
+			AliasCanonical: "caddy.example.test",
+			Unbound:        opnsense.client(),
+		},
+	})
+
+	if changes := opnsense.changes(); changes != "delHostOverride/app-uuid addHostAlias" {
+		t.Fatalf("expected the app override to become an alias of the canonical host, got %q\n%s", changes, out)
+	}
+}
+
+func TestSyncAllAppliesAnswerRules(t *testing.T) {
+	opnsense := newSyncTestOPNsense(t, "")
+
+	out := runSyncAllForTest(t, &runtimeapp.Runtime{
+		CaddyEndpoint: runtimeapp.CaddyEndpoint{ServerIP: "10.0.0.1"},
+		Clients: runtimeapp.ClientSet{
+			CaddySource: watchTestSource{"game.example.test": {Upstream: "10.0.0.7:25565"}},
+			AnswerRules: []config.AnswerRule{{Hostname: "game.*", Answer: config.AnswerUpstream}},
+			Unbound:     opnsense.client(),
+		},
+	})
+
+	if !strings.Contains(out, "add    game.example.test -> 10.0.0.7") {
+		t.Fatalf("expected the answer rule to point game.example.test at its upstream, got\n%s", out)
+	}
+}
+
+func TestSyncAllKeepsHostnamesFromOtherCaddyServers(t *testing.T) {
+	opnsense := newSyncTestOPNsense(t, fmt.Sprintf(
+		`{"uuid":"dmz-uuid","hostname":"wiki","domain":"dmz.test","server":"10.0.1.1","description":%q}`,
+		runtimeapp.CurrentUnboundDescription))
+
+	out := runSyncAllForTest(t, &runtimeapp.Runtime{
+		CaddyEndpoint: runtimeapp.CaddyEndpoint{ServerIP: "10.0.0.1"},
+		Clients: runtimeapp.ClientSet{
+			CaddySource: watchTestSource{"app.example.test": {Upstream: "10.0.0.5:8080"}},
+			CaddyServers: []runtimeapp.CaddyServer{{
+				Name:    "dmz",
+				ServeIP: "10.0.1.1",
+				Source:  watchTestSource{"wiki.dmz.test": {Upstream: "10.0.1.5:8080"}},
+			}},
+			Unbound: opnsense.client(),
+		},
+	})
+
+	if changes := opnsense.changes(); changes != "addHostOverride" {
+		t.Fatalf("expected only app.example.test to be added, got %q\n%s", changes, out)
+	}
+	if strings.Contains(out, "wiki.dmz.test") {
+		t.Fatalf("expected the DMZ hostname to be left alone, got\n%s", out)
+	}
+}
+
+func TestSyncAllReadsRoutesFromTraefik(t *testing.T) {
+	traefik := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/http/routers" {
+			fmt.Fprint(w, "[{\"name\":\"app@docker\",\"provider\":\"docker\",\"rule\":\"Host(`app.example.test`)\",\"service\":\"app\",\"status\":\"enabled\"}]")
+			return
+		}
+		if r.URL.Path == "/api/http/services" {
+			fmt.Fprint(w, `[{"name":"app@docker","provider":"docker","type":"loadbalancer","loadBalancer":{"servers":[{"url":"http://10.0.0.5:8080"}]}}]`)
+			return
+		}
+		fmt.Fprint(w, `[]`)
+	}))
+	defer traefik.Close()
+	opnsense := newSyncTestOPNsense(t, "")
+
+	runtime := &runtimeapp.Runtime{
+		CaddyEndpoint: runtimeapp.CaddyEndpoint{ServerIP: "10.0.0.1"},
+		Clients:       runtimeapp.ClientSet{Unbound: opnsense.client()},
+	}
+	runtime.UseCaddySource(config.CaddyConfig{
+		Source:  config.CaddySourceTraefik,
+		Traefik: api.TraefikConfig{BaseURL: traefik.URL},
+	})
+	out := runSyncAllForTest(t, runtime)
+
+	if !strings.Contains(out, "add    app.example.test -> 10.0.0.1") {
+		t.Fatalf("expected the Traefik router's hostname to be added, got\n%s", out)
+	}
+}
+
+func TestSyncAllReadsRoutesFromCaddyfile(t *testing.T) {
+	caddyfile := filepath.Join(t.TempDir(), "Caddyfile")
+	if err := os.WriteFile(caddyfile, []byte("*.example.test {\n\t@app host app.example.test\n\thandle @app {\n\t\treverse_proxy 10.0.0.5:8080\n\t}\n}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	opnsense := newSyncTestOPNsense(t, "")
+
+	runtime := &runtimeapp.Runtime{
+		CaddyEndpoint: runtimeapp.CaddyEndpoint{ServerIP: "10.0.0.1"},
+		Clients:       runtimeapp.ClientSet{Unbound: opnsense.client()},
+	}
+	runtime.UseCaddySource(config.CaddyConfig{Source: config.CaddySourceCaddyfile, Caddyfile: caddyfile})
+	out := runSyncAllForTest(t, runtime)
+
+	if !strings.Contains(out, "add    app.example.test -> 10.0.0.1") {
+		t.Fatalf("expected the Caddyfile site to be added, got\n%s", out)
+	}
+}
+
+func TestSyncAdguardReportsReplicaDrift(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	adguard := func(rewrites string) *api.AdguardClient {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, rewrites)
+		}))
+		t.Cleanup(server.Close)
+		return api.NewAdguardClient(api.AdguardConfig{BaseURL: server.URL, Enabled: true})
+	}
+	runtime := &runtimeapp.Runtime{
+		CaddyEndpoint: runtimeapp.CaddyEndpoint{ServerIP: "10.0.0.1"},
+		Clients: runtimeapp.ClientSet{
+			CaddySource: watchTestSource{"app.example.test": {Upstream: "10.0.0.5:8080"}},
+			Adguard:     adguard(`[{"domain":"app.example.test","answer":"10.0.0.1"}]`),
+			AdguardInstances: []runtimeapp.AdguardInstance{
+				{Name: "secondary", Client: adguard(`[]`)},
+			},
+		},
+	}
+	previousDryRun := syncDryRun
+	t.Cleanup(func() { syncDryRun = previousDryRun })
+	syncDryRun = true
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+	cmd.SetContext(context.Background())
+	if err := runSyncplanTargets(cmd, runtime, adguardServices(runtime)...); err != nil {
+		t.Fatalf("sync adguard failed: %v\n%s", err, out.String())
+	}
+
+	if !strings.Contains(out.String(), "Replica drift (1)") || !strings.Contains(out.String(), "adguard:secondary=missing") {
+		t.Fatalf("expected the secondary's missing rewrite to be reported as drift, got\n%s", out.String())
+	}
+}
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	runtimeapp "github.com/jeeftor/caddy-dns-sync/internal/app"
+	"github.com/jeeftor/caddy-dns-sync/internal/logging"
+	"github.com/jeeftor/caddy-dns-sync/internal/syncplan"
+	"github.com/jeeftor/caddy-dns-sync/internal/watch"
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchInterval   time.Duration
+	watchResync     time.Duration
+	watchMaxBackoff time.Duration
+	watchService    string
+)
+
+// syncWatchCmd keeps DNS in sync until interrupted
+var syncWatchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Keep DNS in sync whenever Caddy's config changes",
+	Long: `Run continuously, polling the Caddy admin API for configuration changes.
+
+Each poll compares Caddy's config version (its Etag, or a hash of the config)
+with the one last synced, and plans and applies a sync only when it changed.
+A full resync also runs every --resync interval, spread by up to 10% either
+way, to repair edits made directly in a DNS service.
+
+Runs take the same lock as 'sync', so a watcher never overlaps a manual sync;
+a run that finds the lock held, or fails, is retried with exponential backoff
+up to --max-backoff. Applied runs are journaled and can be reverted with
+'undo <run-id>'. Install it as a systemd service with
+'install-service --watch'.`,
+	Example: `  caddy-dns-sync sync watch
+  caddy-dns-sync sync watch --interval 10s --resync 30m --service unbound
+  caddy-dns-sync sync watch --dry-run`,
+	RunE: runSyncWatch,
+}
+
+// watchCmd is 'sync watch' registered at the top level, where the
+// long-running command and its service are easier to find
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: syncWatchCmd.Short,
+	Long:  syncWatchCmd.Long,
+	Example: `  caddy-dns-sync watch
+  caddy-dns-sync watch --interval 10s --resync 30m --service unbound
+  caddy-dns-sync watch --dry-run`,
+	RunE: runSyncWatch,
+}
+
+// configVersioner is implemented by hostname sources that can report a
+// cheap version of their config.
+type configVersioner interface {
+	ConfigVersion(ctx context.Context) (string, error)
+}
+
+func runSyncWatch(cmd *cobra.Command, args []string) error {
+	runtime, err := loadSavedPlanRuntime(syncCaddyServerIP, syncCaddyServerPort)
+	if err != nil {
+		return err
+	}
+	defer closePiholeSession(runtime)
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintln(out, StyleMuted.Render(fmt.Sprintf("Watching Caddy config at %s (poll %s, resync %s)…", runtime.CaddyEndpoint, watchInterval, watchResync)))
+
+	watcher := &watch.Watcher{
+		Version: watchConfigVersion(runtime),
+		Sync: func(ctx context.Context, reason watch.Reason) error {
+			fmt.Fprintf(out, "\n%s\n", StyleSection.Render(fmt.Sprintf("── %s sync (%s)", time.Now().Format(time.DateTime), reason)))
+			return runWatchSync(ctx, cmd, runtime)
+		},
+		Options: watch.Options{
+			PollInterval:   watchInterval,
+			ResyncInterval: watchResync,
+			MaxBackoff:     watchMaxBackoff,
+		},
+	}
+	return watcher.Run(ctx)
+}
+
+// watchConfigVersion combines the config versions of the main Caddy source
+// and every additional Caddy server that can report one. Sources that cannot
+// (e.g. a Caddyfile) only pick up changes at the periodic resync.
+func watchConfigVersion(runtime *runtimeapp.Runtime) func(context.Context) (string, error) {
+	var sources []configVersioner
+	switch source := runtime.Clients.CaddySource.(type) {
+	case nil:
+		if runtime.Clients.Caddy != nil {
+			sources = append(sources, runtime.Clients.Caddy)
+		}
+	case configVersioner:
+		sources = append(sources, source)
+	default:
+		logging.Info("Caddy source cannot report config changes; relying on periodic resync")
+	}
+	for _, server := range runtime.Clients.CaddyServers {
+		if source, ok := server.Source.(configVersioner); ok {
+			sources = append(sources, source)
+		}
+	}
+
+	return func(ctx context.Context) (string, error) {
+		versions := make([]string, 0, len(sources))
+		for _, source := range sources {
+			version, err := source.ConfigVersion(ctx)
+			if err != nil {
+				return "", err
+			}
+			versions = append(versions, version)
+		}
+		return strings.Join(versions, ","), nil
+	}
+}
+
+// runWatchSync plans and applies one watch run. It does not wait for the sync
+// lock: a held lock fails the run, which the watcher retries with backoff. So
+// does a failure to load a service the run syncs; other failures are only
+// reported, so one unreachable service does not stall the rest.
+func runWatchSync(ctx context.Context, cmd *cobra.Command, runtime *runtimeapp.Runtime) error {
+	releaseLock, err := acquireSyncLock()
+	if err != nil {
+		return err
+	}
+	defer releaseLock()
+
+	out := cmd.OutOrStdout()
+	clients := syncplan.NewClients(runtime.Clients)
+	options := syncplan.Options{
+		Service:         watchService,
+		CaddyServerIP:   runtime.CaddyEndpoint.ServerIP,
+		CaddyServiceURL: runtime.CaddyServiceURL,
+		Targets:         clients.Registry(),
+	}
+	entries, err := loadSavedPlanEntries(ctx, runtime, runtime.CaddyEndpoint.ServerIP, targetNames(options), out)
+	if err != nil {
+		return err
+	}
+
+	plan := syncplan.BuildPlan(entries, options)
+	if plan.Actions, err = enforceSyncPolicy(out, plan.Actions, entries, syncAllowMassDelete); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "%s  %d hostnames, %d changes\n", SymOK, len(entries), len(plan.Actions))
+	if len(plan.Actions) == 0 {
+		return nil
+	}
+	printSyncActions(out, plan.Actions)
+	if syncDryRun {
+		fmt.Fprintln(out, StyleWarn.Render("Dry run: no changes applied"))
+		return nil
+	}
+
+	result := syncplan.Apply(ctx, clients, plan, syncplan.ApplyOptions{
+		Journal: syncplan.NewJournal(syncplan.DefaultJournalDir()),
+	})
+	printApplyResult(out, result)
+	if !result.Success {
+		return fmt.Errorf("sync finished with %d error(s)", len(result.Errors))
+	}
+	return nil
+}
+
+func init() {
+	syncCmd.AddCommand(syncWatchCmd)
+	rootCmd.AddCommand(watchCmd)
+	for _, cmd := range []*cobra.Command{syncWatchCmd, watchCmd} {
+		cmd.Flags().DurationVar(&watchInterval, "interval", watch.DefaultPollInterval, "How often to check Caddy for config changes")
+		cmd.Flags().DurationVar(&watchResync, "resync", watch.DefaultResyncInterval, "How often to run a full resync even when Caddy did not change")
+		cmd.Flags().DurationVar(&watchMaxBackoff, "max-backoff", watch.DefaultMaxBackoff, "Longest wait between retries of a failing run")
+		cmd.Flags().StringVar(&watchService, "service", "all", "Service to sync (all, unbound, adguard, pihole, rfc2136, dnsmasq, cloudflare, ...)")
+	}
+
+	// The top-level command does not inherit the sync persistent flags.
+	watchCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "Show what would be changed without applying")
+	watchCmd.Flags().StringVar(&syncCaddyServerIP, "caddy-ip", runtimeapp.DefaultCaddyServerIP, "Caddy server IP")
+	watchCmd.Flags().IntVar(&syncCaddyServerPort, "caddy-port", runtimeapp.DefaultCaddyServerPort, "Caddy admin API port")
+	watchCmd.Flags().BoolVar(&syncAllowMassDelete, "allow-mass-delete", false, "Allow a run to delete more records than sync_policy permits")
+}
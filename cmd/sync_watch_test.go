@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/api"
+	runtimeapp "github.com/jeeftor/caddy-dns-sync/internal/app"
+	"github.com/jeeftor/caddy-dns-sync/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// watchTestSource is an api.HostnameSource with fixed routes.
+type watchTestSource map[string]models.CaddyRouteInfo
+
+func (s watchTestSource) GetHostnameDetails() (map[string]models.CaddyRouteInfo, error) {
+	return s, nil
+}
+
+func TestRunWatchSyncOnlyFailsForServicesItSyncs(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	adguard := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer adguard.Close()
+
+	runtime := &runtimeapp.Runtime{
+		CaddyEndpoint: runtimeapp.CaddyEndpoint{ServerIP: "10.0.0.1"},
+		Clients: runtimeapp.ClientSet{
+			CaddySource: watchTestSource{"app.example.test": {Upstream: "10.0.0.5:8080"}},
+			Adguard:     api.NewAdguardClient(api.AdguardConfig{BaseURL: adguard.URL, Enabled: true}),
+		},
+	}
+	previousService, previousDryRun := watchService, syncDryRun
+	t.Cleanup(func() { watchService, syncDryRun = previousService, previousDryRun })
+	syncDryRun = true
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+
+	// A run for Unbound only warns that AdGuard failed to load.
+	watchService = "unbound"
+	if err := runWatchSync(context.Background(), cmd, runtime); err != nil {
+		t.Fatalf("expected an Unbound run to ignore AdGuard failing, got %v", err)
+	}
+	if !strings.Contains(out.String(), "adguard") {
+		t.Fatalf("expected a warning about AdGuard, got %q", out.String())
+	}
+
+	// A run that syncs AdGuard cannot trust its missing records.
+	watchService = "adguard"
+	err := runWatchSync(context.Background(), cmd, runtime)
+	if err == nil || !strings.Contains(err.Error(), "adguard") {
+		t.Fatalf("expected an AdGuard run to fail, got %v", err)
+	}
+}
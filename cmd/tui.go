@@ -13,6 +13,7 @@ import (
 var (
 	tuiCaddyServerIP   string
 	tuiCaddyServerPort int
+	tuiAllowMassDelete bool
 )
 
 // tuiCmd represents the tui command
@@ -55,6 +56,8 @@ func init() {
 		"IP address of the Caddy server (source of truth)")
 	tuiCmd.Flags().IntVar(&tuiCaddyServerPort, "caddy-server-port", runtimeapp.DefaultCaddyServerPort,
 		"Port number for Caddy admin API")
+	tuiCmd.Flags().BoolVar(&tuiAllowMassDelete, "allow-mass-delete", false,
+		"Allow a sync to delete more records than sync_policy permits")
 }
 
 func runTUI(cmd *cobra.Command, args []string) error {
@@ -69,6 +72,7 @@ func runTUI(cmd *cobra.Command, args []string) error {
 		IncludeUnbound:    true,
 		IncludeDNSMasq:    true,
 		IncludeAdguard:    true,
+		IncludePihole:     true,
 		IncludeCloudflare: true,
 	})
 	if err != nil {
@@ -78,6 +82,12 @@ func runTUI(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	policy, err := loadSyncPolicy(tuiAllowMassDelete)
+	if err != nil {
+		logging.ResetToStderr()
+		return err
+	}
+
 	// Create TUI application
 	tuiApp := tui.NewAppModel(
 		runtime.Clients.Caddy,
@@ -87,7 +97,7 @@ func runTUI(cmd *cobra.Command, args []string) error {
 		runtime.CaddyEndpoint.ServerIP,
 		runtime.Clients.Cloudflare,
 		runtime.CaddyServiceURL,
-	)
+	).WithPiholeClient(runtime.Clients.Pihole).WithSyncPolicy(policy)
 
 	// NOW redirect logging to TUI log widget
 	logging.SetCustomHandler(func(level, message string) {
@@ -53,6 +53,8 @@ func runWeb(cmd *cobra.Command, args []string) error {
 		IncludeUnbound:    true,
 		IncludeDNSMasq:    true,
 		IncludeAdguard:    true,
+		IncludePihole:     true,
+		IncludeRFC2136:    true,
 		IncludeCloudflare: true,
 		IncludeAuthentik:  true,
 	})
@@ -1,9 +1,17 @@
 package api
 
 import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"os"
 	"slices"
 	"strings"
 	"time"
@@ -18,10 +26,40 @@ var caddyHTTPClient = &http.Client{
 	Timeout: 10 * time.Second,
 }
 
+// CaddyAdminConfig describes how the Caddy admin API is reached when it is
+// not plain HTTP on server_ip:server_port.
+type CaddyAdminConfig struct {
+	// Socket is the unix socket the admin API listens on, as a path or a
+	// "unix://" address. When set it replaces server_ip:server_port.
+	Socket string `json:"socket,omitempty" mapstructure:"socket"`
+	// ClientCert and ClientKey are PEM files presented to Caddy's
+	// admin.remote endpoint. Setting them switches requests to HTTPS.
+	ClientCert string `json:"client_cert,omitempty" mapstructure:"client_cert"`
+	ClientKey  string `json:"client_key,omitempty" mapstructure:"client_key"`
+	// CACert verifies the admin.remote server certificate. Empty means the
+	// system roots.
+	CACert string `json:"ca_cert,omitempty" mapstructure:"ca_cert"`
+}
+
+// SocketPath returns the unix socket path without a "unix://" prefix.
+func (c CaddyAdminConfig) SocketPath() string {
+	return strings.TrimPrefix(c.Socket, "unix://")
+}
+
+// UsesTLS reports whether requests go to admin.remote over mutual TLS.
+func (c CaddyAdminConfig) UsesTLS() bool {
+	return c.ClientCert != ""
+}
+
 // CaddyClient handles communication with the Caddy server
 type CaddyClient struct {
 	ServerIP   string
 	ServerPort int
+	// Socket is the unix socket path used instead of ServerIP:ServerPort.
+	Socket string
+
+	baseURL string
+	client  *http.Client
 }
 
 // NewCaddyClient creates a new Caddy client
@@ -32,15 +70,121 @@ func NewCaddyClient(serverIP string, serverPort int) *CaddyClient {
 	}
 }
 
+// NewCaddyAdminClient creates a Caddy client that reaches the admin API over
+// a unix socket, or over HTTPS with a client certificate, as admin describes.
+// Without either it behaves like NewCaddyClient.
+func NewCaddyAdminClient(serverIP string, serverPort int, admin CaddyAdminConfig) (*CaddyClient, error) {
+	client := NewCaddyClient(serverIP, serverPort)
+	if socket := admin.SocketPath(); socket != "" {
+		client.Socket = socket
+		// Caddy only accepts an empty or loopback Host header on its unix
+		// socket, so the URL host is 127.0.0.1 rather than a placeholder.
+		client.baseURL = "http://127.0.0.1"
+		client.client = &http.Client{
+			Timeout: caddyHTTPClient.Timeout,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+				},
+			},
+		}
+		return client, nil
+	}
+	if !admin.UsesTLS() {
+		if admin.ClientKey != "" || admin.CACert != "" {
+			return nil, fmt.Errorf("caddy admin client_key and ca_cert require a client_cert")
+		}
+		return client, nil
+	}
+
+	if admin.ClientKey == "" {
+		return nil, fmt.Errorf("caddy admin client_cert requires a client_key")
+	}
+	pair, err := tls.LoadX509KeyPair(admin.ClientCert, admin.ClientKey)
+	if err != nil {
+		return nil, fmt.Errorf("error loading Caddy admin client certificate: %w", err)
+	}
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{pair}}
+	if admin.CACert != "" {
+		ca, err := os.ReadFile(admin.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("error reading Caddy admin CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no valid certificates in %s", admin.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	client.baseURL = fmt.Sprintf("https://%s", net.JoinHostPort(serverIP, fmt.Sprint(serverPort)))
+	client.client = &http.Client{
+		Timeout:   caddyHTTPClient.Timeout,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+	}
+	return client, nil
+}
+
+// Endpoint describes where the admin API is reached, for messages.
+func (c *CaddyClient) Endpoint() string {
+	if c.Socket != "" {
+		return "unix://" + c.Socket
+	}
+	if c.baseURL != "" {
+		return c.baseURL
+	}
+	return fmt.Sprintf("%s:%d", c.ServerIP, c.ServerPort)
+}
+
+// transport returns the admin API base URL and the HTTP client to reach it.
+func (c *CaddyClient) transport() (string, *http.Client) {
+	baseURL, httpClient := c.baseURL, c.client
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("http://%s:%d", c.ServerIP, c.ServerPort)
+	}
+	if httpClient == nil {
+		httpClient = caddyHTTPClient
+	}
+	return baseURL, httpClient
+}
+
+// ConfigVersion returns a value that changes whenever Caddy's configuration
+// does: the admin API's Etag header, or a hash of the config when Caddy does
+// not send one.
+func (c *CaddyClient) ConfigVersion(ctx context.Context) (string, error) {
+	baseURL, httpClient := c.transport()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/config/", nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to Caddy server at %s: %w", c.Endpoint(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	if etag := resp.Header.Get("Etag"); etag != "" {
+		return etag, nil
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, resp.Body); err != nil {
+		return "", fmt.Errorf("failed to read Caddy config: %w", err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
 // GetConfig fetches the Caddy server configuration
 func (c *CaddyClient) GetConfig() (map[string]interface{}, error) {
-	url := fmt.Sprintf("http://%s:%d/config/", c.ServerIP, c.ServerPort)
+	baseURL, httpClient := c.transport()
+	url := baseURL + "/config/"
 
-	logging.Debug("Fetching Caddy config", "url", url)
-	resp, err := caddyHTTPClient.Get(url)
+	logging.Debug("Fetching Caddy config", "url", url, "endpoint", c.Endpoint())
+	resp, err := httpClient.Get(url)
 	if err != nil {
-		logging.Error("Failed to connect to Caddy server", "error", err)
-		return nil, fmt.Errorf("failed to connect to Caddy server: %w", err)
+		logging.Error("Failed to connect to Caddy server", "endpoint", c.Endpoint(), "error", err)
+		return nil, fmt.Errorf("failed to connect to Caddy server at %s: %w", c.Endpoint(), err)
 	}
 	defer resp.Body.Close()
 
@@ -385,6 +529,19 @@ func (c *CaddyClient) extractUpstreamFromRoutes(routes []interface{}) string {
 	return ""
 }
 
+// HostnameSource supplies per-hostname Caddy route details. *CaddyClient reads
+// them from the live admin API; other sources read a Caddyfile on disk or a
+// Traefik API.
+type HostnameSource interface {
+	GetHostnameDetails() (map[string]models.CaddyRouteInfo, error)
+}
+
+// HostnameMapSource supplies hostname -> upstream, as returned by
+// CaddyClient.GetHostnameMap and TraefikClient.GetHostnameMap.
+type HostnameMapSource interface {
+	GetHostnameMap() (map[string]string, error)
+}
+
 // GetHostnameDetails returns a per-hostname CaddyRouteInfo with the full handler chain,
 // request/response headers, and TLS-transport flag. Purely read-only.
 func (c *CaddyClient) GetHostnameDetails() (map[string]models.CaddyRouteInfo, error) {
@@ -392,6 +549,16 @@ func (c *CaddyClient) GetHostnameDetails() (map[string]models.CaddyRouteInfo, er
 	if err != nil {
 		return nil, err
 	}
+	return c.hostnameDetails(config), nil
+}
+
+// HostnameDetailsFromConfig is GetHostnameDetails for a Caddy JSON config that
+// was obtained some other way, e.g. from 'caddy adapt'.
+func HostnameDetailsFromConfig(config map[string]interface{}) map[string]models.CaddyRouteInfo {
+	return (&CaddyClient{}).hostnameDetails(config)
+}
+
+func (c *CaddyClient) hostnameDetails(config map[string]interface{}) map[string]models.CaddyRouteInfo {
 	result := make(map[string]models.CaddyRouteInfo)
 
 	apps, _ := config["apps"].(map[string]interface{})
@@ -409,7 +576,7 @@ func (c *CaddyClient) GetHostnameDetails() (map[string]models.CaddyRouteInfo, er
 		}
 		c.collectRouteDetails(routes, result)
 	}
-	return result, nil
+	return result
 }
 
 // collectRouteDetails walks a routes array and populates result for every route that
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const caddyAdminFixture = `{"apps":{"http":{"servers":{"srv0":{"routes":[
+	{"match":[{"host":["app.example.test"]}],"handle":[{"handler":"reverse_proxy","upstreams":[{"dial":"10.0.0.20:8080"}]}]}
+]}}}}}`
+
+func TestCaddyAdminClientOverUnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "admin.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("listen on unix socket: %v", err)
+	}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Caddy rejects any Host other than empty or loopback on its socket.
+		if r.Host != "127.0.0.1" || r.URL.Path != "/config/" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprint(w, caddyAdminFixture)
+	}))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	client, err := NewCaddyAdminClient("10.0.0.15", 2019, CaddyAdminConfig{Socket: "unix://" + socket})
+	if err != nil {
+		t.Fatalf("NewCaddyAdminClient failed: %v", err)
+	}
+	details, err := client.GetHostnameDetails()
+	if err != nil {
+		t.Fatalf("GetHostnameDetails failed: %v", err)
+	}
+	if details["app.example.test"].Upstream != "10.0.0.20:8080" {
+		t.Fatalf("unexpected routes over unix socket: %#v", details)
+	}
+	if client.Endpoint() != "unix://"+socket {
+		t.Fatalf("unexpected endpoint %q", client.Endpoint())
+	}
+}
+
+func TestCaddyAdminClientWithClientCertificate(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 || r.TLS.PeerCertificates[0].Subject.CommonName != "caddy-dns-sync" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprint(w, caddyAdminFixture)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	dir := t.TempDir()
+	admin := CaddyAdminConfig{
+		ClientCert: filepath.Join(dir, "client.crt"),
+		ClientKey:  filepath.Join(dir, "client.key"),
+		CACert:     filepath.Join(dir, "ca.crt"),
+	}
+	writeClientCertificate(t, admin.ClientCert, admin.ClientKey)
+	writePEM(t, admin.CACert, "CERTIFICATE", server.Certificate().Raw)
+
+	host, portStr, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("split server address: %v", err)
+	}
+	port, _ := strconv.Atoi(portStr)
+	client, err := NewCaddyAdminClient(host, port, admin)
+	if err != nil {
+		t.Fatalf("NewCaddyAdminClient failed: %v", err)
+	}
+	if _, err := client.GetConfig(); err != nil {
+		t.Fatalf("GetConfig over mTLS failed: %v", err)
+	}
+
+	if _, err := NewCaddyAdminClient(host, port, CaddyAdminConfig{ClientCert: admin.ClientCert}); err == nil {
+		t.Fatal("expected an error for a client certificate without a key")
+	}
+}
+
+func writeClientCertificate(t *testing.T, certPath, keyPath string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "caddy-dns-sync"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	writePEM(t, certPath, "CERTIFICATE", der)
+	writePEM(t, keyPath, "EC PRIVATE KEY", keyDER)
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestCaddyConfigVersionPrefersEtagAndFallsBackToHash(t *testing.T) {
+	config, etag := caddyAdminFixture, `"/config/ 1a2b3c"`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if etag != "" {
+			w.Header().Set("Etag", etag)
+		}
+		fmt.Fprint(w, config)
+	}))
+	defer server.Close()
+
+	host, portText, _ := net.SplitHostPort(server.Listener.Addr().String())
+	port, _ := strconv.Atoi(portText)
+	client := NewCaddyClient(host, port)
+
+	version, err := client.ConfigVersion(context.Background())
+	if err != nil {
+		t.Fatalf("ConfigVersion failed: %v", err)
+	}
+	if version != etag {
+		t.Fatalf("expected the Etag header, got %q", version)
+	}
+
+	etag = ""
+	first, err := client.ConfigVersion(context.Background())
+	if err != nil {
+		t.Fatalf("ConfigVersion failed: %v", err)
+	}
+	config = `{"apps":{}}`
+	second, err := client.ConfigVersion(context.Background())
+	if err != nil {
+		t.Fatalf("ConfigVersion failed: %v", err)
+	}
+	if first == "" || first == second {
+		t.Fatalf("expected the hash to follow the config, got %q then %q", first, second)
+	}
+}
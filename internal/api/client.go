@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"strings"
@@ -23,6 +24,12 @@ type Config struct {
 	APISecret string `json:"api_secret" mapstructure:"api_secret"`
 	BaseURL   string `json:"base_url"   mapstructure:"base_url"`
 	Insecure  bool   `json:"insecure"   mapstructure:"insecure"`
+	// DHCPBackend selects where DHCP leases are read and static reservations
+	// are created: "dnsmasq" (default) or "kea".
+	DHCPBackend string `json:"dhcp_backend,omitempty" mapstructure:"dhcp_backend"`
+	// DNSMasqHosts enables the Dnsmasq host override sync target. Without it
+	// the Dnsmasq API is only used for DHCP leases and reservations.
+	DNSMasqHosts bool `json:"dnsmasq_hosts,omitempty" mapstructure:"dnsmasq_hosts"`
 }
 
 // DNSOverride represents a single DNS override entry
@@ -38,6 +45,16 @@ type DNSOverride struct {
 	Description string `json:"description"`
 }
 
+// IsAAAA reports whether the override is an IPv6 (AAAA) record. Overrides
+// without an RR are typed by their address.
+func (o DNSOverride) IsAAAA() bool {
+	if o.RR != "" {
+		return strings.EqualFold(o.RR, "AAAA")
+	}
+	ip := net.ParseIP(o.Server)
+	return ip != nil && ip.To4() == nil
+}
+
 // APIResponse represents the response from the OPNSense API
 type APIResponse struct {
 	Status   string          `json:"status,omitempty"`
@@ -372,15 +389,21 @@ func (c *Client) GetOverrides() ([]DNSOverride, error) {
 	return overrides, nil
 }
 
-// IsOverrideExists checks if a DNS override with the same host and domain already exists
+// IsOverrideExists checks if an A record override with the same host and domain already exists
 func (c *Client) IsOverrideExists(host, domain string) (bool, string, error) {
+	return c.overrideExists(host, domain, false)
+}
+
+// overrideExists checks for an override of one address family; A and AAAA
+// overrides for the same host coexist.
+func (c *Client) overrideExists(host, domain string, aaaa bool) (bool, string, error) {
 	overrides, err := c.GetOverrides()
 	if err != nil {
 		return false, "", fmt.Errorf("error checking existing overrides: %w", err)
 	}
 
 	for _, override := range overrides {
-		if strings.EqualFold(override.Host, host) && strings.EqualFold(override.Domain, domain) {
+		if strings.EqualFold(override.Host, host) && strings.EqualFold(override.Domain, domain) && override.IsAAAA() == aaaa {
 			return true, override.UUID, nil
 		}
 	}
@@ -391,7 +414,7 @@ func (c *Client) IsOverrideExists(host, domain string) (bool, string, error) {
 // AddOverride creates a new DNS override
 func (c *Client) AddOverride(override DNSOverride) (string, error) {
 	// Check if override already exists
-	exists, uuid, err := c.IsOverrideExists(override.Host, override.Domain)
+	exists, uuid, err := c.overrideExists(override.Host, override.Domain, override.IsAAAA())
 	if err != nil {
 		return "", err
 	}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -133,6 +134,145 @@ func TestClientMutatingEndpointsUseExpectedPaths(t *testing.T) {
 	}
 }
 
+func TestClientHostAliasCRUD(t *testing.T) {
+	var paths []string
+	var bodies []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/api/unbound/settings/searchHostAlias":
+			writeFixture(t, w, "../status/testdata/unbound_aliases_canonical.json")
+		case "/api/unbound/settings/addHostAlias":
+			fmt.Fprint(w, `{"result":"saved","uuid":"uuid-alias-new"}`)
+		case "/api/unbound/settings/setHostAlias/uuid-stale-alias":
+			fmt.Fprint(w, `{"result":"saved"}`)
+		case "/api/unbound/settings/delHostAlias/uuid-stale-alias":
+			fmt.Fprint(w, `{"result":"deleted"}`)
+		default:
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{
+		APIKey:    "fixture-key",
+		APISecret: "fixture-secret",
+		BaseURL:   server.URL,
+		Insecure:  true,
+	})
+	aliases, err := client.GetHostAliases()
+	if err != nil {
+		t.Fatalf("GetHostAliases failed: %v", err)
+	}
+	if len(aliases) != 1 || aliases[0].UUID != "uuid-stale-alias" || aliases[0].Host != "uuid-caddy" || aliases[0].Hostname != "stale" {
+		t.Fatalf("unexpected aliases: %#v", aliases)
+	}
+
+	if _, err := client.AddHostAlias(HostAlias{Enabled: "1", Hostname: "orphan", Domain: "example.test"}); err == nil {
+		t.Fatal("expected an alias without a parent override to be rejected")
+	}
+	uuid, err := client.AddHostAlias(HostAlias{Enabled: "1", Host: "uuid-caddy", Hostname: "new", Domain: "example.test"})
+	if err != nil {
+		t.Fatalf("AddHostAlias failed: %v", err)
+	}
+	if uuid != "uuid-alias-new" {
+		t.Fatalf("expected uuid-alias-new, got %q", uuid)
+	}
+	if !strings.Contains(bodies[len(bodies)-1], `"alias":{`) {
+		t.Fatalf("expected the alias to be wrapped in an alias object, got %s", bodies[len(bodies)-1])
+	}
+	aliases[0].Host = "uuid-app"
+	if err := client.UpdateHostAlias(aliases[0]); err != nil {
+		t.Fatalf("UpdateHostAlias failed: %v", err)
+	}
+	if err := client.DeleteHostAlias("uuid-stale-alias"); err != nil {
+		t.Fatalf("DeleteHostAlias failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"GET /api/unbound/settings/searchHostAlias",
+		"POST /api/unbound/settings/addHostAlias",
+		"POST /api/unbound/settings/setHostAlias/uuid-stale-alias",
+		"POST /api/unbound/settings/delHostAlias/uuid-stale-alias",
+	} {
+		if !contains(paths, want) {
+			t.Fatalf("missing request %q in %#v", want, paths)
+		}
+	}
+}
+
+func TestClientDomainOverrideEndpoints(t *testing.T) {
+	var paths []string
+	var bodies []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/api/unbound/settings/searchForward":
+			fmt.Fprint(w, `{"rows":[{"uuid":"uuid-dc1","enabled":"1","type":"forward","domain":"corp.example.com","server":"10.1.0.10","port":"","description":"DC1"}]}`)
+		case "/api/unbound/settings/addForward":
+			fmt.Fprint(w, `{"result":"saved","uuid":"uuid-dc2"}`)
+		case "/api/unbound/settings/setForward/uuid-dc1":
+			fmt.Fprint(w, `{"result":"saved"}`)
+		case "/api/unbound/settings/delForward/uuid-dc1":
+			fmt.Fprint(w, `{"result":"deleted"}`)
+		default:
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{
+		APIKey:    "fixture-key",
+		APISecret: "fixture-secret",
+		BaseURL:   server.URL,
+		Insecure:  true,
+	})
+	overrides, err := client.GetDomainOverrides()
+	if err != nil {
+		t.Fatalf("GetDomainOverrides failed: %v", err)
+	}
+	if len(overrides) != 1 || overrides[0].Domain != "corp.example.com" || overrides[0].Server != "10.1.0.10" {
+		t.Fatalf("unexpected domain overrides: %#v", overrides)
+	}
+
+	uuid, err := client.AddDomainOverride(DomainOverride{Enabled: "1", Domain: "corp.example.com", Server: "10.1.0.11"})
+	if err != nil {
+		t.Fatalf("AddDomainOverride failed: %v", err)
+	}
+	if uuid != "uuid-dc2" {
+		t.Fatalf("expected uuid-dc2, got %q", uuid)
+	}
+	if !strings.Contains(bodies[len(bodies)-1], `"dot":{`) || !strings.Contains(bodies[len(bodies)-1], `"type":"forward"`) {
+		t.Fatalf("expected a plain forwarder wrapped in a dot object, got %s", bodies[len(bodies)-1])
+	}
+	overrides[0].Server = "10.1.0.12"
+	if err := client.UpdateDomainOverride(overrides[0]); err != nil {
+		t.Fatalf("UpdateDomainOverride failed: %v", err)
+	}
+	if err := client.DeleteDomainOverride("uuid-dc1"); err != nil {
+		t.Fatalf("DeleteDomainOverride failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"GET /api/unbound/settings/searchForward",
+		"POST /api/unbound/settings/addForward",
+		"POST /api/unbound/settings/setForward/uuid-dc1",
+		"POST /api/unbound/settings/delForward/uuid-dc1",
+	} {
+		if !contains(paths, want) {
+			t.Fatalf("missing request %q in %#v", want, paths)
+		}
+	}
+}
+
 func writeFixture(t *testing.T, w http.ResponseWriter, path string) {
 	t.Helper()
 	body, err := os.ReadFile(path)
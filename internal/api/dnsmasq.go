@@ -1,8 +1,11 @@
 package api
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/jeeftor/caddy-dns-sync/internal/logging"
 )
@@ -18,7 +21,35 @@ type DNSMasqLease struct {
 	Type       string   `json:"-"`           // Computed field (not from API)
 }
 
-// DNSMasqClient handles DNSMasq DHCP lease queries via OPNSense API
+// DHCPReservation is a static DHCP mapping of a MAC address to an IP address.
+// It is shared by the Dnsmasq and Kea backends.
+type DHCPReservation struct {
+	MAC         string
+	IP          string
+	Hostname    string
+	Description string
+}
+
+// DNSMasqHost represents a host override entry in OPNSense Dnsmasq
+// (Services > Dnsmasq DNS & DHCP > Hosts)
+type DNSMasqHost struct {
+	UUID        string `json:"uuid,omitempty"`
+	Host        string `json:"host"`
+	Domain      string `json:"domain"`
+	IP          string `json:"ip"`
+	HWAddr      string `json:"hwaddr,omitempty"`
+	Description string `json:"descr"`
+}
+
+// Hostname returns the fully qualified name of the host entry.
+func (h DNSMasqHost) Hostname() string {
+	if h.Domain == "" {
+		return h.Host
+	}
+	return h.Host + "." + h.Domain
+}
+
+// DNSMasqClient handles DNSMasq DHCP lease queries and host overrides via OPNSense API
 type DNSMasqClient struct {
 	client *Client // Reuse OPNSense client (same API, different endpoint)
 }
@@ -30,6 +61,11 @@ func NewDNSMasqClient(config Config) *DNSMasqClient {
 	}
 }
 
+// WithContext returns a shallow copy of the client with the given context.
+func (c *DNSMasqClient) WithContext(ctx context.Context) *DNSMasqClient {
+	return &DNSMasqClient{client: c.client.WithContext(ctx)}
+}
+
 // GetLeases retrieves all DHCP leases from DNSMasq
 func (c *DNSMasqClient) GetLeases() ([]DNSMasqLease, error) {
 	logging.Debug("Fetching DNSMasq DHCP leases")
@@ -102,3 +138,179 @@ func (c *DNSMasqClient) GetLeasesByIP() (map[string]DNSMasqLease, error) {
 	logging.Debug("Built DNSMasq IP→lease map", "count", len(leasesByIP))
 	return leasesByIP, nil
 }
+
+// GetHosts retrieves all Dnsmasq host overrides
+func (c *DNSMasqClient) GetHosts() ([]DNSMasqHost, error) {
+	logging.Debug("Fetching DNSMasq host overrides")
+
+	resp, err := c.client.makeRequest("GET", "/api/dnsmasq/settings/searchHost", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch DNSMasq hosts: %w", err)
+	}
+
+	if len(resp.Rows) == 0 {
+		logging.Debug("No DNSMasq hosts found")
+		return []DNSMasqHost{}, nil
+	}
+
+	var rows []DNSMasqHost
+	if err := json.Unmarshal(resp.Rows, &rows); err != nil {
+		logging.Error("Failed to parse DNSMasq host rows",
+			"error", err,
+			"data", string(resp.Rows),
+		)
+		return nil, fmt.Errorf("error parsing host rows: %w - Data: %s", err, string(resp.Rows))
+	}
+
+	logging.Debug("Successfully fetched DNSMasq hosts", "count", len(rows))
+	return rows, nil
+}
+
+// FindHost returns the host override for a fully qualified hostname, or nil
+// when none exists.
+func (c *DNSMasqClient) FindHost(hostname string) (*DNSMasqHost, error) {
+	hosts, err := c.GetHosts()
+	if err != nil {
+		return nil, err
+	}
+	for i := range hosts {
+		if strings.EqualFold(hosts[i].Hostname(), hostname) {
+			return &hosts[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// AddHost creates a new Dnsmasq host override and returns its UUID
+func (c *DNSMasqClient) AddHost(host DNSMasqHost) (string, error) {
+	existing, err := c.FindHost(host.Hostname())
+	if err != nil {
+		return "", fmt.Errorf("error checking existing hosts: %w", err)
+	}
+	if existing != nil {
+		return existing.UUID, fmt.Errorf(
+			"DNSMasq host for %s already exists with UUID %s",
+			host.Hostname(),
+			existing.UUID,
+		)
+	}
+
+	host.UUID = ""
+	jsonData, err := json.Marshal(map[string]DNSMasqHost{"host": host})
+	if err != nil {
+		return "", fmt.Errorf("error marshaling host: %w", err)
+	}
+
+	resp, err := c.client.makeRequest("POST", "/api/dnsmasq/settings/addHost", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", err
+	}
+	if resp.Result != "saved" {
+		logging.Error("API returned error", "result", resp.Result, "message", resp.Message)
+		if resp.Message != "" {
+			return "", fmt.Errorf("API error: %s - %s", resp.Result, resp.Message)
+		}
+		return "", fmt.Errorf("API error: %s (no additional details provided)", resp.Result)
+	}
+	if resp.UUID == "" {
+		return "", fmt.Errorf("no UUID returned from API")
+	}
+
+	logging.Info("Successfully added DNSMasq host", "hostname", host.Hostname(), "uuid", resp.UUID)
+	return resp.UUID, nil
+}
+
+// UpdateHost updates an existing Dnsmasq host override
+func (c *DNSMasqClient) UpdateHost(host DNSMasqHost) error {
+	if host.UUID == "" {
+		return fmt.Errorf("UUID is required for update")
+	}
+
+	logging.Info("Updating DNSMasq host", "uuid", host.UUID, "hostname", host.Hostname(), "ip", host.IP)
+
+	uuid := host.UUID
+	host.UUID = ""
+	jsonData, err := json.Marshal(map[string]DNSMasqHost{"host": host})
+	if err != nil {
+		return fmt.Errorf("error marshaling host: %w", err)
+	}
+
+	resp, err := c.client.makeRequest("POST", "/api/dnsmasq/settings/setHost/"+uuid, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	if resp.Result != "saved" && resp.Status != "ok" {
+		logging.Error("API returned error", "result", resp.Result, "status", resp.Status, "message", resp.Message)
+		return fmt.Errorf("API returned error: %s", resp.Message)
+	}
+
+	logging.Info("Successfully updated DNSMasq host", "uuid", uuid)
+	return nil
+}
+
+// DeleteHost removes a Dnsmasq host override
+func (c *DNSMasqClient) DeleteHost(uuid string) error {
+	logging.Debug("Deleting DNSMasq host", "uuid", uuid)
+
+	resp, err := c.client.makeRequest("POST", "/api/dnsmasq/settings/delHost/"+uuid, bytes.NewBufferString("{}"))
+	if err != nil {
+		return err
+	}
+	if resp.Result != "deleted" && resp.Status != "ok" {
+		logging.Error("API returned error", "result", resp.Result, "status", resp.Status, "message", resp.Message)
+		return fmt.Errorf("API returned error: %s", resp.Message)
+	}
+
+	logging.Debug("Successfully deleted DNSMasq host", "uuid", uuid)
+	return nil
+}
+
+// Reconfigure applies pending host changes to the Dnsmasq service
+func (c *DNSMasqClient) Reconfigure() error {
+	logging.Debug("Applying changes to DNSMasq service")
+
+	resp, err := c.client.makeRequest("POST", "/api/dnsmasq/service/reconfigure", bytes.NewBufferString("{}"))
+	if err != nil {
+		return err
+	}
+	if resp.Result != "saved" && resp.Status != "ok" {
+		logging.Error("API returned error", "result", resp.Result, "status", resp.Status, "message", resp.Message)
+		return fmt.Errorf("API returned error: %s", resp.Message)
+	}
+
+	logging.Debug("Successfully applied changes to DNSMasq service")
+	if err := c.client.persistConfig(); err != nil {
+		logging.Warn("Config persist after reconfigure failed (non-fatal)", "error", err)
+	}
+	return nil
+}
+
+// AddReservation creates a static DHCP mapping for reservation.MAC. Dnsmasq
+// stores reservations as host entries carrying a MAC address, which is what
+// sets them apart from host overrides; they get no domain, so the device's
+// name never shadows a host override. It is a no-op when the MAC is already
+// reserved for the same IP.
+func (c *DNSMasqClient) AddReservation(reservation DHCPReservation) error {
+	hosts, err := c.GetHosts()
+	if err != nil {
+		return err
+	}
+	for _, host := range hosts {
+		if !strings.EqualFold(host.HWAddr, reservation.MAC) {
+			continue
+		}
+		if host.IP == reservation.IP {
+			logging.Debug("DNSMasq reservation already exists", "mac", reservation.MAC, "ip", reservation.IP)
+			return nil
+		}
+		return fmt.Errorf("MAC %s is already reserved for %s", reservation.MAC, host.IP)
+	}
+
+	_, err = c.AddHost(DNSMasqHost{
+		Host:        reservation.Hostname,
+		IP:          reservation.IP,
+		HWAddr:      strings.ToLower(reservation.MAC),
+		Description: reservation.Description,
+	})
+	return err
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDNSMasqClientGetHostsParsesRows(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/dnsmasq/settings/searchHost" {
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"rows":[
+			{"uuid":"uuid-app","host":"app","domain":"example.test","ip":"10.0.0.15","hwaddr":"","descr":"Managed by caddy-dns-sync"},
+			{"uuid":"uuid-nas","host":"nas","domain":"","ip":"10.0.0.20","hwaddr":"aa:bb:cc:dd:ee:ff","descr":""}
+		],"rowCount":2,"total":2,"current":1}`)
+	}))
+	defer server.Close()
+
+	client := NewDNSMasqClient(Config{BaseURL: server.URL, Insecure: true})
+	hosts, err := client.GetHosts()
+	if err != nil {
+		t.Fatalf("GetHosts failed: %v", err)
+	}
+	if len(hosts) != 2 {
+		t.Fatalf("expected two hosts, got %#v", hosts)
+	}
+	if hosts[0].Hostname() != "app.example.test" || hosts[0].IP != "10.0.0.15" {
+		t.Fatalf("unexpected first host: %#v", hosts[0])
+	}
+	if hosts[1].Hostname() != "nas" || hosts[1].HWAddr != "aa:bb:cc:dd:ee:ff" {
+		t.Fatalf("unexpected second host: %#v", hosts[1])
+	}
+}
+
+func TestDNSMasqClientMutatingEndpointsUseExpectedPaths(t *testing.T) {
+	var paths []string
+	var added map[string]DNSMasqHost
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/api/dnsmasq/settings/searchHost":
+			fmt.Fprint(w, `{"rows":[]}`)
+		case "/api/dnsmasq/settings/addHost":
+			if err := json.NewDecoder(r.Body).Decode(&added); err != nil {
+				t.Fatalf("invalid addHost body: %v", err)
+			}
+			fmt.Fprint(w, `{"result":"saved","uuid":"uuid-new"}`)
+		case "/api/dnsmasq/settings/setHost/uuid-existing":
+			fmt.Fprint(w, `{"result":"saved"}`)
+		case "/api/dnsmasq/settings/delHost/uuid-existing":
+			fmt.Fprint(w, `{"result":"deleted"}`)
+		case "/api/dnsmasq/service/reconfigure":
+			fmt.Fprint(w, `{"status":"ok"}`)
+		case "/api/core/firmware/backup":
+			fmt.Fprint(w, `{"status":"ok"}`)
+		default:
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewDNSMasqClient(Config{BaseURL: server.URL, Insecure: true})
+	uuid, err := client.AddHost(DNSMasqHost{Host: "new", Domain: "example.test", IP: "10.0.0.15", Description: "fixture"})
+	if err != nil {
+		t.Fatalf("AddHost failed: %v", err)
+	}
+	if uuid != "uuid-new" {
+		t.Fatalf("expected uuid-new, got %q", uuid)
+	}
+	if got := added["host"]; got.Host != "new" || got.Domain != "example.test" || got.IP != "10.0.0.15" || got.Description != "fixture" {
+		t.Fatalf("unexpected addHost payload: %#v", added)
+	}
+	if err := client.UpdateHost(DNSMasqHost{UUID: "uuid-existing", Host: "app", Domain: "example.test", IP: "10.0.0.16"}); err != nil {
+		t.Fatalf("UpdateHost failed: %v", err)
+	}
+	if err := client.DeleteHost("uuid-existing"); err != nil {
+		t.Fatalf("DeleteHost failed: %v", err)
+	}
+	if err := client.Reconfigure(); err != nil {
+		t.Fatalf("Reconfigure failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"GET /api/dnsmasq/settings/searchHost",
+		"POST /api/dnsmasq/settings/addHost",
+		"POST /api/dnsmasq/settings/setHost/uuid-existing",
+		"POST /api/dnsmasq/settings/delHost/uuid-existing",
+		"POST /api/dnsmasq/service/reconfigure",
+	} {
+		if !contains(paths, want) {
+			t.Fatalf("missing request %q in %#v", want, paths)
+		}
+	}
+}
+
+func TestDNSMasqClientAddHostRefusesDuplicate(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/dnsmasq/settings/searchHost" {
+			t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		fmt.Fprint(w, `{"rows":[{"uuid":"uuid-app","host":"app","domain":"example.test","ip":"10.0.0.15"}]}`)
+	}))
+	defer server.Close()
+
+	client := NewDNSMasqClient(Config{BaseURL: server.URL, Insecure: true})
+	uuid, err := client.AddHost(DNSMasqHost{Host: "APP", Domain: "example.test", IP: "10.0.0.16"})
+	if err == nil {
+		t.Fatal("expected duplicate host error")
+	}
+	if uuid != "uuid-app" {
+		t.Fatalf("expected existing uuid, got %q", uuid)
+	}
+}
+
+func TestDNSMasqClientAddReservationIsKeyedByMAC(t *testing.T) {
+	var added []DNSMasqHost
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/dnsmasq/settings/searchHost":
+			fmt.Fprint(w, `{"rows":[{"uuid":"uuid-tv","host":"tv","domain":"","ip":"10.0.0.9","hwaddr":"AA:BB:CC:00:00:09"}]}`)
+		case "/api/dnsmasq/settings/addHost":
+			var body map[string]DNSMasqHost
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Fatalf("invalid addHost body: %v", err)
+			}
+			added = append(added, body["host"])
+			fmt.Fprint(w, `{"result":"saved","uuid":"uuid-new"}`)
+		default:
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewDNSMasqClient(Config{BaseURL: server.URL, Insecure: true})
+	if err := client.AddReservation(DHCPReservation{MAC: "aa:bb:cc:00:00:09", IP: "10.0.0.9", Hostname: "tv"}); err != nil {
+		t.Fatalf("expected existing reservation to be a no-op, got %v", err)
+	}
+	if err := client.AddReservation(DHCPReservation{MAC: "aa:bb:cc:00:00:09", IP: "10.0.0.10", Hostname: "tv"}); err == nil {
+		t.Fatal("expected conflicting reservation for the same MAC to fail")
+	}
+	if err := client.AddReservation(DHCPReservation{MAC: "AA:BB:CC:00:00:05", IP: "10.0.0.5", Hostname: "media", Description: "fixture"}); err != nil {
+		t.Fatalf("AddReservation failed: %v", err)
+	}
+
+	want := DNSMasqHost{Host: "media", IP: "10.0.0.5", HWAddr: "aa:bb:cc:00:00:05", Description: "fixture"}
+	if len(added) != 1 || added[0] != want {
+		t.Fatalf("unexpected hosts added: %#v", added)
+	}
+}
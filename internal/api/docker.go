@@ -0,0 +1,292 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/logging"
+	"github.com/jeeftor/caddy-dns-sync/internal/models"
+)
+
+// DefaultDockerHost is the local Docker Engine socket.
+const DefaultDockerHost = "unix:///var/run/docker.sock"
+
+// DockerConfig represents configuration for the Docker Engine API
+type DockerConfig struct {
+	// Host is a Docker host address: "unix:///path/to/docker.sock" or
+	// "tcp://host:2375". Empty means DefaultDockerHost.
+	Host string `json:"host,omitempty" mapstructure:"host"`
+	// Network selects which container network's IP is used as the upstream
+	// when a container is attached to several. Empty means the first one by
+	// name.
+	Network string `json:"network,omitempty" mapstructure:"network"`
+}
+
+// DockerClient discovers hostnames from the labels of running containers:
+// caddy-docker-proxy labels ("caddy", "caddy.reverse_proxy") and Traefik
+// router labels ("traefik.http.routers.<name>.rule"). It implements
+// HostnameSource, reporting each hostname's container IP:port as the upstream.
+type DockerClient struct {
+	Host    string
+	Network string
+	baseURL string
+	client  *http.Client
+}
+
+// DockerContainer is one entry from GET /containers/json
+type DockerContainer struct {
+	ID     string            `json:"Id"`
+	Names  []string          `json:"Names"`
+	Labels map[string]string `json:"Labels"`
+	State  string            `json:"State"`
+	Ports  []struct {
+		PrivatePort int    `json:"PrivatePort"`
+		Type        string `json:"Type"`
+	} `json:"Ports"`
+	NetworkSettings struct {
+		Networks map[string]struct {
+			IPAddress string `json:"IPAddress"`
+		} `json:"Networks"`
+	} `json:"NetworkSettings"`
+}
+
+// Name returns the container name without Docker's leading slash.
+func (c DockerContainer) Name() string {
+	if len(c.Names) == 0 {
+		return c.ID
+	}
+	return strings.TrimPrefix(c.Names[0], "/")
+}
+
+// NewDockerClient creates a Docker Engine API client. Unix socket hosts are
+// dialed directly; tcp:// hosts are reached over plain HTTP.
+func NewDockerClient(config DockerConfig) (*DockerClient, error) {
+	host := config.Host
+	if host == "" {
+		host = DefaultDockerHost
+	}
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Docker host %q: %w", host, err)
+	}
+
+	client := &DockerClient{
+		Host:    host,
+		Network: config.Network,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+	switch u.Scheme {
+	case "unix":
+		socket := u.Path
+		client.baseURL = "http://docker"
+		client.client.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+			},
+		}
+	case "tcp", "http":
+		client.baseURL = "http://" + u.Host
+	default:
+		return nil, fmt.Errorf("unsupported Docker host %q (want unix:// or tcp://)", host)
+	}
+	return client, nil
+}
+
+// ListContainers returns the running containers
+func (d *DockerClient) ListContainers() ([]DockerContainer, error) {
+	endpoint := d.baseURL + "/containers/json"
+	logging.Debug("Listing Docker containers", "host", d.Host)
+	resp, err := d.client.Get(endpoint)
+	if err != nil {
+		logging.Error("Failed to connect to Docker", "host", d.Host, "error", err)
+		return nil, fmt.Errorf("failed to connect to Docker at %s: %w", d.Host, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from Docker: %d", resp.StatusCode)
+	}
+
+	var containers []DockerContainer
+	if err := json.NewDecoder(resp.Body).Decode(&containers); err != nil {
+		return nil, fmt.Errorf("failed to parse Docker container list: %w", err)
+	}
+	return containers, nil
+}
+
+// GetHostnameDetails returns hostname -> route for every hostname named by a
+// running container's labels. Containers are visited by name, so when two
+// containers claim a hostname the result is stable.
+func (d *DockerClient) GetHostnameDetails() (map[string]models.CaddyRouteInfo, error) {
+	containers, err := d.ListContainers()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(containers, func(i, j int) bool { return containers[i].Name() < containers[j].Name() })
+
+	result := make(map[string]models.CaddyRouteInfo)
+	for _, container := range containers {
+		ip := d.containerIP(container)
+		if ip == "" {
+			logging.Debug("Skipping Docker container without a network IP", "container", container.Name())
+			continue
+		}
+		for _, route := range DockerLabelRoutes(container.Labels) {
+			port := route.Port
+			if port == 0 {
+				port = lowestTCPPort(container)
+			}
+			upstream := ip
+			if port != 0 {
+				upstream = net.JoinHostPort(ip, strconv.Itoa(port))
+			}
+			for _, hostname := range route.Hostnames {
+				if _, exists := result[hostname]; exists {
+					continue
+				}
+				result[hostname] = models.CaddyRouteInfo{
+					Upstream:      upstream,
+					HandlerChain:  []string{"reverse_proxy"},
+					TLSToUpstream: route.TLS,
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// containerIP picks the configured network's IP, or the first network's by
+// name.
+func (d *DockerClient) containerIP(container DockerContainer) string {
+	networks := container.NetworkSettings.Networks
+	if network, ok := networks[d.Network]; ok && d.Network != "" {
+		return network.IPAddress
+	}
+	names := make([]string, 0, len(networks))
+	for name := range networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if ip := networks[name].IPAddress; ip != "" {
+			return ip
+		}
+	}
+	return ""
+}
+
+func lowestTCPPort(container DockerContainer) int {
+	lowest := 0
+	for _, port := range container.Ports {
+		if port.Type == "tcp" && port.PrivatePort != 0 && (lowest == 0 || port.PrivatePort < lowest) {
+			lowest = port.PrivatePort
+		}
+	}
+	return lowest
+}
+
+// DockerLabelRoute is one set of hostnames found in container labels. Port is
+// zero when the labels do not name the container port.
+type DockerLabelRoute struct {
+	Hostnames []string
+	Port      int
+	TLS       bool
+}
+
+var (
+	// caddyLabel matches caddy-docker-proxy site labels: "caddy" and the
+	// numbered "caddy_0", "caddy_1", ... forms.
+	caddyLabel = regexp.MustCompile(`^caddy(_\d+)?$`)
+	// upstreamsPort extracts the port from "{{upstreams 8080}}" or
+	// "{{upstreams https 8443}}".
+	upstreamsPort = regexp.MustCompile(`\{\{\s*upstreams\s+(?:(https?)\s+)?(\d+)\s*\}\}`)
+)
+
+// DockerLabelRoutes extracts the routes declared by caddy-docker-proxy and
+// Traefik labels. Wildcard and templated site addresses are skipped because
+// they cannot be turned into DNS records.
+func DockerLabelRoutes(labels map[string]string) []DockerLabelRoute {
+	var routes []DockerLabelRoute
+
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !caddyLabel.MatchString(key) {
+			continue
+		}
+		route := DockerLabelRoute{Hostnames: caddySiteHostnames(labels[key])}
+		if len(route.Hostnames) == 0 {
+			continue
+		}
+		if match := upstreamsPort.FindStringSubmatch(labels[key+".reverse_proxy"]); match != nil {
+			route.Port, _ = strconv.Atoi(match[2])
+			route.TLS = match[1] == "https"
+		}
+		routes = append(routes, route)
+	}
+
+	if labels["traefik.enable"] == "false" {
+		return routes
+	}
+	const routerPrefix, servicePrefix = "traefik.http.routers.", "traefik.http.services."
+	var services []string
+	for _, key := range keys {
+		if name, ok := strings.CutPrefix(key, servicePrefix); ok && strings.HasSuffix(name, ".loadbalancer.server.port") {
+			services = append(services, strings.TrimSuffix(name, ".loadbalancer.server.port"))
+		}
+	}
+	for _, key := range keys {
+		router, ok := strings.CutPrefix(key, routerPrefix)
+		if !ok || !strings.HasSuffix(router, ".rule") {
+			continue
+		}
+		router = strings.TrimSuffix(router, ".rule")
+		route := DockerLabelRoute{Hostnames: ParseTraefikHostRule(labels[key])}
+		if len(route.Hostnames) == 0 {
+			continue
+		}
+		service := labels[routerPrefix+router+".service"]
+		if service == "" && len(services) == 1 {
+			service = services[0]
+		}
+		if service != "" {
+			route.Port, _ = strconv.Atoi(labels[servicePrefix+service+".loadbalancer.server.port"])
+			route.TLS = labels[servicePrefix+service+".loadbalancer.server.scheme"] == "https"
+		}
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+// caddySiteHostnames splits a caddy-docker-proxy site address list such as
+// "app.example.com, http://www.example.com:80" into bare hostnames.
+func caddySiteHostnames(value string) []string {
+	var hostnames []string
+	for _, address := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if i := strings.Index(address, "://"); i >= 0 {
+			address = address[i+3:]
+		}
+		if host, _, err := net.SplitHostPort(address); err == nil {
+			address = host
+		}
+		address = strings.ToLower(strings.TrimSuffix(address, "/"))
+		if address == "" || strings.ContainsAny(address, "*{}") || !strings.Contains(address, ".") {
+			continue
+		}
+		hostnames = append(hostnames, address)
+	}
+	return hostnames
+}
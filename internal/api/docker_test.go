@@ -0,0 +1,113 @@
+package api
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func newDockerFixtureSocket(t *testing.T) string {
+	t.Helper()
+	socket := filepath.Join(t.TempDir(), "docker.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("listen on unix socket: %v", err)
+	}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/containers/json" {
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `[
+			{"Id":"c1","Names":["/sonarr"],"State":"running",
+			 "Labels":{"caddy":"sonarr.example.test","caddy.reverse_proxy":"{{upstreams 8989}}"},
+			 "NetworkSettings":{"Networks":{"proxy":{"IPAddress":"172.18.0.5"}}}},
+			{"Id":"c2","Names":["/grafana"],"State":"running",
+			 "Labels":{"traefik.enable":"true","traefik.http.routers.grafana.rule":"Host(`+"`grafana.example.test`"+`)"},
+			 "Ports":[{"PrivatePort":9090,"Type":"tcp"},{"PrivatePort":3000,"Type":"tcp"}],
+			 "NetworkSettings":{"Networks":{"monitoring":{"IPAddress":"172.19.0.7"},"proxy":{"IPAddress":"172.18.0.7"}}}},
+			{"Id":"c3","Names":["/hostnet"],"State":"running",
+			 "Labels":{"caddy":"hostnet.example.test"},
+			 "NetworkSettings":{"Networks":{"host":{"IPAddress":""}}}},
+			{"Id":"c4","Names":["/unlabelled"],"State":"running","Labels":{},
+			 "NetworkSettings":{"Networks":{"proxy":{"IPAddress":"172.18.0.9"}}}}
+		]`)
+	}))
+	server.Listener = listener
+	server.Start()
+	t.Cleanup(server.Close)
+	return socket
+}
+
+func TestDockerClientGetHostnameDetailsOverUnixSocket(t *testing.T) {
+	socket := newDockerFixtureSocket(t)
+	client, err := NewDockerClient(DockerConfig{Host: "unix://" + socket, Network: "proxy"})
+	if err != nil {
+		t.Fatalf("NewDockerClient failed: %v", err)
+	}
+
+	details, err := client.GetHostnameDetails()
+	if err != nil {
+		t.Fatalf("GetHostnameDetails failed: %v", err)
+	}
+	if len(details) != 2 {
+		t.Fatalf("expected two hostnames, got %#v", details)
+	}
+	if got := details["sonarr.example.test"].Upstream; got != "172.18.0.5:8989" {
+		t.Fatalf("unexpected sonarr upstream %q", got)
+	}
+	if got := details["grafana.example.test"].Upstream; got != "172.18.0.7:3000" {
+		t.Fatalf("expected the proxy network IP and lowest exposed port, got %q", got)
+	}
+}
+
+func TestNewDockerClientRejectsUnknownScheme(t *testing.T) {
+	if _, err := NewDockerClient(DockerConfig{Host: "ssh://docker.lan"}); err == nil {
+		t.Fatal("expected an error for an ssh:// Docker host")
+	}
+}
+
+func TestDockerLabelRoutes(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   []DockerLabelRoute
+	}{
+		{
+			name: "caddy-docker-proxy with numbered sites",
+			labels: map[string]string{
+				"caddy_0":               "app.example.test, http://www.example.test:80",
+				"caddy_0.reverse_proxy": "{{upstreams https 8443}}",
+				"caddy_1":               "*.example.test",
+			},
+			want: []DockerLabelRoute{{Hostnames: []string{"app.example.test", "www.example.test"}, Port: 8443, TLS: true}},
+		},
+		{
+			name: "traefik router with named service",
+			labels: map[string]string{
+				"traefik.http.routers.web.rule":                          "Host(`web.example.test`) && PathPrefix(`/`)",
+				"traefik.http.routers.web.service":                       "web-svc",
+				"traefik.http.services.web-svc.loadbalancer.server.port": "8080",
+				"traefik.http.services.other.loadbalancer.server.port":   "9000",
+			},
+			want: []DockerLabelRoute{{Hostnames: []string{"web.example.test"}, Port: 8080}},
+		},
+		{
+			name: "traefik disabled",
+			labels: map[string]string{
+				"traefik.enable":                "false",
+				"traefik.http.routers.web.rule": "Host(`web.example.test`)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DockerLabelRoutes(tt.labels); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("DockerLabelRoutes() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
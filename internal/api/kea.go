@@ -0,0 +1,221 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/logging"
+)
+
+const (
+	// DHCPBackendDnsmasq selects OPNsense Dnsmasq for DHCP leases and reservations.
+	DHCPBackendDnsmasq = "dnsmasq"
+	// DHCPBackendKea selects OPNsense Kea DHCPv4 for DHCP leases and reservations.
+	DHCPBackendKea = "kea"
+)
+
+// KeaSubnet represents a Kea DHCPv4 subnet
+type KeaSubnet struct {
+	UUID        string `json:"uuid"`
+	Subnet      string `json:"subnet"`
+	Description string `json:"description"`
+}
+
+// KeaReservation represents a Kea DHCPv4 host reservation
+type KeaReservation struct {
+	UUID        string `json:"uuid,omitempty"`
+	Subnet      string `json:"subnet"`
+	IPAddress   string `json:"ip_address"`
+	HWAddress   string `json:"hw_address"`
+	Hostname    string `json:"hostname"`
+	Description string `json:"description"`
+}
+
+// keaLease is a row from /api/kea/leases4/search
+type keaLease struct {
+	Address  string `json:"address"`
+	HWAddr   string `json:"hwaddr"`
+	Hostname string `json:"hostname"`
+	Expire   int64  `json:"expire"`
+}
+
+// KeaClient handles Kea DHCPv4 lease and reservation calls via OPNSense API
+type KeaClient struct {
+	client *Client
+}
+
+// NewKeaClient creates a new Kea client
+func NewKeaClient(config Config) *KeaClient {
+	return &KeaClient{
+		client: NewClient(config),
+	}
+}
+
+// WithContext returns a shallow copy of the client with the given context.
+func (c *KeaClient) WithContext(ctx context.Context) *KeaClient {
+	return &KeaClient{client: c.client.WithContext(ctx)}
+}
+
+// GetLeases retrieves all DHCPv4 leases from Kea. Leases whose MAC address has
+// a reservation are reported as "static".
+func (c *KeaClient) GetLeases() ([]DNSMasqLease, error) {
+	logging.Debug("Fetching Kea DHCP leases")
+
+	resp, err := c.client.makeRequest("GET", "/api/kea/leases4/search", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Kea leases: %w", err)
+	}
+	var rows []keaLease
+	if len(resp.Rows) > 0 {
+		if err := json.Unmarshal(resp.Rows, &rows); err != nil {
+			return nil, fmt.Errorf("error parsing lease rows: %w - Data: %s", err, string(resp.Rows))
+		}
+	}
+
+	reservations, err := c.GetReservations()
+	if err != nil {
+		return nil, err
+	}
+	reserved := make(map[string]bool, len(reservations))
+	for _, reservation := range reservations {
+		reserved[strings.ToLower(reservation.HWAddress)] = true
+	}
+
+	leases := make([]DNSMasqLease, 0, len(rows))
+	for _, row := range rows {
+		lease := DNSMasqLease{
+			Hostname:   strings.TrimSuffix(row.Hostname, "."),
+			IPAddress:  row.Address,
+			MACAddress: row.HWAddr,
+			Expires:    row.Expire,
+			Type:       "dynamic",
+		}
+		if reserved[strings.ToLower(row.HWAddr)] {
+			lease.Type = "static"
+		}
+		leases = append(leases, lease)
+	}
+
+	logging.Debug("Successfully fetched Kea leases", "count", len(leases))
+	return leases, nil
+}
+
+// GetSubnets retrieves all Kea DHCPv4 subnets
+func (c *KeaClient) GetSubnets() ([]KeaSubnet, error) {
+	resp, err := c.client.makeRequest("GET", "/api/kea/dhcpv4/searchSubnet", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Kea subnets: %w", err)
+	}
+	subnets := []KeaSubnet{}
+	if len(resp.Rows) > 0 {
+		if err := json.Unmarshal(resp.Rows, &subnets); err != nil {
+			return nil, fmt.Errorf("error parsing subnet rows: %w - Data: %s", err, string(resp.Rows))
+		}
+	}
+	return subnets, nil
+}
+
+// GetReservations retrieves all Kea DHCPv4 reservations
+func (c *KeaClient) GetReservations() ([]KeaReservation, error) {
+	resp, err := c.client.makeRequest("GET", "/api/kea/dhcpv4/searchReservation", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Kea reservations: %w", err)
+	}
+	reservations := []KeaReservation{}
+	if len(resp.Rows) > 0 {
+		if err := json.Unmarshal(resp.Rows, &reservations); err != nil {
+			return nil, fmt.Errorf("error parsing reservation rows: %w - Data: %s", err, string(resp.Rows))
+		}
+	}
+	return reservations, nil
+}
+
+// AddReservation creates a DHCPv4 reservation in the subnet that contains
+// reservation.IP. It is a no-op when the MAC is already reserved for the same IP.
+func (c *KeaClient) AddReservation(reservation DHCPReservation) error {
+	existing, err := c.GetReservations()
+	if err != nil {
+		return err
+	}
+	for _, r := range existing {
+		if !strings.EqualFold(r.HWAddress, reservation.MAC) {
+			continue
+		}
+		if r.IPAddress == reservation.IP {
+			logging.Debug("Kea reservation already exists", "mac", reservation.MAC, "ip", reservation.IP)
+			return nil
+		}
+		return fmt.Errorf("MAC %s is already reserved for %s", reservation.MAC, r.IPAddress)
+	}
+
+	subnet, err := c.subnetFor(reservation.IP)
+	if err != nil {
+		return err
+	}
+
+	jsonData, err := json.Marshal(map[string]KeaReservation{
+		"reservation": {
+			Subnet:      subnet.UUID,
+			IPAddress:   reservation.IP,
+			HWAddress:   strings.ToLower(reservation.MAC),
+			Hostname:    reservation.Hostname,
+			Description: reservation.Description,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error marshaling reservation: %w", err)
+	}
+
+	resp, err := c.client.makeRequest("POST", "/api/kea/dhcpv4/addReservation", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	if resp.Result != "saved" {
+		logging.Error("API returned error", "result", resp.Result, "message", resp.Message)
+		return fmt.Errorf("API error: %s - %s", resp.Result, resp.Message)
+	}
+
+	logging.Info("Successfully added Kea reservation", "mac", reservation.MAC, "ip", reservation.IP, "subnet", subnet.Subnet)
+	return nil
+}
+
+// Reconfigure applies pending reservation changes to the Kea service
+func (c *KeaClient) Reconfigure() error {
+	logging.Debug("Applying changes to Kea service")
+
+	resp, err := c.client.makeRequest("POST", "/api/kea/service/reconfigure", bytes.NewBufferString("{}"))
+	if err != nil {
+		return err
+	}
+	if resp.Result != "saved" && resp.Status != "ok" {
+		logging.Error("API returned error", "result", resp.Result, "status", resp.Status, "message", resp.Message)
+		return fmt.Errorf("API returned error: %s", resp.Message)
+	}
+
+	if err := c.client.persistConfig(); err != nil {
+		logging.Warn("Config persist after reconfigure failed (non-fatal)", "error", err)
+	}
+	return nil
+}
+
+func (c *KeaClient) subnetFor(ip string) (KeaSubnet, error) {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return KeaSubnet{}, fmt.Errorf("invalid IP address: %q", ip)
+	}
+	subnets, err := c.GetSubnets()
+	if err != nil {
+		return KeaSubnet{}, err
+	}
+	for _, subnet := range subnets {
+		_, network, err := net.ParseCIDR(subnet.Subnet)
+		if err == nil && network.Contains(addr) {
+			return subnet, nil
+		}
+	}
+	return KeaSubnet{}, fmt.Errorf("no Kea subnet contains %s", ip)
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newKeaFixtureServer(t *testing.T, added *[]KeaReservation) *httptest.Server {
+	t.Helper()
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/kea/leases4/search":
+			fmt.Fprint(w, `{"rows":[
+				{"address":"10.0.0.5","hwaddr":"aa:bb:cc:00:00:05","hostname":"media.","expire":1760000000},
+				{"address":"10.0.0.9","hwaddr":"aa:bb:cc:00:00:09","hostname":"tv","expire":1760000000}
+			]}`)
+		case "/api/kea/dhcpv4/searchReservation":
+			fmt.Fprint(w, `{"rows":[{"uuid":"uuid-tv","subnet":"10.0.0.0/24","ip_address":"10.0.0.9","hw_address":"AA:BB:CC:00:00:09","hostname":"tv"}]}`)
+		case "/api/kea/dhcpv4/searchSubnet":
+			fmt.Fprint(w, `{"rows":[{"uuid":"uuid-iot","subnet":"10.0.10.0/24"},{"uuid":"uuid-lan","subnet":"10.0.0.0/24"}]}`)
+		case "/api/kea/dhcpv4/addReservation":
+			var body map[string]KeaReservation
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Fatalf("invalid addReservation body: %v", err)
+			}
+			*added = append(*added, body["reservation"])
+			fmt.Fprint(w, `{"result":"saved","uuid":"uuid-new"}`)
+		case "/api/kea/service/reconfigure", "/api/core/firmware/backup":
+			fmt.Fprint(w, `{"status":"ok"}`)
+		default:
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+	}))
+}
+
+func TestKeaClientGetLeasesMarksReservedMACsStatic(t *testing.T) {
+	var added []KeaReservation
+	server := newKeaFixtureServer(t, &added)
+	defer server.Close()
+
+	leases, err := NewKeaClient(Config{BaseURL: server.URL, Insecure: true}).GetLeases()
+	if err != nil {
+		t.Fatalf("GetLeases failed: %v", err)
+	}
+	if len(leases) != 2 {
+		t.Fatalf("expected two leases, got %#v", leases)
+	}
+	if leases[0].Hostname != "media" || leases[0].Type != "dynamic" {
+		t.Fatalf("unexpected first lease: %#v", leases[0])
+	}
+	if leases[1].Type != "static" {
+		t.Fatalf("expected reserved MAC to be static, got %#v", leases[1])
+	}
+}
+
+func TestKeaClientAddReservationUsesContainingSubnet(t *testing.T) {
+	var added []KeaReservation
+	server := newKeaFixtureServer(t, &added)
+	defer server.Close()
+
+	client := NewKeaClient(Config{BaseURL: server.URL, Insecure: true})
+	if err := client.AddReservation(DHCPReservation{MAC: "aa:bb:cc:00:00:09", IP: "10.0.0.9", Hostname: "tv"}); err != nil {
+		t.Fatalf("expected existing reservation to be a no-op, got %v", err)
+	}
+	if err := client.AddReservation(DHCPReservation{MAC: "AA:BB:CC:00:00:05", IP: "10.0.0.5", Hostname: "media", Description: "fixture"}); err != nil {
+		t.Fatalf("AddReservation failed: %v", err)
+	}
+	if err := client.AddReservation(DHCPReservation{MAC: "aa:bb:cc:00:00:07", IP: "192.168.1.7", Hostname: "guest"}); err == nil {
+		t.Fatal("expected an error for an address outside every subnet")
+	}
+	if err := client.Reconfigure(); err != nil {
+		t.Fatalf("Reconfigure failed: %v", err)
+	}
+
+	want := KeaReservation{Subnet: "uuid-lan", IPAddress: "10.0.0.5", HWAddress: "aa:bb:cc:00:00:05", Hostname: "media", Description: "fixture"}
+	if len(added) != 1 || added[0] != want {
+		t.Fatalf("unexpected reservations added: %#v", added)
+	}
+}
@@ -0,0 +1,492 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/logging"
+	"github.com/jeeftor/caddy-dns-sync/internal/models"
+)
+
+// KubernetesConfig represents configuration for reading Ingress and HTTPRoute
+// objects from a cluster
+type KubernetesConfig struct {
+	// Kubeconfig is the kubeconfig file. Empty means ~/.kube/config.
+	Kubeconfig string `json:"kubeconfig,omitempty" mapstructure:"kubeconfig"`
+	// Context selects a kubeconfig context. Empty means current-context.
+	Context string `json:"context,omitempty" mapstructure:"context"`
+	// Namespace limits discovery to one namespace. Empty means all.
+	Namespace string `json:"namespace,omitempty" mapstructure:"namespace"`
+}
+
+// KubernetesClient discovers hostnames from networking.k8s.io/v1 Ingress and
+// gateway.networking.k8s.io/v1 HTTPRoute objects. Each hostname is served by
+// the LoadBalancer IP of its ingress controller or Gateway.
+type KubernetesClient struct {
+	Server    string
+	Namespace string
+	token     string
+	client    *http.Client
+}
+
+// KubernetesRoute is a hostname found in the cluster. ServeIP is the address
+// its DNS records should carry; Object names the Ingress or HTTPRoute as
+// "kind/namespace/name".
+type KubernetesRoute struct {
+	Route   models.CaddyRouteInfo
+	ServeIP string
+	Object  string
+}
+
+// kubeconfig is the subset of a kubeconfig file needed to reach a cluster.
+type kubeconfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User struct {
+			Token                 string `yaml:"token"`
+			TokenFile             string `yaml:"tokenFile"`
+			ClientCertificate     string `yaml:"client-certificate"`
+			ClientCertificateData string `yaml:"client-certificate-data"`
+			ClientKey             string `yaml:"client-key"`
+			ClientKeyData         string `yaml:"client-key-data"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+	Contexts []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster string `yaml:"cluster"`
+			User    string `yaml:"user"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+}
+
+// DefaultKubeconfigPath returns ~/.kube/config.
+func DefaultKubeconfigPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".kube", "config")
+}
+
+// NewKubernetesClient creates a client from a kubeconfig file. Only static
+// credentials (bearer tokens and client certificates) are supported; exec and
+// auth-provider plugins are not.
+func NewKubernetesClient(config KubernetesConfig) (*KubernetesClient, error) {
+	path := config.Kubeconfig
+	if path == "" {
+		path = DefaultKubeconfigPath()
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading kubeconfig: %w", err)
+	}
+	var kc kubeconfig
+	if err := yaml.Unmarshal(data, &kc); err != nil {
+		return nil, fmt.Errorf("error parsing kubeconfig %s: %w", path, err)
+	}
+	base := filepath.Dir(path)
+
+	contextName := config.Context
+	if contextName == "" {
+		contextName = kc.CurrentContext
+	}
+	var clusterName, userName string
+	found := false
+	for _, c := range kc.Contexts {
+		if c.Name == contextName {
+			clusterName, userName, found = c.Context.Cluster, c.Context.User, true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("kubeconfig context %q not found in %s", contextName, path)
+	}
+
+	client := &KubernetesClient{Namespace: config.Namespace}
+	tlsConfig := &tls.Config{}
+	for _, c := range kc.Clusters {
+		if c.Name != clusterName {
+			continue
+		}
+		client.Server = strings.TrimRight(c.Cluster.Server, "/")
+		tlsConfig.InsecureSkipVerify = c.Cluster.InsecureSkipTLSVerify
+		ca, err := kubeconfigBytes(c.Cluster.CertificateAuthorityData, c.Cluster.CertificateAuthority, base)
+		if err != nil {
+			return nil, fmt.Errorf("error reading cluster CA: %w", err)
+		}
+		if len(ca) > 0 {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(ca) {
+				return nil, fmt.Errorf("cluster %q has no valid CA certificates", clusterName)
+			}
+			tlsConfig.RootCAs = pool
+		}
+	}
+	if client.Server == "" {
+		return nil, fmt.Errorf("kubeconfig cluster %q not found in %s", clusterName, path)
+	}
+
+	for _, u := range kc.Users {
+		if u.Name != userName {
+			continue
+		}
+		client.token = u.User.Token
+		if client.token == "" && u.User.TokenFile != "" {
+			token, err := kubeconfigBytes("", u.User.TokenFile, base)
+			if err != nil {
+				return nil, fmt.Errorf("error reading user token: %w", err)
+			}
+			client.token = strings.TrimSpace(string(token))
+		}
+		cert, err := kubeconfigBytes(u.User.ClientCertificateData, u.User.ClientCertificate, base)
+		if err != nil {
+			return nil, fmt.Errorf("error reading client certificate: %w", err)
+		}
+		key, err := kubeconfigBytes(u.User.ClientKeyData, u.User.ClientKey, base)
+		if err != nil {
+			return nil, fmt.Errorf("error reading client key: %w", err)
+		}
+		if len(cert) > 0 {
+			pair, err := tls.X509KeyPair(cert, key)
+			if err != nil {
+				return nil, fmt.Errorf("invalid client certificate for user %q: %w", userName, err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{pair}
+		}
+	}
+
+	client.client = &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+	}
+	return client, nil
+}
+
+// kubeconfigBytes returns inline base64 data, or the contents of file
+// resolved against the kubeconfig's directory.
+func kubeconfigBytes(data, file, base string) ([]byte, error) {
+	if data != "" {
+		return base64.StdEncoding.DecodeString(data)
+	}
+	if file == "" {
+		return nil, nil
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(base, file)
+	}
+	return os.ReadFile(file)
+}
+
+// kubernetesIngress is the subset of networking.k8s.io/v1 Ingress used here.
+type kubernetesIngress struct {
+	Metadata kubernetesMeta `json:"metadata"`
+	Spec     struct {
+		DefaultBackend *kubernetesIngressBackend `json:"defaultBackend"`
+		Rules          []struct {
+			Host string `json:"host"`
+			HTTP *struct {
+				Paths []struct {
+					Backend kubernetesIngressBackend `json:"backend"`
+				} `json:"paths"`
+			} `json:"http"`
+		} `json:"rules"`
+	} `json:"spec"`
+	Status struct {
+		LoadBalancer struct {
+			Ingress []struct {
+				IP string `json:"ip"`
+			} `json:"ingress"`
+		} `json:"loadBalancer"`
+	} `json:"status"`
+}
+
+type kubernetesIngressBackend struct {
+	Service *struct {
+		Name string `json:"name"`
+		Port struct {
+			Number int    `json:"number"`
+			Name   string `json:"name"`
+		} `json:"port"`
+	} `json:"service"`
+}
+
+// kubernetesHTTPRoute is the subset of gateway.networking.k8s.io/v1 HTTPRoute used here.
+type kubernetesHTTPRoute struct {
+	Metadata kubernetesMeta `json:"metadata"`
+	Spec     struct {
+		Hostnames  []string `json:"hostnames"`
+		ParentRefs []struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+			Kind      string `json:"kind"`
+		} `json:"parentRefs"`
+		Rules []struct {
+			BackendRefs []struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+				Port      int    `json:"port"`
+			} `json:"backendRefs"`
+		} `json:"rules"`
+	} `json:"spec"`
+}
+
+// kubernetesGateway is the subset of gateway.networking.k8s.io/v1 Gateway used here.
+type kubernetesGateway struct {
+	Metadata kubernetesMeta `json:"metadata"`
+	Status   struct {
+		Addresses []struct {
+			Type  string `json:"type"`
+			Value string `json:"value"`
+		} `json:"addresses"`
+	} `json:"status"`
+}
+
+type kubernetesMeta struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// GetRoutes returns hostname -> route for every Ingress rule host and
+// HTTPRoute hostname. Wildcard hosts and objects whose controller has not
+// been assigned an IP yet are skipped. Clusters without the Gateway API
+// installed simply contribute no HTTPRoutes.
+func (k *KubernetesClient) GetRoutes() (map[string]KubernetesRoute, error) {
+	var ingresses []kubernetesIngress
+	if err := k.list("/apis/networking.k8s.io/v1", "ingresses", &ingresses); err != nil {
+		return nil, err
+	}
+	var httpRoutes []kubernetesHTTPRoute
+	var gateways []kubernetesGateway
+	if err := k.list("/apis/gateway.networking.k8s.io/v1", "httproutes", &httpRoutes); err != nil && !isKubernetesNotFound(err) {
+		return nil, err
+	}
+	if len(httpRoutes) > 0 {
+		if err := k.listAll("/apis/gateway.networking.k8s.io/v1/gateways", &gateways); err != nil {
+			return nil, err
+		}
+	}
+
+	result := make(map[string]KubernetesRoute)
+	add := func(hostname string, route KubernetesRoute) {
+		hostname = strings.ToLower(hostname)
+		if hostname == "" || strings.Contains(hostname, "*") {
+			return
+		}
+		if route.ServeIP == "" {
+			logging.Warn("Skipping Kubernetes hostname without a LoadBalancer IP", "hostname", hostname, "object", route.Object)
+			return
+		}
+		if _, exists := result[hostname]; !exists {
+			result[hostname] = route
+		}
+	}
+
+	sort.Slice(ingresses, func(i, j int) bool { return ingresses[i].Metadata.key() < ingresses[j].Metadata.key() })
+	for _, ingress := range ingresses {
+		serveIP := ""
+		for _, lb := range ingress.Status.LoadBalancer.Ingress {
+			if lb.IP != "" {
+				serveIP = lb.IP
+				break
+			}
+		}
+		for _, rule := range ingress.Spec.Rules {
+			backend := ingress.Spec.DefaultBackend
+			if rule.HTTP != nil && len(rule.HTTP.Paths) > 0 {
+				backend = &rule.HTTP.Paths[0].Backend
+			}
+			upstream := ""
+			if backend != nil && backend.Service != nil {
+				port := backend.Service.Port.Name
+				if backend.Service.Port.Number != 0 {
+					port = strconv.Itoa(backend.Service.Port.Number)
+				}
+				upstream = serviceUpstream(backend.Service.Name, ingress.Metadata.Namespace, port)
+			}
+			add(rule.Host, KubernetesRoute{
+				Route:   models.CaddyRouteInfo{Upstream: upstream, HandlerChain: []string{"reverse_proxy"}},
+				ServeIP: serveIP,
+				Object:  "ingress/" + ingress.Metadata.key(),
+			})
+		}
+	}
+
+	gatewayIPs := make(map[string]string, len(gateways))
+	for _, gateway := range gateways {
+		for _, address := range gateway.Status.Addresses {
+			if address.Type == "" || address.Type == "IPAddress" {
+				gatewayIPs[gateway.Metadata.key()] = address.Value
+				break
+			}
+		}
+	}
+	sort.Slice(httpRoutes, func(i, j int) bool { return httpRoutes[i].Metadata.key() < httpRoutes[j].Metadata.key() })
+	for _, httpRoute := range httpRoutes {
+		serveIP := ""
+		for _, parent := range httpRoute.Spec.ParentRefs {
+			if parent.Kind != "" && parent.Kind != "Gateway" {
+				continue
+			}
+			namespace := parent.Namespace
+			if namespace == "" {
+				namespace = httpRoute.Metadata.Namespace
+			}
+			if ip := gatewayIPs[namespace+"/"+parent.Name]; ip != "" {
+				serveIP = ip
+				break
+			}
+		}
+		upstream := ""
+		for _, rule := range httpRoute.Spec.Rules {
+			if len(rule.BackendRefs) > 0 {
+				ref := rule.BackendRefs[0]
+				namespace := ref.Namespace
+				if namespace == "" {
+					namespace = httpRoute.Metadata.Namespace
+				}
+				upstream = serviceUpstream(ref.Name, namespace, strconv.Itoa(ref.Port))
+				break
+			}
+		}
+		for _, hostname := range httpRoute.Spec.Hostnames {
+			add(hostname, KubernetesRoute{
+				Route:   models.CaddyRouteInfo{Upstream: upstream, HandlerChain: []string{"reverse_proxy"}},
+				ServeIP: serveIP,
+				Object:  "httproute/" + httpRoute.Metadata.key(),
+			})
+		}
+	}
+	return result, nil
+}
+
+// GetHostnameDetails returns hostname -> route, implementing HostnameSource.
+func (k *KubernetesClient) GetHostnameDetails() (map[string]models.CaddyRouteInfo, error) {
+	routes, err := k.GetRoutes()
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]models.CaddyRouteInfo, len(routes))
+	for hostname, route := range routes {
+		result[hostname] = route.Route
+	}
+	return result, nil
+}
+
+func (m kubernetesMeta) key() string {
+	return m.Namespace + "/" + m.Name
+}
+
+// serviceUpstream formats a backend Service as its in-cluster DNS name.
+func serviceUpstream(name, namespace, port string) string {
+	host := name + "." + namespace + ".svc"
+	if port == "" || port == "0" {
+		return host
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// kubernetesStatusError is a non-200 response from the API server.
+type kubernetesStatusError struct {
+	Path   string
+	Status int
+}
+
+func (e *kubernetesStatusError) Error() string {
+	return fmt.Sprintf("unexpected status code from %s: %d", e.Path, e.Status)
+}
+
+func isKubernetesNotFound(err error) bool {
+	statusErr, ok := err.(*kubernetesStatusError)
+	return ok && statusErr.Status == http.StatusNotFound
+}
+
+// list fetches a namespaced resource, across all namespaces unless the client
+// is limited to one.
+func (k *KubernetesClient) list(group, resource string, out interface{}) error {
+	path := group + "/" + resource
+	if k.Namespace != "" {
+		path = group + "/namespaces/" + k.Namespace + "/" + resource
+	}
+	return k.listAll(path, out)
+}
+
+// listAll fetches every page of a list endpoint into out.
+func (k *KubernetesClient) listAll(path string, out interface{}) error {
+	var all []json.RawMessage
+	continueToken := ""
+	for {
+		endpoint := k.Server + path + "?limit=500"
+		if continueToken != "" {
+			endpoint += "&continue=" + url.QueryEscape(continueToken)
+		}
+		logging.Debug("Fetching Kubernetes API", "url", endpoint)
+		req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+		if err != nil {
+			return fmt.Errorf("error creating request: %w", err)
+		}
+		if k.token != "" {
+			req.Header.Set("Authorization", "Bearer "+k.token)
+		}
+		req.Header.Set("Accept", "application/json")
+
+		resp, err := k.client.Do(req)
+		if err != nil {
+			logging.Error("Failed to connect to Kubernetes API", "error", err)
+			return fmt.Errorf("failed to connect to Kubernetes API: %w", err)
+		}
+		var page struct {
+			Metadata struct {
+				Continue string `json:"continue"`
+			} `json:"metadata"`
+			Items []json.RawMessage `json:"items"`
+		}
+		err = func() error {
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return &kubernetesStatusError{Path: path, Status: resp.StatusCode}
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+				return fmt.Errorf("failed to parse Kubernetes response from %s: %w", path, err)
+			}
+			return nil
+		}()
+		if err != nil {
+			return err
+		}
+		all = append(all, page.Items...)
+		if page.Metadata.Continue == "" {
+			break
+		}
+		continueToken = page.Metadata.Continue
+	}
+
+	data, err := json.Marshal(all)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
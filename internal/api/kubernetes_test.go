@@ -0,0 +1,136 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newKubernetesFixtureServer(t *testing.T, gatewayAPI bool) *httptest.Server {
+	t.Helper()
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer fixture-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/apis/networking.k8s.io/v1/ingresses":
+			if r.URL.Query().Get("continue") == "" {
+				fmt.Fprint(w, `{"metadata":{"continue":"page-2"},"items":[
+					{"metadata":{"name":"wiki","namespace":"apps"},
+					 "spec":{"rules":[{"host":"Wiki.example.test","http":{"paths":[{"backend":{"service":{"name":"wiki","port":{"number":3000}}}}]}},
+					                  {"host":"*.example.test"}]},
+					 "status":{"loadBalancer":{"ingress":[{"ip":"10.0.0.40"}]}}}
+				]}`)
+				return
+			}
+			fmt.Fprint(w, `{"metadata":{},"items":[
+				{"metadata":{"name":"pending","namespace":"apps"},
+				 "spec":{"rules":[{"host":"pending.example.test"}]},
+				 "status":{"loadBalancer":{}}}
+			]}`)
+		case "/apis/gateway.networking.k8s.io/v1/httproutes":
+			if !gatewayAPI {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			fmt.Fprint(w, `{"metadata":{},"items":[
+				{"metadata":{"name":"grafana","namespace":"monitoring"},
+				 "spec":{"hostnames":["grafana.example.test"],
+				         "parentRefs":[{"name":"lan","namespace":"gateways"}],
+				         "rules":[{"backendRefs":[{"name":"grafana","port":80}]}]}}
+			]}`)
+		case "/apis/gateway.networking.k8s.io/v1/gateways":
+			fmt.Fprint(w, `{"metadata":{},"items":[
+				{"metadata":{"name":"lan","namespace":"gateways"},"status":{"addresses":[{"type":"IPAddress","value":"10.0.0.41"}]}}
+			]}`)
+		default:
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+	}))
+}
+
+func writeKubeconfig(t *testing.T, server string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config")
+	kubeconfig := fmt.Sprintf(`apiVersion: v1
+kind: Config
+current-context: other
+clusters:
+- name: k3s
+  cluster:
+    server: %s
+    insecure-skip-tls-verify: true
+users:
+- name: k3s-admin
+  user:
+    tokenFile: token
+contexts:
+- name: other
+  context: {cluster: missing, user: missing}
+- name: k3s
+  context: {cluster: k3s, user: k3s-admin}
+`, server)
+	if err := os.WriteFile(path, []byte(kubeconfig), 0o600); err != nil {
+		t.Fatalf("write kubeconfig: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(filepath.Dir(path), "token"), []byte("fixture-token\n"), 0o600); err != nil {
+		t.Fatalf("write token: %v", err)
+	}
+	return path
+}
+
+func TestKubernetesClientGetRoutes(t *testing.T) {
+	server := newKubernetesFixtureServer(t, true)
+	defer server.Close()
+
+	client, err := NewKubernetesClient(KubernetesConfig{Kubeconfig: writeKubeconfig(t, server.URL), Context: "k3s"})
+	if err != nil {
+		t.Fatalf("NewKubernetesClient failed: %v", err)
+	}
+	routes, err := client.GetRoutes()
+	if err != nil {
+		t.Fatalf("GetRoutes failed: %v", err)
+	}
+	if len(routes) != 2 {
+		t.Fatalf("expected two routable hostnames, got %#v", routes)
+	}
+	wiki := routes["wiki.example.test"]
+	if wiki.ServeIP != "10.0.0.40" || wiki.Route.Upstream != "wiki.apps.svc:3000" || wiki.Object != "ingress/apps/wiki" {
+		t.Fatalf("unexpected ingress route: %#v", wiki)
+	}
+	grafana := routes["grafana.example.test"]
+	if grafana.ServeIP != "10.0.0.41" || grafana.Route.Upstream != "grafana.monitoring.svc:80" {
+		t.Fatalf("unexpected HTTPRoute route: %#v", grafana)
+	}
+}
+
+func TestKubernetesClientWithoutGatewayAPI(t *testing.T) {
+	server := newKubernetesFixtureServer(t, false)
+	defer server.Close()
+
+	client, err := NewKubernetesClient(KubernetesConfig{Kubeconfig: writeKubeconfig(t, server.URL), Context: "k3s"})
+	if err != nil {
+		t.Fatalf("NewKubernetesClient failed: %v", err)
+	}
+	details, err := client.GetHostnameDetails()
+	if err != nil {
+		t.Fatalf("GetHostnameDetails failed: %v", err)
+	}
+	if len(details) != 1 || details["wiki.example.test"].Upstream == "" {
+		t.Fatalf("expected only the ingress hostname, got %#v", details)
+	}
+}
+
+func TestNewKubernetesClientRejectsUnknownContext(t *testing.T) {
+	path := writeKubeconfig(t, "https://127.0.0.1:6443")
+	if _, err := NewKubernetesClient(KubernetesConfig{Kubeconfig: path}); err == nil {
+		t.Fatal("expected an error for a context whose cluster is missing")
+	}
+	if _, err := NewKubernetesClient(KubernetesConfig{Kubeconfig: path, Context: "prod"}); err == nil {
+		t.Fatal("expected an error for an unknown context")
+	}
+}
@@ -0,0 +1,433 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/logging"
+)
+
+// PiholeConfig represents configuration for the Pi-hole v6 REST API
+type PiholeConfig struct {
+	BaseURL  string `json:"base_url" mapstructure:"base_url"`
+	Password string `json:"password" mapstructure:"password"`
+	Insecure bool   `json:"insecure" mapstructure:"insecure"`
+	Enabled  bool   `json:"enabled" mapstructure:"enabled"`
+}
+
+// PiholeClient handles communication with the Pi-hole v6 REST API.
+// Pi-hole v6 authenticates with a password (or app password) exchanged for a
+// session ID, which is sent as X-FTL-SID on every request. The session is
+// shared by copies made with WithContext and renewed on a 401.
+type PiholeClient struct {
+	BaseURL  string
+	Password string
+	client   *http.Client
+	session  *piholeSession
+	ctx      context.Context
+}
+
+type piholeSession struct {
+	mu  sync.Mutex
+	sid string
+}
+
+// PiholeHost is one local DNS record ("IP hostname") from dns.hosts. Line is
+// the dns.hosts line it was read from, which may list several hostnames.
+type PiholeHost struct {
+	IP       string `json:"ip"`
+	Hostname string `json:"hostname"`
+	Line     string `json:"-"`
+}
+
+// PiholeCNAME is one local CNAME record ("domain,target[,ttl]") from dns.cnameRecords
+type PiholeCNAME struct {
+	Domain string `json:"domain"`
+	Target string `json:"target"`
+	TTL    int    `json:"ttl,omitempty"`
+}
+
+// piholeAuthResponse is the body returned by POST /api/auth
+type piholeAuthResponse struct {
+	Session struct {
+		Valid   bool   `json:"valid"`
+		SID     string `json:"sid"`
+		Message string `json:"message"`
+	} `json:"session"`
+}
+
+// piholeErrorResponse is the error envelope used by every Pi-hole v6 endpoint
+type piholeErrorResponse struct {
+	Error struct {
+		Key     string `json:"key"`
+		Message string `json:"message"`
+		Hint    any    `json:"hint"`
+	} `json:"error"`
+}
+
+// NewPiholeClient creates a new Pi-hole v6 API client
+func NewPiholeClient(config PiholeConfig) *PiholeClient {
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	// Handle insecure TLS if specified in config
+	if config.Insecure {
+		client.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		}
+	}
+
+	return &PiholeClient{
+		BaseURL:  strings.TrimRight(config.BaseURL, "/"),
+		Password: config.Password,
+		client:   client,
+		session:  &piholeSession{},
+	}
+}
+
+// WithContext returns a shallow copy of the client with the given context.
+// The copy shares the parent's session.
+func (p *PiholeClient) WithContext(ctx context.Context) *PiholeClient {
+	return &PiholeClient{
+		BaseURL:  p.BaseURL,
+		Password: p.Password,
+		client:   p.client,
+		session:  p.session,
+		ctx:      ctx,
+	}
+}
+
+// getCtx returns the client's context, falling back to context.Background().
+func (p *PiholeClient) getCtx() context.Context {
+	if p.ctx != nil {
+		return p.ctx
+	}
+	return context.Background()
+}
+
+// login exchanges the password for a session ID. A Pi-hole without a password
+// accepts unauthenticated requests, so an empty password skips the exchange.
+func (p *PiholeClient) login() (string, error) {
+	p.session.mu.Lock()
+	defer p.session.mu.Unlock()
+
+	if p.session.sid != "" || p.Password == "" {
+		return p.session.sid, nil
+	}
+
+	payload, err := json.Marshal(map[string]string{"password": p.Password})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal auth request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(p.getCtx(), http.MethodPost, p.BaseURL+"/api/auth", bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("failed to create auth request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to authenticate with Pi-hole: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", piholeResponseError(resp)
+	}
+
+	var auth piholeAuthResponse
+	if err := json.NewDecoder(resp.Body).Decode(&auth); err != nil {
+		return "", fmt.Errorf("failed to parse auth response: %w", err)
+	}
+	if !auth.Session.Valid || auth.Session.SID == "" {
+		return "", fmt.Errorf("Pi-hole authentication failed: %s", auth.Session.Message)
+	}
+
+	logging.Debug("Authenticated with Pi-hole", "url", p.BaseURL)
+	p.session.sid = auth.Session.SID
+	return p.session.sid, nil
+}
+
+// invalidateSession forgets sid so the next request logs in again.
+func (p *PiholeClient) invalidateSession(sid string) {
+	p.session.mu.Lock()
+	defer p.session.mu.Unlock()
+	if p.session.sid == sid {
+		p.session.sid = ""
+	}
+}
+
+// Logout ends the current session. Pi-hole limits concurrent sessions, so
+// short-lived commands should log out when they are done.
+func (p *PiholeClient) Logout() error {
+	p.session.mu.Lock()
+	sid := p.session.sid
+	p.session.sid = ""
+	p.session.mu.Unlock()
+
+	if sid == "" {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(p.getCtx(), http.MethodDelete, p.BaseURL+"/api/auth", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create logout request: %w", err)
+	}
+	req.Header.Set("X-FTL-SID", sid)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to log out of Pi-hole: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusUnauthorized {
+		return piholeResponseError(resp)
+	}
+	return nil
+}
+
+// makeRequest performs an authenticated request, logging in again once if the
+// session has expired.
+func (p *PiholeClient) makeRequest(method, endpoint string) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		sid, err := p.login()
+		if err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequestWithContext(p.getCtx(), method, p.BaseURL+endpoint, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Accept", "application/json")
+		if sid != "" {
+			req.Header.Set("X-FTL-SID", sid)
+		}
+
+		logging.Debug("Making Pi-hole API request", "method", method, "url", p.BaseURL+endpoint)
+
+		resp, err := p.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to make request: %w", err)
+		}
+
+		if resp.StatusCode == http.StatusUnauthorized && sid != "" && attempt == 0 {
+			resp.Body.Close()
+			logging.Debug("Pi-hole session expired, re-authenticating")
+			p.invalidateSession(sid)
+			continue
+		}
+
+		return resp, nil
+	}
+}
+
+// getConfigList fetches one array-valued dns.* config element, e.g. "hosts".
+func (p *PiholeClient) getConfigList(element string) ([]string, error) {
+	resp, err := p.makeRequest(http.MethodGet, "/api/config/dns/"+element)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, piholeResponseError(resp)
+	}
+
+	var body struct {
+		Config struct {
+			DNS map[string][]string `json:"dns"`
+		} `json:"config"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return body.Config.DNS[element], nil
+}
+
+// modifyConfigList adds (PUT) or removes (DELETE) one value of an array-valued
+// dns.* config element. Pi-hole applies the change immediately.
+func (p *PiholeClient) modifyConfigList(method, element, value string) error {
+	resp, err := p.makeRequest(method, "/api/config/dns/"+element+"/"+url.PathEscape(value))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return nil
+	default:
+		return piholeResponseError(resp)
+	}
+}
+
+// ListHosts retrieves all local DNS host records
+func (p *PiholeClient) ListHosts() ([]PiholeHost, error) {
+	lines, err := p.getConfigList("hosts")
+	if err != nil {
+		return nil, err
+	}
+
+	hosts := make([]PiholeHost, 0, len(lines))
+	for _, line := range lines {
+		// A line may map one IP to several hostnames, like /etc/hosts.
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		for _, hostname := range fields[1:] {
+			hosts = append(hosts, PiholeHost{IP: fields[0], Hostname: hostname, Line: line})
+		}
+	}
+
+	logging.Debug("Retrieved Pi-hole local DNS records", "count", len(hosts))
+	return hosts, nil
+}
+
+// AddHost adds a local DNS host record
+func (p *PiholeClient) AddHost(hostname, ip string) error {
+	if err := p.modifyConfigList(http.MethodPut, "hosts", ip+" "+hostname); err != nil {
+		return err
+	}
+	logging.Debug("Successfully added Pi-hole local DNS record", "hostname", hostname, "ip", ip)
+	return nil
+}
+
+// DeleteHost removes a local DNS host record
+func (p *PiholeClient) DeleteHost(hostname, ip string) error {
+	hosts, err := p.ListHosts()
+	if err != nil {
+		return err
+	}
+	for _, host := range hosts {
+		if host.IP == ip && strings.EqualFold(host.Hostname, hostname) {
+			return p.RemoveHost(host)
+		}
+	}
+	return p.RemoveHost(PiholeHost{IP: ip, Hostname: hostname})
+}
+
+// RemoveHost removes host, as returned by ListHosts, from its dns.hosts line.
+// Pi-hole only deletes whole lines, so a line that also lists other hostnames
+// is rewritten without this one.
+func (p *PiholeClient) RemoveHost(host PiholeHost) error {
+	line := host.Line
+	if line == "" {
+		line = host.IP + " " + host.Hostname
+	}
+	fields := strings.Fields(line)
+	rest := make([]string, 0, len(fields))
+	for _, hostname := range fields[1:] {
+		if !strings.EqualFold(hostname, host.Hostname) {
+			rest = append(rest, hostname)
+		}
+	}
+	// Add the rewritten line first so the other hostnames never go missing.
+	if len(rest) > 0 {
+		if err := p.modifyConfigList(http.MethodPut, "hosts", fields[0]+" "+strings.Join(rest, " ")); err != nil {
+			return fmt.Errorf("failed to rewrite %q: %w", line, err)
+		}
+	}
+	if err := p.modifyConfigList(http.MethodDelete, "hosts", line); err != nil {
+		return err
+	}
+	logging.Debug("Successfully deleted Pi-hole local DNS record", "hostname", host.Hostname, "ip", host.IP)
+	return nil
+}
+
+// UpdateHost points an existing local DNS host record at a new IP. Pi-hole has
+// no in-place update, so the old record is removed and the new one added.
+func (p *PiholeClient) UpdateHost(hostname, oldIP, newIP string) error {
+	if err := p.DeleteHost(hostname, oldIP); err != nil {
+		return fmt.Errorf("failed to remove old record: %w", err)
+	}
+	return p.AddHost(hostname, newIP)
+}
+
+// ListCNAMEs retrieves all local CNAME records
+func (p *PiholeClient) ListCNAMEs() ([]PiholeCNAME, error) {
+	lines, err := p.getConfigList("cnameRecords")
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]PiholeCNAME, 0, len(lines))
+	for _, line := range lines {
+		parts := strings.Split(line, ",")
+		if len(parts) < 2 {
+			continue
+		}
+		record := PiholeCNAME{Domain: strings.TrimSpace(parts[0]), Target: strings.TrimSpace(parts[1])}
+		if len(parts) > 2 {
+			if ttl, err := strconv.Atoi(strings.TrimSpace(parts[2])); err == nil {
+				record.TTL = ttl
+			}
+		}
+		records = append(records, record)
+	}
+
+	logging.Debug("Retrieved Pi-hole CNAME records", "count", len(records))
+	return records, nil
+}
+
+// AddCNAME adds a local CNAME record
+func (p *PiholeClient) AddCNAME(record PiholeCNAME) error {
+	if err := p.modifyConfigList(http.MethodPut, "cnameRecords", record.value()); err != nil {
+		return err
+	}
+	logging.Debug("Successfully added Pi-hole CNAME record", "domain", record.Domain, "target", record.Target)
+	return nil
+}
+
+// DeleteCNAME removes a local CNAME record
+func (p *PiholeClient) DeleteCNAME(record PiholeCNAME) error {
+	if err := p.modifyConfigList(http.MethodDelete, "cnameRecords", record.value()); err != nil {
+		return err
+	}
+	logging.Debug("Successfully deleted Pi-hole CNAME record", "domain", record.Domain, "target", record.Target)
+	return nil
+}
+
+// value renders the record in Pi-hole's "domain,target[,ttl]" form.
+func (r PiholeCNAME) value() string {
+	if r.TTL > 0 {
+		return fmt.Sprintf("%s,%s,%d", r.Domain, r.Target, r.TTL)
+	}
+	return r.Domain + "," + r.Target
+}
+
+// piholeResponseError builds an error from a non-success response, preferring
+// the message in Pi-hole's JSON error envelope.
+func piholeResponseError(resp *http.Response) error {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logging.Error("Failed to read Pi-hole response body", "error", err)
+	}
+	var apiErr piholeErrorResponse
+	if json.Unmarshal(body, &apiErr) == nil && apiErr.Error.Message != "" {
+		logging.Error("Pi-hole API request failed",
+			"statusCode", resp.StatusCode,
+			"key", apiErr.Error.Key,
+			"message", apiErr.Error.Message)
+		return fmt.Errorf("unexpected status code: %d, %s: %s", resp.StatusCode, apiErr.Error.Key, apiErr.Error.Message)
+	}
+	logging.Error("Pi-hole API request failed",
+		"statusCode", resp.StatusCode,
+		"responseBody", string(body))
+	return fmt.Errorf("unexpected status code: %d, response: %s", resp.StatusCode, string(body))
+}
@@ -0,0 +1,212 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newPiholeTestServer(t *testing.T, handler func(w http.ResponseWriter, r *http.Request)) (*httptest.Server, *int) {
+	t.Helper()
+	logins := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/auth" && r.Method == http.MethodPost {
+			var body map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("Failed to parse auth body: %v", err)
+			}
+			if body["password"] != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(`{"session":{"valid":false,"sid":null,"message":"password incorrect"}}`))
+				return
+			}
+			logins++
+			_, _ = w.Write([]byte(`{"session":{"valid":true,"sid":"sid-` + string(rune('0'+logins)) + `","validity":1800}}`))
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, &logins
+}
+
+func TestPiholeClient_ListHosts(t *testing.T) {
+	server, logins := newPiholeTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/api/config/dns/hosts" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("X-FTL-SID") != "sid-1" {
+			t.Errorf("Expected X-FTL-SID sid-1, got %q", r.Header.Get("X-FTL-SID"))
+		}
+		_, _ = w.Write([]byte(`{"config":{"dns":{"hosts":["10.0.0.15 app.example.com","10.0.0.16 a.example.com b.example.com","bogus"]}}}`))
+	})
+
+	client := NewPiholeClient(PiholeConfig{BaseURL: server.URL + "/", Password: "secret"})
+	hosts, err := client.ListHosts()
+	if err != nil {
+		t.Fatalf("ListHosts failed: %v", err)
+	}
+
+	want := []PiholeHost{
+		{IP: "10.0.0.15", Hostname: "app.example.com", Line: "10.0.0.15 app.example.com"},
+		{IP: "10.0.0.16", Hostname: "a.example.com", Line: "10.0.0.16 a.example.com b.example.com"},
+		{IP: "10.0.0.16", Hostname: "b.example.com", Line: "10.0.0.16 a.example.com b.example.com"},
+	}
+	if len(hosts) != len(want) {
+		t.Fatalf("Expected %d hosts, got %#v", len(want), hosts)
+	}
+	for i := range want {
+		if hosts[i] != want[i] {
+			t.Errorf("host %d = %#v, want %#v", i, hosts[i], want[i])
+		}
+	}
+	if *logins != 1 {
+		t.Errorf("Expected one login, got %d", *logins)
+	}
+}
+
+func TestPiholeClient_AddAndDeleteHostEscapeValue(t *testing.T) {
+	var requests []string
+	server, _ := newPiholeTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.EscapedPath())
+		switch r.Method {
+		case http.MethodGet:
+			_, _ = w.Write([]byte(`{"config":{"dns":{"hosts":["10.0.0.99 app.example.com"]}}}`))
+		case http.MethodPut:
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	client := NewPiholeClient(PiholeConfig{BaseURL: server.URL, Password: "secret"})
+	if err := client.UpdateHost("app.example.com", "10.0.0.99", "10.0.0.15"); err != nil {
+		t.Fatalf("UpdateHost failed: %v", err)
+	}
+
+	want := []string{
+		"GET /api/config/dns/hosts",
+		"DELETE /api/config/dns/hosts/10.0.0.99%20app.example.com",
+		"PUT /api/config/dns/hosts/10.0.0.15%20app.example.com",
+	}
+	if len(requests) != len(want) {
+		t.Fatalf("Expected requests %v, got %v", want, requests)
+	}
+	for i := range want {
+		if requests[i] != want[i] {
+			t.Errorf("request %d = %q, want %q", i, requests[i], want[i])
+		}
+	}
+}
+
+func TestPiholeClient_UpdateHostRewritesMultiNameLine(t *testing.T) {
+	var requests []string
+	server, _ := newPiholeTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.EscapedPath())
+		switch r.Method {
+		case http.MethodGet:
+			_, _ = w.Write([]byte(`{"config":{"dns":{"hosts":["10.0.0.16 a.example.com b.example.com"]}}}`))
+		case http.MethodPut:
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	client := NewPiholeClient(PiholeConfig{BaseURL: server.URL, Password: "secret"})
+	if err := client.UpdateHost("b.example.com", "10.0.0.16", "10.0.0.20"); err != nil {
+		t.Fatalf("UpdateHost failed: %v", err)
+	}
+
+	// The shared line is replaced by one without b.example.com, which then
+	// gets a line of its own.
+	want := []string{
+		"GET /api/config/dns/hosts",
+		"PUT /api/config/dns/hosts/10.0.0.16%20a.example.com",
+		"DELETE /api/config/dns/hosts/10.0.0.16%20a.example.com%20b.example.com",
+		"PUT /api/config/dns/hosts/10.0.0.20%20b.example.com",
+	}
+	if len(requests) != len(want) {
+		t.Fatalf("Expected requests %v, got %v", want, requests)
+	}
+	for i := range want {
+		if requests[i] != want[i] {
+			t.Errorf("request %d = %q, want %q", i, requests[i], want[i])
+		}
+	}
+}
+
+func TestPiholeClient_CNAMERecords(t *testing.T) {
+	var added string
+	server, _ := newPiholeTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			_, _ = w.Write([]byte(`{"config":{"dns":{"cnameRecords":["alias.example.com,app.example.com","ttl.example.com,app.example.com,300"]}}}`))
+		case http.MethodPut:
+			added = r.URL.Path
+			w.WriteHeader(http.StatusCreated)
+		}
+	})
+
+	client := NewPiholeClient(PiholeConfig{BaseURL: server.URL, Password: "secret"})
+	records, err := client.ListCNAMEs()
+	if err != nil {
+		t.Fatalf("ListCNAMEs failed: %v", err)
+	}
+	if len(records) != 2 || records[0].Target != "app.example.com" || records[1].TTL != 300 {
+		t.Fatalf("Unexpected CNAME records: %#v", records)
+	}
+
+	if err := client.AddCNAME(PiholeCNAME{Domain: "new.example.com", Target: "app.example.com"}); err != nil {
+		t.Fatalf("AddCNAME failed: %v", err)
+	}
+	if added != "/api/config/dns/cnameRecords/new.example.com,app.example.com" {
+		t.Errorf("Unexpected CNAME add path %q", added)
+	}
+}
+
+func TestPiholeClient_ReauthenticatesOnExpiredSession(t *testing.T) {
+	calls := 0
+	server, logins := newPiholeTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("X-FTL-SID") == "sid-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"key":"unauthorized","message":"Unauthorized","hint":null}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"config":{"dns":{"hosts":[]}}}`))
+	})
+
+	client := NewPiholeClient(PiholeConfig{BaseURL: server.URL, Password: "secret"})
+	if _, err := client.WithContext(t.Context()).ListHosts(); err != nil {
+		t.Fatalf("ListHosts failed: %v", err)
+	}
+	if *logins != 2 || calls != 2 {
+		t.Errorf("Expected two logins and two calls, got logins=%d calls=%d", *logins, calls)
+	}
+}
+
+func TestPiholeClient_ReportsAPIError(t *testing.T) {
+	server, _ := newPiholeTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":{"key":"bad_request","message":"Item already present","hint":null}}`))
+	})
+
+	client := NewPiholeClient(PiholeConfig{BaseURL: server.URL, Password: "secret"})
+	err := client.AddHost("app.example.com", "10.0.0.15")
+	if err == nil || err.Error() != "unexpected status code: 400, bad_request: Item already present" {
+		t.Fatalf("Expected API error message, got %v", err)
+	}
+}
+
+func TestPiholeClient_WrongPassword(t *testing.T) {
+	server, _ := newPiholeTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected request after failed login: %s", r.URL.Path)
+	})
+
+	client := NewPiholeClient(PiholeConfig{BaseURL: server.URL, Password: "wrong"})
+	if _, err := client.ListHosts(); err == nil {
+		t.Fatal("Expected authentication error")
+	}
+}
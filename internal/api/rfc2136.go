@@ -0,0 +1,315 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/logging"
+	"github.com/miekg/dns"
+)
+
+const (
+	// DefaultRFC2136TTL is the TTL used for records created by caddy-dns-sync.
+	DefaultRFC2136TTL = 300
+	// DefaultRFC2136OwnerMarker is the TXT value that marks a name as managed
+	// by caddy-dns-sync. Names without it are never updated or deleted.
+	DefaultRFC2136OwnerMarker = "heritage=caddy-dns-sync"
+	// RFC2136OwnerLabel is prepended to a managed name to form the owner name
+	// that holds its marker. Keeping the marker off the name itself leaves
+	// room for other TXT records there, which would otherwise break the
+	// value-dependent ownership prerequisite.
+	RFC2136OwnerLabel = "_owner"
+	// DefaultTSIGAlgorithm is used when no TSIG algorithm is configured.
+	DefaultTSIGAlgorithm = "hmac-sha256"
+)
+
+// RFC2136Config represents the configuration for an RFC 2136 dynamic update
+// primary (BIND, Knot, PowerDNS, Technitium, ...).
+type RFC2136Config struct {
+	Server        string // host or host:port of the primary; port defaults to 53
+	Zone          string
+	TSIGKeyName   string
+	TSIGSecret    string // base64-encoded shared secret
+	TSIGAlgorithm string // e.g. hmac-sha256
+	TTL           uint32
+	OwnerMarker   string
+	Enabled       bool
+}
+
+// RFC2136Record is an A record read from the zone, together with whether the
+// name's owner name carries the ownership TXT marker.
+type RFC2136Record struct {
+	Hostname string `json:"hostname"`
+	IP       string `json:"ip"`
+	TTL      uint32 `json:"ttl"`
+	Owned    bool   `json:"owned"`
+}
+
+// RFC2136Client sends TSIG-signed RFC 2136 UPDATE messages to a primary and
+// reads zone state via AXFR.
+type RFC2136Client struct {
+	Server      string
+	Zone        string
+	TTL         uint32
+	OwnerMarker string
+	keyName     string
+	secret      string
+	algorithm   string
+	timeout     time.Duration
+	ctx         context.Context
+}
+
+// NewRFC2136Client creates a new RFC 2136 client
+func NewRFC2136Client(config RFC2136Config) *RFC2136Client {
+	server := config.Server
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(strings.Trim(server, "[]"), "53")
+	}
+	ttl := config.TTL
+	if ttl == 0 {
+		ttl = DefaultRFC2136TTL
+	}
+	marker := config.OwnerMarker
+	if marker == "" {
+		marker = DefaultRFC2136OwnerMarker
+	}
+	algorithm := config.TSIGAlgorithm
+	if algorithm == "" {
+		algorithm = DefaultTSIGAlgorithm
+	}
+
+	client := &RFC2136Client{
+		Server:      server,
+		Zone:        dns.Fqdn(strings.ToLower(config.Zone)),
+		TTL:         ttl,
+		OwnerMarker: marker,
+		algorithm:   dns.Fqdn(strings.ToLower(algorithm)),
+		timeout:     10 * time.Second,
+	}
+	if config.TSIGKeyName != "" {
+		client.keyName = dns.Fqdn(strings.ToLower(config.TSIGKeyName))
+		client.secret = config.TSIGSecret
+	}
+	return client
+}
+
+// WithContext returns a shallow copy of the client that uses ctx for requests.
+func (c *RFC2136Client) WithContext(ctx context.Context) *RFC2136Client {
+	copy := *c
+	copy.ctx = ctx
+	return &copy
+}
+
+func (c *RFC2136Client) context() context.Context {
+	if c.ctx != nil {
+		return c.ctx
+	}
+	return context.Background()
+}
+
+func (c *RFC2136Client) tsigSecret() map[string]string {
+	if c.keyName == "" {
+		return nil
+	}
+	return map[string]string{c.keyName: c.secret}
+}
+
+func (c *RFC2136Client) sign(msg *dns.Msg) {
+	if c.keyName != "" {
+		msg.SetTsig(c.keyName, c.algorithm, 300, time.Now().Unix())
+	}
+}
+
+// ListRecords transfers the zone and returns its A records, sorted by hostname.
+// The primary must allow AXFR for the configured TSIG key.
+func (c *RFC2136Client) ListRecords() ([]RFC2136Record, error) {
+	logging.Debug("Transferring RFC 2136 zone", "zone", c.Zone, "server", c.Server)
+
+	msg := new(dns.Msg)
+	msg.SetAxfr(c.Zone)
+	c.sign(msg)
+
+	transfer := &dns.Transfer{
+		TsigSecret:   c.tsigSecret(),
+		DialTimeout:  c.timeout,
+		ReadTimeout:  c.timeout,
+		WriteTimeout: c.timeout,
+	}
+
+	ctx, cancel := context.WithTimeout(c.context(), 2*c.timeout)
+	defer cancel()
+	conn, err := new(net.Dialer).DialContext(ctx, "tcp", c.Server)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to %s: %w", c.Server, err)
+	}
+	transfer.Conn = &dns.Conn{Conn: conn}
+	defer transfer.Close()
+
+	envelopes, err := transfer.In(msg, c.Server)
+	if err != nil {
+		return nil, fmt.Errorf("error starting zone transfer: %w", err)
+	}
+
+	hosts := make(map[string][]*dns.A)
+	owned := make(map[string]bool)
+	for envelope := range envelopes {
+		if envelope.Error != nil {
+			return nil, fmt.Errorf("error transferring zone %s: %w", c.Zone, envelope.Error)
+		}
+		for _, rr := range envelope.RR {
+			name := strings.ToLower(rr.Header().Name)
+			switch record := rr.(type) {
+			case *dns.A:
+				hosts[name] = append(hosts[name], record)
+			case *dns.TXT:
+				owner, ok := strings.CutPrefix(name, RFC2136OwnerLabel+".")
+				if ok && strings.Join(record.Txt, "") == c.OwnerMarker {
+					owned[owner] = true
+				}
+			}
+		}
+	}
+
+	records := make([]RFC2136Record, 0, len(hosts))
+	for name, rrs := range hosts {
+		for _, rr := range rrs {
+			records = append(records, RFC2136Record{
+				Hostname: strings.TrimSuffix(name, "."),
+				IP:       rr.A.String(),
+				TTL:      rr.Hdr.Ttl,
+				Owned:    owned[name],
+			})
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Hostname == records[j].Hostname {
+			return records[i].IP < records[j].IP
+		}
+		return records[i].Hostname < records[j].Hostname
+	})
+
+	logging.Debug("Transferred RFC 2136 zone", "zone", c.Zone, "records", len(records))
+	return records, nil
+}
+
+// AddHost creates an A record and its ownership marker. The update carries an
+// "RRset does not exist" prerequisite, so it fails instead of adding a second
+// address to a name that already has one.
+func (c *RFC2136Client) AddHost(hostname, ip string) error {
+	name, err := c.fqdn(hostname)
+	if err != nil {
+		return err
+	}
+	a, err := c.aRecord(name, ip)
+	if err != nil {
+		return err
+	}
+
+	msg := new(dns.Msg)
+	msg.SetUpdate(c.Zone)
+	msg.RRsetNotUsed([]dns.RR{&dns.A{Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeA}}})
+	msg.Insert([]dns.RR{a, c.markerRecord(name)})
+
+	logging.Info("Adding RFC 2136 host", "hostname", hostname, "ip", ip, "zone", c.Zone)
+	return c.update(msg, hostname)
+}
+
+// UpdateHost replaces the A RRset of a managed name with newIP.
+func (c *RFC2136Client) UpdateHost(hostname, oldIP, newIP string) error {
+	name, err := c.fqdn(hostname)
+	if err != nil {
+		return err
+	}
+	a, err := c.aRecord(name, newIP)
+	if err != nil {
+		return err
+	}
+
+	msg := new(dns.Msg)
+	msg.SetUpdate(c.Zone)
+	msg.Used([]dns.RR{c.markerRecord(name)})
+	msg.RemoveRRset([]dns.RR{&dns.A{Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeA}}})
+	msg.Insert([]dns.RR{a})
+
+	logging.Info("Updating RFC 2136 host", "hostname", hostname, "old_ip", oldIP, "new_ip", newIP, "zone", c.Zone)
+	return c.update(msg, hostname)
+}
+
+// DeleteHost removes the A RRset and ownership marker of a managed name.
+// Other records at the name are left untouched.
+func (c *RFC2136Client) DeleteHost(hostname, ip string) error {
+	name, err := c.fqdn(hostname)
+	if err != nil {
+		return err
+	}
+
+	msg := new(dns.Msg)
+	msg.SetUpdate(c.Zone)
+	msg.Used([]dns.RR{c.markerRecord(name)})
+	msg.RemoveRRset([]dns.RR{&dns.A{Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeA}}})
+	msg.RemoveRRset([]dns.RR{&dns.TXT{Hdr: dns.RR_Header{Name: ownerName(name), Rrtype: dns.TypeTXT}}})
+
+	logging.Info("Deleting RFC 2136 host", "hostname", hostname, "ip", ip, "zone", c.Zone)
+	return c.update(msg, hostname)
+}
+
+func (c *RFC2136Client) update(msg *dns.Msg, hostname string) error {
+	c.sign(msg)
+	client := &dns.Client{
+		Net:        "tcp",
+		Timeout:    c.timeout,
+		TsigSecret: c.tsigSecret(),
+	}
+	resp, _, err := client.ExchangeContext(c.context(), msg, c.Server)
+	if err != nil {
+		return fmt.Errorf("error sending update to %s: %w", c.Server, err)
+	}
+
+	switch resp.Rcode {
+	case dns.RcodeSuccess:
+		return nil
+	case dns.RcodeYXRrset:
+		return fmt.Errorf("%s already has an A record that is not managed by caddy-dns-sync", hostname)
+	case dns.RcodeNXRrset:
+		return fmt.Errorf("%s is not managed by caddy-dns-sync (ownership TXT record missing)", hostname)
+	default:
+		return fmt.Errorf("update rejected by %s: %s", c.Server, dns.RcodeToString[resp.Rcode])
+	}
+}
+
+func (c *RFC2136Client) fqdn(hostname string) (string, error) {
+	name := dns.Fqdn(strings.ToLower(hostname))
+	if !dns.IsSubDomain(c.Zone, name) {
+		return "", fmt.Errorf("hostname %s is outside zone %s", hostname, strings.TrimSuffix(c.Zone, "."))
+	}
+	return name, nil
+}
+
+func (c *RFC2136Client) aRecord(name, ip string) (*dns.A, error) {
+	addr := net.ParseIP(ip).To4()
+	if addr == nil {
+		return nil, fmt.Errorf("invalid IPv4 address: %q", ip)
+	}
+	return &dns.A{
+		Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: c.TTL},
+		A:   addr,
+	}, nil
+}
+
+// markerRecord returns the ownership TXT record for name, placed on its owner
+// name.
+func (c *RFC2136Client) markerRecord(name string) *dns.TXT {
+	return &dns.TXT{
+		Hdr: dns.RR_Header{Name: ownerName(name), Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: c.TTL},
+		Txt: []string{c.OwnerMarker},
+	}
+}
+
+// ownerName returns the name that holds the ownership marker of name.
+func ownerName(name string) string {
+	return RFC2136OwnerLabel + "." + name
+}
@@ -0,0 +1,255 @@
+package api
+
+import (
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+const (
+	testTSIGKey    = "caddy-dns-sync."
+	testTSIGSecret = "c2VjcmV0LXNlY3JldC1zZWNyZXQtc2VjcmV0IQ=="
+)
+
+// fakeRFC2136Server is a minimal TSIG-verifying primary for example.com that
+// supports AXFR and the prerequisite/update subset used by RFC2136Client.
+type fakeRFC2136Server struct {
+	mu      sync.Mutex
+	records []dns.RR
+	addr    string
+}
+
+func newFakeRFC2136Server(t *testing.T, records ...string) *fakeRFC2136Server {
+	t.Helper()
+	fake := &fakeRFC2136Server{}
+	for _, record := range records {
+		rr, err := dns.NewRR(record)
+		if err != nil {
+			t.Fatalf("invalid fixture record %q: %v", record, err)
+		}
+		fake.records = append(fake.records, rr)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	started := make(chan struct{})
+	server := &dns.Server{
+		Listener:   listener,
+		Net:        "tcp",
+		TsigSecret: map[string]string{testTSIGKey: testTSIGSecret},
+		Handler:    fake,
+		MsgAcceptFunc: func(dh dns.Header) dns.MsgAcceptAction {
+			if int(dh.Bits>>11)&0xF == dns.OpcodeUpdate {
+				return dns.MsgAccept
+			}
+			return dns.DefaultMsgAcceptFunc(dh)
+		},
+		NotifyStartedFunc: func() { close(started) },
+	}
+	go func() { _ = server.ActivateAndServe() }()
+	<-started
+	t.Cleanup(func() { _ = server.Shutdown() })
+
+	fake.addr = listener.Addr().String()
+	return fake
+}
+
+func (f *fakeRFC2136Server) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	resp := new(dns.Msg)
+	resp.SetReply(r)
+	if r.IsTsig() == nil || w.TsigStatus() != nil {
+		resp.Rcode = dns.RcodeNotAuth
+		_ = w.WriteMsg(resp)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.Opcode == dns.OpcodeUpdate:
+		resp.Rcode = f.update(r)
+	case len(r.Question) == 1 && r.Question[0].Qtype == dns.TypeAXFR:
+		soa, _ := dns.NewRR("example.com. 3600 IN SOA ns.example.com. admin.example.com. 1 3600 600 86400 300")
+		resp.Answer = append([]dns.RR{soa}, f.records...)
+		resp.Answer = append(resp.Answer, soa)
+	default:
+		resp.Rcode = dns.RcodeNotImplemented
+	}
+	resp.SetTsig(testTSIGKey, dns.HmacSHA256, 300, time.Now().Unix())
+	_ = w.WriteMsg(resp)
+}
+
+func (f *fakeRFC2136Server) update(r *dns.Msg) int {
+	for _, prereq := range r.Answer {
+		h := prereq.Header()
+		exists := false
+		for _, rr := range f.records {
+			if !strings.EqualFold(rr.Header().Name, h.Name) || rr.Header().Rrtype != h.Rrtype {
+				continue
+			}
+			if h.Class != dns.ClassINET {
+				exists = true
+				continue
+			}
+			// A value-dependent prerequisite needs the whole RRset to
+			// match (RFC 2136 section 3.2.3), not just one record of it.
+			if !slices.ContainsFunc(r.Answer, func(want dns.RR) bool { return dns.IsDuplicate(rr, want) }) {
+				exists = false
+				break
+			}
+			exists = true
+		}
+		switch {
+		case h.Class == dns.ClassNONE && exists:
+			return dns.RcodeYXRrset
+		case h.Class != dns.ClassNONE && !exists:
+			return dns.RcodeNXRrset
+		}
+	}
+
+	for _, change := range r.Ns {
+		h := change.Header()
+		switch h.Class {
+		case dns.ClassINET:
+			f.records = append(f.records, change)
+		case dns.ClassANY, dns.ClassNONE:
+			kept := f.records[:0]
+			for _, rr := range f.records {
+				match := strings.EqualFold(rr.Header().Name, h.Name) && rr.Header().Rrtype == h.Rrtype
+				if match && h.Class == dns.ClassNONE {
+					target := dns.Copy(change)
+					target.Header().Class = dns.ClassINET
+					match = dns.IsDuplicate(rr, target)
+				}
+				if !match {
+					kept = append(kept, rr)
+				}
+			}
+			f.records = kept
+		}
+	}
+	return dns.RcodeSuccess
+}
+
+func (f *fakeRFC2136Server) client() *RFC2136Client {
+	return NewRFC2136Client(RFC2136Config{
+		Server:      f.addr,
+		Zone:        "example.com",
+		TSIGKeyName: "caddy-dns-sync",
+		TSIGSecret:  testTSIGSecret,
+	})
+}
+
+func TestRFC2136Client_ListRecordsReportsOwnership(t *testing.T) {
+	fake := newFakeRFC2136Server(t,
+		`app.example.com. 300 IN A 10.0.0.15`,
+		`_owner.app.example.com. 300 IN TXT "heritage=caddy-dns-sync"`,
+		`printer.example.com. 300 IN A 10.0.0.50`,
+		`printer.example.com. 300 IN TXT "heritage=caddy-dns-sync"`,
+	)
+
+	records, err := fake.client().ListRecords()
+	if err != nil {
+		t.Fatalf("ListRecords failed: %v", err)
+	}
+
+	want := []RFC2136Record{
+		{Hostname: "app.example.com", IP: "10.0.0.15", TTL: 300, Owned: true},
+		{Hostname: "printer.example.com", IP: "10.0.0.50", TTL: 300, Owned: false},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("Expected %d records, got %#v", len(want), records)
+	}
+	for i := range want {
+		if records[i] != want[i] {
+			t.Errorf("record %d = %#v, want %#v", i, records[i], want[i])
+		}
+	}
+}
+
+func TestRFC2136Client_AddUpdateDelete(t *testing.T) {
+	fake := newFakeRFC2136Server(t)
+	client := fake.client()
+
+	if err := client.AddHost("app.example.com", "10.0.0.15"); err != nil {
+		t.Fatalf("AddHost failed: %v", err)
+	}
+	if err := client.UpdateHost("app.example.com", "10.0.0.15", "10.0.0.16"); err != nil {
+		t.Fatalf("UpdateHost failed: %v", err)
+	}
+
+	records, err := client.ListRecords()
+	if err != nil {
+		t.Fatalf("ListRecords failed: %v", err)
+	}
+	if len(records) != 1 || records[0].IP != "10.0.0.16" || !records[0].Owned {
+		t.Fatalf("Expected one owned record for 10.0.0.16, got %#v", records)
+	}
+
+	if err := client.DeleteHost("app.example.com", "10.0.0.16"); err != nil {
+		t.Fatalf("DeleteHost failed: %v", err)
+	}
+	if len(fake.records) != 0 {
+		t.Fatalf("Expected the A record and marker to be removed, got %v", fake.records)
+	}
+}
+
+func TestRFC2136Client_UpdatesNamesWithOtherTXTRecords(t *testing.T) {
+	fake := newFakeRFC2136Server(t, `app.example.com. 300 IN TXT "v=spf1 -all"`)
+	client := fake.client()
+
+	if err := client.AddHost("app.example.com", "10.0.0.15"); err != nil {
+		t.Fatalf("AddHost failed: %v", err)
+	}
+	if err := client.UpdateHost("app.example.com", "10.0.0.15", "10.0.0.16"); err != nil {
+		t.Fatalf("UpdateHost failed: %v", err)
+	}
+	if err := client.DeleteHost("app.example.com", "10.0.0.16"); err != nil {
+		t.Fatalf("DeleteHost failed: %v", err)
+	}
+	if len(fake.records) != 1 || fake.records[0].Header().Rrtype != dns.TypeTXT || fake.records[0].Header().Name != "app.example.com." {
+		t.Fatalf("Expected only the unrelated TXT record to remain, got %v", fake.records)
+	}
+}
+
+func TestRFC2136Client_RefusesForeignRecords(t *testing.T) {
+	fake := newFakeRFC2136Server(t, `printer.example.com. 300 IN A 10.0.0.50`)
+	client := fake.client()
+
+	err := client.AddHost("printer.example.com", "10.0.0.15")
+	if err == nil || !strings.Contains(err.Error(), "not managed by caddy-dns-sync") {
+		t.Fatalf("Expected AddHost to refuse an existing foreign record, got %v", err)
+	}
+	err = client.DeleteHost("printer.example.com", "10.0.0.50")
+	if err == nil || !strings.Contains(err.Error(), "ownership TXT record missing") {
+		t.Fatalf("Expected DeleteHost to refuse a foreign record, got %v", err)
+	}
+	if len(fake.records) != 1 {
+		t.Fatalf("Expected the foreign record to be left alone, got %v", fake.records)
+	}
+}
+
+func TestRFC2136Client_RejectsWrongKeyAndOutOfZoneNames(t *testing.T) {
+	fake := newFakeRFC2136Server(t)
+	client := NewRFC2136Client(RFC2136Config{
+		Server:      fake.addr,
+		Zone:        "example.com.",
+		TSIGKeyName: "other-key",
+		TSIGSecret:  testTSIGSecret,
+	})
+	if err := client.AddHost("app.example.com", "10.0.0.15"); err == nil {
+		t.Fatal("Expected an error for an unknown TSIG key")
+	}
+
+	if err := fake.client().AddHost("app.example.org", "10.0.0.15"); err == nil || !strings.Contains(err.Error(), "outside zone example.com") {
+		t.Fatalf("Expected out-of-zone error, got %v", err)
+	}
+}
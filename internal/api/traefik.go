@@ -0,0 +1,335 @@
+package api
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/logging"
+	"github.com/jeeftor/caddy-dns-sync/internal/models"
+)
+
+// TraefikConfig represents configuration for the Traefik API
+type TraefikConfig struct {
+	BaseURL  string `json:"base_url" mapstructure:"base_url"`
+	Username string `json:"username,omitempty" mapstructure:"username"`
+	Password string `json:"password,omitempty" mapstructure:"password"`
+	Insecure bool   `json:"insecure,omitempty" mapstructure:"insecure"`
+}
+
+// TraefikClient reads HTTP routers and services from the Traefik API and
+// reports them in the same shape as the Caddy admin API, so Traefik can stand
+// in as the reverse-proxy source of truth. It implements HostnameSource.
+type TraefikClient struct {
+	BaseURL  string
+	Username string
+	Password string
+	client   *http.Client
+}
+
+// TraefikRouter is one entry from GET /api/http/routers
+type TraefikRouter struct {
+	Name        string   `json:"name"`
+	Provider    string   `json:"provider"`
+	Rule        string   `json:"rule"`
+	Service     string   `json:"service"`
+	Middlewares []string `json:"middlewares,omitempty"`
+	EntryPoints []string `json:"entryPoints,omitempty"`
+	Status      string   `json:"status"`
+}
+
+// TraefikService is one entry from GET /api/http/services
+type TraefikService struct {
+	Name         string `json:"name"`
+	Provider     string `json:"provider"`
+	Type         string `json:"type"`
+	LoadBalancer *struct {
+		Servers []struct {
+			URL string `json:"url"`
+		} `json:"servers"`
+	} `json:"loadBalancer,omitempty"`
+}
+
+// TraefikMiddleware is one entry from GET /api/http/middlewares. Only the
+// middleware types that map onto CaddyRouteInfo are decoded.
+type TraefikMiddleware struct {
+	Name        string `json:"name"`
+	Provider    string `json:"provider"`
+	Type        string `json:"type"`
+	ForwardAuth *struct {
+		Address string `json:"address"`
+	} `json:"forwardAuth,omitempty"`
+	Headers *struct {
+		CustomRequestHeaders  map[string]string `json:"customRequestHeaders,omitempty"`
+		CustomResponseHeaders map[string]string `json:"customResponseHeaders,omitempty"`
+	} `json:"headers,omitempty"`
+}
+
+// NewTraefikClient creates a new Traefik API client
+func NewTraefikClient(config TraefikConfig) *TraefikClient {
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+	}
+
+	// Handle insecure TLS if specified in config
+	if config.Insecure {
+		client.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		}
+	}
+
+	return &TraefikClient{
+		BaseURL:  strings.TrimRight(config.BaseURL, "/"),
+		Username: config.Username,
+		Password: config.Password,
+		client:   client,
+	}
+}
+
+// GetRouters returns every HTTP router known to Traefik
+func (t *TraefikClient) GetRouters() ([]TraefikRouter, error) {
+	var routers []TraefikRouter
+	if err := t.list("/api/http/routers", &routers); err != nil {
+		return nil, err
+	}
+	return routers, nil
+}
+
+// GetServices returns every HTTP service known to Traefik
+func (t *TraefikClient) GetServices() ([]TraefikService, error) {
+	var services []TraefikService
+	if err := t.list("/api/http/services", &services); err != nil {
+		return nil, err
+	}
+	return services, nil
+}
+
+// GetMiddlewares returns every HTTP middleware known to Traefik
+func (t *TraefikClient) GetMiddlewares() ([]TraefikMiddleware, error) {
+	var middlewares []TraefikMiddleware
+	if err := t.list("/api/http/middlewares", &middlewares); err != nil {
+		return nil, err
+	}
+	return middlewares, nil
+}
+
+// GetHostnameMap returns hostname -> upstream, like CaddyClient.GetHostnameMap
+func (t *TraefikClient) GetHostnameMap() (map[string]string, error) {
+	details, err := t.GetHostnameDetails()
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]string, len(details))
+	for hostname, route := range details {
+		result[hostname] = route.Upstream
+	}
+	return result, nil
+}
+
+// GetHostnameDetails returns hostname -> CaddyRouteInfo for every Host() rule
+// of an enabled router. The upstream is the first load-balancer server of the
+// router's service; middlewares become the handler chain, ahead of a final
+// "reverse_proxy". When several routers match a hostname (e.g. an HTTP
+// redirect router and the HTTPS one) the first with an upstream wins.
+func (t *TraefikClient) GetHostnameDetails() (map[string]models.CaddyRouteInfo, error) {
+	routers, err := t.GetRouters()
+	if err != nil {
+		return nil, err
+	}
+	services, err := t.GetServices()
+	if err != nil {
+		return nil, err
+	}
+	middlewares, err := t.GetMiddlewares()
+	if err != nil {
+		return nil, err
+	}
+	return traefikHostnameDetails(routers, services, middlewares), nil
+}
+
+func traefikHostnameDetails(routers []TraefikRouter, services []TraefikService, middlewares []TraefikMiddleware) map[string]models.CaddyRouteInfo {
+	servicesByName := make(map[string]TraefikService, len(services))
+	for _, service := range services {
+		servicesByName[service.Name] = service
+	}
+	middlewaresByName := make(map[string]TraefikMiddleware, len(middlewares))
+	for _, middleware := range middlewares {
+		middlewaresByName[middleware.Name] = middleware
+	}
+
+	sort.Slice(routers, func(i, j int) bool { return routers[i].Name < routers[j].Name })
+
+	result := make(map[string]models.CaddyRouteInfo)
+	for _, router := range routers {
+		if router.Status == "disabled" {
+			logging.Debug("Skipping disabled Traefik router", "router", router.Name)
+			continue
+		}
+		hostnames := ParseTraefikHostRule(router.Rule)
+		if len(hostnames) == 0 {
+			continue
+		}
+
+		var route models.CaddyRouteInfo
+		for _, name := range router.Middlewares {
+			middleware, ok := middlewaresByName[traefikQualifiedName(name, router.Provider)]
+			if !ok {
+				continue
+			}
+			applyTraefikMiddleware(&route, middleware)
+		}
+		if service, ok := servicesByName[traefikQualifiedName(router.Service, router.Provider)]; ok && service.LoadBalancer != nil && len(service.LoadBalancer.Servers) > 0 {
+			route.Upstream, route.TLSToUpstream = traefikDialAddress(service.LoadBalancer.Servers[0].URL)
+			route.HandlerChain = append(route.HandlerChain, "reverse_proxy")
+		}
+
+		for _, hostname := range hostnames {
+			if existing, exists := result[hostname]; !exists || (existing.Upstream == "" && route.Upstream != "") {
+				result[hostname] = route
+			}
+		}
+	}
+	return result
+}
+
+// applyTraefikMiddleware records a router middleware on route the way the
+// equivalent Caddy handler would be recorded.
+func applyTraefikMiddleware(route *models.CaddyRouteInfo, middleware TraefikMiddleware) {
+	route.HandlerChain = append(route.HandlerChain, strings.ToLower(middleware.Type))
+	if middleware.ForwardAuth != nil && strings.Contains(middleware.ForwardAuth.Address, "outpost.goauthentik.io") {
+		route.HasForwardAuth = true
+	}
+	if middleware.Headers == nil {
+		return
+	}
+	for name, value := range middleware.Headers.CustomRequestHeaders {
+		if route.RequestHeadersSet == nil {
+			route.RequestHeadersSet = make(map[string]string)
+		}
+		route.RequestHeadersSet[name] = value
+	}
+	for name, value := range middleware.Headers.CustomResponseHeaders {
+		if route.ResponseHeadersSet == nil {
+			route.ResponseHeadersSet = make(map[string]string)
+		}
+		route.ResponseHeadersSet[name] = value
+	}
+}
+
+// traefikHostRule matches Host(...) matchers, but not HostRegexp, HostSNI or
+// HostHeader. A preceding "!" is captured so negated matchers can be skipped.
+var (
+	traefikHostRule  = regexp.MustCompile("(!?)\\s*\\bHost\\(([^)]*)\\)")
+	traefikRuleValue = regexp.MustCompile("[`\"]([^`\"]+)[`\"]")
+)
+
+// ParseTraefikHostRule returns the hostnames named by the Host() matchers of a
+// router rule, e.g. "Host(`a.example.com`) || Host(`b.example.com`)". Both the
+// Traefik v2 multi-argument form and the v3 single-argument form are accepted.
+func ParseTraefikHostRule(rule string) []string {
+	var hostnames []string
+	seen := make(map[string]bool)
+	for _, match := range traefikHostRule.FindAllStringSubmatch(rule, -1) {
+		if match[1] == "!" {
+			continue
+		}
+		for _, value := range traefikRuleValue.FindAllStringSubmatch(match[2], -1) {
+			hostname := strings.ToLower(strings.TrimSpace(value[1]))
+			if hostname != "" && !seen[hostname] {
+				seen[hostname] = true
+				hostnames = append(hostnames, hostname)
+			}
+		}
+	}
+	return hostnames
+}
+
+// traefikQualifiedName resolves a router's reference to a service or
+// middleware. References without an "@provider" suffix belong to the
+// router's own provider.
+func traefikQualifiedName(name, provider string) string {
+	if strings.Contains(name, "@") || provider == "" {
+		return name
+	}
+	return name + "@" + provider
+}
+
+// traefikDialAddress turns a load-balancer server URL such as
+// "http://10.0.0.5:8080" into the host:port form Caddy reports, and reports
+// whether the upstream is reached over TLS.
+func traefikDialAddress(serverURL string) (string, bool) {
+	u, err := url.Parse(serverURL)
+	if err != nil || u.Host == "" {
+		return serverURL, false
+	}
+	tlsUpstream := u.Scheme == "https"
+	if u.Port() != "" {
+		return u.Host, tlsUpstream
+	}
+	port := "80"
+	if tlsUpstream {
+		port = "443"
+	}
+	return net.JoinHostPort(u.Hostname(), port), tlsUpstream
+}
+
+// list fetches every page of a Traefik API collection into out. Traefik pages
+// with ?page= and advertises the next page in the X-Next-Page header.
+func (t *TraefikClient) list(path string, out interface{}) error {
+	var all []json.RawMessage
+	for page := 1; page > 0; {
+		endpoint := fmt.Sprintf("%s%s?per_page=100&page=%d", t.BaseURL, path, page)
+		logging.Debug("Fetching Traefik API", "url", endpoint)
+		req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+		if err != nil {
+			return fmt.Errorf("error creating request: %w", err)
+		}
+		if t.Username != "" {
+			req.SetBasicAuth(t.Username, t.Password)
+		}
+
+		resp, err := t.client.Do(req)
+		if err != nil {
+			logging.Error("Failed to connect to Traefik", "error", err)
+			return fmt.Errorf("failed to connect to Traefik: %w", err)
+		}
+		var items []json.RawMessage
+		err = func() error {
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("unexpected status code from %s: %d", path, resp.StatusCode)
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
+				return fmt.Errorf("failed to parse Traefik response from %s: %w", path, err)
+			}
+			return nil
+		}()
+		if err != nil {
+			logging.Error("Traefik API request failed", "path", path, "error", err)
+			return err
+		}
+		all = append(all, items...)
+
+		next, _ := strconv.Atoi(resp.Header.Get("X-Next-Page"))
+		if next <= page {
+			next = 0
+		}
+		page = next
+	}
+
+	data, err := json.Marshal(all)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
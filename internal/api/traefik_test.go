@@ -0,0 +1,119 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func newTraefikFixtureServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "admin" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		page := r.URL.Query().Get("page")
+		switch r.URL.Path {
+		case "/api/http/routers":
+			if page == "1" {
+				w.Header().Set("X-Next-Page", "2")
+				fmt.Fprint(w, `[
+					{"name":"app-http@docker","provider":"docker","rule":"Host(`+"`app.example.test`"+`)","service":"noop@internal","middlewares":["redirect@file"],"status":"enabled"},
+					{"name":"app@docker","provider":"docker","rule":"Host(`+"`App.example.test`"+`) || (Host(`+"`www.example.test`"+`) && PathPrefix(`+"`/`"+`))","service":"app","middlewares":["authentik@file","proto@file"],"status":"enabled"}
+				]`)
+				return
+			}
+			fmt.Fprint(w, `[
+				{"name":"nas@file","provider":"file","rule":"Host(`+"`nas.example.test`"+`, `+"`files.example.test`"+`)","service":"nas","status":"enabled"},
+				{"name":"regexp@file","provider":"file","rule":"HostRegexp(`+"`.+`"+`)","service":"nas","status":"enabled"},
+				{"name":"broken@docker","provider":"docker","rule":"Host(`+"`broken.example.test`"+`)","service":"missing","status":"disabled"}
+			]`)
+		case "/api/http/services":
+			fmt.Fprint(w, `[
+				{"name":"app@docker","provider":"docker","type":"loadbalancer","loadBalancer":{"servers":[{"url":"http://10.0.0.5:8080"}]}},
+				{"name":"nas@file","provider":"file","type":"loadbalancer","loadBalancer":{"servers":[{"url":"https://10.0.0.20"}]}},
+				{"name":"noop@internal","provider":"internal"}
+			]`)
+		case "/api/http/middlewares":
+			fmt.Fprint(w, `[
+				{"name":"authentik@file","provider":"file","type":"forwardauth","forwardAuth":{"address":"http://authentik:9000/outpost.goauthentik.io/auth/traefik"}},
+				{"name":"proto@file","provider":"file","type":"headers","headers":{"customRequestHeaders":{"X-Forwarded-Proto":"https"}}},
+				{"name":"redirect@file","provider":"file","type":"redirectscheme"}
+			]`)
+		default:
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+	}))
+}
+
+func TestTraefikClientGetHostnameDetails(t *testing.T) {
+	server := newTraefikFixtureServer(t)
+	defer server.Close()
+
+	client := NewTraefikClient(TraefikConfig{BaseURL: server.URL + "/", Username: "admin", Password: "secret"})
+	details, err := client.GetHostnameDetails()
+	if err != nil {
+		t.Fatalf("GetHostnameDetails failed: %v", err)
+	}
+	if len(details) != 4 {
+		t.Fatalf("expected four hostnames, got %#v", details)
+	}
+
+	app := details["app.example.test"]
+	if app.Upstream != "10.0.0.5:8080" || app.TLSToUpstream {
+		t.Fatalf("expected the HTTPS router to win for app.example.test, got %#v", app)
+	}
+	if !reflect.DeepEqual(app.HandlerChain, []string{"forwardauth", "headers", "reverse_proxy"}) {
+		t.Fatalf("unexpected handler chain: %v", app.HandlerChain)
+	}
+	if !app.HasForwardAuth || !app.HasXForwardedProto() {
+		t.Fatalf("expected forward auth and X-Forwarded-Proto, got %#v", app)
+	}
+	if got := details["www.example.test"]; got.Upstream != "10.0.0.5:8080" {
+		t.Fatalf("expected www.example.test to share the app service, got %#v", got)
+	}
+	for _, hostname := range []string{"nas.example.test", "files.example.test"} {
+		if got := details[hostname]; got.Upstream != "10.0.0.20:443" || !got.TLSToUpstream {
+			t.Fatalf("unexpected route for %s: %#v", hostname, got)
+		}
+	}
+	if _, ok := details["broken.example.test"]; ok {
+		t.Fatal("expected disabled routers to be skipped")
+	}
+
+	hostnames, err := client.GetHostnameMap()
+	if err != nil || hostnames["nas.example.test"] != "10.0.0.20:443" {
+		t.Fatalf("unexpected hostname map %#v (err %v)", hostnames, err)
+	}
+}
+
+func TestTraefikClientReportsAuthFailure(t *testing.T) {
+	server := newTraefikFixtureServer(t)
+	defer server.Close()
+
+	if _, err := NewTraefikClient(TraefikConfig{BaseURL: server.URL}).GetHostnameDetails(); err == nil {
+		t.Fatal("expected an unauthorized Traefik API to fail")
+	}
+}
+
+func TestParseTraefikHostRule(t *testing.T) {
+	tests := []struct {
+		rule string
+		want []string
+	}{
+		{"Host(`a.example.test`)", []string{"a.example.test"}},
+		{"Host(`a.example.test`, `b.example.test`)", []string{"a.example.test", "b.example.test"}},
+		{"Host(\"a.example.test\") || Host(`A.example.test`)", []string{"a.example.test"}},
+		{"Host(`a.example.test`) && !Host(`b.example.test`)", []string{"a.example.test"}},
+		{"HostSNI(`a.example.test`) || HostRegexp(`.+`) || HostHeader(`c.example.test`)", nil},
+		{"PathPrefix(`/api`)", nil},
+	}
+	for _, tt := range tests {
+		if got := ParseTraefikHostRule(tt.rule); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseTraefikHostRule(%q) = %v, want %v", tt.rule, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/logging"
+)
+
+// HostAlias is an OPNsense Unbound host alias: an extra hostname that answers
+// with the records of the host override it belongs to.
+type HostAlias struct {
+	UUID    string `json:"uuid,omitempty"`
+	Enabled string `json:"enabled"`
+	// Host is the UUID of the parent host override.
+	Host        string `json:"host"`
+	Hostname    string `json:"hostname"`
+	Domain      string `json:"domain"`
+	Description string `json:"description"`
+}
+
+// GetHostAliases retrieves all host aliases
+func (c *Client) GetHostAliases() ([]HostAlias, error) {
+	logging.Debug("Fetching host aliases")
+
+	resp, err := c.makeRequest("GET", "/api/unbound/settings/searchHostAlias", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	aliases := []HostAlias{}
+	if len(resp.Rows) > 0 {
+		if err := json.Unmarshal(resp.Rows, &aliases); err != nil {
+			return nil, fmt.Errorf("error parsing host alias rows: %w - Data: %s", err, string(resp.Rows))
+		}
+	}
+
+	logging.Debug("Successfully fetched host aliases", "count", len(aliases))
+	return aliases, nil
+}
+
+// AddHostAlias creates a host alias under the override alias.Host and
+// returns its UUID.
+func (c *Client) AddHostAlias(alias HostAlias) (string, error) {
+	if alias.Host == "" {
+		return "", fmt.Errorf("parent host override UUID is required for alias %s.%s", alias.Hostname, alias.Domain)
+	}
+
+	jsonData, err := json.Marshal(map[string]HostAlias{
+		"alias": alias,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error marshaling host alias: %w", err)
+	}
+
+	resp, err := c.makeRequest("POST", "/api/unbound/settings/addHostAlias", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", err
+	}
+	if resp.Result != "saved" {
+		if resp.Message != "" {
+			return "", fmt.Errorf("API error: %s - %s", resp.Result, resp.Message)
+		}
+		return "", fmt.Errorf("API error: %s (no additional details provided)", resp.Result)
+	}
+	if resp.UUID == "" {
+		return "", fmt.Errorf("no UUID returned from API")
+	}
+
+	logging.Info("Successfully added host alias", "uuid", resp.UUID, "hostname", alias.Hostname, "domain", alias.Domain)
+	return resp.UUID, nil
+}
+
+// UpdateHostAlias updates an existing host alias, which may move it under a
+// different host override.
+func (c *Client) UpdateHostAlias(alias HostAlias) error {
+	if alias.UUID == "" {
+		return fmt.Errorf("UUID is required for update")
+	}
+	if alias.Host == "" {
+		return fmt.Errorf("parent host override UUID is required for alias %s.%s", alias.Hostname, alias.Domain)
+	}
+
+	jsonData, err := json.Marshal(map[string]HostAlias{
+		"alias": alias,
+	})
+	if err != nil {
+		return fmt.Errorf("error marshaling host alias: %w", err)
+	}
+
+	resp, err := c.makeRequest("POST", "/api/unbound/settings/setHostAlias/"+alias.UUID, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	if resp.Result != "saved" && resp.Status != "ok" {
+		return fmt.Errorf("API returned error: %s", resp.Message)
+	}
+
+	logging.Info("Successfully updated host alias", "uuid", alias.UUID, "hostname", alias.Hostname, "domain", alias.Domain)
+	return nil
+}
+
+// DeleteHostAlias deletes a host alias by UUID
+func (c *Client) DeleteHostAlias(uuid string) error {
+	logging.Debug("Deleting host alias", "uuid", uuid)
+
+	resp, err := c.makeRequest("POST", "/api/unbound/settings/delHostAlias/"+uuid, bytes.NewBufferString("{}"))
+	if err != nil {
+		return err
+	}
+	if resp.Result != "deleted" && resp.Status != "ok" {
+		return fmt.Errorf("API returned error: %s", resp.Message)
+	}
+
+	logging.Debug("Successfully deleted host alias", "uuid", uuid)
+	return nil
+}
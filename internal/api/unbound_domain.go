@@ -0,0 +1,125 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/logging"
+)
+
+// DomainOverrideTypeForward marks a plain DNS forwarder, as opposed to a
+// DNS-over-TLS ("dot") one.
+const DomainOverrideTypeForward = "forward"
+
+// DomainOverride is an OPNsense Unbound query forwarding entry, called a
+// domain override before OPNsense 23.7: queries for Domain and its
+// subdomains are sent to Server instead of being resolved.
+type DomainOverride struct {
+	UUID        string `json:"uuid,omitempty"`
+	Enabled     string `json:"enabled"`
+	Type        string `json:"type,omitempty"`
+	Domain      string `json:"domain"`
+	Server      string `json:"server"`
+	Port        string `json:"port,omitempty"`
+	Description string `json:"description"`
+}
+
+// GetDomainOverrides retrieves all domain overrides
+func (c *Client) GetDomainOverrides() ([]DomainOverride, error) {
+	logging.Debug("Fetching domain overrides")
+
+	resp, err := c.makeRequest("GET", "/api/unbound/settings/searchForward", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := []DomainOverride{}
+	if len(resp.Rows) > 0 {
+		if err := json.Unmarshal(resp.Rows, &overrides); err != nil {
+			return nil, fmt.Errorf("error parsing domain override rows: %w - Data: %s", err, string(resp.Rows))
+		}
+	}
+
+	logging.Debug("Successfully fetched domain overrides", "count", len(overrides))
+	return overrides, nil
+}
+
+// AddDomainOverride creates a domain override and returns its UUID. An empty
+// Type creates a plain DNS forwarder.
+func (c *Client) AddDomainOverride(override DomainOverride) (string, error) {
+	if override.Domain == "" || override.Server == "" {
+		return "", fmt.Errorf("domain and server are required")
+	}
+	if override.Type == "" {
+		override.Type = DomainOverrideTypeForward
+	}
+
+	jsonData, err := json.Marshal(map[string]DomainOverride{
+		"dot": override,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error marshaling domain override: %w", err)
+	}
+
+	resp, err := c.makeRequest("POST", "/api/unbound/settings/addForward", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", err
+	}
+	if resp.Result != "saved" {
+		if resp.Message != "" {
+			return "", fmt.Errorf("API error: %s - %s", resp.Result, resp.Message)
+		}
+		return "", fmt.Errorf("API error: %s (no additional details provided)", resp.Result)
+	}
+	if resp.UUID == "" {
+		return "", fmt.Errorf("no UUID returned from API")
+	}
+
+	logging.Info("Successfully added domain override", "uuid", resp.UUID, "domain", override.Domain, "server", override.Server)
+	return resp.UUID, nil
+}
+
+// UpdateDomainOverride updates an existing domain override
+func (c *Client) UpdateDomainOverride(override DomainOverride) error {
+	if override.UUID == "" {
+		return fmt.Errorf("UUID is required for update")
+	}
+	if override.Type == "" {
+		override.Type = DomainOverrideTypeForward
+	}
+
+	jsonData, err := json.Marshal(map[string]DomainOverride{
+		"dot": override,
+	})
+	if err != nil {
+		return fmt.Errorf("error marshaling domain override: %w", err)
+	}
+
+	resp, err := c.makeRequest("POST", "/api/unbound/settings/setForward/"+override.UUID, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	if resp.Result != "saved" && resp.Status != "ok" {
+		return fmt.Errorf("API returned error: %s", resp.Message)
+	}
+
+	logging.Info("Successfully updated domain override", "uuid", override.UUID, "domain", override.Domain, "server", override.Server)
+	return nil
+}
+
+// DeleteDomainOverride deletes a domain override by UUID
+func (c *Client) DeleteDomainOverride(uuid string) error {
+	logging.Debug("Deleting domain override", "uuid", uuid)
+
+	resp, err := c.makeRequest("POST", "/api/unbound/settings/delForward/"+uuid, bytes.NewBufferString("{}"))
+	if err != nil {
+		return err
+	}
+	if resp.Result != "deleted" && resp.Status != "ok" {
+		return fmt.Errorf("API returned error: %s", resp.Message)
+	}
+
+	logging.Debug("Successfully deleted domain override", "uuid", uuid)
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/jeeftor/caddy-dns-sync/internal/api"
+	"github.com/jeeftor/caddy-dns-sync/internal/caddyeditor"
 	"github.com/jeeftor/caddy-dns-sync/internal/config"
 	"github.com/jeeftor/caddy-dns-sync/internal/logging"
 )
@@ -15,10 +16,52 @@ const (
 	DefaultCaddyServerPort = 2019
 )
 
-// CaddyEndpoint identifies the Caddy admin API endpoint.
+// CaddyEndpoint identifies the Caddy admin API endpoint. ServerIP stays the
+// address Caddy serves on even when Admin reaches the API over a unix socket.
 type CaddyEndpoint struct {
 	ServerIP   string
 	ServerPort int
+	Admin      api.CaddyAdminConfig
+	// ServerIPv6 is the AAAA answer for hostnames the server proxies; empty
+	// when AAAA records are not managed.
+	ServerIPv6 string
+}
+
+// String describes the admin API address for messages.
+func (e CaddyEndpoint) String() string {
+	if e.Admin.Socket != "" {
+		return "unix://" + e.Admin.SocketPath()
+	}
+	if e.Admin.UsesTLS() {
+		return fmt.Sprintf("https://%s:%d", e.ServerIP, e.ServerPort)
+	}
+	return fmt.Sprintf("%s:%d", e.ServerIP, e.ServerPort)
+}
+
+// UnboundInstance is an additional named OPNsense Unbound endpoint. TargetIP,
+// when set, is the answer its host overrides should carry instead of the Caddy
+// server IP (e.g. a branch-office firewall pointing at a local Caddy).
+type UnboundInstance struct {
+	Name     string
+	TargetIP string
+	Client   *api.Client
+}
+
+// AdguardInstance is an additional named AdGuard Home replica. AnswerOverride,
+// when set, is the answer its rewrites should carry instead of the Caddy
+// server IP.
+type AdguardInstance struct {
+	Name           string
+	AnswerOverride string
+	Client         *api.AdguardClient
+}
+
+// CaddyServer is an additional named Caddy server whose hostnames are merged
+// with the main server's. ServeIP is the DNS answer for its hostnames.
+type CaddyServer struct {
+	Name    string
+	ServeIP string
+	Source  api.HostnameSource
 }
 
 // ClientSet contains the service clients shared by CLI, TUI, and future web adapters.
@@ -26,15 +69,50 @@ type ClientSet struct {
 	Caddy      *api.CaddyClient
 	Unbound    *api.Client
 	DNSMasq    *api.DNSMasqClient
+	Kea        *api.KeaClient
 	Adguard    *api.AdguardClient
+	Pihole     *api.PiholeClient
+	RFC2136    *api.RFC2136Client
 	Cloudflare *api.CloudflareClient
 	Authentik  *api.AuthentikClient
+	// DNSMasqHosts syncs DNSMasq's host overrides as a target. DNSMasq alone
+	// only serves DHCP leases and reservations.
+	DNSMasqHosts bool
+	// UnboundInstances are synced alongside Unbound, each as its own target.
+	UnboundInstances []UnboundInstance
+	// AdguardAnswerOverride replaces the Caddy server IP in the primary
+	// AdGuard Home's rewrites; AdguardInstances are its replicas.
+	AdguardAnswerOverride string
+	AdguardInstances      []AdguardInstance
+	// CaddySource, when set, replaces the Caddy admin API as the source of
+	// route data for status and plans (e.g. a Caddyfile in a checkout).
+	CaddySource api.HostnameSource
+	// CaddyServers are additional Caddy servers merged into status and plans.
+	CaddyServers []CaddyServer
+	// Docker, when set, adds hostnames from container labels that Caddy
+	// does not serve yet.
+	Docker *api.DockerClient
+	// Kubernetes, when set, adds Ingress and HTTPRoute hostnames served by
+	// the cluster's LoadBalancer IPs.
+	Kubernetes *api.KubernetesClient
+	// Manifest lists static hostnames (printers, NAS boxes) that are synced
+	// and kept like Caddy hostnames without being proxied.
+	Manifest []config.ManifestHost
+	// AnswerRules override the Caddy server IP as the DNS answer for
+	// matching hostnames, per target when a rule names targets.
+	AnswerRules []config.AnswerRule
+	// AliasCanonical, when set, enables alias mode: hostnames on the main
+	// Caddy server alias this canonical hostname instead of carrying its IP.
+	AliasCanonical string
 }
 
 // Runtime contains loaded configuration, resolved defaults, and constructed clients.
 type Runtime struct {
 	UnboundConfig    api.Config
+	UnboundInstances []config.UnboundInstanceConfig
 	AdguardConfig    config.AdguardConfig
+	PiholeConfig     config.PiholeConfig
+	RFC2136Config    config.RFC2136Config
 	CloudflareConfig config.CloudflareConfig
 	AuthentikConfig  config.AuthentikConfig
 	CaddyEndpoint    CaddyEndpoint
@@ -46,11 +124,21 @@ type Runtime struct {
 type RuntimeOptions struct {
 	CaddyServerIP   string
 	CaddyServerPort int
+	// CaddyAdmin is the admin API transport; LoadRuntime fills it from the
+	// caddy.admin config when unset.
+	CaddyAdmin api.CaddyAdminConfig
+	// CaddyServerIPv6 is the Caddy server's IPv6 address; LoadRuntime fills
+	// it from caddy.server_ipv6 when unset.
+	CaddyServerIPv6 string
 
 	IncludeUnbound    bool
 	IncludeDNSMasq    bool
 	IncludeAdguard    bool
 	RequireAdguard    bool
+	IncludePihole     bool
+	RequirePihole     bool
+	IncludeRFC2136    bool
+	RequireRFC2136    bool
 	IncludeCloudflare bool
 	RequireCloudflare bool
 	IncludeAuthentik  bool
@@ -75,6 +163,22 @@ func LoadRuntime(options RuntimeOptions) (*Runtime, error) {
 		}
 	}
 
+	var piholeConfig config.PiholeConfig
+	if options.IncludePihole {
+		piholeConfig, err = config.LoadPiholeConfig()
+		if err != nil && options.RequirePihole {
+			return nil, fmt.Errorf("error loading Pi-hole configuration: %w", err)
+		}
+	}
+
+	var rfc2136Config config.RFC2136Config
+	if options.IncludeRFC2136 {
+		rfc2136Config, err = config.LoadRFC2136Config()
+		if err != nil && options.RequireRFC2136 {
+			return nil, fmt.Errorf("error loading RFC 2136 configuration: %w", err)
+		}
+	}
+
 	var cloudflareConfig config.CloudflareConfig
 	if options.IncludeCloudflare {
 		cloudflareConfig, err = config.LoadCloudflareConfig()
@@ -91,28 +195,179 @@ func LoadRuntime(options RuntimeOptions) (*Runtime, error) {
 		}
 	}
 
-	return NewRuntimeFromConfigs(unboundConfig, adguardConfig, cloudflareConfig, authentikConfig, options)
+	caddyConfig, err := config.LoadCaddyConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error loading Caddy configuration: %w", err)
+	}
+	if options.CaddyAdmin == (api.CaddyAdminConfig{}) {
+		options.CaddyAdmin = caddyConfig.Admin
+	}
+	if options.CaddyServerIPv6 == "" {
+		options.CaddyServerIPv6 = caddyConfig.ServerIPv6
+	}
+
+	runtime, err := NewRuntimeFromConfigs(unboundConfig, adguardConfig, piholeConfig, rfc2136Config, cloudflareConfig, authentikConfig, options)
+	if err != nil {
+		return nil, err
+	}
+
+	runtime.UseCaddySource(caddyConfig)
+	if err := runtime.AddCaddyServers(caddyConfig.Servers); err != nil {
+		return nil, err
+	}
+
+	dockerConfig, err := config.LoadDockerConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error loading Docker configuration: %w", err)
+	}
+	if err := runtime.UseDocker(dockerConfig); err != nil {
+		return nil, err
+	}
+
+	kubernetesConfig, err := config.LoadKubernetesConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error loading Kubernetes configuration: %w", err)
+	}
+	if err := runtime.UseKubernetes(kubernetesConfig); err != nil {
+		return nil, err
+	}
+
+	runtime.Clients.Manifest, err = config.LoadManifestHosts()
+	if err != nil {
+		return nil, fmt.Errorf("error loading host manifest: %w", err)
+	}
+
+	runtime.Clients.AnswerRules, err = config.LoadAnswerRules()
+	if err != nil {
+		return nil, fmt.Errorf("error loading answer rules: %w", err)
+	}
+
+	aliasMode, err := config.LoadAliasModeConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error loading alias mode: %w", err)
+	}
+	runtime.Clients.AliasCanonical = aliasMode.Canonical
+
+	if options.IncludeUnbound {
+		instances, err := config.LoadUnboundInstances()
+		if err != nil {
+			return nil, fmt.Errorf("error loading Unbound instances: %w", err)
+		}
+		runtime.AddUnboundInstances(instances)
+	}
+
+	return runtime, nil
+}
+
+// UseCaddySource selects where route data is read from. The Caddy client is
+// kept either way for commands that talk to the admin API directly.
+func (r *Runtime) UseCaddySource(cfg config.CaddyConfig) {
+	r.Clients.CaddySource = nil
+	switch cfg.Source {
+	case config.CaddySourceCaddyfile:
+		logging.Info("Reading Caddy routes from Caddyfile", "path", cfg.Caddyfile)
+		r.Clients.CaddySource = caddyeditor.NewCaddyfileSource(cfg.Caddyfile)
+	case config.CaddySourceTraefik:
+		logging.Info("Reading routes from Traefik", "url", cfg.Traefik.BaseURL)
+		r.Clients.CaddySource = api.NewTraefikClient(cfg.Traefik)
+	}
+}
+
+// UseDocker builds the Docker client when label discovery is enabled.
+func (r *Runtime) UseDocker(cfg config.DockerConfig) error {
+	r.Clients.Docker = nil
+	if !cfg.Enabled {
+		return nil
+	}
+	client, err := api.NewDockerClient(cfg.GetDockerAPIConfig())
+	if err != nil {
+		return fmt.Errorf("error creating Docker client: %w", err)
+	}
+	logging.Info("Discovering hostnames from Docker labels", "host", client.Host)
+	r.Clients.Docker = client
+	return nil
+}
+
+// UseKubernetes builds the Kubernetes client when discovery is enabled.
+func (r *Runtime) UseKubernetes(cfg config.KubernetesConfig) error {
+	r.Clients.Kubernetes = nil
+	if !cfg.Enabled {
+		return nil
+	}
+	client, err := api.NewKubernetesClient(cfg.GetKubernetesAPIConfig())
+	if err != nil {
+		return fmt.Errorf("error creating Kubernetes client: %w", err)
+	}
+	logging.Info("Discovering hostnames from Kubernetes", "server", client.Server)
+	r.Clients.Kubernetes = client
+	return nil
+}
+
+// AddCaddyServers builds an admin API client for each additional Caddy server.
+func (r *Runtime) AddCaddyServers(servers []config.CaddyServerConfig) error {
+	for _, server := range servers {
+		port := server.ServerPort
+		if port == 0 {
+			port = DefaultCaddyServerPort
+		}
+		client, err := api.NewCaddyAdminClient(server.ServerIP, port, server.Admin)
+		if err != nil {
+			return fmt.Errorf("error creating client for Caddy server %q: %w", server.Name, err)
+		}
+		r.Clients.CaddyServers = append(r.Clients.CaddyServers, CaddyServer{
+			Name:    server.Name,
+			ServeIP: server.GetServeIP(),
+			Source:  client,
+		})
+	}
+	return nil
+}
+
+// AddUnboundInstances builds a client for each additional Unbound endpoint.
+// Like the main Unbound client, legacy description stamps are migrated on a
+// best-effort basis; an unreachable instance is logged and still added so its
+// failure shows up in status reports rather than hiding the instance.
+func (r *Runtime) AddUnboundInstances(instances []config.UnboundInstanceConfig) {
+	for _, instance := range instances {
+		client := api.NewClient(instance.GetUnboundAPIConfig())
+		MigrateUnboundDescriptions(client)
+		r.UnboundInstances = append(r.UnboundInstances, instance)
+		r.Clients.UnboundInstances = append(r.Clients.UnboundInstances, UnboundInstance{
+			Name:     instance.Name,
+			TargetIP: instance.TargetIP,
+			Client:   client,
+		})
+	}
 }
 
 // NewRuntimeFromConfigs builds runtime clients from already-loaded configuration.
 func NewRuntimeFromConfigs(
 	unboundConfig api.Config,
 	adguardConfig config.AdguardConfig,
+	piholeConfig config.PiholeConfig,
+	rfc2136Config config.RFC2136Config,
 	cloudflareConfig config.CloudflareConfig,
 	authentikConfig config.AuthentikConfig,
 	options RuntimeOptions,
 ) (*Runtime, error) {
-	endpoint := ResolveCaddyEndpoint(options.CaddyServerIP, options.CaddyServerPort)
+	endpoint := ResolveCaddyEndpoint(options.CaddyServerIP, options.CaddyServerPort, options.CaddyAdmin)
+	endpoint.ServerIPv6 = options.CaddyServerIPv6
+	caddyClient, err := api.NewCaddyAdminClient(endpoint.ServerIP, endpoint.ServerPort, endpoint.Admin)
+	if err != nil {
+		return nil, fmt.Errorf("error creating Caddy client: %w", err)
+	}
 
 	runtime := &Runtime{
 		UnboundConfig:    unboundConfig,
 		AdguardConfig:    adguardConfig,
+		PiholeConfig:     piholeConfig,
+		RFC2136Config:    rfc2136Config,
 		CloudflareConfig: cloudflareConfig,
 		AuthentikConfig:  authentikConfig,
 		CaddyEndpoint:    endpoint,
 		CaddyServiceURL:  ResolveCaddyServiceURL(cloudflareConfig, endpoint),
 		Clients: ClientSet{
-			Caddy: api.NewCaddyClient(endpoint.ServerIP, endpoint.ServerPort),
+			Caddy: caddyClient,
 		},
 	}
 
@@ -127,16 +382,49 @@ func NewRuntimeFromConfigs(
 
 	if options.IncludeDNSMasq {
 		runtime.Clients.DNSMasq = api.NewDNSMasqClient(unboundConfig)
+		runtime.Clients.DNSMasqHosts = unboundConfig.DNSMasqHosts
+		// Kea replaces Dnsmasq as the DHCP server (leases and reservations);
+		// Dnsmasq host overrides stay available either way.
+		if unboundConfig.DHCPBackend == api.DHCPBackendKea {
+			runtime.Clients.Kea = api.NewKeaClient(unboundConfig)
+		}
 	}
 
 	if options.IncludeAdguard {
 		if isAdguardComplete(adguardConfig) {
+			if err := config.ValidateAdguardInstances(adguardConfig); err != nil {
+				return nil, err
+			}
 			runtime.Clients.Adguard = api.NewAdguardClient(adguardConfig.GetAdguardAPIConfig())
+			runtime.Clients.AdguardAnswerOverride = adguardConfig.AnswerOverride
+			for _, instance := range adguardConfig.Instances {
+				runtime.Clients.AdguardInstances = append(runtime.Clients.AdguardInstances, AdguardInstance{
+					Name:           instance.Name,
+					AnswerOverride: instance.AnswerOverride,
+					Client:         api.NewAdguardClient(instance.GetAdguardAPIConfig()),
+				})
+			}
 		} else if options.RequireAdguard {
 			return nil, fmt.Errorf("AdguardHome configuration missing required fields (BaseURL, Username, Password)")
 		}
 	}
 
+	if options.IncludePihole {
+		if isPiholeComplete(piholeConfig) {
+			runtime.Clients.Pihole = api.NewPiholeClient(piholeConfig.GetPiholeAPIConfig())
+		} else if options.RequirePihole {
+			return nil, fmt.Errorf("Pi-hole configuration missing required enabled flag or base URL")
+		}
+	}
+
+	if options.IncludeRFC2136 {
+		if isRFC2136Complete(rfc2136Config) {
+			runtime.Clients.RFC2136 = api.NewRFC2136Client(rfc2136Config.GetRFC2136APIConfig())
+		} else if options.RequireRFC2136 {
+			return nil, fmt.Errorf("RFC 2136 configuration missing required fields (Enabled, Server, Zone, TSIG key, TSIG secret)")
+		}
+	}
+
 	if options.IncludeCloudflare && cloudflareConfig.Enabled && cloudflareConfig.APIToken != "" && cloudflareConfig.AccountID != "" {
 		cfClient, err := api.NewCloudflareClient(cloudflareConfig.GetCloudflareAPIConfig())
 		if err != nil {
@@ -163,14 +451,16 @@ func NewRuntimeFromConfigs(
 }
 
 // ResolveCaddyEndpoint applies existing command defaults to an optional endpoint override.
-func ResolveCaddyEndpoint(serverIP string, serverPort int) CaddyEndpoint {
+// The admin transport is kept as given; with a unix socket, serverIP remains
+// the address Caddy serves on.
+func ResolveCaddyEndpoint(serverIP string, serverPort int, admin api.CaddyAdminConfig) CaddyEndpoint {
 	if serverIP == "" {
 		serverIP = DefaultCaddyServerIP
 	}
 	if serverPort == 0 {
 		serverPort = DefaultCaddyServerPort
 	}
-	return CaddyEndpoint{ServerIP: serverIP, ServerPort: serverPort}
+	return CaddyEndpoint{ServerIP: serverIP, ServerPort: serverPort, Admin: admin}
 }
 
 // ResolveCaddyServiceURL returns the service URL used for Cloudflare quick-fill actions.
@@ -187,3 +477,19 @@ func isAdguardComplete(adguardConfig config.AdguardConfig) bool {
 		adguardConfig.Username != "" &&
 		adguardConfig.Password != ""
 }
+
+// isPiholeComplete reports whether a Pi-hole client can be built. The password
+// is optional: a Pi-hole without one accepts unauthenticated API requests.
+func isPiholeComplete(piholeConfig config.PiholeConfig) bool {
+	return piholeConfig.Enabled && piholeConfig.BaseURL != ""
+}
+
+// isRFC2136Complete reports whether an RFC 2136 client can be built. Updates
+// are always TSIG-signed, so the key and secret are required.
+func isRFC2136Complete(rfc2136Config config.RFC2136Config) bool {
+	return rfc2136Config.Enabled &&
+		rfc2136Config.Server != "" &&
+		rfc2136Config.Zone != "" &&
+		rfc2136Config.TSIGKeyName != "" &&
+		rfc2136Config.TSIGSecret != ""
+}
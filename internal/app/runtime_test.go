@@ -12,7 +12,7 @@ func TestNewRuntimeFromConfigsBuildsCoreClientsWithDefaults(t *testing.T) {
 		APIKey:    "key",
 		APISecret: "secret",
 		BaseURL:   "https://opnsense.example",
-	}, config.AdguardConfig{}, config.CloudflareConfig{}, config.AuthentikConfig{}, RuntimeOptions{
+	}, config.AdguardConfig{}, config.PiholeConfig{}, config.RFC2136Config{}, config.CloudflareConfig{}, config.AuthentikConfig{}, RuntimeOptions{
 		IncludeUnbound: true,
 		IncludeDNSMasq: true,
 	})
@@ -41,7 +41,7 @@ func TestNewRuntimeFromConfigsBuildsCoreClientsWithDefaults(t *testing.T) {
 }
 
 func TestNewRuntimeFromConfigsUsesCaddyOverridesAndCloudflareServiceURL(t *testing.T) {
-	runtime, err := NewRuntimeFromConfigs(api.Config{}, config.AdguardConfig{}, config.CloudflareConfig{
+	runtime, err := NewRuntimeFromConfigs(api.Config{}, config.AdguardConfig{}, config.PiholeConfig{}, config.RFC2136Config{}, config.CloudflareConfig{
 		CaddyServiceURL: "http://caddy.internal:8080",
 	}, config.AuthentikConfig{}, RuntimeOptions{
 		CaddyServerIP:   "10.0.0.10",
@@ -62,13 +62,36 @@ func TestNewRuntimeFromConfigsUsesCaddyOverridesAndCloudflareServiceURL(t *testi
 	}
 }
 
+func TestNewRuntimeFromConfigsReachesCaddyAdminOverUnixSocket(t *testing.T) {
+	runtime, err := NewRuntimeFromConfigs(api.Config{}, config.AdguardConfig{}, config.PiholeConfig{}, config.RFC2136Config{}, config.CloudflareConfig{}, config.AuthentikConfig{}, RuntimeOptions{
+		CaddyAdmin: api.CaddyAdminConfig{Socket: "unix:///run/caddy/admin.sock"},
+	})
+	if err != nil {
+		t.Fatalf("NewRuntimeFromConfigs failed: %v", err)
+	}
+
+	if runtime.Clients.Caddy.Socket != "/run/caddy/admin.sock" {
+		t.Fatalf("expected the Caddy client to use the admin socket, got %#v", runtime.Clients.Caddy)
+	}
+	if runtime.CaddyEndpoint.ServerIP != DefaultCaddyServerIP || runtime.CaddyEndpoint.String() != "unix:///run/caddy/admin.sock" {
+		t.Fatalf("expected the serving IP to stay the default, got %#v", runtime.CaddyEndpoint)
+	}
+
+	_, err = NewRuntimeFromConfigs(api.Config{}, config.AdguardConfig{}, config.PiholeConfig{}, config.RFC2136Config{}, config.CloudflareConfig{}, config.AuthentikConfig{}, RuntimeOptions{
+		CaddyAdmin: api.CaddyAdminConfig{ClientCert: "/missing/admin.crt", ClientKey: "/missing/admin.key"},
+	})
+	if err == nil {
+		t.Fatal("expected an error for an unreadable Caddy admin client certificate")
+	}
+}
+
 func TestNewRuntimeFromConfigsBuildsOptionalAdguardWhenComplete(t *testing.T) {
 	runtime, err := NewRuntimeFromConfigs(api.Config{}, config.AdguardConfig{
 		Enabled:  true,
 		BaseURL:  "http://adguard.example",
 		Username: "user",
 		Password: "pass",
-	}, config.CloudflareConfig{}, config.AuthentikConfig{}, RuntimeOptions{
+	}, config.PiholeConfig{}, config.RFC2136Config{}, config.CloudflareConfig{}, config.AuthentikConfig{}, RuntimeOptions{
 		IncludeAdguard: true,
 	})
 	if err != nil {
@@ -84,7 +107,7 @@ func TestNewRuntimeFromConfigsRequiresAdguardWhenRequested(t *testing.T) {
 	_, err := NewRuntimeFromConfigs(api.Config{}, config.AdguardConfig{
 		Enabled: true,
 		BaseURL: "http://adguard.example",
-	}, config.CloudflareConfig{}, config.AuthentikConfig{}, RuntimeOptions{
+	}, config.PiholeConfig{}, config.RFC2136Config{}, config.CloudflareConfig{}, config.AuthentikConfig{}, RuntimeOptions{
 		IncludeAdguard: true,
 		RequireAdguard: true,
 	})
@@ -94,7 +117,7 @@ func TestNewRuntimeFromConfigsRequiresAdguardWhenRequested(t *testing.T) {
 }
 
 func TestNewRuntimeFromConfigsBuildsCloudflareFromCredentials(t *testing.T) {
-	runtime, err := NewRuntimeFromConfigs(api.Config{}, config.AdguardConfig{}, config.CloudflareConfig{
+	runtime, err := NewRuntimeFromConfigs(api.Config{}, config.AdguardConfig{}, config.PiholeConfig{}, config.RFC2136Config{}, config.CloudflareConfig{
 		Enabled:   true,
 		APIToken:  "token",
 		AccountID: "account-id",
@@ -113,7 +136,7 @@ func TestNewRuntimeFromConfigsBuildsCloudflareFromCredentials(t *testing.T) {
 }
 
 func TestNewRuntimeFromConfigsSkipsDisabledCloudflare(t *testing.T) {
-	runtime, err := NewRuntimeFromConfigs(api.Config{}, config.AdguardConfig{}, config.CloudflareConfig{
+	runtime, err := NewRuntimeFromConfigs(api.Config{}, config.AdguardConfig{}, config.PiholeConfig{}, config.RFC2136Config{}, config.CloudflareConfig{
 		Enabled:   false,
 		APIToken:  "token",
 		AccountID: "account-id",
@@ -130,3 +153,127 @@ func TestNewRuntimeFromConfigsSkipsDisabledCloudflare(t *testing.T) {
 		t.Fatal("expected disabled Cloudflare config to skip client creation")
 	}
 }
+
+func TestNewRuntimeFromConfigsBuildsPiholeWithoutPassword(t *testing.T) {
+	runtime, err := NewRuntimeFromConfigs(api.Config{}, config.AdguardConfig{}, config.PiholeConfig{
+		Enabled: true,
+		BaseURL: "http://pihole.example",
+	}, config.RFC2136Config{}, config.CloudflareConfig{}, config.AuthentikConfig{}, RuntimeOptions{
+		IncludePihole: true,
+		RequirePihole: true,
+	})
+	if err != nil {
+		t.Fatalf("NewRuntimeFromConfigs failed: %v", err)
+	}
+
+	if runtime.Clients.Pihole == nil {
+		t.Fatal("expected Pi-hole client")
+	}
+}
+
+func TestNewRuntimeFromConfigsRequiresPiholeWhenRequested(t *testing.T) {
+	_, err := NewRuntimeFromConfigs(api.Config{}, config.AdguardConfig{}, config.PiholeConfig{
+		BaseURL: "http://pihole.example",
+	}, config.RFC2136Config{}, config.CloudflareConfig{}, config.AuthentikConfig{}, RuntimeOptions{
+		IncludePihole: true,
+		RequirePihole: true,
+	})
+	if err == nil {
+		t.Fatal("expected error for disabled required Pi-hole config")
+	}
+}
+
+func TestNewRuntimeFromConfigsRequiresRFC2136TSIGKey(t *testing.T) {
+	rfc2136Config := config.RFC2136Config{
+		Enabled: true,
+		Server:  "10.0.0.53",
+		Zone:    "home.example",
+	}
+	_, err := NewRuntimeFromConfigs(api.Config{}, config.AdguardConfig{}, config.PiholeConfig{}, rfc2136Config, config.CloudflareConfig{}, config.AuthentikConfig{}, RuntimeOptions{
+		IncludeRFC2136: true,
+		RequireRFC2136: true,
+	})
+	if err == nil {
+		t.Fatal("expected error for RFC 2136 config without a TSIG key")
+	}
+
+	rfc2136Config.TSIGKeyName = "caddy-dns-sync"
+	rfc2136Config.TSIGSecret = "c2VjcmV0"
+	runtime, err := NewRuntimeFromConfigs(api.Config{}, config.AdguardConfig{}, config.PiholeConfig{}, rfc2136Config, config.CloudflareConfig{}, config.AuthentikConfig{}, RuntimeOptions{
+		IncludeRFC2136: true,
+		RequireRFC2136: true,
+	})
+	if err != nil {
+		t.Fatalf("NewRuntimeFromConfigs failed: %v", err)
+	}
+	if runtime.Clients.RFC2136 == nil || runtime.Clients.RFC2136.Server != "10.0.0.53:53" {
+		t.Fatalf("expected RFC 2136 client on port 53, got %#v", runtime.Clients.RFC2136)
+	}
+}
+
+func TestNewRuntimeFromConfigsBuildsKeaForKeaDHCPBackend(t *testing.T) {
+	runtime, err := NewRuntimeFromConfigs(api.Config{
+		BaseURL:     "https://opnsense.example",
+		DHCPBackend: api.DHCPBackendKea,
+	}, config.AdguardConfig{}, config.PiholeConfig{}, config.RFC2136Config{}, config.CloudflareConfig{}, config.AuthentikConfig{}, RuntimeOptions{
+		IncludeDNSMasq: true,
+	})
+	if err != nil {
+		t.Fatalf("NewRuntimeFromConfigs failed: %v", err)
+	}
+	if runtime.Clients.Kea == nil {
+		t.Fatal("expected Kea client")
+	}
+	if runtime.Clients.DNSMasq == nil {
+		t.Fatal("expected Dnsmasq client to remain available for host overrides")
+	}
+}
+
+func TestAddUnboundInstancesBuildsNamedClients(t *testing.T) {
+	runtime, err := NewRuntimeFromConfigs(api.Config{}, config.AdguardConfig{}, config.PiholeConfig{}, config.RFC2136Config{}, config.CloudflareConfig{}, config.AuthentikConfig{}, RuntimeOptions{})
+	if err != nil {
+		t.Fatalf("NewRuntimeFromConfigs failed: %v", err)
+	}
+
+	runtime.AddUnboundInstances([]config.UnboundInstanceConfig{
+		{Name: "fw-b", BaseURL: "https://127.0.0.1:1"},
+		{Name: "site-2", BaseURL: "https://127.0.0.1:1", TargetIP: "10.2.0.15"},
+	})
+
+	instances := runtime.Clients.UnboundInstances
+	if len(instances) != 2 || len(runtime.UnboundInstances) != 2 {
+		t.Fatalf("expected two Unbound instances, got %#v", instances)
+	}
+	if instances[1].Name != "site-2" || instances[1].TargetIP != "10.2.0.15" || instances[1].Client == nil {
+		t.Fatalf("unexpected second instance: %#v", instances[1])
+	}
+}
+
+func TestNewRuntimeFromConfigsBuildsAdguardInstances(t *testing.T) {
+	adguardConfig := config.AdguardConfig{
+		Enabled:  true,
+		BaseURL:  "http://adguard-a.example",
+		Username: "user",
+		Password: "pass",
+		Instances: []config.AdguardInstanceConfig{
+			{Name: "secondary", BaseURL: "http://adguard-b.example", AnswerOverride: "10.20.0.15"},
+		},
+	}
+	runtime, err := NewRuntimeFromConfigs(api.Config{}, adguardConfig, config.PiholeConfig{}, config.RFC2136Config{}, config.CloudflareConfig{}, config.AuthentikConfig{}, RuntimeOptions{
+		IncludeAdguard: true,
+	})
+	if err != nil {
+		t.Fatalf("NewRuntimeFromConfigs failed: %v", err)
+	}
+	instances := runtime.Clients.AdguardInstances
+	if len(instances) != 1 || instances[0].Name != "secondary" || instances[0].AnswerOverride != "10.20.0.15" || instances[0].Client == nil {
+		t.Fatalf("unexpected AdGuard instances: %#v", instances)
+	}
+
+	adguardConfig.Instances[0].AnswerOverride = "not-an-ip"
+	if _, err := NewRuntimeFromConfigs(api.Config{}, adguardConfig, config.PiholeConfig{}, config.RFC2136Config{}, config.CloudflareConfig{}, config.AuthentikConfig{}, RuntimeOptions{
+		IncludeAdguard: true,
+	}); err == nil {
+		t.Fatal("expected invalid answer_override to be rejected")
+	}
+}
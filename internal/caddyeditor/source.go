@@ -0,0 +1,131 @@
+package caddyeditor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/api"
+	"github.com/jeeftor/caddy-dns-sync/internal/logging"
+	"github.com/jeeftor/caddy-dns-sync/internal/models"
+)
+
+// CaddyfileSource reads Caddy route details from a Caddyfile on disk instead
+// of a running admin API, so DNS changes can be planned from a checkout before
+// the Caddyfile is deployed. It implements api.HostnameSource.
+//
+// When a caddy binary is available the file is run through 'caddy adapt' and
+// read exactly like the admin API config; otherwise ParseCaddyfile is used,
+// which only understands the wildcard+matcher layout.
+type CaddyfileSource struct {
+	Path string
+	// CaddyBinary is the executable used for 'caddy adapt'. Empty means
+	// "caddy" on PATH.
+	CaddyBinary string
+}
+
+// NewCaddyfileSource creates a source for the Caddyfile at path.
+func NewCaddyfileSource(path string) *CaddyfileSource {
+	return &CaddyfileSource{Path: path}
+}
+
+// GetHostnameDetails returns hostname -> CaddyRouteInfo for the Caddyfile.
+func (s *CaddyfileSource) GetHostnameDetails() (map[string]models.CaddyRouteInfo, error) {
+	if bin, err := exec.LookPath(s.caddyBinary()); err == nil {
+		details, err := s.adapt(bin)
+		if err == nil {
+			return details, nil
+		}
+		logging.Warn("caddy adapt failed; falling back to the built-in Caddyfile parser", "path", s.Path, "error", err)
+	} else {
+		logging.Debug("caddy binary not found; using the built-in Caddyfile parser", "path", s.Path)
+	}
+
+	blocks, err := ParseCaddyfile(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]models.CaddyRouteInfo, len(blocks))
+	for _, block := range blocks {
+		if _, exists := result[block.Hostname]; !exists {
+			result[block.Hostname] = routeInfoFromBlock(block)
+		}
+	}
+	return result, nil
+}
+
+func (s *CaddyfileSource) caddyBinary() string {
+	if s.CaddyBinary != "" {
+		return s.CaddyBinary
+	}
+	return "caddy"
+}
+
+// adapt converts the Caddyfile to JSON with 'caddy adapt' and extracts the
+// route details the same way the admin API config is read.
+func (s *CaddyfileSource) adapt(bin string) (map[string]models.CaddyRouteInfo, error) {
+	cmd := exec.Command(bin, "adapt", "--config", s.Path, "--adapter", "caddyfile") //nolint:gosec
+	cmd.Dir = filepath.Dir(s.Path)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var config map[string]interface{}
+	if err := json.Unmarshal(stdout.Bytes(), &config); err != nil {
+		return nil, fmt.Errorf("parsing caddy adapt output: %w", err)
+	}
+	return api.HostnameDetailsFromConfig(config), nil
+}
+
+// routeInfoFromBlock approximates what the admin API reports for a parsed
+// site block: the reverse_proxy upstream as a dial address and the handle
+// block's top-level directives as the handler chain.
+func routeInfoFromBlock(block SiteBlock) models.CaddyRouteInfo {
+	upstream, tlsUpstream := dialAddress(block.Upstream)
+	var chain []string
+	for _, directive := range block.Directives {
+		// ParseCaddyfile keeps the closing brace of nested blocks.
+		if name := strings.Fields(directive)[0]; name != "}" {
+			chain = append(chain, name)
+		}
+	}
+	if upstream != "" {
+		chain = append(chain, "reverse_proxy")
+	}
+	return models.CaddyRouteInfo{
+		Upstream:       upstream,
+		HandlerChain:   chain,
+		TLSToUpstream:  tlsUpstream,
+		HasForwardAuth: strings.Contains(block.Raw, "outpost.goauthentik.io"),
+	}
+}
+
+// dialAddress turns a Caddyfile upstream such as "http://10.0.0.5:8080" or
+// "https://nas.lan" into the host:port dial form Caddy's JSON config uses,
+// and reports whether the upstream is reached over TLS.
+func dialAddress(upstream string) (string, bool) {
+	if !strings.Contains(upstream, "://") {
+		return upstream, false
+	}
+	u, err := url.Parse(upstream)
+	if err != nil || u.Host == "" {
+		return upstream, false
+	}
+	tlsUpstream := u.Scheme == "https"
+	if u.Port() != "" {
+		return u.Host, tlsUpstream
+	}
+	port := "80"
+	if tlsUpstream {
+		port = "443"
+	}
+	return net.JoinHostPort(u.Hostname(), port), tlsUpstream
+}
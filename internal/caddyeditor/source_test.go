@@ -0,0 +1,74 @@
+package caddyeditor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCaddyfileSourceFallsBackToParserWithoutCaddy(t *testing.T) {
+	cfg := writeCaddyfile(t, `*.vookie.net {
+	@sonarr host sonarr.vookie.net
+	handle @sonarr {
+		reverse_proxy http://10.0.0.112:8989
+	}
+
+	@nas host nas.vookie.net
+	handle @nas {
+		import authentik
+		reverse_proxy https://10.0.0.20 {
+			transport http {
+				tls_insecure_skip_verify
+			}
+		}
+	}
+}
+`)
+	source := NewCaddyfileSource(AbsCaddyfilePath(cfg))
+	source.CaddyBinary = filepath.Join(t.TempDir(), "missing-caddy")
+
+	details, err := source.GetHostnameDetails()
+	if err != nil {
+		t.Fatalf("GetHostnameDetails failed: %v", err)
+	}
+	if len(details) != 2 {
+		t.Fatalf("expected two hostnames, got %#v", details)
+	}
+	if got := details["sonarr.vookie.net"]; got.Upstream != "10.0.0.112:8989" || got.TLSToUpstream {
+		t.Fatalf("unexpected sonarr route: %#v", got)
+	}
+	nas := details["nas.vookie.net"]
+	if nas.Upstream != "10.0.0.20:443" || !nas.TLSToUpstream {
+		t.Fatalf("unexpected nas route: %#v", nas)
+	}
+	if !reflect.DeepEqual(nas.HandlerChain, []string{"import", "reverse_proxy"}) {
+		t.Fatalf("unexpected nas handler chain: %v", nas.HandlerChain)
+	}
+}
+
+func TestCaddyfileSourceUsesCaddyAdaptWhenAvailable(t *testing.T) {
+	cfg := writeCaddyfile(t, emptyCaddyfile)
+	bin := filepath.Join(t.TempDir(), "caddy")
+	script := `#!/bin/sh
+[ "$1" = adapt ] || exit 2
+cat <<'EOF'
+{"apps":{"http":{"servers":{"srv0":{"routes":[
+	{"match":[{"host":["app.vookie.net"]}],"handle":[{"handler":"reverse_proxy","upstreams":[{"dial":"10.0.0.5:8080"}]}]}
+]}}}}}
+EOF
+`
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil {
+		t.Fatalf("write fake caddy: %v", err)
+	}
+
+	source := NewCaddyfileSource(AbsCaddyfilePath(cfg))
+	source.CaddyBinary = bin
+	details, err := source.GetHostnameDetails()
+	if err != nil {
+		t.Fatalf("GetHostnameDetails failed: %v", err)
+	}
+	if got := details["app.vookie.net"]; got.Upstream != "10.0.0.5:8080" {
+		t.Fatalf("expected adapted route for app.vookie.net, got %#v", details)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
@@ -16,8 +17,15 @@ import (
 
 // UnboundDataSource implements ListDataSource for Unbound DNS
 type UnboundDataSource struct {
-	client    *api.Client
-	overrides []api.DNSOverride
+	client  *api.Client
+	listing UnboundListing
+}
+
+// UnboundListing holds Unbound host overrides and the host aliases under
+// them.
+type UnboundListing struct {
+	Overrides []api.DNSOverride `json:"overrides"`
+	Aliases   []api.HostAlias   `json:"aliases"`
 }
 
 // NewUnboundDataSource creates a new Unbound data source
@@ -39,26 +47,51 @@ func (s *UnboundDataSource) FetchData() (interface{}, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch Unbound overrides: %w", err)
 	}
-	s.overrides = overrides
-	return overrides, nil
+	aliases, err := s.client.GetHostAliases()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Unbound host aliases: %w", err)
+	}
+	s.listing = UnboundListing{Overrides: overrides, Aliases: aliases}
+	if len(overrides) == 0 && len(aliases) == 0 {
+		return nil, nil
+	}
+	return s.listing, nil
 }
 
 func (s *UnboundDataSource) FormatAsTable() tables.TableConfig {
-	headers := []string{"UUID", "Host", "Domain", "IP Address", "Description", "Enabled"}
+	headers := []string{"UUID", "Host", "Domain", "Type", "Answer", "Description", "Enabled"}
 	rows := [][]string{}
 
-	for _, o := range s.overrides {
-		enabled := "No"
-		if o.Enabled == "1" {
-			enabled = "Yes"
+	parents := make(map[string]string, len(s.listing.Overrides))
+	for _, o := range s.listing.Overrides {
+		parents[o.UUID] = o.Host + "." + o.Domain
+		rr := o.RR
+		if rr == "" {
+			rr = "A"
 		}
 		rows = append(rows, []string{
 			o.UUID,
 			o.Host,
 			o.Domain,
+			rr,
 			o.Server,
 			o.Description,
-			enabled,
+			enabledLabel(o.Enabled),
+		})
+	}
+	for _, a := range s.listing.Aliases {
+		parent := parents[a.Host]
+		if parent == "" {
+			parent = a.Host
+		}
+		rows = append(rows, []string{
+			a.UUID,
+			a.Hostname,
+			a.Domain,
+			"alias",
+			parent,
+			a.Description,
+			enabledLabel(a.Enabled),
 		})
 	}
 
@@ -66,18 +99,87 @@ func (s *UnboundDataSource) FormatAsTable() tables.TableConfig {
 		Title:   "UNBOUND DNS OVERRIDES",
 		Headers: headers,
 		Rows:    rows,
-		Summary: fmt.Sprintf("Total: %d overrides", len(s.overrides)),
+		Summary: fmt.Sprintf("Total: %d overrides, %d aliases", len(s.listing.Overrides), len(s.listing.Aliases)),
 	}
 }
 
 func (s *UnboundDataSource) FormatAsJSON() ([]byte, error) {
-	return json.MarshalIndent(s.overrides, "", "  ")
+	return json.MarshalIndent(s.listing, "", "  ")
 }
 
 func (s *UnboundDataSource) EmptyMessage() string {
 	return "No DNS overrides found."
 }
 
+func enabledLabel(enabled string) string {
+	if enabled == "1" {
+		return "Yes"
+	}
+	return "No"
+}
+
+// UnboundDomainDataSource implements ListDataSource for Unbound domain
+// overrides
+type UnboundDomainDataSource struct {
+	client    *api.Client
+	overrides []api.DomainOverride
+}
+
+// NewUnboundDomainDataSource creates a new Unbound domain override data source
+func NewUnboundDomainDataSource() *UnboundDomainDataSource {
+	return &UnboundDomainDataSource{}
+}
+
+func (s *UnboundDomainDataSource) Initialize() error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	s.client = api.NewClient(cfg)
+	return nil
+}
+
+func (s *UnboundDomainDataSource) FetchData() (interface{}, error) {
+	overrides, err := s.client.GetDomainOverrides()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Unbound domain overrides: %w", err)
+	}
+	s.overrides = overrides
+	return overrides, nil
+}
+
+func (s *UnboundDomainDataSource) FormatAsTable() tables.TableConfig {
+	headers := []string{"UUID", "Domain", "Server", "Port", "Type", "Description", "Enabled"}
+	rows := [][]string{}
+
+	for _, o := range s.overrides {
+		rows = append(rows, []string{
+			o.UUID,
+			o.Domain,
+			o.Server,
+			o.Port,
+			o.Type,
+			o.Description,
+			enabledLabel(o.Enabled),
+		})
+	}
+
+	return tables.TableConfig{
+		Title:   "UNBOUND DOMAIN OVERRIDES",
+		Headers: headers,
+		Rows:    rows,
+		Summary: fmt.Sprintf("Total: %d domain overrides", len(s.overrides)),
+	}
+}
+
+func (s *UnboundDomainDataSource) FormatAsJSON() ([]byte, error) {
+	return json.MarshalIndent(s.overrides, "", "  ")
+}
+
+func (s *UnboundDomainDataSource) EmptyMessage() string {
+	return "No domain overrides found."
+}
+
 // AdguardDataSource implements ListDataSource for AdguardHome
 type AdguardDataSource struct {
 	client   *api.AdguardClient
@@ -139,6 +241,161 @@ func (s *AdguardDataSource) EmptyMessage() string {
 	return "No DNS rewrites found."
 }
 
+// PiholeDataSource implements ListDataSource for Pi-hole local DNS records
+type PiholeDataSource struct {
+	client  *api.PiholeClient
+	records []PiholeRecord
+}
+
+// PiholeRecord is a Pi-hole local DNS host or CNAME record.
+type PiholeRecord struct {
+	Domain string `json:"domain"`
+	Type   string `json:"type"`
+	Answer string `json:"answer"`
+}
+
+// NewPiholeDataSource creates a new Pi-hole data source
+func NewPiholeDataSource() *PiholeDataSource {
+	return &PiholeDataSource{}
+}
+
+func (s *PiholeDataSource) Initialize() error {
+	cfg, err := config.LoadPiholeConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if !cfg.Enabled {
+		return fmt.Errorf("Pi-hole is not enabled in configuration")
+	}
+
+	s.client = api.NewPiholeClient(cfg.GetPiholeAPIConfig())
+	return nil
+}
+
+func (s *PiholeDataSource) FetchData() (interface{}, error) {
+	defer s.client.Logout()
+
+	hosts, err := s.client.ListHosts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Pi-hole hosts: %w", err)
+	}
+	cnames, err := s.client.ListCNAMEs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Pi-hole CNAME records: %w", err)
+	}
+
+	records := make([]PiholeRecord, 0, len(hosts)+len(cnames))
+	for _, host := range hosts {
+		records = append(records, PiholeRecord{Domain: host.Hostname, Type: "A", Answer: host.IP})
+	}
+	for _, cname := range cnames {
+		records = append(records, PiholeRecord{Domain: cname.Domain, Type: "CNAME", Answer: cname.Target})
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Domain < records[j].Domain
+	})
+	s.records = records
+	return records, nil
+}
+
+func (s *PiholeDataSource) FormatAsTable() tables.TableConfig {
+	headers := []string{"Domain", "Type", "Answer"}
+	rows := [][]string{}
+
+	for _, r := range s.records {
+		rows = append(rows, []string{
+			r.Domain,
+			r.Type,
+			r.Answer,
+		})
+	}
+
+	return tables.TableConfig{
+		Title:   "PI-HOLE LOCAL DNS RECORDS",
+		Headers: headers,
+		Rows:    rows,
+		Summary: fmt.Sprintf("Total: %d records", len(s.records)),
+	}
+}
+
+func (s *PiholeDataSource) FormatAsJSON() ([]byte, error) {
+	return json.MarshalIndent(s.records, "", "  ")
+}
+
+func (s *PiholeDataSource) EmptyMessage() string {
+	return "No local DNS records found."
+}
+
+// RFC2136DataSource implements ListDataSource for an RFC 2136 zone
+type RFC2136DataSource struct {
+	client  *api.RFC2136Client
+	records []api.RFC2136Record
+}
+
+// NewRFC2136DataSource creates a new RFC 2136 data source
+func NewRFC2136DataSource() *RFC2136DataSource {
+	return &RFC2136DataSource{}
+}
+
+func (s *RFC2136DataSource) Initialize() error {
+	cfg, err := config.LoadRFC2136Config()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if !cfg.Enabled {
+		return fmt.Errorf("RFC 2136 is not enabled in configuration")
+	}
+
+	s.client = api.NewRFC2136Client(cfg.GetRFC2136APIConfig())
+	return nil
+}
+
+func (s *RFC2136DataSource) FetchData() (interface{}, error) {
+	records, err := s.client.ListRecords()
+	if err != nil {
+		return nil, fmt.Errorf("failed to transfer RFC 2136 zone: %w", err)
+	}
+	s.records = records
+	return records, nil
+}
+
+func (s *RFC2136DataSource) FormatAsTable() tables.TableConfig {
+	headers := []string{"Hostname", "IP", "TTL", "Managed"}
+	rows := [][]string{}
+
+	managed := 0
+	for _, r := range s.records {
+		owner := "no"
+		if r.Owned {
+			owner = "yes"
+			managed++
+		}
+		rows = append(rows, []string{
+			r.Hostname,
+			r.IP,
+			fmt.Sprintf("%d", r.TTL),
+			owner,
+		})
+	}
+
+	return tables.TableConfig{
+		Title:   "RFC 2136 ZONE RECORDS (" + strings.TrimSuffix(s.client.Zone, ".") + ")",
+		Headers: headers,
+		Rows:    rows,
+		Summary: fmt.Sprintf("Total: %d A records, %d managed by caddy-dns-sync", len(s.records), managed),
+	}
+}
+
+func (s *RFC2136DataSource) FormatAsJSON() ([]byte, error) {
+	return json.MarshalIndent(s.records, "", "  ")
+}
+
+func (s *RFC2136DataSource) EmptyMessage() string {
+	return "No A records found in zone."
+}
+
 // DHCPDataSource implements ListDataSource for DHCP/DNSMasq
 type DHCPDataSource struct {
 	client *api.DNSMasqClient
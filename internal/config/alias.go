@@ -0,0 +1,81 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// EnvAliasCanonical enables alias mode with the given canonical hostname.
+const EnvAliasCanonical = "CADDY_DNS_SYNC_ALIAS_CANONICAL"
+
+// AliasModeConfig switches DNS overrides to alias mode. Canonical is the one
+// hostname (e.g. "caddy.lan") that gets an A record for the Caddy server;
+// every other hostname Caddy serves becomes an Unbound host alias or an
+// AdGuard CNAME-style rewrite pointing at it, so moving Caddy only rewrites
+// one record. Empty disables alias mode.
+type AliasModeConfig struct {
+	Canonical string `json:"canonical,omitempty" mapstructure:"canonical"`
+}
+
+// Enabled reports whether alias mode is on.
+func (c AliasModeConfig) Enabled() bool {
+	return c.Canonical != ""
+}
+
+// LoadAliasModeConfig loads the "alias_mode" section from viper or the config
+// file, then applies the environment override.
+func LoadAliasModeConfig() (AliasModeConfig, error) {
+	var cfg AliasModeConfig
+
+	if viper.IsSet("alias_mode") {
+		if err := viper.UnmarshalKey("alias_mode", &cfg); err != nil {
+			return cfg, fmt.Errorf("error parsing alias mode from viper: %w", err)
+		}
+	} else {
+		configPath, err := GetDefaultConfigPath()
+		if err != nil {
+			return cfg, err
+		}
+		data, err := os.ReadFile(configPath)
+		if err != nil && !os.IsNotExist(err) {
+			return cfg, fmt.Errorf("error reading config file: %w", err)
+		}
+		if err == nil {
+			var extendedConfig ExtendedConfig
+			if err := json.Unmarshal(data, &extendedConfig); err != nil {
+				return cfg, fmt.Errorf("error parsing extended config file: %w", err)
+			}
+			cfg = extendedConfig.AliasMode
+		}
+	}
+
+	if canonical := os.Getenv(EnvAliasCanonical); canonical != "" {
+		cfg.Canonical = canonical
+	}
+	cfg.Canonical = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(cfg.Canonical), "."))
+
+	if err := ValidateAliasMode(cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// ValidateAliasMode checks that the canonical name is a fully qualified
+// hostname rather than an address.
+func ValidateAliasMode(cfg AliasModeConfig) error {
+	if !cfg.Enabled() {
+		return nil
+	}
+	if net.ParseIP(cfg.Canonical) != nil {
+		return fmt.Errorf("alias_mode.canonical must be a hostname, got IP address %q", cfg.Canonical)
+	}
+	if !strings.Contains(cfg.Canonical, ".") || strings.ContainsAny(cfg.Canonical, " */") {
+		return fmt.Errorf("alias_mode.canonical must be a fully qualified hostname such as caddy.lan, got %q", cfg.Canonical)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestLoadAliasModeConfig_FromConfigFileWithEnvOverride(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Cleanup(viper.Reset)
+
+	data := `{"alias_mode": {"canonical": "Caddy.LAN."}}`
+	if err := os.WriteFile(filepath.Join(home, DefaultConfigFileName), []byte(data), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := LoadAliasModeConfig()
+	if err != nil {
+		t.Fatalf("LoadAliasModeConfig failed: %v", err)
+	}
+	if cfg.Canonical != "caddy.lan" {
+		t.Errorf("Expected the canonical name to be normalized, got %q", cfg.Canonical)
+	}
+
+	t.Setenv(EnvAliasCanonical, "proxy.home.example.com")
+	cfg, err = LoadAliasModeConfig()
+	if err != nil {
+		t.Fatalf("LoadAliasModeConfig failed: %v", err)
+	}
+	if cfg.Canonical != "proxy.home.example.com" {
+		t.Errorf("Expected the canonical name from %s, got %q", EnvAliasCanonical, cfg.Canonical)
+	}
+}
+
+func TestValidateAliasMode(t *testing.T) {
+	for _, canonical := range []string{"10.0.0.15", "caddy", "*.lan"} {
+		if err := ValidateAliasMode(AliasModeConfig{Canonical: canonical}); err == nil {
+			t.Errorf("Expected canonical %q to be rejected", canonical)
+		}
+	}
+	if err := ValidateAliasMode(AliasModeConfig{}); err != nil {
+		t.Errorf("Expected alias mode to be optional, got %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// AnswerCaddy answers with the Caddy server IP (the default).
+	AnswerCaddy = "caddy"
+	// AnswerUpstream answers with the reverse_proxy upstream IP, bypassing
+	// Caddy (game servers, MQTT brokers, SMB shares).
+	AnswerUpstream = "upstream"
+)
+
+// AnswerRule picks the DNS answer for matching hostnames. Hostname is a glob
+// such as "*.games.example.com" and Handler a Caddy handler type in the
+// route's chain such as "layer4"; a rule needs at least one of them and
+// matches only when all given ones do. Answer is AnswerCaddy, AnswerUpstream
+// or a literal IP. Targets limits the rule to the named sync targets (e.g.
+// "unbound:iot" for the resolver serving one VLAN); empty means every target.
+type AnswerRule struct {
+	Hostname string   `json:"hostname,omitempty" mapstructure:"hostname"`
+	Handler  string   `json:"handler,omitempty" mapstructure:"handler"`
+	Answer   string   `json:"answer" mapstructure:"answer"`
+	Targets  []string `json:"targets,omitempty" mapstructure:"targets"`
+}
+
+// LoadAnswerRules loads the "answer_rules" list from viper or the config
+// file. Rules are configured only in the config file.
+func LoadAnswerRules() ([]AnswerRule, error) {
+	var rules []AnswerRule
+
+	if viper.IsSet("answer_rules") {
+		if err := viper.UnmarshalKey("answer_rules", &rules); err != nil {
+			return nil, fmt.Errorf("error parsing answer rules from viper: %w", err)
+		}
+		return rules, ValidateAnswerRules(rules)
+	}
+
+	configPath, err := GetDefaultConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file: %w", err)
+	}
+
+	var extendedConfig ExtendedConfig
+	if err := json.Unmarshal(data, &extendedConfig); err != nil {
+		return nil, fmt.Errorf("error parsing extended config file: %w", err)
+	}
+	rules = extendedConfig.AnswerRules
+	if err := ValidateAnswerRules(rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// ValidateAnswerRules checks that every rule matches on something and
+// answers with a known keyword or an IP address.
+func ValidateAnswerRules(rules []AnswerRule) error {
+	for i, rule := range rules {
+		if rule.Hostname == "" && rule.Handler == "" {
+			return fmt.Errorf("answer_rules[%d]: hostname or handler is required", i)
+		}
+		if rule.Hostname != "" {
+			if _, err := path.Match(rule.Hostname, ""); err != nil {
+				return fmt.Errorf("answer_rules[%d]: invalid hostname pattern %q: %w", i, rule.Hostname, err)
+			}
+		}
+		switch rule.Answer {
+		case AnswerCaddy, AnswerUpstream:
+		case "":
+			return fmt.Errorf("answer_rules[%d]: answer is required", i)
+		default:
+			if net.ParseIP(rule.Answer) == nil {
+				return fmt.Errorf("answer_rules[%d]: answer must be %q, %q or an IP address, got %q", i, AnswerCaddy, AnswerUpstream, rule.Answer)
+			}
+		}
+	}
+	return nil
+}
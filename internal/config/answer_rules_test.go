@@ -0,0 +1,54 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestLoadAnswerRules_FromConfigFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Cleanup(viper.Reset)
+
+	data := `{"answer_rules": [
+		{"hostname": "*.games.example.com", "answer": "upstream"},
+		{"hostname": "nas.example.com", "answer": "192.168.20.5", "targets": ["unbound:iot"]}
+	]}`
+	if err := os.WriteFile(filepath.Join(home, DefaultConfigFileName), []byte(data), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	rules, err := LoadAnswerRules()
+	if err != nil {
+		t.Fatalf("LoadAnswerRules failed: %v", err)
+	}
+	if len(rules) != 2 || rules[0].Answer != AnswerUpstream || rules[1].Targets[0] != "unbound:iot" {
+		t.Fatalf("Unexpected rules: %#v", rules)
+	}
+}
+
+func TestValidateAnswerRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    AnswerRule
+		wantErr bool
+	}{
+		{"handler rule", AnswerRule{Handler: "layer4", Answer: AnswerUpstream}, false},
+		{"literal IPv6", AnswerRule{Hostname: "*.example.com", Answer: "fd00::5"}, false},
+		{"no match", AnswerRule{Answer: AnswerCaddy}, true},
+		{"no answer", AnswerRule{Hostname: "*.example.com"}, true},
+		{"bad answer", AnswerRule{Hostname: "*.example.com", Answer: "elsewhere"}, true},
+		{"bad glob", AnswerRule{Hostname: "[a-", Answer: AnswerCaddy}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAnswerRules([]AnswerRule{tt.rule})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateAnswerRules() error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
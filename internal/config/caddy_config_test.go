@@ -0,0 +1,123 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/api"
+	"github.com/spf13/viper"
+)
+
+func TestLoadCaddyConfig_CaddyfileSourceDefaultsToEditorCaddyfile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Cleanup(viper.Reset)
+
+	data := `{
+		"caddy": {"source": "caddyfile"},
+		"caddy_editor": {"repo_path": "/srv/infra", "caddyfile": "caddy/Caddyfile"}
+	}`
+	if err := os.WriteFile(filepath.Join(home, DefaultConfigFileName), []byte(data), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := LoadCaddyConfig()
+	if err != nil {
+		t.Fatalf("LoadCaddyConfig failed: %v", err)
+	}
+	if cfg.Source != CaddySourceCaddyfile || cfg.Caddyfile != "/srv/infra/caddy/Caddyfile" {
+		t.Errorf("Unexpected Caddy config: %#v", cfg)
+	}
+
+	t.Setenv(EnvCaddyCaddyfile, "/tmp/checkout/Caddyfile")
+	cfg, err = LoadCaddyConfig()
+	if err != nil {
+		t.Fatalf("LoadCaddyConfig failed: %v", err)
+	}
+	if cfg.Caddyfile != "/tmp/checkout/Caddyfile" {
+		t.Errorf("Expected %s to override the Caddyfile, got %q", EnvCaddyCaddyfile, cfg.Caddyfile)
+	}
+}
+
+func TestLoadCaddyConfig_RejectsUnusableSource(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Cleanup(viper.Reset)
+
+	t.Setenv(EnvCaddySource, "caddyfile")
+	if _, err := LoadCaddyConfig(); err == nil {
+		t.Error("Expected error for caddyfile source without a Caddyfile")
+	}
+
+	t.Setenv(EnvCaddySource, "traefik")
+	if _, err := LoadCaddyConfig(); err == nil {
+		t.Error("Expected error for traefik source without an API URL")
+	}
+	t.Setenv(EnvCaddyTraefikURL, "http://traefik.lan:8080")
+	if cfg, err := LoadCaddyConfig(); err != nil || cfg.Traefik.BaseURL != "http://traefik.lan:8080" {
+		t.Errorf("Expected %s to configure the traefik source, got %#v, %v", EnvCaddyTraefikURL, cfg, err)
+	}
+
+	t.Setenv(EnvCaddySource, "etcd")
+	if _, err := LoadCaddyConfig(); err == nil {
+		t.Error("Expected error for unknown source")
+	}
+
+	t.Setenv(EnvCaddySource, "")
+	cfg, err := LoadCaddyConfig()
+	if err != nil || cfg.Source != "" {
+		t.Errorf("Expected the admin API default without config, got %#v, %v", cfg, err)
+	}
+}
+
+func TestLoadCaddyConfig_AdminTransport(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Cleanup(viper.Reset)
+
+	data := `{"caddy": {"server_ip": "10.0.0.15", "admin": {"client_cert": "/etc/caddy-dns-sync/admin.crt", "client_key": "/etc/caddy-dns-sync/admin.key"}}}`
+	if err := os.WriteFile(filepath.Join(home, DefaultConfigFileName), []byte(data), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	cfg, err := LoadCaddyConfig()
+	if err != nil || cfg.Admin.ClientKey != "/etc/caddy-dns-sync/admin.key" {
+		t.Fatalf("Expected the admin client certificate from the config file, got %#v, %v", cfg.Admin, err)
+	}
+
+	t.Setenv(EnvCaddyAdminSocket, "unix:///run/caddy/admin.sock")
+	if _, err := LoadCaddyConfig(); err == nil {
+		t.Error("Expected error for a unix socket combined with a client certificate")
+	}
+
+	if err := os.WriteFile(filepath.Join(home, DefaultConfigFileName), []byte(`{"caddy": {}}`), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	cfg, err = LoadCaddyConfig()
+	if err != nil || cfg.Admin.SocketPath() != "/run/caddy/admin.sock" {
+		t.Errorf("Expected %s to select the unix socket, got %#v, %v", EnvCaddyAdminSocket, cfg.Admin, err)
+	}
+}
+
+func TestValidateCaddyServers(t *testing.T) {
+	tests := []struct {
+		name    string
+		servers []CaddyServerConfig
+		wantErr bool
+	}{
+		{name: "serve ip defaults to server ip", servers: []CaddyServerConfig{{Name: "dmz", ServerIP: "10.50.0.2"}}},
+		{name: "explicit serve ip", servers: []CaddyServerConfig{{Name: "dmz", ServerIP: "caddy-dmz.lan", ServeIP: "10.50.0.15"}}},
+		{name: "hostname without serve ip", servers: []CaddyServerConfig{{Name: "dmz", ServerIP: "caddy-dmz.lan"}}, wantErr: true},
+		{name: "reserved primary name", servers: []CaddyServerConfig{{Name: PrimaryCaddyServerName, ServerIP: "10.50.0.2"}}, wantErr: true},
+		{name: "missing server ip", servers: []CaddyServerConfig{{Name: "dmz"}}, wantErr: true},
+		{name: "unix socket with serve ip", servers: []CaddyServerConfig{{Name: "local", ServeIP: "10.0.0.15", Admin: api.CaddyAdminConfig{Socket: "/run/caddy/admin.sock"}}}},
+		{name: "client cert without key", servers: []CaddyServerConfig{{Name: "dmz", ServerIP: "10.50.0.2", Admin: api.CaddyAdminConfig{ClientCert: "/etc/ssl/admin.crt"}}}, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateCaddyServers(tc.servers)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("ValidateCaddyServers() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 
@@ -22,6 +23,10 @@ const (
 	EnvAPISecret = "CADDY_DNS_SYNC_API_SECRET"
 	EnvBaseURL   = "CADDY_DNS_SYNC_BASE_URL"
 	EnvInsecure  = "CADDY_DNS_SYNC_INSECURE"
+	// EnvDHCPBackend selects the OPNsense DHCP server: "dnsmasq" (default) or "kea".
+	EnvDHCPBackend = "CADDY_DNS_SYNC_DHCP_BACKEND"
+	// EnvDNSMasqHosts enables syncing OPNsense Dnsmasq host overrides.
+	EnvDNSMasqHosts = "CADDY_DNS_SYNC_DNSMASQ_HOSTS"
 
 	// Deprecated: kept as fallback aliases for backwards compatibility.
 	// These will be removed in a future release.
@@ -30,6 +35,18 @@ const (
 	EnvBaseURLDeprecated   = "UNBOUND_CLI_BASE_URL"
 	EnvInsecureDeprecated  = "UNBOUND_CLI_INSECURE"
 
+	// Caddy route source environment variables
+	EnvCaddySource          = "CADDY_SOURCE"
+	EnvCaddyCaddyfile       = "CADDY_CADDYFILE"
+	EnvCaddyTraefikURL      = "CADDY_TRAEFIK_URL"
+	EnvCaddyTraefikUsername = "CADDY_TRAEFIK_USERNAME"
+	EnvCaddyTraefikPassword = "CADDY_TRAEFIK_PASSWORD"
+	EnvCaddyAdminSocket     = "CADDY_ADMIN_SOCKET"
+	EnvCaddyAdminClientCert = "CADDY_ADMIN_CLIENT_CERT"
+	EnvCaddyAdminClientKey  = "CADDY_ADMIN_CLIENT_KEY"
+	EnvCaddyAdminCACert     = "CADDY_ADMIN_CA_CERT"
+	EnvCaddyServerIPv6      = "CADDY_SERVER_IPV6"
+
 	// AdguardHome specific environment variables
 	EnvAdguardEnabled  = "ADGUARD_ENABLED"
 	EnvAdguardUsername = "ADGUARD_USERNAME"
@@ -37,6 +54,31 @@ const (
 	EnvAdguardBaseURL  = "ADGUARD_BASE_URL"
 	EnvAdguardInsecure = "ADGUARD_INSECURE"
 
+	// Pi-hole specific environment variables
+	EnvPiholeEnabled  = "PIHOLE_ENABLED"
+	EnvPiholePassword = "PIHOLE_PASSWORD"
+	EnvPiholeBaseURL  = "PIHOLE_BASE_URL"
+	EnvPiholeInsecure = "PIHOLE_INSECURE"
+
+	// RFC 2136 dynamic update specific environment variables
+	EnvRFC2136Enabled       = "RFC2136_ENABLED"
+	EnvRFC2136Server        = "RFC2136_SERVER"
+	EnvRFC2136Zone          = "RFC2136_ZONE"
+	EnvRFC2136TSIGKey       = "RFC2136_TSIG_KEY"
+	EnvRFC2136TSIGSecret    = "RFC2136_TSIG_SECRET"
+	EnvRFC2136TSIGAlgorithm = "RFC2136_TSIG_ALGORITHM"
+
+	// Docker label discovery specific environment variables
+	EnvDockerEnabled = "DOCKER_DISCOVERY_ENABLED"
+	EnvDockerHost    = "DOCKER_HOST"
+	EnvDockerNetwork = "DOCKER_DISCOVERY_NETWORK"
+
+	// Kubernetes Ingress/HTTPRoute discovery specific environment variables
+	EnvKubernetesEnabled   = "KUBERNETES_DISCOVERY_ENABLED"
+	EnvKubeconfig          = "KUBECONFIG"
+	EnvKubernetesContext   = "KUBERNETES_CONTEXT"
+	EnvKubernetesNamespace = "KUBERNETES_NAMESPACE"
+
 	// Cloudflare specific environment variables
 	EnvCFEnabled         = "CF_ENABLED"
 	EnvCFAPIToken        = "CF_API_TOKEN"
@@ -70,10 +112,64 @@ func envBoolOr(primary, fallback string) bool {
 	return v == "true" || v == "1"
 }
 
+func envBool(name string) bool {
+	v := os.Getenv(name)
+	return v == "true" || v == "1"
+}
+
+// Caddy route sources selectable with CaddyConfig.Source.
+const (
+	CaddySourceAdminAPI  = "admin_api"
+	CaddySourceCaddyfile = "caddyfile"
+	CaddySourceTraefik   = "traefik"
+)
+
 // CaddyConfig represents configuration specific to Caddy server integration
 type CaddyConfig struct {
 	ServerIP   string `json:"server_ip,omitempty" mapstructure:"server_ip"`
 	ServerPort int    `json:"server_port,omitempty" mapstructure:"server_port"`
+	// ServerIPv6 is the Caddy server's IPv6 address. When set, hostnames it
+	// serves also get AAAA records pointing at it.
+	ServerIPv6 string `json:"server_ipv6,omitempty" mapstructure:"server_ipv6"`
+	// Admin reaches the admin API over a unix socket or with a client
+	// certificate (admin.remote) instead of plain HTTP.
+	Admin api.CaddyAdminConfig `json:"admin,omitempty" mapstructure:"admin"`
+	// Source selects where route data is read from: the admin API (default),
+	// a Caddyfile on disk, or a Traefik API.
+	Source string `json:"source,omitempty" mapstructure:"source"`
+	// Caddyfile is the file read when Source is "caddyfile". It defaults to
+	// the caddy_editor Caddyfile.
+	Caddyfile string `json:"caddyfile,omitempty" mapstructure:"caddyfile"`
+	// Traefik is the API read when Source is "traefik".
+	Traefik api.TraefikConfig `json:"traefik,omitempty" mapstructure:"traefik"`
+	// Servers are Caddy instances whose hostnames are merged with the main
+	// server's, e.g. a DMZ Caddy next to the internal one.
+	Servers []CaddyServerConfig `json:"servers,omitempty" mapstructure:"servers"`
+}
+
+// PrimaryCaddyServerName names the main Caddy server (server_ip/server_port)
+// when hostnames from several servers are merged.
+const PrimaryCaddyServerName = "default"
+
+// CaddyServerConfig is an additional named Caddy server. ServeIP is the
+// address clients reach it on, and the answer its hostnames' DNS records
+// carry; it defaults to ServerIP.
+type CaddyServerConfig struct {
+	Name       string `json:"name" mapstructure:"name"`
+	ServerIP   string `json:"server_ip" mapstructure:"server_ip"`
+	ServerPort int    `json:"server_port,omitempty" mapstructure:"server_port"`
+	ServeIP    string `json:"serve_ip,omitempty" mapstructure:"serve_ip"`
+	// Admin reaches this server's admin API over a unix socket or with a
+	// client certificate.
+	Admin api.CaddyAdminConfig `json:"admin,omitempty" mapstructure:"admin"`
+}
+
+// GetServeIP returns the DNS answer for hostnames served by this Caddy.
+func (c CaddyServerConfig) GetServeIP() string {
+	if c.ServeIP != "" {
+		return c.ServeIP
+	}
+	return c.ServerIP
 }
 
 // AdguardConfig represents configuration specific to AdguardHome integration
@@ -84,6 +180,135 @@ type AdguardConfig struct {
 	BaseURL     string `json:"base_url,omitempty" mapstructure:"base_url"`
 	Insecure    bool   `json:"insecure" mapstructure:"insecure"`
 	Description string `json:"description" mapstructure:"description"`
+	// AnswerOverride, when set, is written to this instance's rewrites in
+	// place of the Caddy server IP.
+	AnswerOverride string `json:"answer_override,omitempty" mapstructure:"answer_override"`
+	// Instances are additional AdGuard Home replicas (e.g. a secondary on
+	// another VLAN) reconciled independently alongside this one.
+	Instances []AdguardInstanceConfig `json:"instances,omitempty" mapstructure:"instances"`
+}
+
+// AdguardInstanceConfig is one additional named AdGuard Home replica.
+type AdguardInstanceConfig struct {
+	Name           string `json:"name" mapstructure:"name"`
+	Username       string `json:"username,omitempty" mapstructure:"username"`
+	Password       string `json:"password,omitempty" mapstructure:"password"`
+	BaseURL        string `json:"base_url" mapstructure:"base_url"`
+	Insecure       bool   `json:"insecure" mapstructure:"insecure"`
+	AnswerOverride string `json:"answer_override,omitempty" mapstructure:"answer_override"`
+}
+
+// GetAdguardAPIConfig creates an AdguardConfig suitable for API client use
+func (a AdguardInstanceConfig) GetAdguardAPIConfig() api.AdguardConfig {
+	return api.AdguardConfig{
+		BaseURL:  a.BaseURL,
+		Username: a.Username,
+		Password: a.Password,
+		Insecure: a.Insecure,
+		Enabled:  true,
+	}
+}
+
+// PiholeConfig represents configuration specific to Pi-hole v6 integration.
+// Password may be the web interface password or an app password.
+type PiholeConfig struct {
+	Enabled  bool   `json:"enabled" mapstructure:"enabled"`
+	Password string `json:"password,omitempty" mapstructure:"password"`
+	BaseURL  string `json:"base_url,omitempty" mapstructure:"base_url"`
+	Insecure bool   `json:"insecure" mapstructure:"insecure"`
+}
+
+// GetPiholeAPIConfig creates a PiholeConfig suitable for API client use
+func (p PiholeConfig) GetPiholeAPIConfig() api.PiholeConfig {
+	return api.PiholeConfig{
+		BaseURL:  p.BaseURL,
+		Password: p.Password,
+		Insecure: p.Insecure,
+		Enabled:  p.Enabled,
+	}
+}
+
+// RFC2136Config represents configuration for an RFC 2136 dynamic update
+// primary. TSIGSecret is the base64 secret from the server's key definition.
+type RFC2136Config struct {
+	Enabled       bool   `json:"enabled" mapstructure:"enabled"`
+	Server        string `json:"server,omitempty" mapstructure:"server"`
+	Zone          string `json:"zone,omitempty" mapstructure:"zone"`
+	TSIGKeyName   string `json:"tsig_key,omitempty" mapstructure:"tsig_key"`
+	TSIGSecret    string `json:"tsig_secret,omitempty" mapstructure:"tsig_secret"`
+	TSIGAlgorithm string `json:"tsig_algorithm,omitempty" mapstructure:"tsig_algorithm"`
+	TTL           uint32 `json:"ttl,omitempty" mapstructure:"ttl"`
+	OwnerMarker   string `json:"owner_marker,omitempty" mapstructure:"owner_marker"`
+}
+
+// GetRFC2136APIConfig creates an RFC2136Config suitable for API client use
+func (r RFC2136Config) GetRFC2136APIConfig() api.RFC2136Config {
+	return api.RFC2136Config{
+		Server:        r.Server,
+		Zone:          r.Zone,
+		TSIGKeyName:   r.TSIGKeyName,
+		TSIGSecret:    r.TSIGSecret,
+		TSIGAlgorithm: r.TSIGAlgorithm,
+		TTL:           r.TTL,
+		OwnerMarker:   r.OwnerMarker,
+		Enabled:       r.Enabled,
+	}
+}
+
+// DockerConfig represents configuration for discovering hostnames from the
+// labels of running Docker containers.
+type DockerConfig struct {
+	Enabled bool   `json:"enabled" mapstructure:"enabled"`
+	Host    string `json:"host,omitempty" mapstructure:"host"`
+	Network string `json:"network,omitempty" mapstructure:"network"`
+}
+
+// GetDockerAPIConfig creates a DockerConfig suitable for API client use
+func (d DockerConfig) GetDockerAPIConfig() api.DockerConfig {
+	return api.DockerConfig{
+		Host:    d.Host,
+		Network: d.Network,
+	}
+}
+
+// KubernetesConfig represents configuration for discovering hostnames from
+// Ingress and HTTPRoute objects in a cluster.
+type KubernetesConfig struct {
+	Enabled    bool   `json:"enabled" mapstructure:"enabled"`
+	Kubeconfig string `json:"kubeconfig,omitempty" mapstructure:"kubeconfig"`
+	Context    string `json:"context,omitempty" mapstructure:"context"`
+	Namespace  string `json:"namespace,omitempty" mapstructure:"namespace"`
+}
+
+// GetKubernetesAPIConfig creates a KubernetesConfig suitable for API client use
+func (k KubernetesConfig) GetKubernetesAPIConfig() api.KubernetesConfig {
+	return api.KubernetesConfig{
+		Kubeconfig: k.Kubeconfig,
+		Context:    k.Context,
+		Namespace:  k.Namespace,
+	}
+}
+
+// UnboundInstanceConfig is one additional named OPNsense Unbound endpoint,
+// such as the second firewall of an HA pair or a per-site firewall. TargetIP,
+// when set, replaces the Caddy server IP in that instance's host overrides.
+type UnboundInstanceConfig struct {
+	Name      string `json:"name" mapstructure:"name"`
+	APIKey    string `json:"api_key" mapstructure:"api_key"`
+	APISecret string `json:"api_secret" mapstructure:"api_secret"`
+	BaseURL   string `json:"base_url" mapstructure:"base_url"`
+	Insecure  bool   `json:"insecure" mapstructure:"insecure"`
+	TargetIP  string `json:"target_ip,omitempty" mapstructure:"target_ip"`
+}
+
+// GetUnboundAPIConfig creates an api.Config suitable for API client use
+func (u UnboundInstanceConfig) GetUnboundAPIConfig() api.Config {
+	return api.Config{
+		APIKey:    u.APIKey,
+		APISecret: u.APISecret,
+		BaseURL:   u.BaseURL,
+		Insecure:  u.Insecure,
+	}
 }
 
 // CloudflareConfig represents configuration specific to Cloudflare integration
@@ -130,12 +355,33 @@ func (c AuthentikConfig) GetAuthentikAPIConfig() api.AuthentikConfig {
 
 // ExtendedConfig represents the full application configuration including AdguardHome and Caddy
 type ExtendedConfig struct {
-	api.Config  `json:",inline" mapstructure:",squash"`
-	Caddy       CaddyConfig              `json:"caddy" mapstructure:"caddy"`
-	Adguard     AdguardConfig            `json:"adguard" mapstructure:"adguard"`
-	Cloudflare  CloudflareConfig         `json:"cloudflare" mapstructure:"cloudflare"`
-	Authentik   AuthentikConfig          `json:"authentik" mapstructure:"authentik"`
-	CaddyEditor caddyeditor.EditorConfig `json:"caddy_editor" mapstructure:"caddy_editor"`
+	api.Config `json:",inline" mapstructure:",squash"`
+	Caddy      CaddyConfig   `json:"caddy" mapstructure:"caddy"`
+	Adguard    AdguardConfig `json:"adguard" mapstructure:"adguard"`
+	Pihole     PiholeConfig  `json:"pihole" mapstructure:"pihole"`
+	RFC2136    RFC2136Config `json:"rfc2136" mapstructure:"rfc2136"`
+	Docker     DockerConfig  `json:"docker" mapstructure:"docker"`
+	// UnboundInstances are Unbound endpoints synced in addition to the main
+	// OPNsense configuration above.
+	UnboundInstances []UnboundInstanceConfig  `json:"unbound_instances,omitempty" mapstructure:"unbound_instances"`
+	Cloudflare       CloudflareConfig         `json:"cloudflare" mapstructure:"cloudflare"`
+	Authentik        AuthentikConfig          `json:"authentik" mapstructure:"authentik"`
+	CaddyEditor      caddyeditor.EditorConfig `json:"caddy_editor" mapstructure:"caddy_editor"`
+	Kubernetes       KubernetesConfig         `json:"kubernetes" mapstructure:"kubernetes"`
+	// Manifest is a YAML or JSON file of static hostnames (see HostManifest).
+	Manifest string `json:"manifest,omitempty" mapstructure:"manifest"`
+	// SyncPolicy guards sync runs against mass deletes and protects hostnames.
+	SyncPolicy SyncPolicyConfig `json:"sync_policy,omitempty" mapstructure:"sync_policy"`
+	// AnswerRules pick a DNS answer other than the Caddy server IP for
+	// matching hostnames; the first matching rule wins.
+	AnswerRules []AnswerRule `json:"answer_rules,omitempty" mapstructure:"answer_rules"`
+	// AliasMode points hostnames at one canonical record instead of giving
+	// each its own A record.
+	AliasMode AliasModeConfig `json:"alias_mode,omitempty" mapstructure:"alias_mode"`
+	// DomainOverrides are the Unbound query forwarders sync keeps in place.
+	DomainOverrides []DomainOverride `json:"domain_overrides,omitempty" mapstructure:"domain_overrides"`
+	// Webhooks are the inbound hooks the web UI serves under /api/hooks/.
+	Webhooks []Webhook `json:"webhooks,omitempty" mapstructure:"webhooks"`
 }
 
 // GetDefaultConfigPath returns the default path for the config file
@@ -189,6 +435,8 @@ func LoadConfig() (api.Config, error) {
 		config.APISecret = envOr(EnvAPISecret, EnvAPISecretDeprecated)
 		config.BaseURL = envOr(EnvBaseURL, EnvBaseURLDeprecated)
 		config.Insecure = envBoolOr(EnvInsecure, EnvInsecureDeprecated)
+		config.DHCPBackend = os.Getenv(EnvDHCPBackend)
+		config.DNSMasqHosts = envBool(EnvDNSMasqHosts)
 
 		// Validate required fields
 		if config.APISecret != "" && config.BaseURL != "" {
@@ -202,6 +450,8 @@ func LoadConfig() (api.Config, error) {
 		config.APISecret = viper.GetString("api_secret")
 		config.BaseURL = viper.GetString("base_url")
 		config.Insecure = viper.GetBool("insecure")
+		config.DHCPBackend = viper.GetString("dhcp_backend")
+		config.DNSMasqHosts = viper.GetBool("dnsmasq_hosts")
 		return config, nil
 	}
 
@@ -232,6 +482,8 @@ func LoadConfig() (api.Config, error) {
 	viper.Set("api_secret", config.APISecret)
 	viper.Set("base_url", config.BaseURL)
 	viper.Set("insecure", config.Insecure)
+	viper.Set("dhcp_backend", config.DHCPBackend)
+	viper.Set("dnsmasq_hosts", config.DNSMasqHosts)
 
 	return config, nil
 }
@@ -309,6 +561,448 @@ func LoadAdguardConfig() (AdguardConfig, error) {
 	return config, nil
 }
 
+// LoadPiholeConfig loads Pi-hole-specific configuration from environment
+// variables, viper, or config file. Pi-hole is optional — if not configured,
+// the returned config will have Enabled=false.
+func LoadPiholeConfig() (PiholeConfig, error) {
+	var cfg PiholeConfig
+
+	// Check environment variables first
+	if enabledEnv := os.Getenv(EnvPiholeEnabled); enabledEnv != "" {
+		cfg.Enabled = enabledEnv == "true" || enabledEnv == "1"
+		cfg.Password = os.Getenv(EnvPiholePassword)
+		cfg.BaseURL = os.Getenv(EnvPiholeBaseURL)
+		insecureEnv := os.Getenv(EnvPiholeInsecure)
+		cfg.Insecure = insecureEnv == "true" || insecureEnv == "1"
+		return cfg, nil
+	}
+
+	// Try to load from viper
+	if viper.IsSet("pihole") {
+		if err := viper.UnmarshalKey("pihole", &cfg); err != nil {
+			return cfg, fmt.Errorf("error parsing Pi-hole config from viper: %w", err)
+		}
+		return cfg, nil
+	}
+
+	// Try to load from config file
+	configPath, err := GetDefaultConfigPath()
+	if err != nil {
+		return cfg, err
+	}
+
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return cfg, fmt.Errorf("error reading config file: %w", err)
+	}
+
+	var extendedConfig ExtendedConfig
+	if err := json.Unmarshal(data, &extendedConfig); err != nil {
+		return cfg, fmt.Errorf("error parsing extended config file: %w", err)
+	}
+
+	cfg = extendedConfig.Pihole
+
+	viper.Set("pihole", cfg)
+
+	return cfg, nil
+}
+
+// LoadRFC2136Config loads RFC 2136 configuration from environment variables,
+// viper, or config file. The target is optional — if not configured, the
+// returned config will have Enabled=false.
+func LoadRFC2136Config() (RFC2136Config, error) {
+	var cfg RFC2136Config
+
+	// Check environment variables first
+	if enabledEnv := os.Getenv(EnvRFC2136Enabled); enabledEnv != "" {
+		cfg.Enabled = enabledEnv == "true" || enabledEnv == "1"
+		cfg.Server = os.Getenv(EnvRFC2136Server)
+		cfg.Zone = os.Getenv(EnvRFC2136Zone)
+		cfg.TSIGKeyName = os.Getenv(EnvRFC2136TSIGKey)
+		cfg.TSIGSecret = os.Getenv(EnvRFC2136TSIGSecret)
+		cfg.TSIGAlgorithm = os.Getenv(EnvRFC2136TSIGAlgorithm)
+		return cfg, nil
+	}
+
+	// Try to load from viper
+	if viper.IsSet("rfc2136") {
+		if err := viper.UnmarshalKey("rfc2136", &cfg); err != nil {
+			return cfg, fmt.Errorf("error parsing RFC 2136 config from viper: %w", err)
+		}
+		return cfg, nil
+	}
+
+	// Try to load from config file
+	configPath, err := GetDefaultConfigPath()
+	if err != nil {
+		return cfg, err
+	}
+
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return cfg, fmt.Errorf("error reading config file: %w", err)
+	}
+
+	var extendedConfig ExtendedConfig
+	if err := json.Unmarshal(data, &extendedConfig); err != nil {
+		return cfg, fmt.Errorf("error parsing extended config file: %w", err)
+	}
+
+	cfg = extendedConfig.RFC2136
+
+	viper.Set("rfc2136", cfg)
+
+	return cfg, nil
+}
+
+// LoadDockerConfig loads Docker label discovery configuration from environment
+// variables, viper, or config file. Discovery is optional — if not
+// configured, the returned config will have Enabled=false.
+func LoadDockerConfig() (DockerConfig, error) {
+	var cfg DockerConfig
+
+	// Check environment variables first
+	if enabledEnv := os.Getenv(EnvDockerEnabled); enabledEnv != "" {
+		cfg.Enabled = enabledEnv == "true" || enabledEnv == "1"
+		cfg.Host = os.Getenv(EnvDockerHost)
+		cfg.Network = os.Getenv(EnvDockerNetwork)
+		return cfg, nil
+	}
+
+	// Try to load from viper
+	if viper.IsSet("docker") {
+		if err := viper.UnmarshalKey("docker", &cfg); err != nil {
+			return cfg, fmt.Errorf("error parsing Docker config from viper: %w", err)
+		}
+		return cfg, nil
+	}
+
+	// Try to load from config file
+	configPath, err := GetDefaultConfigPath()
+	if err != nil {
+		return cfg, err
+	}
+
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return cfg, fmt.Errorf("error reading config file: %w", err)
+	}
+
+	var extendedConfig ExtendedConfig
+	if err := json.Unmarshal(data, &extendedConfig); err != nil {
+		return cfg, fmt.Errorf("error parsing extended config file: %w", err)
+	}
+
+	cfg = extendedConfig.Docker
+
+	viper.Set("docker", cfg)
+
+	return cfg, nil
+}
+
+// LoadKubernetesConfig loads Kubernetes discovery configuration from
+// environment variables, viper, or config file. Discovery is optional — if
+// not configured, the returned config will have Enabled=false. KUBECONFIG may
+// list several files; the first one is used.
+func LoadKubernetesConfig() (KubernetesConfig, error) {
+	var cfg KubernetesConfig
+
+	// Check environment variables first
+	if enabledEnv := os.Getenv(EnvKubernetesEnabled); enabledEnv != "" {
+		cfg.Enabled = enabledEnv == "true" || enabledEnv == "1"
+		if paths := filepath.SplitList(os.Getenv(EnvKubeconfig)); len(paths) > 0 {
+			cfg.Kubeconfig = paths[0]
+		}
+		cfg.Context = os.Getenv(EnvKubernetesContext)
+		cfg.Namespace = os.Getenv(EnvKubernetesNamespace)
+		return cfg, nil
+	}
+
+	// Try to load from viper
+	if viper.IsSet("kubernetes") {
+		if err := viper.UnmarshalKey("kubernetes", &cfg); err != nil {
+			return cfg, fmt.Errorf("error parsing Kubernetes config from viper: %w", err)
+		}
+		return cfg, nil
+	}
+
+	// Try to load from config file
+	configPath, err := GetDefaultConfigPath()
+	if err != nil {
+		return cfg, err
+	}
+
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return cfg, fmt.Errorf("error reading config file: %w", err)
+	}
+
+	var extendedConfig ExtendedConfig
+	if err := json.Unmarshal(data, &extendedConfig); err != nil {
+		return cfg, fmt.Errorf("error parsing extended config file: %w", err)
+	}
+
+	cfg = extendedConfig.Kubernetes
+
+	viper.Set("kubernetes", cfg)
+
+	return cfg, nil
+}
+
+// LoadCaddyConfig loads the Caddy section from environment variables, viper,
+// or the config file, resolving the Caddyfile path for the "caddyfile" source
+// and requiring an API URL for the "traefik" source.
+func LoadCaddyConfig() (CaddyConfig, error) {
+	var cfg CaddyConfig
+	var editor caddyeditor.EditorConfig
+
+	if viper.IsSet("caddy") {
+		if err := viper.UnmarshalKey("caddy", &cfg); err != nil {
+			return cfg, fmt.Errorf("error parsing Caddy config from viper: %w", err)
+		}
+		if err := viper.UnmarshalKey("caddy_editor", &editor); err != nil {
+			return cfg, fmt.Errorf("error parsing caddy_editor config from viper: %w", err)
+		}
+	} else {
+		configPath, err := GetDefaultConfigPath()
+		if err != nil {
+			return cfg, err
+		}
+		data, err := os.ReadFile(configPath)
+		if err != nil && !os.IsNotExist(err) {
+			return cfg, fmt.Errorf("error reading config file: %w", err)
+		}
+		if err == nil {
+			var extendedConfig ExtendedConfig
+			if err := json.Unmarshal(data, &extendedConfig); err != nil {
+				return cfg, fmt.Errorf("error parsing extended config file: %w", err)
+			}
+			cfg = extendedConfig.Caddy
+			editor = extendedConfig.CaddyEditor
+		}
+	}
+
+	// Environment variables override the file for CI checkouts.
+	if source := os.Getenv(EnvCaddySource); source != "" {
+		cfg.Source = source
+	}
+	if caddyfile := os.Getenv(EnvCaddyCaddyfile); caddyfile != "" {
+		cfg.Caddyfile = caddyfile
+	}
+	if traefikURL := os.Getenv(EnvCaddyTraefikURL); traefikURL != "" {
+		cfg.Traefik.BaseURL = traefikURL
+	}
+	if username := os.Getenv(EnvCaddyTraefikUsername); username != "" {
+		cfg.Traefik.Username = username
+	}
+	if password := os.Getenv(EnvCaddyTraefikPassword); password != "" {
+		cfg.Traefik.Password = password
+	}
+	if socket := os.Getenv(EnvCaddyAdminSocket); socket != "" {
+		cfg.Admin.Socket = socket
+	}
+	if cert := os.Getenv(EnvCaddyAdminClientCert); cert != "" {
+		cfg.Admin.ClientCert = cert
+	}
+	if key := os.Getenv(EnvCaddyAdminClientKey); key != "" {
+		cfg.Admin.ClientKey = key
+	}
+	if ca := os.Getenv(EnvCaddyAdminCACert); ca != "" {
+		cfg.Admin.CACert = ca
+	}
+	if ipv6 := os.Getenv(EnvCaddyServerIPv6); ipv6 != "" {
+		cfg.ServerIPv6 = ipv6
+	}
+
+	if cfg.ServerIPv6 != "" {
+		if ip := net.ParseIP(cfg.ServerIPv6); ip == nil || ip.To4() != nil {
+			return cfg, fmt.Errorf("caddy.server_ipv6: invalid IPv6 address %q", cfg.ServerIPv6)
+		}
+	}
+
+	if err := ValidateCaddyAdmin(cfg.Admin); err != nil {
+		return cfg, fmt.Errorf("caddy.admin: %w", err)
+	}
+
+	if err := ValidateCaddyServers(cfg.Servers); err != nil {
+		return cfg, err
+	}
+
+	switch cfg.Source {
+	case "", CaddySourceAdminAPI:
+	case CaddySourceCaddyfile:
+		if cfg.Caddyfile == "" && editor.RepoPath != "" {
+			if editor.CaddyfilePath == "" {
+				editor.CaddyfilePath = caddyeditor.DefaultEditorConfig().CaddyfilePath
+			}
+			cfg.Caddyfile = caddyeditor.AbsCaddyfilePath(editor)
+		}
+		if cfg.Caddyfile == "" {
+			return cfg, fmt.Errorf("caddy.source is %q but no caddy.caddyfile or caddy_editor.repo_path is configured", CaddySourceCaddyfile)
+		}
+	case CaddySourceTraefik:
+		if cfg.Traefik.BaseURL == "" {
+			return cfg, fmt.Errorf("caddy.source is %q but no caddy.traefik.base_url is configured", CaddySourceTraefik)
+		}
+	default:
+		return cfg, fmt.Errorf("unknown caddy.source %q (want %q, %q or %q)", cfg.Source, CaddySourceAdminAPI, CaddySourceCaddyfile, CaddySourceTraefik)
+	}
+	return cfg, nil
+}
+
+// LoadUnboundInstances loads the additional named Unbound endpoints from viper
+// or the config file. There is no environment variable form; an empty list
+// means only the main OPNsense endpoint is synced.
+func LoadUnboundInstances() ([]UnboundInstanceConfig, error) {
+	var instances []UnboundInstanceConfig
+
+	// Try to load from viper
+	if viper.IsSet("unbound_instances") {
+		if err := viper.UnmarshalKey("unbound_instances", &instances); err != nil {
+			return nil, fmt.Errorf("error parsing Unbound instances from viper: %w", err)
+		}
+		return instances, ValidateUnboundInstances(instances)
+	}
+
+	// Try to load from config file
+	configPath, err := GetDefaultConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file: %w", err)
+	}
+
+	var extendedConfig ExtendedConfig
+	if err := json.Unmarshal(data, &extendedConfig); err != nil {
+		return nil, fmt.Errorf("error parsing extended config file: %w", err)
+	}
+
+	instances = extendedConfig.UnboundInstances
+	if err := ValidateUnboundInstances(instances); err != nil {
+		return nil, err
+	}
+
+	viper.Set("unbound_instances", instances)
+
+	return instances, nil
+}
+
+// ValidateUnboundInstances checks that every instance has a unique name made
+// of lowercase letters, digits, '-' and '_' (it becomes part of the sync
+// target name, e.g. "unbound:site-b"), a base URL, and a valid target IP.
+func ValidateUnboundInstances(instances []UnboundInstanceConfig) error {
+	seen := make(map[string]bool, len(instances))
+	for i, instance := range instances {
+		if err := validateInstanceName("unbound_instances", i, instance.Name, seen); err != nil {
+			return err
+		}
+		if instance.BaseURL == "" {
+			return fmt.Errorf("unbound instance %q: base_url is required", instance.Name)
+		}
+		if instance.TargetIP != "" && net.ParseIP(instance.TargetIP) == nil {
+			return fmt.Errorf("unbound instance %q: invalid target_ip %q", instance.Name, instance.TargetIP)
+		}
+	}
+	return nil
+}
+
+// ValidateCaddyServers checks the additional Caddy servers' names and
+// addresses. The primary server's name is reserved.
+func ValidateCaddyServers(servers []CaddyServerConfig) error {
+	seen := map[string]bool{PrimaryCaddyServerName: true}
+	for i, server := range servers {
+		if err := validateInstanceName("caddy.servers", i, server.Name, seen); err != nil {
+			return err
+		}
+		if server.ServerIP == "" && server.Admin.Socket == "" {
+			return fmt.Errorf("caddy server %q: server_ip or admin.socket is required", server.Name)
+		}
+		if net.ParseIP(server.GetServeIP()) == nil {
+			return fmt.Errorf("caddy server %q: invalid serve_ip %q", server.Name, server.GetServeIP())
+		}
+		if err := ValidateCaddyAdmin(server.Admin); err != nil {
+			return fmt.Errorf("caddy server %q: %w", server.Name, err)
+		}
+	}
+	return nil
+}
+
+// ValidateCaddyAdmin checks that a client certificate comes with its key and
+// is not combined with a unix socket, which has no TLS.
+func ValidateCaddyAdmin(admin api.CaddyAdminConfig) error {
+	if admin.Socket != "" && (admin.ClientCert != "" || admin.CACert != "") {
+		return fmt.Errorf("socket cannot be combined with client_cert or ca_cert")
+	}
+	if (admin.ClientCert == "") != (admin.ClientKey == "") {
+		return fmt.Errorf("client_cert and client_key must be set together")
+	}
+	if admin.CACert != "" && admin.ClientCert == "" {
+		return fmt.Errorf("ca_cert requires client_cert and client_key")
+	}
+	return nil
+}
+
+// ValidateAdguardInstances applies the same naming rules to AdGuard Home
+// replicas ("adguard:<name>") and checks each answer override.
+func ValidateAdguardInstances(cfg AdguardConfig) error {
+	if cfg.AnswerOverride != "" && net.ParseIP(cfg.AnswerOverride) == nil {
+		return fmt.Errorf("adguard: invalid answer_override %q", cfg.AnswerOverride)
+	}
+	seen := make(map[string]bool, len(cfg.Instances))
+	for i, instance := range cfg.Instances {
+		if err := validateInstanceName("adguard.instances", i, instance.Name, seen); err != nil {
+			return err
+		}
+		if instance.BaseURL == "" {
+			return fmt.Errorf("adguard instance %q: base_url is required", instance.Name)
+		}
+		if instance.AnswerOverride != "" && net.ParseIP(instance.AnswerOverride) == nil {
+			return fmt.Errorf("adguard instance %q: invalid answer_override %q", instance.Name, instance.AnswerOverride)
+		}
+	}
+	return nil
+}
+
+func validateInstanceName(list string, index int, name string, seen map[string]bool) error {
+	if name == "" {
+		return fmt.Errorf("%s[%d]: name is required", list, index)
+	}
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return fmt.Errorf("%s[%d]: invalid name %q (use lowercase letters, digits, '-' or '_')", list, index, name)
+		}
+	}
+	if seen[name] {
+		return fmt.Errorf("%s[%d]: duplicate name %q", list, index, name)
+	}
+	seen[name] = true
+	return nil
+}
+
 // LoadCloudflareConfig loads Cloudflare-specific configuration from environment variables, viper, or config file
 func LoadCloudflareConfig() (CloudflareConfig, error) {
 	var cfg CloudflareConfig
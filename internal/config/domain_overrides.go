@@ -0,0 +1,85 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// DomainOverride forwards queries for Domain and its subdomains to Servers,
+// e.g. "corp.example.com" to the Active Directory DNS servers. Sync keeps
+// one Unbound domain override per server and removes the ones it created
+// that are no longer listed.
+type DomainOverride struct {
+	Domain  string   `json:"domain" mapstructure:"domain"`
+	Servers []string `json:"servers" mapstructure:"servers"`
+}
+
+// LoadDomainOverrides loads the "domain_overrides" list from viper or the
+// config file. Domains are lowercased and lose any trailing dot.
+func LoadDomainOverrides() ([]DomainOverride, error) {
+	var overrides []DomainOverride
+
+	if viper.IsSet("domain_overrides") {
+		if err := viper.UnmarshalKey("domain_overrides", &overrides); err != nil {
+			return nil, fmt.Errorf("error parsing domain overrides from viper: %w", err)
+		}
+	} else {
+		configPath, err := GetDefaultConfigPath()
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(configPath)
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading config file: %w", err)
+		}
+
+		var extendedConfig ExtendedConfig
+		if err := json.Unmarshal(data, &extendedConfig); err != nil {
+			return nil, fmt.Errorf("error parsing extended config file: %w", err)
+		}
+		overrides = extendedConfig.DomainOverrides
+	}
+
+	for i := range overrides {
+		overrides[i].Domain = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(overrides[i].Domain), "."))
+	}
+	if err := ValidateDomainOverrides(overrides); err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
+
+// ValidateDomainOverrides checks that every domain is listed once and
+// forwards to at least one IP address.
+func ValidateDomainOverrides(overrides []DomainOverride) error {
+	seen := make(map[string]bool, len(overrides))
+	for i, override := range overrides {
+		if override.Domain == "" {
+			return fmt.Errorf("domain_overrides[%d]: domain is required", i)
+		}
+		if strings.ContainsAny(override.Domain, " */") {
+			return fmt.Errorf("domain_overrides[%d]: invalid domain %q", i, override.Domain)
+		}
+		if seen[override.Domain] {
+			return fmt.Errorf("domain_overrides[%d]: domain %q is listed more than once", i, override.Domain)
+		}
+		seen[override.Domain] = true
+		if len(override.Servers) == 0 {
+			return fmt.Errorf("domain_overrides[%d]: at least one server is required for %s", i, override.Domain)
+		}
+		for _, server := range override.Servers {
+			if net.ParseIP(server) == nil {
+				return fmt.Errorf("domain_overrides[%d]: server must be an IP address, got %q", i, server)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestLoadDomainOverrides_FromConfigFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Cleanup(viper.Reset)
+
+	data := `{"domain_overrides": [{"domain": "Corp.Example.com.", "servers": ["10.1.0.10", "10.1.0.11"]}]}`
+	if err := os.WriteFile(filepath.Join(home, DefaultConfigFileName), []byte(data), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	overrides, err := LoadDomainOverrides()
+	if err != nil {
+		t.Fatalf("LoadDomainOverrides failed: %v", err)
+	}
+	if len(overrides) != 1 || overrides[0].Domain != "corp.example.com" || len(overrides[0].Servers) != 2 {
+		t.Errorf("Expected one normalized domain with two servers, got %#v", overrides)
+	}
+}
+
+func TestValidateDomainOverrides(t *testing.T) {
+	invalid := map[string][]DomainOverride{
+		"missing domain":   {{Servers: []string{"10.1.0.10"}}},
+		"missing servers":  {{Domain: "corp.example.com"}},
+		"hostname server":  {{Domain: "corp.example.com", Servers: []string{"dc1.corp.example.com"}}},
+		"duplicate domain": {{Domain: "corp.example.com", Servers: []string{"10.1.0.10"}}, {Domain: "corp.example.com", Servers: []string{"10.1.0.11"}}},
+	}
+	for name, overrides := range invalid {
+		if err := ValidateDomainOverrides(overrides); err == nil {
+			t.Errorf("Expected %s to be rejected", name)
+		}
+	}
+	if err := ValidateDomainOverrides(nil); err != nil {
+		t.Errorf("Expected domain overrides to be optional, got %v", err)
+	}
+}
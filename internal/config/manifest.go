@@ -0,0 +1,125 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvManifest names the static hostname manifest file.
+const EnvManifest = "CADDY_DNS_SYNC_MANIFEST"
+
+// HostManifest lists hostnames that need DNS overrides but are never served
+// by Caddy: printers, NAS boxes, IPMI interfaces. It is read from YAML or
+// JSON (JSON being valid YAML):
+//
+//	hosts:
+//	  - hostname: printer.home.example.com
+//	    ip: 10.0.0.50
+//	  - hostname: nas.home.example.com
+//	    mac: aa:bb:cc:dd:ee:ff
+type HostManifest struct {
+	Hosts []ManifestHost `json:"hosts" yaml:"hosts"`
+}
+
+// ManifestHost is one static hostname. Exactly one of IP and MAC is set: IP
+// is a fixed IPv4 answer, MAC follows whatever address DHCP leases to the
+// device.
+type ManifestHost struct {
+	Hostname string `json:"hostname" yaml:"hostname"`
+	IP       string `json:"ip,omitempty" yaml:"ip,omitempty"`
+	MAC      string `json:"mac,omitempty" yaml:"mac,omitempty"`
+}
+
+// LoadHostManifest reads and validates the manifest at path. Hostnames and
+// MAC addresses are normalized to lower case.
+func LoadHostManifest(path string) (HostManifest, error) {
+	var manifest HostManifest
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return manifest, fmt.Errorf("error reading host manifest: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return manifest, fmt.Errorf("error parsing host manifest %s: %w", path, err)
+	}
+	if err := ValidateHostManifest(manifest); err != nil {
+		return manifest, fmt.Errorf("invalid host manifest %s: %w", path, err)
+	}
+	for i := range manifest.Hosts {
+		manifest.Hosts[i].Hostname = strings.ToLower(strings.TrimSuffix(manifest.Hosts[i].Hostname, "."))
+		manifest.Hosts[i].MAC = strings.ToLower(manifest.Hosts[i].MAC)
+	}
+	return manifest, nil
+}
+
+// ValidateHostManifest checks that every host has a unique hostname and
+// exactly one valid IPv4 or MAC address.
+func ValidateHostManifest(manifest HostManifest) error {
+	seen := make(map[string]bool, len(manifest.Hosts))
+	for i, host := range manifest.Hosts {
+		hostname := strings.ToLower(strings.TrimSuffix(host.Hostname, "."))
+		if hostname == "" {
+			return fmt.Errorf("hosts[%d]: hostname is required", i)
+		}
+		if seen[hostname] {
+			return fmt.Errorf("hosts[%d]: duplicate hostname %q", i, hostname)
+		}
+		seen[hostname] = true
+		switch {
+		case host.IP != "" && host.MAC != "":
+			return fmt.Errorf("hosts[%d] (%s): set either ip or mac, not both", i, hostname)
+		case host.IP != "":
+			// The IP becomes the host's A record, so it must be IPv4.
+			if ip := net.ParseIP(host.IP); ip == nil || ip.To4() == nil {
+				return fmt.Errorf("hosts[%d] (%s): invalid ip %q: must be an IPv4 address", i, hostname, host.IP)
+			}
+		case host.MAC != "":
+			if _, err := net.ParseMAC(host.MAC); err != nil {
+				return fmt.Errorf("hosts[%d] (%s): invalid mac %q", i, hostname, host.MAC)
+			}
+		default:
+			return fmt.Errorf("hosts[%d] (%s): ip or mac is required", i, hostname)
+		}
+	}
+	return nil
+}
+
+// LoadManifestHosts loads the manifest named by CADDY_DNS_SYNC_MANIFEST or
+// the config file's "manifest" key. No manifest configured means no hosts.
+func LoadManifestHosts() ([]ManifestHost, error) {
+	path := os.Getenv(EnvManifest)
+	if path == "" && viper.IsSet("manifest") {
+		path = viper.GetString("manifest")
+	}
+	if path == "" && !viper.IsSet("manifest") {
+		configPath, err := GetDefaultConfigPath()
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(configPath)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("error reading config file: %w", err)
+		}
+		if err == nil {
+			var extendedConfig ExtendedConfig
+			if err := json.Unmarshal(data, &extendedConfig); err != nil {
+				return nil, fmt.Errorf("error parsing extended config file: %w", err)
+			}
+			path = extendedConfig.Manifest
+		}
+	}
+	if path == "" {
+		return nil, nil
+	}
+
+	manifest, err := LoadHostManifest(path)
+	if err != nil {
+		return nil, err
+	}
+	return manifest.Hosts, nil
+}
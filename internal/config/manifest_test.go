@@ -0,0 +1,94 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestLoadManifestHosts_FromConfigFileYAML(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Cleanup(viper.Reset)
+
+	manifest := filepath.Join(home, "hosts.yaml")
+	yamlData := `hosts:
+  - hostname: Printer.home.example.com.
+    ip: 10.0.0.50
+  - hostname: nas.home.example.com
+    mac: AA:BB:CC:DD:EE:FF
+`
+	if err := os.WriteFile(manifest, []byte(yamlData), 0600); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(home, DefaultConfigFileName), []byte(`{"manifest": "`+manifest+`"}`), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	hosts, err := LoadManifestHosts()
+	if err != nil {
+		t.Fatalf("LoadManifestHosts failed: %v", err)
+	}
+	if len(hosts) != 2 {
+		t.Fatalf("Expected 2 hosts, got %#v", hosts)
+	}
+	if hosts[0].Hostname != "printer.home.example.com" || hosts[0].IP != "10.0.0.50" {
+		t.Errorf("Unexpected first host: %#v", hosts[0])
+	}
+	if hosts[1].MAC != "aa:bb:cc:dd:ee:ff" {
+		t.Errorf("Expected a normalized MAC, got %#v", hosts[1])
+	}
+}
+
+func TestLoadManifestHosts_FromEnvJSON(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Cleanup(viper.Reset)
+
+	manifest := filepath.Join(t.TempDir(), "hosts.json")
+	if err := os.WriteFile(manifest, []byte(`{"hosts": [{"hostname": "ipmi.example.com", "ip": "10.0.1.5"}]}`), 0600); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+	t.Setenv(EnvManifest, manifest)
+
+	hosts, err := LoadManifestHosts()
+	if err != nil || len(hosts) != 1 || hosts[0].IP != "10.0.1.5" {
+		t.Fatalf("Expected one host from %s, got %#v, %v", EnvManifest, hosts, err)
+	}
+}
+
+func TestLoadManifestHosts_NoneConfigured(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Cleanup(viper.Reset)
+
+	hosts, err := LoadManifestHosts()
+	if err != nil || hosts != nil {
+		t.Fatalf("Expected no hosts without a manifest, got %#v, %v", hosts, err)
+	}
+}
+
+func TestValidateHostManifest(t *testing.T) {
+	tests := []struct {
+		name    string
+		hosts   []ManifestHost
+		wantErr bool
+	}{
+		{name: "ip and mac hosts", hosts: []ManifestHost{{Hostname: "a.example.com", IP: "10.0.0.1"}, {Hostname: "b.example.com", MAC: "aa:bb:cc:dd:ee:ff"}}},
+		{name: "missing hostname", hosts: []ManifestHost{{IP: "10.0.0.1"}}, wantErr: true},
+		{name: "duplicate hostname", hosts: []ManifestHost{{Hostname: "a.example.com", IP: "10.0.0.1"}, {Hostname: "A.example.com.", IP: "10.0.0.2"}}, wantErr: true},
+		{name: "ip and mac", hosts: []ManifestHost{{Hostname: "a.example.com", IP: "10.0.0.1", MAC: "aa:bb:cc:dd:ee:ff"}}, wantErr: true},
+		{name: "neither ip nor mac", hosts: []ManifestHost{{Hostname: "a.example.com"}}, wantErr: true},
+		{name: "invalid ip", hosts: []ManifestHost{{Hostname: "a.example.com", IP: "10.0.0"}}, wantErr: true},
+		{name: "ipv6 ip", hosts: []ManifestHost{{Hostname: "a.example.com", IP: "fd00::50"}}, wantErr: true},
+		{name: "invalid mac", hosts: []ManifestHost{{Hostname: "a.example.com", MAC: "not-a-mac"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateHostManifest(HostManifest{Hosts: tt.hosts})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateHostManifest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// EnvMaxDeletes caps the number of records one sync run may delete.
+	EnvMaxDeletes = "CADDY_DNS_SYNC_MAX_DELETES"
+	// EnvMaxDeletePercent caps the share of a target's records one sync run
+	// may delete.
+	EnvMaxDeletePercent = "CADDY_DNS_SYNC_MAX_DELETE_PERCENT"
+	// EnvProtectedHosts is a comma-separated list of hostname globs that sync
+	// never updates or deletes.
+	EnvProtectedHosts = "CADDY_DNS_SYNC_PROTECTED_HOSTS"
+)
+
+// SyncPolicyConfig limits what a sync run may change. A zero MaxDeletes or
+// MaxDeletePercent selects the built-in default; a negative value disables
+// that limit. Protected holds hostname globs such as "*.prod.example.com".
+type SyncPolicyConfig struct {
+	MaxDeletes       int      `json:"max_deletes,omitempty" mapstructure:"max_deletes"`
+	MaxDeletePercent int      `json:"max_delete_percent,omitempty" mapstructure:"max_delete_percent"`
+	Protected        []string `json:"protected,omitempty" mapstructure:"protected"`
+}
+
+// LoadSyncPolicyConfig loads the "sync_policy" section from viper or the
+// config file, then applies any environment overrides field by field.
+func LoadSyncPolicyConfig() (SyncPolicyConfig, error) {
+	var cfg SyncPolicyConfig
+
+	if viper.IsSet("sync_policy") {
+		if err := viper.UnmarshalKey("sync_policy", &cfg); err != nil {
+			return cfg, fmt.Errorf("error parsing sync policy from viper: %w", err)
+		}
+	} else {
+		configPath, err := GetDefaultConfigPath()
+		if err != nil {
+			return cfg, err
+		}
+		data, err := os.ReadFile(configPath)
+		if err != nil && !os.IsNotExist(err) {
+			return cfg, fmt.Errorf("error reading config file: %w", err)
+		}
+		if err == nil {
+			var extendedConfig ExtendedConfig
+			if err := json.Unmarshal(data, &extendedConfig); err != nil {
+				return cfg, fmt.Errorf("error parsing extended config file: %w", err)
+			}
+			cfg = extendedConfig.SyncPolicy
+		}
+	}
+
+	if value := os.Getenv(EnvMaxDeletes); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid %s %q: %w", EnvMaxDeletes, value, err)
+		}
+		cfg.MaxDeletes = n
+	}
+	if value := os.Getenv(EnvMaxDeletePercent); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid %s %q: %w", EnvMaxDeletePercent, value, err)
+		}
+		cfg.MaxDeletePercent = n
+	}
+	if value := os.Getenv(EnvProtectedHosts); value != "" {
+		cfg.Protected = nil
+		for _, pattern := range strings.Split(value, ",") {
+			if pattern = strings.TrimSpace(pattern); pattern != "" {
+				cfg.Protected = append(cfg.Protected, pattern)
+			}
+		}
+	}
+
+	if err := ValidateSyncPolicy(cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// ValidateSyncPolicy checks the delete percentage and protected globs.
+func ValidateSyncPolicy(cfg SyncPolicyConfig) error {
+	if cfg.MaxDeletePercent > 100 {
+		return fmt.Errorf("sync_policy.max_delete_percent must be at most 100, got %d", cfg.MaxDeletePercent)
+	}
+	for i, pattern := range cfg.Protected {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("sync_policy.protected[%d]: invalid pattern %q: %w", i, pattern, err)
+		}
+	}
+	return nil
+}
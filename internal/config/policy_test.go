@@ -0,0 +1,45 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestLoadSyncPolicyConfig_FromConfigFileWithEnvOverride(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Cleanup(viper.Reset)
+
+	data := `{"sync_policy": {"max_deletes": 5, "max_delete_percent": 20, "protected": ["*.prod.example.com"]}}`
+	if err := os.WriteFile(filepath.Join(home, DefaultConfigFileName), []byte(data), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	t.Setenv(EnvMaxDeletes, "-1")
+	t.Setenv(EnvProtectedHosts, "router.example.com, *.infra.example.com")
+
+	cfg, err := LoadSyncPolicyConfig()
+	if err != nil {
+		t.Fatalf("LoadSyncPolicyConfig failed: %v", err)
+	}
+	if cfg.MaxDeletes != -1 || cfg.MaxDeletePercent != 20 {
+		t.Errorf("Unexpected limits: %#v", cfg)
+	}
+	if len(cfg.Protected) != 2 || cfg.Protected[1] != "*.infra.example.com" {
+		t.Errorf("Expected protected hosts from %s, got %#v", EnvProtectedHosts, cfg.Protected)
+	}
+}
+
+func TestValidateSyncPolicy(t *testing.T) {
+	if err := ValidateSyncPolicy(SyncPolicyConfig{MaxDeletePercent: 101}); err == nil {
+		t.Error("Expected a percentage above 100 to be rejected")
+	}
+	if err := ValidateSyncPolicy(SyncPolicyConfig{Protected: []string{"[a-"}}); err == nil {
+		t.Error("Expected an invalid glob to be rejected")
+	}
+	if err := ValidateSyncPolicy(SyncPolicyConfig{MaxDeletes: -1, Protected: []string{"*.example.com"}}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestLoadUnboundInstances_FromConfigFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Cleanup(viper.Reset)
+
+	data := `{
+		"api_key": "main-key",
+		"base_url": "https://fw-a.example",
+		"unbound_instances": [
+			{"name": "fw-b", "api_key": "b-key", "api_secret": "b-secret", "base_url": "https://fw-b.example"},
+			{"name": "site-2", "api_key": "s-key", "api_secret": "s-secret", "base_url": "https://site2.example", "insecure": true, "target_ip": "10.2.0.15"}
+		]
+	}`
+	if err := os.WriteFile(filepath.Join(home, DefaultConfigFileName), []byte(data), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	instances, err := LoadUnboundInstances()
+	if err != nil {
+		t.Fatalf("LoadUnboundInstances failed: %v", err)
+	}
+	if len(instances) != 2 {
+		t.Fatalf("Expected 2 instances, got %#v", instances)
+	}
+	if instances[1].Name != "site-2" || instances[1].TargetIP != "10.2.0.15" {
+		t.Errorf("Unexpected second instance: %#v", instances[1])
+	}
+	apiConfig := instances[1].GetUnboundAPIConfig()
+	if apiConfig.APIKey != "s-key" || apiConfig.BaseURL != "https://site2.example" || !apiConfig.Insecure {
+		t.Errorf("Unexpected API config: %#v", apiConfig)
+	}
+}
+
+func TestValidateUnboundInstances(t *testing.T) {
+	valid := UnboundInstanceConfig{Name: "fw-b", BaseURL: "https://fw-b.example"}
+	tests := []struct {
+		name      string
+		instances []UnboundInstanceConfig
+		wantErr   string
+	}{
+		{name: "valid", instances: []UnboundInstanceConfig{valid}},
+		{name: "missing name", instances: []UnboundInstanceConfig{{BaseURL: "https://x"}}, wantErr: "name is required"},
+		{name: "invalid name", instances: []UnboundInstanceConfig{{Name: "FW B", BaseURL: "https://x"}}, wantErr: "invalid name"},
+		{name: "duplicate", instances: []UnboundInstanceConfig{valid, valid}, wantErr: "duplicate name"},
+		{name: "missing base url", instances: []UnboundInstanceConfig{{Name: "fw-c"}}, wantErr: "base_url is required"},
+		{name: "invalid target ip", instances: []UnboundInstanceConfig{{Name: "fw-c", BaseURL: "https://x", TargetIP: "caddy"}}, wantErr: "invalid target_ip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateUnboundInstances(tt.instances)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Webhook is an inbound hook served by the web UI at POST /api/hooks/{name}.
+// Deliveries must carry an HMAC-SHA256 signature of the body made with
+// Secret, as GitHub and Gitea send it, and a send time in the body; stale or
+// repeated deliveries are rejected. Deploy pulls the Caddy editor repo and
+// runs its deploy pipeline; Sync then syncs DNS for Service ("all" when
+// empty). Branch, when set, ignores pushes to other branches.
+type Webhook struct {
+	Name    string `json:"name" mapstructure:"name"`
+	Secret  string `json:"secret" mapstructure:"secret"`
+	Deploy  bool   `json:"deploy,omitempty" mapstructure:"deploy"`
+	Sync    bool   `json:"sync,omitempty" mapstructure:"sync"`
+	Service string `json:"service,omitempty" mapstructure:"service"`
+	Branch  string `json:"branch,omitempty" mapstructure:"branch"`
+}
+
+var webhookNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// LoadWebhooks loads the "webhooks" list from viper or the config file.
+// An empty Service becomes "all".
+func LoadWebhooks() ([]Webhook, error) {
+	var hooks []Webhook
+
+	if viper.IsSet("webhooks") {
+		if err := viper.UnmarshalKey("webhooks", &hooks); err != nil {
+			return nil, fmt.Errorf("error parsing webhooks from viper: %w", err)
+		}
+	} else {
+		configPath, err := GetDefaultConfigPath()
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(configPath)
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading config file: %w", err)
+		}
+
+		var extendedConfig ExtendedConfig
+		if err := json.Unmarshal(data, &extendedConfig); err != nil {
+			return nil, fmt.Errorf("error parsing extended config file: %w", err)
+		}
+		hooks = extendedConfig.Webhooks
+	}
+
+	for i := range hooks {
+		hooks[i].Service = strings.TrimSpace(hooks[i].Service)
+		if hooks[i].Service == "" {
+			hooks[i].Service = "all"
+		}
+	}
+	if err := ValidateWebhooks(hooks); err != nil {
+		return nil, err
+	}
+	return hooks, nil
+}
+
+// ValidateWebhooks checks that every hook has a unique URL-safe name, a
+// secret, and something to do.
+func ValidateWebhooks(hooks []Webhook) error {
+	seen := make(map[string]bool, len(hooks))
+	for i, hook := range hooks {
+		if !webhookNamePattern.MatchString(hook.Name) {
+			return fmt.Errorf("webhooks[%d]: name %q must be letters, digits, '-' or '_'", i, hook.Name)
+		}
+		if seen[hook.Name] {
+			return fmt.Errorf("webhooks[%d]: name %q is listed more than once", i, hook.Name)
+		}
+		seen[hook.Name] = true
+		if hook.Secret == "" {
+			return fmt.Errorf("webhooks[%d]: secret is required for %s", i, hook.Name)
+		}
+		if !hook.Deploy && !hook.Sync {
+			return fmt.Errorf("webhooks[%d]: %s must enable deploy, sync or both", i, hook.Name)
+		}
+	}
+	return nil
+}
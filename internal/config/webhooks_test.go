@@ -0,0 +1,46 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestLoadWebhooks_FromConfigFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Cleanup(viper.Reset)
+
+	data := `{"webhooks": [{"name": "gitea", "secret": "s3cret", "deploy": true, "sync": true, "branch": "main"}]}`
+	if err := os.WriteFile(filepath.Join(home, DefaultConfigFileName), []byte(data), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	hooks, err := LoadWebhooks()
+	if err != nil {
+		t.Fatalf("LoadWebhooks failed: %v", err)
+	}
+	if len(hooks) != 1 || hooks[0].Name != "gitea" || hooks[0].Service != "all" || !hooks[0].Deploy || hooks[0].Branch != "main" {
+		t.Errorf("Expected one deploy+sync hook defaulting to service all, got %#v", hooks)
+	}
+}
+
+func TestValidateWebhooks(t *testing.T) {
+	invalid := map[string][]Webhook{
+		"missing name":   {{Secret: "s", Sync: true}},
+		"path in name":   {{Name: "a/b", Secret: "s", Sync: true}},
+		"missing secret": {{Name: "gitea", Sync: true}},
+		"no action":      {{Name: "gitea", Secret: "s"}},
+		"duplicate name": {{Name: "gitea", Secret: "s", Sync: true}, {Name: "gitea", Secret: "t", Deploy: true}},
+	}
+	for name, hooks := range invalid {
+		if err := ValidateWebhooks(hooks); err == nil {
+			t.Errorf("Expected %s to be rejected", name)
+		}
+	}
+	if err := ValidateWebhooks([]Webhook{{Name: "caddy-reload", Secret: "s", Sync: true}}); err != nil {
+		t.Errorf("Expected valid hook, got %v", err)
+	}
+}
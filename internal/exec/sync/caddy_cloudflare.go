@@ -42,7 +42,7 @@ type CloudflareEntry struct {
 // SyncCaddyWithCloudflare synchronizes DNS entries for dual-mode Cloudflare tunnel routing
 func SyncCaddyWithCloudflare(unboundClient *api.Client, options CaddyCloudflareSyncOptions) (*CaddyCloudflareSyncResult, error) {
 	// Fetch hostname map from Caddy
-	caddyClient := api.NewCaddyClient(options.CaddyServerIP, options.CaddyServerPort)
+	caddyClient := options.caddyClient()
 	hostnameMap, err := caddyClient.GetHostnameMap()
 	if err != nil {
 		logging.Error("Error fetching Caddy hostnames", "error", err)
@@ -135,6 +135,12 @@ func SyncCaddyWithCloudflare(unboundClient *api.Client, options CaddyCloudflareS
 	for _, override := range remainingSyncOverrides {
 		toRemove = append(toRemove, override)
 	}
+	if err := enforcePolicy(options.Policy, "Unbound", len(syncCreatedOverrides),
+		&toUpdate, func(entry CloudflareEntry) string { return entry.Hostname + "." + entry.Domain },
+		&toRemove, func(override api.DNSOverride) string { return override.Host + "." + override.Domain },
+	); err != nil {
+		return nil, err
+	}
 
 	result := &CaddyCloudflareSyncResult{
 		HostnameMap:    hostnameMap,
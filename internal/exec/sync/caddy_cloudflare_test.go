@@ -2,6 +2,7 @@ package sync
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -11,6 +12,7 @@ import (
 	"testing"
 
 	"github.com/jeeftor/caddy-dns-sync/internal/api"
+	"github.com/jeeftor/caddy-dns-sync/internal/syncplan"
 )
 
 func TestSyncCaddyWithCloudflareAppliesPlannedUpdates(t *testing.T) {
@@ -124,3 +126,78 @@ func TestSyncCaddyWithCloudflareAppliesPlannedUpdates(t *testing.T) {
 		t.Fatalf("expected server to update to Caddy upstream, got %q", updatedHost.Server)
 	}
 }
+
+func TestSyncCaddyWithCloudflareEnforcesSyncPolicy(t *testing.T) {
+	caddyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"apps":{"http":{"servers":{"srv0":{"routes":[
+			{"match":[{"host":["app.example.com"]}],"handle":[{"handler":"reverse_proxy","upstreams":[{"dial":"10.0.0.5:8080"}]}]}
+		]}}}}}`)
+	}))
+	defer caddyServer.Close()
+	caddyURL, err := url.Parse(caddyServer.URL)
+	if err != nil {
+		t.Fatalf("failed to parse Caddy server URL: %v", err)
+	}
+	caddyHost, caddyPortString, err := net.SplitHostPort(caddyURL.Host)
+	if err != nil {
+		t.Fatalf("failed to split Caddy host/port: %v", err)
+	}
+	caddyPort, err := strconv.Atoi(caddyPortString)
+	if err != nil {
+		t.Fatalf("failed to parse Caddy port: %v", err)
+	}
+
+	var deleted []string
+	unboundServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/unbound/settings/searchHostOverride":
+			fmt.Fprint(w, `{"rows":[
+				{"uuid":"app","enabled":"1","hostname":"app","domain":"dev.example.com","server":"10.0.0.5:8080","description":"managed by test"},
+				{"uuid":"old","enabled":"1","hostname":"old","domain":"dev.example.com","server":"10.0.0.6","description":"managed by test"},
+				{"uuid":"nas","enabled":"1","hostname":"nas","domain":"dev.example.com","server":"10.0.0.9","description":"managed by test"}
+			]}`)
+		case "/api/unbound/settings/delHostOverride/old", "/api/unbound/settings/delHostOverride/nas":
+			deleted = append(deleted, r.URL.Path)
+			fmt.Fprint(w, `{"result":"deleted"}`)
+		default:
+			t.Fatalf("unexpected Unbound path %s", r.URL.Path)
+		}
+	}))
+	defer unboundServer.Close()
+	client := api.NewClient(api.Config{
+		APIKey:    "key",
+		APISecret: "secret",
+		BaseURL:   unboundServer.URL,
+		Insecure:  true,
+	})
+
+	run := func(policy syncplan.Policy) (*CaddyCloudflareSyncResult, error) {
+		return SyncCaddyWithCloudflare(client, CaddyCloudflareSyncOptions{
+			BaseSyncOptions: BaseSyncOptions{
+				CaddyServerIP:    caddyHost,
+				CaddyServerPort:  caddyPort,
+				EntryDescription: "managed by test",
+				Policy:           policy,
+			},
+			DirectSubdomain: "dev",
+			SyncDirect:      true,
+		})
+	}
+
+	if _, err := run(syncplan.Policy{MaxDeletes: 1}); !errors.Is(err, syncplan.ErrMassDelete) {
+		t.Fatalf("expected the two stale overrides to be refused as a mass delete, got %v", err)
+	}
+	if len(deleted) != 0 {
+		t.Fatalf("expected nothing deleted after a refused run, got %v", deleted)
+	}
+
+	result, err := run(syncplan.Policy{MaxDeletes: 1, Protected: []string{"nas.*"}})
+	if err != nil {
+		t.Fatalf("SyncCaddyWithCloudflare failed: %v", err)
+	}
+	if len(result.ToRemove) != 1 || len(deleted) != 1 || deleted[0] != "/api/unbound/settings/delHostOverride/old" {
+		t.Fatalf("expected only the unprotected stale override to be deleted, got %v", deleted)
+	}
+}
@@ -19,6 +19,7 @@ type CaddyToCloudflareSyncOptions struct {
 	ExcludeHostnames []string // hostnames to skip entirely; their CF rules are left untouched
 	DirectHostSuffix string   // optional: add sibling direct hosts, e.g. "-direct" creates app-direct.example.com
 	Verbose          bool
+	Policy           syncplan.Policy // protects hostnames and limits deletes per run
 }
 
 // CaddyToCloudflareSyncResult holds the outcome of a Caddy-to-Cloudflare push sync.
@@ -34,12 +35,13 @@ type CaddyToCloudflareSyncResult struct {
 	DryRun         bool
 }
 
-// SyncCaddyToCloudflare reads hostnames from Caddy and synchronizes them into the
-// default Cloudflare tunnel (cfClient.tunnelID), while treating all other account
-// tunnels as read-only. DNS CNAME records are created/removed to match.
+// SyncCaddyToCloudflare reads hostnames from Caddy (or another reverse-proxy
+// source such as Traefik) and synchronizes them into the default Cloudflare
+// tunnel (cfClient.tunnelID), while treating all other account tunnels as
+// read-only. DNS CNAME records are created/removed to match.
 func SyncCaddyToCloudflare(
 	ctx context.Context,
-	caddyClient *api.CaddyClient,
+	caddyClient api.HostnameMapSource,
 	cfClient *api.CloudflareClient,
 	options CaddyToCloudflareSyncOptions,
 ) (*CaddyToCloudflareSyncResult, error) {
@@ -111,6 +113,20 @@ func SyncCaddyToCloudflare(
 		CaddyServiceURL:   options.CaddyServiceURL,
 		IncludeCloudflare: true,
 	})
+	owned := 0
+	for _, entry := range allCFHosts {
+		if entry.IsDefaultTunnel {
+			owned++
+		}
+	}
+	allowed, protected, err := options.Policy.Enforce(plan.Actions, map[string]int{"cloudflare": owned})
+	for _, action := range protected {
+		logging.Info("Leaving protected hostname unchanged", "hostname", action.Hostname, "target", "Cloudflare")
+	}
+	if err != nil {
+		return nil, err
+	}
+	plan.Actions = allowed
 	for _, action := range plan.Actions {
 		switch action.Type {
 		case "add":
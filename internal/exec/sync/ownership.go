@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/jeeftor/caddy-dns-sync/internal/api"
+	"github.com/jeeftor/caddy-dns-sync/internal/syncplan"
 )
 
 // IsLegacyDescription checks if the description matches one of the known
@@ -31,7 +32,8 @@ func IsSyncOwnedOverride(override api.DNSOverride, entryDescription string, lega
 //   - syncCreated: overrides owned by the sync engine (description matches)
 //   - other: overrides created manually or by other tools
 //
-// The key for each map is "host.domain" (the FQDN).
+// The key for each map is "host.domain" (the FQDN). AAAA overrides are left
+// out; they are managed by the sync plan alongside the A record.
 func OrganizeOverridesByOwnership(
 	existingOverrides []api.DNSOverride,
 	entryDescription string,
@@ -41,6 +43,9 @@ func OrganizeOverridesByOwnership(
 	other = make(map[string]api.DNSOverride)
 
 	for _, override := range existingOverrides {
+		if override.IsAAAA() {
+			continue
+		}
 		key := fmt.Sprintf("%s.%s", override.Host, override.Domain)
 		if IsSyncOwnedOverride(override, entryDescription, legacyDescriptions) {
 			syncCreated[key] = override
@@ -78,4 +83,17 @@ type BaseSyncOptions struct {
 	EntryDescription   string
 	LegacyDescriptions []string
 	Verbose            bool
+	// CaddyClient, when set, is used instead of a plain HTTP client for
+	// CaddyServerIP:CaddyServerPort (e.g. to reach a unix socket admin API).
+	CaddyClient *api.CaddyClient
+	// Policy protects hostnames and limits deletes per run.
+	Policy syncplan.Policy
+}
+
+// caddyClient returns the configured Caddy client or a plain HTTP one.
+func (o BaseSyncOptions) caddyClient() *api.CaddyClient {
+	if o.CaddyClient != nil {
+		return o.CaddyClient
+	}
+	return api.NewCaddyClient(o.CaddyServerIP, o.CaddyServerPort)
 }
@@ -0,0 +1,29 @@
+package sync
+
+import (
+	"github.com/jeeftor/caddy-dns-sync/internal/logging"
+	"github.com/jeeftor/caddy-dns-sync/internal/syncplan"
+)
+
+// enforcePolicy drops protected hostnames from the planned update and
+// removal lists, in place, and refuses a mass delete of the owned records in
+// target. updateHost and removeHost return the full hostname of a list item.
+func enforcePolicy[U, R any](policy syncplan.Policy, target string, owned int, toUpdate *[]U, updateHost func(U) string, toRemove *[]R, removeHost func(R) string) error {
+	*toUpdate = unprotected(policy, target, *toUpdate, updateHost)
+	*toRemove = unprotected(policy, target, *toRemove, removeHost)
+	return policy.CheckDeletes(target, len(*toRemove), owned)
+}
+
+// unprotected returns the items of list whose hostname policy allows sync to
+// modify, logging each one it leaves alone.
+func unprotected[T any](policy syncplan.Policy, target string, list []T, hostname func(T) string) []T {
+	var allowed []T
+	for _, item := range list {
+		if policy.IsProtected(hostname(item)) {
+			logging.Info("Leaving protected hostname unchanged", "hostname", hostname(item), "target", target)
+			continue
+		}
+		allowed = append(allowed, item)
+	}
+	return allowed
+}
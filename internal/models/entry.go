@@ -1,5 +1,7 @@
 package models
 
+import "net"
+
 // Entry represents a unified DNS/service entry with data from all sources
 type Entry struct {
 	// Identification
@@ -11,16 +13,37 @@ type Entry struct {
 	CaddyPort     string         // Extracted port: "8096"
 	CaddyRoute    CaddyRouteInfo // full handler chain from Caddy config
 	CaddyServerIP string         // IP of the Caddy reverse proxy itself (e.g., "10.0.0.15")
+	// CaddyServerIPv6 is the Caddy reverse proxy's IPv6 address; empty when
+	// this hostname gets no AAAA records.
+	CaddyServerIPv6 string
+	CaddyServer     string // name of the Caddy server that owns this hostname
+	// CaddyConflicts lists every Caddy server claiming this hostname when more
+	// than one does; CaddyServer is the first of them.
+	CaddyConflicts []string
+
+	// Static manifest (devices that are never proxied, e.g. printers)
+	Static   bool   // declared in the static hostname manifest
+	StaticIP string // manifest IP, or the IP leased to its MAC; empty if that MAC has no lease
+
+	// Alias is the canonical hostname this entry's records point at in alias
+	// mode; empty when records carry addresses.
+	Alias string
+
+	// Answer rules (split-horizon and bypass-Caddy overrides)
+	DNSAnswer     *Answer           // answer chosen by a rule for every target; nil means the Caddy addresses
+	TargetAnswers map[string]Answer // answer chosen by a rule, keyed by target name
 
 	// DNS Services
 	UnboundStatus ServiceStatus
 	AdguardStatus ServiceStatus
+	TargetStatus  map[string]ServiceStatus // additional registered sync targets (e.g. "pihole"), keyed by target name
 
 	// DHCP
 	DHCPStatus DHCPStatus
 
 	// DNS Resolution (what DNS actually resolves to)
-	DNSResolved string // Current DNS resolution result
+	DNSResolved   string // Current DNS resolution result (first IPv4 address, "NONE" or "FAIL")
+	DNSResolvedV6 string // First IPv6 address DNS resolved to, if any
 
 	// Cloudflare
 	CloudflareStatus         CloudflareStatus
@@ -40,6 +63,17 @@ func (e *Entry) IsConfiguredInCaddy() bool {
 	return e.CaddyUpstream != ""
 }
 
+// IsDeclared returns true if a source of truth — Caddy or the static
+// manifest — declares this hostname, so its DNS records must be kept.
+func (e *Entry) IsDeclared() bool {
+	return e.IsConfiguredInCaddy() || e.Static
+}
+
+// HasCaddyConflict returns true if more than one Caddy server claims this hostname.
+func (e *Entry) HasCaddyConflict() bool {
+	return len(e.CaddyConflicts) > 1
+}
+
 // IsConfiguredInCloudflare returns true if this hostname has an ingress rule in any CF tunnel.
 func (e *Entry) IsConfiguredInCloudflare() bool {
 	return e.CloudflareStatus.Configured
@@ -51,34 +85,191 @@ func (e *Entry) NeedsHTTPHostHeader() bool {
 	return e.CloudflareStatus.Configured && e.CloudflareStatus.HTTPHostHeader == ""
 }
 
-// HasDNSMismatch returns true if DNS resolves to an IP that differs from the
-// Caddy server IP. This indicates that a DNS override is pointing to the wrong
-// address (or a stale public record is being picked up instead of the LAN override).
+// Answer is the pair of addresses a hostname's DNS records carry: an A
+// record for IPv4 and an AAAA record for IPv6. An empty family gets no record.
+// In alias mode IPv4 holds the canonical hostname instead (see IsAlias), which
+// answers for both families.
+type Answer struct {
+	IPv4 string
+	IPv6 string
+}
+
+// With returns the answer with ip replacing the address of its own family.
+func (a Answer) With(ip string) Answer {
+	if IsIPv6(ip) {
+		a.IPv6 = ip
+	} else {
+		a.IPv4 = ip
+	}
+	return a
+}
+
+// IsAlias reports whether a DNS answer is a hostname (an alias or CNAME
+// target) rather than an address.
+func IsAlias(answer string) bool {
+	return answer != "" && net.ParseIP(answer) == nil
+}
+
+// IsIPv6 reports whether address is an IPv6 (not IPv4-mapped) address.
+func IsIPv6(address string) bool {
+	ip := net.ParseIP(address)
+	return ip != nil && ip.To4() == nil
+}
+
+// CaddyAnswer returns the Caddy server addresses this hostname answers with
+// when no answer rule applies.
+func (e *Entry) CaddyAnswer() Answer {
+	return Answer{IPv4: e.CaddyServerIP, IPv6: e.CaddyServerIPv6}
+}
+
+// ExpectedIP returns the IPv4 address this hostname should resolve to: the
+// answer rule's choice when one applies to every target, otherwise
+// CaddyServerIP.
+func (e *Entry) ExpectedIP() string {
+	if e.DNSAnswer != nil {
+		return e.DNSAnswer.IPv4
+	}
+	return e.CaddyServerIP
+}
+
+// ExpectedIPv6 returns the IPv6 address this hostname should resolve to, or
+// "" when it should have no AAAA record.
+func (e *Entry) ExpectedIPv6() string {
+	if e.DNSAnswer != nil {
+		return e.DNSAnswer.IPv6
+	}
+	return e.CaddyServerIPv6
+}
+
+// AnswerFor returns the addresses the named target should answer with: the
+// answer rule's choice for that target, or fallback when no rule applies.
+func (e *Entry) AnswerFor(target string, fallback Answer) Answer {
+	if answer, ok := e.TargetAnswers[target]; ok {
+		return answer
+	}
+	return fallback
+}
+
+// SetAnswerFor records the addresses an answer rule chose for the named target.
+func (e *Entry) SetAnswerFor(target string, answer Answer) {
+	if e.TargetAnswers == nil {
+		e.TargetAnswers = make(map[string]Answer)
+	}
+	e.TargetAnswers[target] = answer
+}
+
+// ResolvedAddresses returns the resolved IPv4 and IPv6 addresses for display,
+// or "" when the lookup found neither.
+func (e *Entry) ResolvedAddresses() string {
+	ipv4 := e.DNSResolved
+	if ipv4 == "NONE" || ipv4 == "FAIL" {
+		ipv4 = ""
+	}
+	return ServiceStatus{IP: ipv4, IPv6: e.DNSResolvedV6}.Addresses()
+}
+
+// HasDNSMismatch returns true if DNS resolves to an address that differs from
+// the expected one (see ExpectedIP and ExpectedIPv6). This indicates that a DNS
+// override is pointing to the wrong address (or a stale public record is being
+// picked up instead of the LAN override).
 // Returns false when DNS resolution failed or the Caddy server IP is unknown.
 func (e *Entry) HasDNSMismatch() bool {
 	if e.DNSResolved == "" || e.DNSResolved == "NONE" || e.DNSResolved == "FAIL" {
 		return false
 	}
-	if e.CaddyServerIP == "" {
+	expected := e.ExpectedIP()
+	if expected == "" {
 		return false
 	}
-	return e.DNSResolved != e.CaddyServerIP
+	if e.DNSResolved != expected {
+		return true
+	}
+	expectedV6 := e.ExpectedIPv6()
+	return expectedV6 != "" && e.DNSResolvedV6 != expectedV6
 }
 
-// NeedsSyncToUnbound returns true if Unbound needs to be updated
-func (e *Entry) NeedsSyncToUnbound() bool {
+// StatusFor returns the DNS service status recorded for the named sync target.
+// The built-in "unbound" and "adguard" targets map to their dedicated fields;
+// any other target is looked up in TargetStatus.
+func (e *Entry) StatusFor(target string) ServiceStatus {
+	switch target {
+	case "unbound":
+		return e.UnboundStatus
+	case "adguard":
+		return e.AdguardStatus
+	default:
+		if status, ok := e.TargetStatus[target]; ok {
+			return status
+		}
+		return NotConfigured()
+	}
+}
+
+// SetStatusFor records the DNS service status for the named sync target.
+func (e *Entry) SetStatusFor(target string, status ServiceStatus) {
+	switch target {
+	case "unbound":
+		e.UnboundStatus = status
+	case "adguard":
+		e.AdguardStatus = status
+	default:
+		if e.TargetStatus == nil {
+			e.TargetStatus = make(map[string]ServiceStatus)
+		}
+		e.TargetStatus[target] = status
+	}
+}
+
+// PiholeStatus returns the Pi-hole status recorded in TargetStatus, or nil
+// when no Pi-hole is configured.
+func (e *Entry) PiholeStatus() *ServiceStatus {
+	status, ok := e.TargetStatus["pihole"]
+	if !ok {
+		return nil
+	}
+	return &status
+}
+
+// DNSStatuses returns the status of every DNS target known for this entry,
+// keyed by target name. Unbound and AdGuard are always present; other targets
+// only when they have been loaded.
+func (e *Entry) DNSStatuses() map[string]ServiceStatus {
+	statuses := make(map[string]ServiceStatus, 2+len(e.TargetStatus))
+	statuses["unbound"] = e.UnboundStatus
+	statuses["adguard"] = e.AdguardStatus
+	for target, status := range e.TargetStatus {
+		statuses[target] = status
+	}
+	return statuses
+}
+
+// NeedsSyncTo returns true if the named DNS target's A record needs to be
+// added or updated
+func (e *Entry) NeedsSyncTo(target string) bool {
+	if e.Static && !e.IsConfiguredInCaddy() {
+		// A MAC without a lease has no answer to sync yet.
+		return e.StaticIP != "" && !e.StatusFor(target).InSync
+	}
 	if !e.IsConfiguredInCaddy() {
 		return false
 	}
-	return !e.UnboundStatus.InSync
+	return !e.StatusFor(target).InSync
+}
+
+// NeedsRemovalFrom returns true if this entry should be removed from the named
+// DNS target (configured there but neither in Caddy nor the static manifest)
+func (e *Entry) NeedsRemovalFrom(target string) bool {
+	return e.StatusFor(target).Configured && !e.IsDeclared()
+}
+
+// NeedsSyncToUnbound returns true if Unbound needs to be updated
+func (e *Entry) NeedsSyncToUnbound() bool {
+	return e.NeedsSyncTo("unbound")
 }
 
 // NeedsSyncToAdguard returns true if AdGuard needs to be updated
 func (e *Entry) NeedsSyncToAdguard() bool {
-	if !e.IsConfiguredInCaddy() {
-		return false
-	}
-	return !e.AdguardStatus.InSync
+	return e.NeedsSyncTo("adguard")
 }
 
 // NeedsDHCPStaticEntry returns true if a static DHCP entry should be created
@@ -93,15 +284,13 @@ func (e *Entry) NeedsDHCPStaticEntry() bool {
 // NeedsRemovalFromUnbound returns true if this entry should be removed from Unbound
 // (configured in Unbound but NOT in Caddy anymore)
 func (e *Entry) NeedsRemovalFromUnbound() bool {
-	// Only remove if it's configured in Unbound but NOT in Caddy
-	return e.UnboundStatus.Configured && !e.IsConfiguredInCaddy()
+	return e.NeedsRemovalFrom("unbound")
 }
 
 // NeedsRemovalFromAdguard returns true if this entry should be removed from AdGuard
 // (configured in AdGuard but NOT in Caddy anymore)
 func (e *Entry) NeedsRemovalFromAdguard() bool {
-	// Only remove if it's configured in AdGuard but NOT in Caddy
-	return e.AdguardStatus.Configured && !e.IsConfiguredInCaddy()
+	return e.NeedsRemovalFrom("adguard")
 }
 
 // HasAuthBypassRisk returns true if Caddy uses forward_auth (Authentik) for this
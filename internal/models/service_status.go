@@ -2,9 +2,37 @@ package models
 
 // ServiceStatus represents the configuration status of a DNS service (Unbound or AdGuard)
 type ServiceStatus struct {
-	Configured bool   // Is this service configured for this hostname?
-	IP         string // What IP is configured (if any)
+	Configured bool   // Is this service configured for this hostname? (A or AAAA record)
+	IP         string // What IP is configured in the A record (if any)
 	InSync     bool   // Does the configured IP match the expected IP?
+	Foreign    bool   // Record exists but lacks caddy-dns-sync's ownership marker
+
+	IPv6         string // What IPv6 address is configured in the AAAA record (if any)
+	IPv6Mismatch bool   // Is the AAAA record missing, unexpected or pointing elsewhere?
+}
+
+// Matches reports whether both the A record and the AAAA record hold their
+// expected answers.
+func (s ServiceStatus) Matches() bool {
+	return s.InSync && !s.IPv6Mismatch
+}
+
+// IsAlias reports whether the record is an alias answering with the records
+// of the hostname in IP rather than an address record.
+func (s ServiceStatus) IsAlias() bool {
+	return IsAlias(s.IP)
+}
+
+// Addresses returns the configured addresses for display, IPv4 first.
+func (s ServiceStatus) Addresses() string {
+	switch {
+	case s.IP != "" && s.IPv6 != "":
+		return s.IP + ", " + s.IPv6
+	case s.IPv6 != "":
+		return s.IPv6
+	default:
+		return s.IP
+	}
 }
 
 // NewServiceStatus creates a new ServiceStatus
@@ -83,40 +83,46 @@ func (s SyncStatus) Label() string {
 	}
 }
 
-// ComputeSyncStatus calculates the overall sync status for an entry
+// ComputeSyncStatus calculates the overall sync status for an entry across
+// every DNS target recorded on it (Unbound, AdGuard and any registered extras).
 func ComputeSyncStatus(entry *Entry, caddyServerIP string) SyncStatus {
-	inCaddy := entry.IsConfiguredInCaddy()
-	inUnbound := entry.UnboundStatus.Configured
-	inAdguard := entry.AdguardStatus.Configured
+	declared := entry.IsDeclared()
+
+	configured := 0
+	wrong := 0
+	statuses := entry.DNSStatuses()
+	for _, status := range statuses {
+		if !status.Configured {
+			continue
+		}
+		configured++
+		if !status.Matches() {
+			wrong++
+		}
+	}
 
-	// Stale: exists in DNS but not in Caddy
-	if !inCaddy && (inUnbound || inAdguard) {
+	// Stale: exists in DNS but neither in Caddy nor the static manifest
+	if !declared && configured > 0 {
 		return Stale
 	}
 
 	// Caddy Only: exists in Caddy but not configured in DNS services
-	if inCaddy && !inUnbound && !inAdguard {
+	if declared && configured == 0 {
 		return CaddyOnly
 	}
 
-	// Out of Sync: configured but IPs don't match
-	if inCaddy {
-		unboundWrong := inUnbound && !entry.UnboundStatus.InSync
-		adguardWrong := inAdguard && !entry.AdguardStatus.InSync
-
-		if unboundWrong || adguardWrong {
-			return OutOfSync
-		}
+	// Out of Sync: configured but an A or AAAA answer doesn't match
+	if declared && wrong > 0 {
+		return OutOfSync
 	}
 
-	// Partially In Sync: one service configured, other missing
-	if inCaddy && (inUnbound != inAdguard) {
+	// Partially In Sync: some services configured, others missing
+	if declared && configured < len(statuses) {
 		return PartiallyInSync
 	}
 
-	// Fully In Sync: both services configured with correct IPs
-	if inCaddy && inUnbound && inAdguard &&
-		entry.UnboundStatus.InSync && entry.AdguardStatus.InSync {
+	// Fully In Sync: every service configured with correct IPs
+	if declared && configured == len(statuses) {
 		return FullyInSync
 	}
 
@@ -185,9 +191,9 @@ func ApplyFilter(entry *Entry, filter FilterMode) bool {
 	case FilterStale:
 		return entry.OverallStatus == Stale
 	case FilterUnboundIssues:
-		return entry.IsConfiguredInCaddy() && (!entry.UnboundStatus.Configured || !entry.UnboundStatus.InSync)
+		return entry.IsDeclared() && (!entry.UnboundStatus.Configured || !entry.UnboundStatus.Matches())
 	case FilterAdguardIssues:
-		return entry.IsConfiguredInCaddy() && (!entry.AdguardStatus.Configured || !entry.AdguardStatus.InSync)
+		return entry.IsDeclared() && (!entry.AdguardStatus.Configured || !entry.AdguardStatus.Matches())
 	case FilterDHCPMismatches:
 		return !entry.DHCPStatus.InSync && entry.DHCPStatus.Configured
 	case FilterInCF:
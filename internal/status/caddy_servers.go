@@ -0,0 +1,100 @@
+package status
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/app"
+	"github.com/jeeftor/caddy-dns-sync/internal/config"
+	"github.com/jeeftor/caddy-dns-sync/internal/logging"
+	"github.com/jeeftor/caddy-dns-sync/internal/models"
+)
+
+// CaddyConflict records a hostname claimed by more than one Caddy server.
+// Servers are listed in load order; the first one owns the entry.
+type CaddyConflict struct {
+	Hostname string   `json:"hostname"`
+	Servers  []string `json:"servers"`
+}
+
+// WithCaddyServers merges the hostnames of additional Caddy servers with the
+// main one. Each hostname's DNS answer becomes the serve IP of the server that
+// owns it.
+func (d *DataLoader) WithCaddyServers(servers []app.CaddyServer) {
+	d.caddyServers = servers
+}
+
+// loadAllCaddyHostnames loads the main Caddy server and every additional one
+// in parallel and merges their routes. claims maps each hostname to the
+// servers that define it, main server first. Any server failing fails the
+// load: a missing server would otherwise turn its hostnames into stale
+// records scheduled for deletion.
+func (d *DataLoader) loadAllCaddyHostnames() (map[string]models.CaddyRouteInfo, map[string][]string, error) {
+	type serverResult struct {
+		routes map[string]models.CaddyRouteInfo
+		err    error
+	}
+	results := make([]serverResult, len(d.caddyServers)+1)
+
+	var wg sync.WaitGroup
+	for i, server := range d.caddyServers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer logging.Recover("loader: caddy server " + server.Name)
+			if server.Source == nil {
+				results[i+1].err = fmt.Errorf("Caddy server %q has no client", server.Name)
+				return
+			}
+			results[i+1].routes, results[i+1].err = server.Source.GetHostnameDetails()
+		}()
+	}
+	results[0].routes, results[0].err = d.loadCaddyHostnames()
+	wg.Wait()
+
+	if results[0].err != nil {
+		return nil, nil, results[0].err
+	}
+	merged := results[0].routes
+	claims := make(map[string][]string, len(merged))
+	for hostname := range merged {
+		claims[hostname] = []string{config.PrimaryCaddyServerName}
+	}
+	for i, server := range d.caddyServers {
+		result := results[i+1]
+		if result.err != nil {
+			return nil, nil, fmt.Errorf("Caddy server %q: %w", server.Name, result.err)
+		}
+		logging.Info("Loaded Caddy hostnames", "server", server.Name, "count", len(result.routes))
+		for hostname, route := range result.routes {
+			if _, exists := merged[hostname]; !exists {
+				merged[hostname] = route
+			}
+			claims[hostname] = append(claims[hostname], server.Name)
+		}
+	}
+	return merged, claims, nil
+}
+
+// caddyServeIP returns the DNS answer for hostnames owned by the named server.
+func (d *DataLoader) caddyServeIP(server string) string {
+	for _, s := range d.caddyServers {
+		if s.Name == server && s.ServeIP != "" {
+			return s.ServeIP
+		}
+	}
+	return d.caddyServerIP
+}
+
+// caddyConflicts lists the hostnames claimed by more than one Caddy server.
+func caddyConflicts(entries []*models.Entry) []CaddyConflict {
+	var conflicts []CaddyConflict
+	for _, entry := range entries {
+		if entry.HasCaddyConflict() {
+			conflicts = append(conflicts, CaddyConflict{Hostname: entry.Hostname, Servers: entry.CaddyConflicts})
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Hostname < conflicts[j].Hostname })
+	return conflicts
+}
@@ -0,0 +1,84 @@
+package status
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/models"
+)
+
+// Replica states reported in ReplicaDrift.States. Any other value is the
+// unexpected answer the replica currently holds.
+const (
+	ReplicaInSync  = "in sync"
+	ReplicaMissing = "missing"
+)
+
+// ReplicaDrift records a hostname whose replicas of one DNS service disagree,
+// e.g. a rewrite present on the primary AdGuard Home but missing on the
+// secondary. Replicas are the targets sharing a base name: "adguard" and
+// "adguard:secondary", or "unbound" and "unbound:fw-b".
+type ReplicaDrift struct {
+	Hostname string `json:"hostname"`
+	Service  string `json:"service"`
+	// States maps each replica's target name to ReplicaInSync, ReplicaMissing
+	// or the answer it currently holds.
+	States map[string]string `json:"states"`
+}
+
+// replicaDrift compares every entry across replica groups. Replicas whose
+// records failed to load are left out so an unreachable instance is not
+// reported as missing every hostname.
+func (d *DataLoader) replicaDrift(entries []*models.Entry, loadErrs map[string]error) []ReplicaDrift {
+	groups := make(map[string][]string)
+	var services []string
+	for _, target := range d.targets.RecordListers() {
+		if !target.Available() || loadErrs[target.Name()] != nil {
+			continue
+		}
+		service, _, _ := strings.Cut(target.Name(), ":")
+		if _, ok := groups[service]; !ok {
+			services = append(services, service)
+		}
+		groups[service] = append(groups[service], target.Name())
+	}
+
+	var drift []ReplicaDrift
+	for _, entry := range entries {
+		for _, service := range services {
+			replicas := groups[service]
+			if len(replicas) < 2 {
+				continue
+			}
+			states := make(map[string]string, len(replicas))
+			agree := true
+			for _, name := range replicas {
+				states[name] = replicaState(entry.StatusFor(name))
+				if states[name] != states[replicas[0]] {
+					agree = false
+				}
+			}
+			if !agree {
+				drift = append(drift, ReplicaDrift{Hostname: entry.Hostname, Service: service, States: states})
+			}
+		}
+	}
+	sort.SliceStable(drift, func(i, j int) bool {
+		if drift[i].Hostname != drift[j].Hostname {
+			return drift[i].Hostname < drift[j].Hostname
+		}
+		return drift[i].Service < drift[j].Service
+	})
+	return drift
+}
+
+func replicaState(status models.ServiceStatus) string {
+	switch {
+	case !status.Configured:
+		return ReplicaMissing
+	case status.InSync:
+		return ReplicaInSync
+	default:
+		return status.IP
+	}
+}
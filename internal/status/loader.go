@@ -10,8 +10,10 @@ import (
 
 	"github.com/jeeftor/caddy-dns-sync/internal/api"
 	"github.com/jeeftor/caddy-dns-sync/internal/app"
+	"github.com/jeeftor/caddy-dns-sync/internal/config"
 	"github.com/jeeftor/caddy-dns-sync/internal/logging"
 	"github.com/jeeftor/caddy-dns-sync/internal/models"
+	"github.com/jeeftor/caddy-dns-sync/internal/syncplan"
 )
 
 type ServiceName string
@@ -23,6 +25,8 @@ const (
 	ServiceDHCP       ServiceName = "dhcp"
 	ServiceCloudflare ServiceName = "cloudflare"
 	ServiceDNS        ServiceName = "dns"
+	ServiceDocker     ServiceName = "docker"
+	ServiceKubernetes ServiceName = "kubernetes"
 )
 
 type ServiceState string
@@ -42,6 +46,10 @@ type ServiceReport struct {
 
 type LoadReport struct {
 	Services map[ServiceName]ServiceReport `json:"services"`
+	// Drift lists hostnames on which replicas of one DNS service disagree.
+	Drift []ReplicaDrift `json:"drift,omitempty"`
+	// Conflicts lists hostnames claimed by more than one Caddy server.
+	Conflicts []CaddyConflict `json:"conflicts,omitempty"`
 }
 
 type ProgressEvent struct {
@@ -53,19 +61,35 @@ type ProgressEvent struct {
 
 type Options struct {
 	CaddyServerIP string
-	Progress      func(ProgressEvent)
+	// CaddyServerIPv6 is the main Caddy server's AAAA answer, if it has one.
+	CaddyServerIPv6 string
+	Progress        func(ProgressEvent)
+	// Targets are additional sync targets loaded alongside the built-in
+	// Unbound and AdGuard targets. Only targets implementing
+	// syncplan.RecordLister contribute per-entry status.
+	Targets []syncplan.Target
 }
 
 // DataLoader handles loading data from all API clients and building unified Entry models
 type DataLoader struct {
-	caddyClient   *api.CaddyClient
-	unboundClient *api.Client
-	adguardClient *api.AdguardClient
-	dnsmasqClient *api.DNSMasqClient
-	cfClient      *api.CloudflareClient
-	caddyServerIP string
-	progress      func(ProgressEvent)
-	ctx           context.Context
+	caddyClient     *api.CaddyClient
+	caddySource     api.HostnameSource
+	caddyServers    []app.CaddyServer
+	dockerClient    *api.DockerClient
+	k8sClient       *api.KubernetesClient
+	manifest        map[string]config.ManifestHost
+	answerRules     syncplan.AnswerRules
+	aliasCanonical  string
+	unboundClient   *api.Client
+	adguardClient   *api.AdguardClient
+	dnsmasqClient   *api.DNSMasqClient
+	keaClient       *api.KeaClient
+	cfClient        *api.CloudflareClient
+	targets         *syncplan.Registry
+	caddyServerIP   string
+	caddyServerIPv6 string
+	progress        func(ProgressEvent)
+	ctx             context.Context
 }
 
 func LoadEntries(ctx context.Context, clients app.ClientSet, options Options) ([]*models.Entry, LoadReport, error) {
@@ -79,12 +103,141 @@ func LoadEntries(ctx context.Context, clients app.ClientSet, options Options) ([
 		clients.DNSMasq,
 		options.CaddyServerIP,
 	)
+	loader.WithCaddyServerIPv6(options.CaddyServerIPv6)
+	loader.WithCaddySource(clients.CaddySource)
+	loader.WithCaddyServers(clients.CaddyServers)
+	loader.WithDockerClient(clients.Docker)
+	loader.WithKubernetesClient(clients.Kubernetes)
+	loader.WithManifest(clients.Manifest)
+	loader.WithAnswerRules(clients.AnswerRules)
+	loader.WithAliasCanonical(clients.AliasCanonical)
 	loader.WithCloudflareClient(clients.Cloudflare)
+	loader.WithKeaClient(clients.Kea)
+	if clients.DNSMasqHosts {
+		loader.WithDNSMasqHosts(clients.DNSMasq)
+	}
+	loader.WithPiholeClient(clients.Pihole)
+	loader.WithRFC2136Client(clients.RFC2136)
+	loader.WithUnboundInstances(clients.UnboundInstances)
+	loader.WithAdguardInstances(clients.AdguardAnswerOverride, clients.AdguardInstances)
+	loader.WithTargets(options.Targets...)
 	loader.WithContext(ctx)
 	loader.progress = options.Progress
 	return loader.LoadDataWithReport()
 }
 
+// WithDNSMasqHosts registers the Dnsmasq host override target. The Dnsmasq
+// client passed to NewDataLoader only supplies DHCP leases.
+func (d *DataLoader) WithDNSMasqHosts(c *api.DNSMasqClient) {
+	if c != nil {
+		d.targets.Register(syncplan.NewDNSMasqTarget(c))
+	}
+}
+
+// WithPiholeClient sets an optional Pi-hole client. If nil, Pi-hole data is
+// skipped and entries carry no "pihole" TargetStatus.
+func (d *DataLoader) WithPiholeClient(c *api.PiholeClient) {
+	if c != nil {
+		d.targets.Register(syncplan.NewPiholeTarget(c))
+	}
+}
+
+// WithKeaClient sets an optional Kea client. When set, DHCP leases are read
+// from Kea instead of Dnsmasq.
+func (d *DataLoader) WithKeaClient(c *api.KeaClient) {
+	d.keaClient = c
+}
+
+// WithCaddySource reads Caddy routes from source (e.g. a Caddyfile) instead
+// of the Caddy client. A nil source keeps the Caddy client.
+func (d *DataLoader) WithCaddySource(source api.HostnameSource) {
+	d.caddySource = source
+}
+
+// WithDockerClient sets an optional Docker client. Hostnames found in
+// container labels but not in Caddy become entries with DataSource "docker",
+// so they are planned before Caddy serves them.
+func (d *DataLoader) WithDockerClient(c *api.DockerClient) {
+	d.dockerClient = c
+}
+
+// WithKubernetesClient sets an optional Kubernetes client. Ingress and
+// HTTPRoute hostnames not in Caddy become entries with DataSource
+// "kubernetes" whose DNS answer is their controller's LoadBalancer IP.
+func (d *DataLoader) WithKubernetesClient(c *api.KubernetesClient) {
+	d.k8sClient = c
+}
+
+// WithManifest adds static hostnames from the host manifest. They are
+// planned like Caddy hostnames, answering with their manifest IP or the IP
+// leased to their MAC, and are never treated as stale.
+func (d *DataLoader) WithManifest(hosts []config.ManifestHost) {
+	d.manifest = make(map[string]config.ManifestHost, len(hosts))
+	for _, host := range hosts {
+		d.manifest[host.Hostname] = host
+	}
+}
+
+// WithCaddyServerIPv6 sets the main Caddy server's IPv6 address. Hostnames
+// it serves get AAAA records next to their A records.
+func (d *DataLoader) WithCaddyServerIPv6(ip string) {
+	d.caddyServerIPv6 = ip
+}
+
+// WithAnswerRules sets the rules that point matching hostnames at an address
+// other than the Caddy server IP, optionally per sync target.
+func (d *DataLoader) WithAnswerRules(rules []config.AnswerRule) {
+	d.answerRules = rules
+}
+
+// WithAliasCanonical enables alias mode: the canonical hostname gets the
+// Caddy server's A record and hostnames on the main Caddy server are
+// expected as aliases of it on targets that support aliases.
+func (d *DataLoader) WithAliasCanonical(canonical string) {
+	d.aliasCanonical = canonical
+}
+
+// WithRFC2136Client sets an optional RFC 2136 client. If nil, the zone is not
+// transferred and entries carry no "rfc2136" status.
+func (d *DataLoader) WithRFC2136Client(c *api.RFC2136Client) {
+	if c != nil {
+		d.targets.Register(syncplan.NewRFC2136Target(c))
+	}
+}
+
+// WithUnboundInstances registers one "unbound:<name>" target per additional
+// Unbound endpoint, so each instance gets its own status column and a failing
+// firewall only fails its own column.
+func (d *DataLoader) WithUnboundInstances(instances []app.UnboundInstance) {
+	for _, instance := range instances {
+		if instance.Client != nil {
+			d.targets.Register(syncplan.NewUnboundInstanceTarget(instance.Name, instance.TargetIP, instance.Client))
+		}
+	}
+}
+
+// WithAdguardInstances applies the primary AdGuard Home's answer override and
+// registers one "adguard:<name>" target per replica, so each replica is
+// compared against its own expected answer.
+func (d *DataLoader) WithAdguardInstances(answerOverride string, instances []app.AdguardInstance) {
+	if answerOverride != "" && d.adguardClient != nil {
+		d.targets.Register(syncplan.NewAdguardInstanceTarget("", answerOverride, d.adguardClient))
+	}
+	for _, instance := range instances {
+		if instance.Client != nil {
+			d.targets.Register(syncplan.NewAdguardInstanceTarget(instance.Name, instance.AnswerOverride, instance.Client))
+		}
+	}
+}
+
+// WithTargets registers additional sync targets whose records are loaded and
+// recorded on each entry. A target with a built-in name replaces the built-in.
+func (d *DataLoader) WithTargets(targets ...syncplan.Target) {
+	for _, target := range targets {
+		d.targets.Register(target)
+	}
+}
+
 // WithContext sets the cancellation context for load phases that support it.
 func (d *DataLoader) WithContext(ctx context.Context) {
 	if ctx == nil {
@@ -119,13 +272,15 @@ func NewDataLoader(
 		unboundClient: unboundClient,
 		adguardClient: adguardClient,
 		dnsmasqClient: dnsmasqClient,
+		targets:       syncplan.NewClients(app.ClientSet{Unbound: unboundClient, Adguard: adguardClient}).Registry(),
 		caddyServerIP: caddyServerIP,
 		ctx:           context.Background(),
 	}
 }
 
 // LoadData loads all data from API clients concurrently and builds Entry models.
-// Caddy, Unbound, AdGuard, DHCP, and Cloudflare are fetched in parallel.
+// Caddy, every record-listing sync target (Unbound, AdGuard, ...), DHCP, and
+// Cloudflare are fetched in parallel.
 // DNS resolution for all entries is also parallelized with a worker pool.
 func (d *DataLoader) LoadData() ([]*models.Entry, error) {
 	entries, _, err := d.LoadDataWithReport()
@@ -133,12 +288,12 @@ func (d *DataLoader) LoadData() ([]*models.Entry, error) {
 }
 
 func (d *DataLoader) LoadDataWithReport() ([]*models.Entry, LoadReport, error) {
-	report := newLoadReport()
-	for _, service := range loadReportServices() {
+	report := d.newLoadReport()
+	for _, service := range d.loadReportServices() {
 		d.emit(ProgressEvent{Service: service, Status: ServicePending})
 	}
 	if err := d.contextErr(); err != nil {
-		report.markUnfinished(ServiceFailed, err.Error())
+		d.markUnfinished(report, ServiceFailed, err.Error())
 		d.emitAllReports(report)
 		return nil, report, err
 	}
@@ -147,43 +302,67 @@ func (d *DataLoader) LoadDataWithReport() ([]*models.Entry, LoadReport, error) {
 	data, errs := d.fetchAllData()
 
 	if err := d.contextErr(); err != nil {
-		report.markUnfinished(ServiceFailed, err.Error())
+		d.markUnfinished(report, ServiceFailed, err.Error())
 		d.emitAllReports(report)
 		return nil, report, err
 	}
 
 	report.set(ServiceCaddy, serviceReport(data.caddyHostnames, errs.caddy, false))
-	report.set(ServiceUnbound, serviceReport(data.unboundOverrides, errs.unbound, d.unboundClient == nil))
-	report.set(ServiceAdguard, serviceReport(data.adguardRewrites, errs.adguard, d.adguardClient == nil))
-	report.set(ServiceDHCP, serviceReport(data.dhcpLeases, errs.dhcp, d.dnsmasqClient == nil))
+	fetched := []ServiceName{ServiceCaddy}
+	if d.dockerClient != nil {
+		report.set(ServiceDocker, serviceReport(data.dockerRoutes, errs.docker, false))
+		fetched = append(fetched, ServiceDocker)
+	}
+	if d.k8sClient != nil {
+		report.set(ServiceKubernetes, serviceReport(data.k8sRoutes, errs.k8s, false))
+		fetched = append(fetched, ServiceKubernetes)
+	}
+	for _, target := range d.targets.RecordListers() {
+		name := ServiceName(target.Name())
+		report.set(name, serviceReport(data.targetRecords[target.Name()], errs.targets[target.Name()], !target.Available()))
+		fetched = append(fetched, name)
+	}
+	report.set(ServiceDHCP, serviceReport(data.dhcpLeases, errs.dhcp, d.leaseSource() == nil))
 	if report.Services[ServiceDHCP].Status == ServiceLoaded {
 		dhcpReport := report.Services[ServiceDHCP]
 		dhcpReport.Count = data.dhcpLeaseCount
 		report.set(ServiceDHCP, dhcpReport)
 	}
 	report.set(ServiceCloudflare, serviceReport(data.cfDetails, errs.cf, d.cfClient == nil))
-	d.emitReports(report, []ServiceName{ServiceCaddy, ServiceUnbound, ServiceAdguard, ServiceDHCP, ServiceCloudflare})
+	d.emitReports(report, append(fetched, ServiceDHCP, ServiceCloudflare))
 
 	if errs.caddy != nil {
 		report.set(ServiceDNS, ServiceReport{Status: ServiceSkipped, Error: "skipped because Caddy load failed"})
 		d.emitServiceReport(ServiceDNS, report.Services[ServiceDNS])
 		return nil, report, fmt.Errorf("failed to load Caddy hostnames: %w", errs.caddy)
 	}
+	// Like a missing Caddy server, a missing Docker host would turn its
+	// hostnames into stale records scheduled for deletion.
+	if errs.docker != nil {
+		report.set(ServiceDNS, ServiceReport{Status: ServiceSkipped, Error: "skipped because Docker load failed"})
+		d.emitServiceReport(ServiceDNS, report.Services[ServiceDNS])
+		return nil, report, fmt.Errorf("failed to load Docker containers: %w", errs.docker)
+	}
+	if errs.k8s != nil {
+		report.set(ServiceDNS, ServiceReport{Status: ServiceSkipped, Error: "skipped because Kubernetes load failed"})
+		d.emitServiceReport(ServiceDNS, report.Services[ServiceDNS])
+		return nil, report, fmt.Errorf("failed to load Kubernetes routes: %w", errs.k8s)
+	}
 	if err := d.contextErr(); err != nil {
-		report.markUnfinished(ServiceFailed, err.Error())
+		d.markUnfinished(report, ServiceFailed, err.Error())
 		d.emitAllReports(report)
 		return nil, report, err
 	}
 
 	// --- Phase 2: build entry models ---
 	logging.Info("Building unified entry models...")
-	entries := d.buildEntries(data.caddyHostnames, data.unboundOverrides, data.adguardRewrites, data.dhcpLeases)
+	entries := d.buildEntries(data.caddyHostnames, data.caddyClaims, discoveredRoutes{docker: data.dockerRoutes, k8s: data.k8sRoutes}, data.targetRecords, data.dhcpLeases)
 	logging.Info("Built entry models", "count", len(entries))
 
 	// --- Phase 3: parallel DNS resolution ---
 	logging.Info("Resolving DNS hostnames in parallel...")
 	if err := d.contextErr(); err != nil {
-		report.markUnfinished(ServiceFailed, err.Error())
+		d.markUnfinished(report, ServiceFailed, err.Error())
 		d.emitAllReports(report)
 		return nil, report, err
 	}
@@ -196,7 +375,17 @@ func (d *DataLoader) LoadDataWithReport() ([]*models.Entry, LoadReport, error) {
 
 	// Recompute overall status now that CF data is merged
 	for _, e := range entries {
-		e.OverallStatus = models.ComputeSyncStatus(e, d.caddyServerIP)
+		e.OverallStatus = models.ComputeSyncStatus(e, e.CaddyServerIP)
+	}
+
+	report.Conflicts = caddyConflicts(entries)
+	for _, conflict := range report.Conflicts {
+		logging.Warn("Hostname claimed by several Caddy servers", "hostname", conflict.Hostname, "servers", conflict.Servers)
+	}
+
+	report.Drift = d.replicaDrift(entries, errs.targets)
+	for _, drift := range report.Drift {
+		logging.Warn("Replica drift", "service", drift.Service, "hostname", drift.Hostname, "states", drift.States)
 	}
 
 	d.logLoadSummary(entries)
@@ -206,20 +395,23 @@ func (d *DataLoader) LoadDataWithReport() ([]*models.Entry, LoadReport, error) {
 
 // fetchedData holds the results of parallel API fetches.
 type fetchedData struct {
-	caddyHostnames   map[string]models.CaddyRouteInfo
-	unboundOverrides map[string]*api.DNSOverride
-	adguardRewrites  map[string]*api.Rewrite
-	dhcpLeases       map[string]*api.DNSMasqLease
-	dhcpLeaseCount   int
-	cfDetails        map[string]api.CloudflareIngressEntry
-	cfDNSRecords     map[string]string
+	caddyHostnames map[string]models.CaddyRouteInfo
+	caddyClaims    map[string][]string                   // hostname → Caddy servers defining it
+	dockerRoutes   map[string]models.CaddyRouteInfo      // hostname → route from container labels
+	k8sRoutes      map[string]api.KubernetesRoute        // hostname → Ingress/HTTPRoute route
+	targetRecords  map[string]map[string]syncplan.Record // target name → hostname → record
+	dhcpLeases     map[string]*api.DNSMasqLease
+	dhcpLeaseCount int
+	cfDetails      map[string]api.CloudflareIngressEntry
+	cfDNSRecords   map[string]string
 }
 
 // fetchErrors holds errors from parallel API fetches.
 type fetchErrors struct {
 	caddy   error
-	unbound error
-	adguard error
+	docker  error
+	k8s     error
+	targets map[string]error // keyed by target name
 	dhcp    error
 	cf      error
 }
@@ -227,10 +419,13 @@ type fetchErrors struct {
 // fetchAllData runs all API fetches in parallel and returns the collected data and errors.
 func (d *DataLoader) fetchAllData() (fetchedData, fetchErrors) {
 	var (
-		data fetchedData
-		errs fetchErrors
-		wg   sync.WaitGroup
+		data      fetchedData
+		errs      fetchErrors
+		wg        sync.WaitGroup
+		targetsMu sync.Mutex
 	)
+	data.targetRecords = make(map[string]map[string]syncplan.Record)
+	errs.targets = make(map[string]error)
 
 	// Wrap clients with the loader's context so API calls can be cancelled.
 	ctx := d.ctx
@@ -241,14 +436,6 @@ func (d *DataLoader) fetchAllData() (fetchedData, fetchErrors) {
 	if cfClient != nil {
 		cfClient = cfClient.WithContext(ctx)
 	}
-	unboundClient := d.unboundClient
-	if unboundClient != nil {
-		unboundClient = unboundClient.WithContext(ctx)
-	}
-	adguardClient := d.adguardClient
-	if adguardClient != nil {
-		adguardClient = adguardClient.WithContext(ctx)
-	}
 
 	wg.Add(1)
 	go func() {
@@ -258,7 +445,7 @@ func (d *DataLoader) fetchAllData() (fetchedData, fetchErrors) {
 			return
 		}
 		logging.Info("Loading Caddy configuration...")
-		data.caddyHostnames, errs.caddy = d.loadCaddyHostnames()
+		data.caddyHostnames, data.caddyClaims, errs.caddy = d.loadAllCaddyHostnames()
 		if errs.caddy != nil {
 			logging.Error("Failed to load Caddy hostnames", "error", errs.caddy)
 		} else {
@@ -266,47 +453,68 @@ func (d *DataLoader) fetchAllData() (fetchedData, fetchErrors) {
 		}
 	}()
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		defer logging.Recover("loader: unbound overrides")
-		if d.contextErr() != nil {
-			return
-		}
-		logging.Info("Loading Unbound DNS overrides...")
-		if unboundClient == nil {
-			data.unboundOverrides = make(map[string]*api.DNSOverride)
-			return
-		}
-		data.unboundOverrides, errs.unbound = d.loadUnboundOverrides()
-		if errs.unbound != nil {
-			logging.Warn("Failed to load Unbound overrides", "error", errs.unbound)
-			data.unboundOverrides = make(map[string]*api.DNSOverride)
-		} else {
-			logging.Info("Loaded Unbound overrides", "count", len(data.unboundOverrides))
-		}
-	}()
+	if d.dockerClient != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer logging.Recover("loader: docker containers")
+			if d.contextErr() != nil {
+				return
+			}
+			logging.Info("Loading Docker container labels...")
+			data.dockerRoutes, errs.docker = d.dockerClient.GetHostnameDetails()
+			if errs.docker != nil {
+				logging.Error("Failed to load Docker containers", "error", errs.docker)
+			} else {
+				logging.Info("Loaded Docker hostnames", "count", len(data.dockerRoutes))
+			}
+		}()
+	}
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		defer logging.Recover("loader: adguard rewrites")
-		if d.contextErr() != nil {
-			return
-		}
-		if adguardClient == nil {
-			data.adguardRewrites = make(map[string]*api.Rewrite)
-			return
-		}
-		logging.Info("Loading AdGuard DNS rewrites...")
-		data.adguardRewrites, errs.adguard = d.loadAdguardRewrites()
-		if errs.adguard != nil {
-			logging.Warn("Failed to load AdGuard rewrites", "error", errs.adguard)
-			data.adguardRewrites = make(map[string]*api.Rewrite)
-		} else {
-			logging.Info("Loaded AdGuard rewrites", "count", len(data.adguardRewrites))
-		}
-	}()
+	if d.k8sClient != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer logging.Recover("loader: kubernetes routes")
+			if d.contextErr() != nil {
+				return
+			}
+			logging.Info("Loading Kubernetes Ingress and HTTPRoute objects...")
+			data.k8sRoutes, errs.k8s = d.k8sClient.GetRoutes()
+			if errs.k8s != nil {
+				logging.Error("Failed to load Kubernetes routes", "error", errs.k8s)
+			} else {
+				logging.Info("Loaded Kubernetes hostnames", "count", len(data.k8sRoutes))
+			}
+		}()
+	}
+
+	for _, target := range d.targets.RecordListers() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer logging.Recover("loader: " + target.Name() + " records")
+			if d.contextErr() != nil {
+				return
+			}
+			records := make(map[string]syncplan.Record)
+			var err error
+			if target.Available() {
+				logging.Info("Loading sync target records...", "target", target.Label())
+				records, err = d.loadTargetRecords(ctx, target)
+				if err != nil {
+					logging.Warn("Failed to load sync target records", "target", target.Label(), "error", err)
+					records = make(map[string]syncplan.Record)
+				} else {
+					logging.Info("Loaded sync target records", "target", target.Label(), "count", len(records))
+				}
+			}
+			targetsMu.Lock()
+			data.targetRecords[target.Name()] = records
+			errs.targets[target.Name()] = err
+			targetsMu.Unlock()
+		}()
+	}
 
 	wg.Add(1)
 	go func() {
@@ -316,7 +524,7 @@ func (d *DataLoader) fetchAllData() (fetchedData, fetchErrors) {
 			return
 		}
 		logging.Info("Loading DHCP leases...")
-		if d.dnsmasqClient == nil {
+		if d.leaseSource() == nil {
 			data.dhcpLeases = make(map[string]*api.DNSMasqLease)
 			return
 		}
@@ -453,25 +661,26 @@ func (d *DataLoader) logLoadSummary(entries []*models.Entry) {
 	}
 }
 
-func loadReportServices() []ServiceName {
-	return []ServiceName{
-		ServiceCaddy,
-		ServiceUnbound,
-		ServiceAdguard,
-		ServiceDHCP,
-		ServiceCloudflare,
-		ServiceDNS,
+// loadReportServices lists the services reported on, in display order: Caddy,
+// Docker and Kubernetes when configured, each record-listing sync target, then DHCP, Cloudflare and DNS resolution.
+func (d *DataLoader) loadReportServices() []ServiceName {
+	services := []ServiceName{ServiceCaddy}
+	if d.dockerClient != nil {
+		services = append(services, ServiceDocker)
 	}
+	if d.k8sClient != nil {
+		services = append(services, ServiceKubernetes)
+	}
+	for _, target := range d.targets.RecordListers() {
+		services = append(services, ServiceName(target.Name()))
+	}
+	return append(services, ServiceDHCP, ServiceCloudflare, ServiceDNS)
 }
 
-func newLoadReport() LoadReport {
-	services := map[ServiceName]ServiceReport{
-		ServiceCaddy:      {Status: ServicePending},
-		ServiceUnbound:    {Status: ServicePending},
-		ServiceAdguard:    {Status: ServicePending},
-		ServiceDHCP:       {Status: ServicePending},
-		ServiceCloudflare: {Status: ServicePending},
-		ServiceDNS:        {Status: ServicePending},
+func (d *DataLoader) newLoadReport() LoadReport {
+	services := make(map[ServiceName]ServiceReport)
+	for _, service := range d.loadReportServices() {
+		services[service] = ServiceReport{Status: ServicePending}
 	}
 	return LoadReport{Services: services}
 }
@@ -480,8 +689,8 @@ func (r LoadReport) set(service ServiceName, serviceReport ServiceReport) {
 	r.Services[service] = serviceReport
 }
 
-func (r LoadReport) markUnfinished(status ServiceState, err string) {
-	for _, service := range loadReportServices() {
+func (d *DataLoader) markUnfinished(r LoadReport, status ServiceState, err string) {
+	for _, service := range d.loadReportServices() {
 		if r.Services[service].Status == ServicePending {
 			r.set(service, ServiceReport{Status: status, Error: err})
 		}
@@ -502,7 +711,7 @@ func (d *DataLoader) emitReports(report LoadReport, services []ServiceName) {
 }
 
 func (d *DataLoader) emitAllReports(report LoadReport) {
-	d.emitReports(report, loadReportServices())
+	d.emitReports(report, d.loadReportServices())
 }
 
 func (d *DataLoader) emitServiceReport(service ServiceName, report ServiceReport) {
@@ -562,7 +771,7 @@ func (d *DataLoader) resolveAllDNS(entries []*models.Entry) {
 			if d.contextErr() != nil {
 				return
 			}
-			e.DNSResolved = d.resolveDNS(e.Hostname)
+			e.DNSResolved, e.DNSResolvedV6 = d.resolveDNS(e.Hostname)
 			if e.DNSResolved == "FAIL" {
 				logging.Warn("DNS resolution failed", "hostname", e.Hostname)
 			}
@@ -571,64 +780,71 @@ func (d *DataLoader) resolveAllDNS(entries []*models.Entry) {
 	wg.Wait()
 }
 
-// loadCaddyHostnames loads full route details (hostname -> CaddyRouteInfo) from Caddy.
+// loadCaddyHostnames loads full route details (hostname -> CaddyRouteInfo) from
+// the configured Caddy source, falling back to the admin API.
 func (d *DataLoader) loadCaddyHostnames() (map[string]models.CaddyRouteInfo, error) {
+	if d.caddySource != nil {
+		return d.caddySource.GetHostnameDetails()
+	}
 	if d.caddyClient == nil {
 		return nil, fmt.Errorf("Caddy client not initialized")
 	}
 	return d.caddyClient.GetHostnameDetails()
 }
 
-// loadUnboundOverrides loads DNS overrides from Unbound
-func (d *DataLoader) loadUnboundOverrides() (map[string]*api.DNSOverride, error) {
-	if d.unboundClient == nil {
-		return nil, fmt.Errorf("Unbound client not initialized")
-	}
-
-	overrides, err := d.unboundClient.GetOverrides()
+// loadTargetRecords lists a sync target's DNS records indexed by hostname.
+func (d *DataLoader) loadTargetRecords(ctx context.Context, target syncplan.RecordLister) (map[string]syncplan.Record, error) {
+	records, err := target.Records(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	// Index by hostname
-	overrideMap := make(map[string]*api.DNSOverride)
-	for i := range overrides {
-		hostname := overrides[i].Host + "." + overrides[i].Domain
-		overrideMap[hostname] = &overrides[i]
+	// A and AAAA records for the same hostname merge into one Record.
+	recordMap := make(map[string]syncplan.Record, len(records))
+	for _, record := range records {
+		merged, seen := recordMap[record.Hostname]
+		if !models.IsIPv6(record.Answer) {
+			record.AnswerIPv6 = merged.AnswerIPv6
+			recordMap[record.Hostname] = record
+			continue
+		}
+		if !seen {
+			merged = syncplan.Record{Hostname: record.Hostname, Owned: record.Owned}
+		}
+		merged.AnswerIPv6 = record.Answer
+		recordMap[record.Hostname] = merged
 	}
 
-	return overrideMap, nil
+	return recordMap, nil
 }
 
-// loadAdguardRewrites loads DNS rewrites from AdGuard
-func (d *DataLoader) loadAdguardRewrites() (map[string]*api.Rewrite, error) {
-	if d.adguardClient == nil {
-		return nil, fmt.Errorf("AdGuard client not initialized")
-	}
+// leaseLister is implemented by the DHCP backends leases are read from.
+type leaseLister interface {
+	GetLeases() ([]api.DNSMasqLease, error)
+}
 
-	rewrites, err := d.adguardClient.ListRewrites()
-	if err != nil {
-		return nil, err
+// leaseSource returns Kea when configured, otherwise Dnsmasq, or nil when
+// neither client is set.
+func (d *DataLoader) leaseSource() leaseLister {
+	if d.keaClient != nil {
+		return d.keaClient
 	}
-
-	// Index by domain
-	rewriteMap := make(map[string]*api.Rewrite)
-	for i := range rewrites {
-		rewriteMap[rewrites[i].Domain] = &rewrites[i]
+	if d.dnsmasqClient != nil {
+		return d.dnsmasqClient
 	}
-
-	return rewriteMap, nil
+	return nil
 }
 
-// loadDHCPLeases loads DHCP leases from DNSMasq.
+// loadDHCPLeases loads DHCP leases from Kea or DNSMasq.
 // Returns a map keyed by BOTH short hostname (when present) AND IP address so
 // that static reservations without a hostname can still be matched by IP.
 func (d *DataLoader) loadDHCPLeases() (map[string]*api.DNSMasqLease, error) {
-	if d.dnsmasqClient == nil {
-		return nil, fmt.Errorf("DNSMasq client not initialized")
+	source := d.leaseSource()
+	if source == nil {
+		return nil, fmt.Errorf("DHCP client not initialized")
 	}
 
-	leases, err := d.dnsmasqClient.GetLeases()
+	leases, err := source.GetLeases()
 	if err != nil {
 		return nil, err
 	}
@@ -652,8 +868,9 @@ func (d *DataLoader) loadDHCPLeases() (map[string]*api.DNSMasqLease, error) {
 // buildEntries builds unified Entry models from all data sources
 func (d *DataLoader) buildEntries(
 	caddyHostnames map[string]models.CaddyRouteInfo,
-	unboundOverrides map[string]*api.DNSOverride,
-	adguardRewrites map[string]*api.Rewrite,
+	caddyClaims map[string][]string,
+	discovered discoveredRoutes,
+	targetRecords map[string]map[string]syncplan.Record,
 	dhcpLeases map[string]*api.DNSMasqLease,
 ) []*models.Entry {
 	// Collect all unique hostnames from all sources
@@ -664,21 +881,47 @@ func (d *DataLoader) buildEntries(
 		hostnameSet[hostname] = true
 	}
 
-	// Add Unbound hostnames
-	for hostname := range unboundOverrides {
+	// Add hostnames discovered from Docker labels and Kubernetes
+	for hostname := range discovered.docker {
+		hostnameSet[hostname] = true
+	}
+	for hostname := range discovered.k8s {
 		hostnameSet[hostname] = true
 	}
 
-	// Add AdGuard hostnames
-	for hostname := range adguardRewrites {
+	// Add static manifest hostnames
+	for hostname := range d.manifest {
 		hostnameSet[hostname] = true
 	}
 
+	// Add the alias mode canonical hostname
+	if d.aliasCanonical != "" {
+		hostnameSet[d.aliasCanonical] = true
+	}
+
+	// Add hostnames held by each sync target. Records a target does not own
+	// (e.g. the rest of an RFC 2136 zone) only matter for declared hostnames,
+	// so they do not add entries that would show up as stale.
+	for name, records := range targetRecords {
+		tracksOwnership := false
+		if target, ok := d.targets.Lookup(name); ok {
+			if tracker, ok := target.(syncplan.OwnershipTracker); ok {
+				tracksOwnership = tracker.TracksOwnership()
+			}
+		}
+		for hostname, record := range records {
+			if tracksOwnership && !record.Owned {
+				continue
+			}
+			hostnameSet[hostname] = true
+		}
+	}
+
 	// Build entries
 	entries := make([]*models.Entry, 0, len(hostnameSet))
 
 	for hostname := range hostnameSet {
-		entry := d.buildEntry(hostname, caddyHostnames, unboundOverrides, adguardRewrites, dhcpLeases)
+		entry := d.buildEntry(hostname, caddyHostnames, caddyClaims[hostname], discovered, targetRecords, dhcpLeases)
 		entries = append(entries, entry)
 	}
 
@@ -689,54 +932,134 @@ func (d *DataLoader) buildEntries(
 func (d *DataLoader) buildEntry(
 	hostname string,
 	caddyHostnames map[string]models.CaddyRouteInfo,
-	unboundOverrides map[string]*api.DNSOverride,
-	adguardRewrites map[string]*api.Rewrite,
+	caddyServers []string,
+	discovered discoveredRoutes,
+	targetRecords map[string]map[string]syncplan.Record,
 	dhcpLeases map[string]*api.DNSMasqLease,
 ) *models.Entry {
 	entry := &models.Entry{
 		Hostname: hostname,
 	}
 
-	// Caddy data (source of truth)
+	// The owning Caddy server decides the DNS answer; HasDNSMismatch() also
+	// compares against it. Hostnames not in Caddy fall back to the main server.
+	entry.CaddyServerIP = d.caddyServerIP
+	if len(caddyServers) > 0 {
+		entry.CaddyServer = caddyServers[0]
+		entry.CaddyServerIP = d.caddyServeIP(caddyServers[0])
+	}
+	// Only the main server has an IPv6 address configured.
+	if entry.CaddyServerIP == d.caddyServerIP {
+		entry.CaddyServerIPv6 = d.caddyServerIPv6
+	}
+	if len(caddyServers) > 1 {
+		entry.CaddyConflicts = caddyServers
+	}
+
+	// Caddy data (source of truth). Kubernetes routes and Docker labels
+	// stand in for hostnames Caddy does not serve, so they are planned as if
+	// it did; Kubernetes hostnames point at their LoadBalancer IP instead.
 	if routeInfo, exists := caddyHostnames[hostname]; exists {
-		entry.CaddyUpstream = routeInfo.Upstream
-		entry.CaddyRoute = routeInfo
+		setCaddyRoute(entry, routeInfo)
 		entry.DataSource = "Caddy"
+	} else if route, exists := discovered.k8s[hostname]; exists {
+		setCaddyRoute(entry, route.Route)
+		entry.CaddyServerIP = route.ServeIP
+		entry.CaddyServerIPv6 = ""
+		entry.DataSource = "kubernetes"
+	} else if routeInfo, exists := discovered.docker[hostname]; exists {
+		setCaddyRoute(entry, routeInfo)
+		entry.DataSource = "docker"
+	}
 
-		// Extract IP and port from upstream
-		// Upstream format: "10.0.0.112:8096" or "10.0.0.112"
-		if parts := strings.Split(routeInfo.Upstream, ":"); len(parts) >= 1 {
-			entry.CaddyIP = parts[0]
-			if len(parts) == 2 {
-				entry.CaddyPort = parts[1]
+	// Static manifest hosts answer with their own address instead of Caddy's.
+	if host, exists := d.manifest[hostname]; exists {
+		if entry.IsConfiguredInCaddy() {
+			logging.Warn("Manifest hostname is also served by Caddy; using Caddy", "hostname", hostname)
+		} else {
+			entry.Static = true
+			entry.CaddyServerIPv6 = ""
+			entry.StaticIP = manifestIP(host, dhcpLeases)
+			if entry.StaticIP != "" {
+				entry.CaddyServerIP = entry.StaticIP
+			} else {
+				logging.Warn("No DHCP lease for manifest MAC; not syncing until it has one", "hostname", hostname, "mac", host.MAC)
 			}
+			entry.DataSource = "manifest"
 		}
 	}
 
-	// Unbound data
-	if override, exists := unboundOverrides[hostname]; exists {
-		configured := override.Server != ""
-		inSync := configured && override.Server == d.caddyServerIP
-		entry.UnboundStatus = models.NewServiceStatus(configured, override.Server, inSync)
-
-		if entry.DataSource == "" {
-			entry.DataSource = "Unbound"
+	// In alias mode the canonical hostname carries the main Caddy server's
+	// addresses and the hostnames that server owns point at it.
+	if d.aliasCanonical != "" {
+		switch {
+		case hostname == d.aliasCanonical:
+			if !entry.IsConfiguredInCaddy() {
+				entry.Static = true
+				entry.StaticIP = d.caddyServerIP
+				entry.CaddyServerIP = d.caddyServerIP
+				entry.CaddyServerIPv6 = d.caddyServerIPv6
+				entry.DataSource = "alias"
+			}
+		case entry.IsConfiguredInCaddy() && !entry.Static && entry.DataSource != "kubernetes" && entry.CaddyServerIP == d.caddyServerIP:
+			entry.Alias = d.aliasCanonical
 		}
-	} else {
-		entry.UnboundStatus = models.NotConfigured()
 	}
 
-	// AdGuard data
-	if rewrite, exists := adguardRewrites[hostname]; exists {
-		configured := rewrite.Answer != ""
-		inSync := configured && rewrite.Answer == d.caddyServerIP
-		entry.AdguardStatus = models.NewServiceStatus(configured, rewrite.Answer, inSync)
+	// Answer rules replace the Caddy address for matching hostnames; a rule
+	// naming targets only changes what those targets answer.
+	if answer, ok := d.answerRules.Answer(entry, "", entry.CaddyAnswer()); ok {
+		entry.DNSAnswer = &answer
+	}
+
+	// Sync target data, in registry order so DataSource is deterministic
+	for _, target := range d.targets.RecordListers() {
+		if !target.Available() {
+			continue
+		}
+		expected := entry.CaddyAnswer()
+		if provider, ok := target.(syncplan.TargetIPProvider); ok && provider.TargetIP() != "" {
+			expected = expected.With(provider.TargetIP())
+		}
+		if answer, ok := d.answerRules.Answer(entry, target.Name(), expected); ok {
+			entry.SetAnswerFor(target.Name(), answer)
+			expected = answer
+		} else if aliasing, ok := target.(syncplan.AliasTarget); ok && entry.Alias != "" && aliasing.SupportsAliases() {
+			expected = models.Answer{IPv4: entry.Alias}
+			entry.SetAnswerFor(target.Name(), expected)
+		}
+		// AAAA records are compared only on targets that manage them and
+		// only once an IPv6 answer is configured for the hostname.
+		_, managesAAAA := target.(syncplan.AAAATarget)
+		checkIPv6 := managesAAAA && (expected.IPv6 != "" || entry.CaddyServerIPv6 != "")
+		record, exists := targetRecords[target.Name()][hostname]
+		if !exists {
+			status := models.NotConfigured()
+			status.IPv6Mismatch = checkIPv6 && expected.IPv6 != ""
+			entry.SetStatusFor(target.Name(), status)
+			continue
+		}
+		configured := record.Answer != "" || record.AnswerIPv6 != ""
+		answer := record
+		if models.IsAlias(record.Answer) && !models.IsAlias(expected.IPv4) {
+			// An alias answers with the records of the override it
+			// belongs to, so compare those against the expected addresses.
+			if parent, ok := targetRecords[target.Name()][record.Answer]; ok {
+				answer.Answer, answer.AnswerIPv6 = parent.Answer, parent.AnswerIPv6
+			}
+		}
+		inSync := configured && answer.Answer == expected.IPv4
+		status := models.NewServiceStatus(configured, record.Answer, inSync)
+		status.IPv6 = record.AnswerIPv6
+		status.IPv6Mismatch = checkIPv6 && answer.AnswerIPv6 != expected.IPv6
+		if tracker, ok := target.(syncplan.OwnershipTracker); ok && tracker.TracksOwnership() {
+			status.Foreign = !record.Owned
+		}
+		entry.SetStatusFor(target.Name(), status)
 
 		if entry.DataSource == "" {
-			entry.DataSource = "AdGuard"
+			entry.DataSource = target.Label()
 		}
-	} else {
-		entry.AdguardStatus = models.NotConfigured()
 	}
 
 	// DHCP data
@@ -776,19 +1099,52 @@ func (d *DataLoader) buildEntry(
 		entry.DHCPStatus = models.NoDHCP()
 	}
 
-	// Store the Caddy server IP so HasDNSMismatch() can compare against it.
-	entry.CaddyServerIP = d.caddyServerIP
-
 	// Compute overall sync status (DNS resolution happens separately in parallel)
-	entry.OverallStatus = models.ComputeSyncStatus(entry, d.caddyServerIP)
+	entry.OverallStatus = models.ComputeSyncStatus(entry, entry.CaddyServerIP)
 
 	return entry
 }
 
+// manifestIP returns a manifest host's fixed IP, or the IPv4 address
+// currently leased to its MAC.
+func manifestIP(host config.ManifestHost, dhcpLeases map[string]*api.DNSMasqLease) string {
+	if host.IP != "" {
+		return host.IP
+	}
+	for _, lease := range dhcpLeases {
+		if lease != nil && strings.EqualFold(lease.MACAddress, host.MAC) && net.ParseIP(lease.IPAddress).To4() != nil {
+			return lease.IPAddress
+		}
+	}
+	return ""
+}
+
+// discoveredRoutes holds hostnames found outside Caddy.
+type discoveredRoutes struct {
+	docker map[string]models.CaddyRouteInfo
+	k8s    map[string]api.KubernetesRoute
+}
+
+// setCaddyRoute records the route serving the entry's hostname.
+func setCaddyRoute(entry *models.Entry, routeInfo models.CaddyRouteInfo) {
+	entry.CaddyUpstream = routeInfo.Upstream
+	entry.CaddyRoute = routeInfo
+
+	// Extract IP and port from upstream
+	// Upstream format: "10.0.0.112:8096" or "10.0.0.112"
+	if parts := strings.Split(routeInfo.Upstream, ":"); len(parts) >= 1 {
+		entry.CaddyIP = parts[0]
+		if len(parts) == 2 {
+			entry.CaddyPort = parts[1]
+		}
+	}
+}
+
 // resolveDNS performs a DNS lookup for the hostname via Unbound directly (if available),
 // falling back to the system resolver. This avoids Tailscale MagicDNS intercepting queries
-// and returning Tailscale IPs instead of the LAN IPs that Unbound serves.
-func (d *DataLoader) resolveDNS(hostname string) string {
+// and returning Tailscale IPs instead of the LAN IPs that Unbound serves. It returns the
+// first IPv4 address (or "NONE"/"FAIL") and the first IPv6 address, if any.
+func (d *DataLoader) resolveDNS(hostname string) (ipv4, ipv6 string) {
 	parent := d.ctx
 	if parent == nil {
 		parent = context.Background()
@@ -811,12 +1167,20 @@ func (d *DataLoader) resolveDNS(hostname string) string {
 
 	addrs, err := resolver.LookupHost(ctx, hostname)
 	if err != nil {
-		return "FAIL"
+		return "FAIL", ""
 	}
 
-	if len(addrs) == 0 {
-		return "NONE"
+	for _, addr := range addrs {
+		if models.IsIPv6(addr) {
+			if ipv6 == "" {
+				ipv6 = addr
+			}
+		} else if ipv4 == "" {
+			ipv4 = addr
+		}
 	}
-
-	return addrs[0]
+	if ipv4 == "" {
+		ipv4 = "NONE"
+	}
+	return ipv4, ipv6
 }
@@ -8,12 +8,18 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
 	"strconv"
 	"sync/atomic"
 	"testing"
 
 	"github.com/jeeftor/caddy-dns-sync/internal/api"
 	"github.com/jeeftor/caddy-dns-sync/internal/app"
+	"github.com/jeeftor/caddy-dns-sync/internal/caddyeditor"
+	"github.com/jeeftor/caddy-dns-sync/internal/config"
+	"github.com/jeeftor/caddy-dns-sync/internal/models"
 	"github.com/jeeftor/caddy-dns-sync/internal/syncplan"
 )
 
@@ -92,6 +98,44 @@ func TestLoadEntriesUsesLocalCaddyFixtureAndReportsOptionalServices(t *testing.T
 	}
 }
 
+func TestLoadEntriesLeavesDnsmasqHostsOutUnlessEnabled(t *testing.T) {
+	caddy := httptest.NewServer(fixtureHandler(t, map[string]string{
+		"/config/": "testdata/caddy_config.json",
+	}))
+	defer caddy.Close()
+
+	// No searchHost fixture: the Dnsmasq client only serves DHCP leases.
+	opnsense := httptest.NewTLSServer(fixtureHandler(t, map[string]string{
+		"/api/dnsmasq/leases/search": "testdata/dhcp_leases.json",
+	}))
+	defer opnsense.Close()
+
+	caddyHost, caddyPort := splitServerHostPort(t, caddy.URL)
+	entries, report, err := LoadEntries(context.Background(), app.ClientSet{
+		Caddy: api.NewCaddyClient(caddyHost, caddyPort),
+		DNSMasq: api.NewDNSMasqClient(api.Config{
+			APIKey:    "fixture-key",
+			APISecret: "fixture-secret",
+			BaseURL:   opnsense.URL,
+			Insecure:  true,
+		}),
+	}, Options{CaddyServerIP: "10.0.0.1"})
+	if err != nil {
+		t.Fatalf("LoadEntries failed: %v", err)
+	}
+	if report.Services["dnsmasq"].Status != ServiceSkipped {
+		t.Fatalf("expected dnsmasq hosts to be skipped without dnsmasq_hosts, got %#v", report.Services["dnsmasq"])
+	}
+	if report.Services[ServiceDHCP].Status != ServiceLoaded {
+		t.Fatalf("expected DHCP leases to load, got %#v", report.Services[ServiceDHCP])
+	}
+	for _, entry := range entries {
+		if _, ok := entry.TargetStatus["dnsmasq"]; ok {
+			t.Fatalf("expected %s to carry no dnsmasq status, got %#v", entry.Hostname, entry.TargetStatus)
+		}
+	}
+}
+
 func TestLoadPlanApplyWithNoLANFixtures(t *testing.T) {
 	caddy := httptest.NewServer(fixtureHandler(t, map[string]string{
 		"/config/": "testdata/caddy_config.json",
@@ -100,7 +144,9 @@ func TestLoadPlanApplyWithNoLANFixtures(t *testing.T) {
 
 	opnsense := httptest.NewTLSServer(fixtureHandler(t, map[string]string{
 		"/api/unbound/settings/searchHostOverride": "testdata/unbound_overrides.json",
+		"/api/unbound/settings/searchHostAlias":    "testdata/unbound_aliases.json",
 		"/api/dnsmasq/leases/search":               "testdata/dhcp_leases.json",
+		"/api/dnsmasq/settings/searchHost":         "testdata/dnsmasq_hosts.json",
 	}))
 	defer opnsense.Close()
 
@@ -125,6 +171,7 @@ func TestLoadPlanApplyWithNoLANFixtures(t *testing.T) {
 			BaseURL:   opnsense.URL,
 			Insecure:  true,
 		}),
+		DNSMasqHosts: true,
 		Adguard: api.NewAdguardClient(api.AdguardConfig{
 			BaseURL:  adguard.URL,
 			Username: "fixture-user",
@@ -142,7 +189,7 @@ func TestLoadPlanApplyWithNoLANFixtures(t *testing.T) {
 	if len(entries) != 3 {
 		t.Fatalf("expected three entries from fixtures, got %d", len(entries))
 	}
-	for _, service := range []ServiceName{ServiceCaddy, ServiceUnbound, ServiceAdguard, ServiceDHCP, ServiceDNS} {
+	for _, service := range []ServiceName{ServiceCaddy, ServiceUnbound, "dnsmasq", ServiceAdguard, ServiceDHCP, ServiceDNS} {
 		if report.Services[service].Status != ServiceLoaded {
 			t.Fatalf("expected %s loaded status, got %#v", service, report.Services[service])
 		}
@@ -174,6 +221,20 @@ func TestLoadPlanApplyWithNoLANFixtures(t *testing.T) {
 		t.Fatalf("unexpected unbound mutations: added=%#v deleted=%#v reconfigured=%t", unbound.added, unbound.deleted, unbound.reconfigured)
 	}
 
+	dnsmasqPlan := syncplan.BuildPlan(entries, syncplan.Options{
+		Service:       "dnsmasq",
+		CaddyServerIP: "10.0.0.1",
+	})
+	if len(dnsmasqPlan.Actions) != 2 {
+		t.Fatalf("expected two dnsmasq actions, got %#v", dnsmasqPlan.Actions)
+	}
+	allPlan := syncplan.BuildPlan(entries, syncplan.Options{CaddyServerIP: "10.0.0.1"})
+	for _, action := range allPlan.Actions {
+		if action.Service == "dnsmasq" {
+			t.Fatalf("expected dnsmasq to stay out of all plans by default, got %#v", action)
+		}
+	}
+
 	adguardPlan := syncplan.BuildPlan(entries, syncplan.Options{
 		Service:       "adguard",
 		CaddyServerIP: "10.0.0.1",
@@ -220,6 +281,732 @@ func TestLoadEntriesHonorsCancelledContextBeforeLoading(t *testing.T) {
 	}
 }
 
+func TestLoadEntriesFlagsForeignRecordsForOwnershipTrackers(t *testing.T) {
+	caddy := httptest.NewServer(fixtureHandler(t, map[string]string{
+		"/config/": "testdata/caddy_config.json",
+	}))
+	defer caddy.Close()
+
+	host, port := splitServerHostPort(t, caddy.URL)
+	entries, _, err := LoadEntries(context.Background(), app.ClientSet{
+		Caddy: api.NewCaddyClient(host, port),
+	}, Options{
+		CaddyServerIP: "10.0.0.15",
+		Targets: []syncplan.Target{&ownershipLister{records: []syncplan.Record{
+			{Hostname: "app.example.test", Answer: "10.0.0.15", Owned: true},
+			{Hostname: "stale.example.test", Answer: "10.0.0.50"},
+			{Hostname: "printer.example.test", Answer: "10.0.0.60"},
+		}}},
+	})
+	if err != nil {
+		t.Fatalf("LoadEntries failed: %v", err)
+	}
+
+	foreign := map[string]bool{}
+	for _, entry := range entries {
+		if entry.Hostname == "printer.example.test" {
+			t.Fatalf("expected no entry for a foreign record Caddy does not serve, got %#v", entry)
+		}
+		if status, ok := entry.TargetStatus["zone"]; ok && status.Configured {
+			foreign[entry.Hostname] = status.Foreign
+		}
+	}
+	want := map[string]bool{"app.example.test": false, "stale.example.test": true}
+	if !reflect.DeepEqual(foreign, want) {
+		t.Fatalf("foreign flags = %v, want %v", foreign, want)
+	}
+}
+
+func TestLoadEntriesReportsOnlyOwnedZoneRecordsAsStale(t *testing.T) {
+	caddy := httptest.NewServer(fixtureHandler(t, map[string]string{
+		"/config/": "testdata/caddy_config.json",
+	}))
+	defer caddy.Close()
+
+	host, port := splitServerHostPort(t, caddy.URL)
+	entries, _, err := LoadEntries(context.Background(), app.ClientSet{
+		Caddy: api.NewCaddyClient(host, port),
+	}, Options{
+		CaddyServerIP: "10.0.0.15",
+		Targets: []syncplan.Target{&ownershipLister{records: []syncplan.Record{
+			{Hostname: "gone.example.test", Answer: "10.0.0.15", Owned: true},
+			{Hostname: "router.example.test", Answer: "10.0.0.1"},
+		}}},
+	})
+	if err != nil {
+		t.Fatalf("LoadEntries failed: %v", err)
+	}
+
+	var stale []string
+	for _, entry := range entries {
+		if entry.OverallStatus == models.Stale {
+			stale = append(stale, entry.Hostname)
+		}
+	}
+	if !reflect.DeepEqual(stale, []string{"gone.example.test"}) {
+		t.Fatalf("stale hostnames = %v, want only the owned record", stale)
+	}
+}
+
+func TestLoadEntriesReportsUnboundInstancesIndependently(t *testing.T) {
+	caddy := httptest.NewServer(fixtureHandler(t, map[string]string{
+		"/config/": "testdata/caddy_config.json",
+	}))
+	defer caddy.Close()
+
+	site2 := httptest.NewTLSServer(fixtureHandler(t, map[string]string{
+		"/api/unbound/settings/searchHostOverride": "testdata/unbound_overrides.json",
+		"/api/unbound/settings/searchHostAlias":    "testdata/unbound_aliases.json",
+	}))
+	defer site2.Close()
+
+	fwB := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "firewall down", http.StatusBadGateway)
+	}))
+	defer fwB.Close()
+
+	instanceClient := func(baseURL string) *api.Client {
+		return api.NewClient(api.Config{APIKey: "fixture-key", APISecret: "fixture-secret", BaseURL: baseURL, Insecure: true})
+	}
+	host, port := splitServerHostPort(t, caddy.URL)
+	entries, report, err := LoadEntries(context.Background(), app.ClientSet{
+		Caddy: api.NewCaddyClient(host, port),
+		UnboundInstances: []app.UnboundInstance{
+			{Name: "fw-b", Client: instanceClient(fwB.URL)},
+			{Name: "site-2", TargetIP: "10.0.0.1", Client: instanceClient(site2.URL)},
+		},
+	}, Options{CaddyServerIP: "10.0.0.15"})
+	if err != nil {
+		t.Fatalf("LoadEntries failed: %v", err)
+	}
+
+	if got := report.Services["unbound:fw-b"].Status; got != ServiceFailed {
+		t.Fatalf("expected fw-b to fail, got %q", got)
+	}
+	if got := report.Services["unbound:site-2"]; got.Status != ServiceLoaded || got.Count != 2 {
+		t.Fatalf("expected site-2 to load two overrides, got %#v", got)
+	}
+
+	for _, entry := range entries {
+		if entry.Hostname != "app.example.test" {
+			continue
+		}
+		status, ok := entry.TargetStatus["unbound:site-2"]
+		if !ok || !status.Configured || !status.InSync {
+			t.Fatalf("expected app to be in sync with site-2's target IP, got %#v", entry.TargetStatus)
+		}
+		return
+	}
+	t.Fatal("app.example.test entry not found")
+}
+
+func TestLoadEntriesReportsAdguardReplicaDrift(t *testing.T) {
+	caddy := httptest.NewServer(fixtureHandler(t, map[string]string{
+		"/config/": "testdata/caddy_config.json",
+	}))
+	defer caddy.Close()
+
+	primary := httptest.NewServer(fixtureHandler(t, map[string]string{
+		"/control/rewrite/list": "testdata/adguard_rewrites.json",
+	}))
+	defer primary.Close()
+
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[{"domain":"app.example.test","answer":"10.20.0.15"}]`)
+	}))
+	defer secondary.Close()
+
+	adguardClient := func(baseURL string) *api.AdguardClient {
+		return api.NewAdguardClient(api.AdguardConfig{BaseURL: baseURL, Username: "user", Password: "pass"})
+	}
+	host, port := splitServerHostPort(t, caddy.URL)
+	entries, report, err := LoadEntries(context.Background(), app.ClientSet{
+		Caddy:                 api.NewCaddyClient(host, port),
+		Adguard:               adguardClient(primary.URL),
+		AdguardAnswerOverride: "10.0.0.1",
+		AdguardInstances: []app.AdguardInstance{
+			{Name: "secondary", AnswerOverride: "10.20.0.15", Client: adguardClient(secondary.URL)},
+		},
+	}, Options{CaddyServerIP: "10.0.0.15"})
+	if err != nil {
+		t.Fatalf("LoadEntries failed: %v", err)
+	}
+
+	for _, entry := range entries {
+		if entry.Hostname != "app.example.test" {
+			continue
+		}
+		if !entry.AdguardStatus.InSync {
+			t.Fatalf("expected primary to be in sync with its answer override, got %#v", entry.AdguardStatus)
+		}
+		if status := entry.TargetStatus["adguard:secondary"]; !status.InSync {
+			t.Fatalf("expected secondary to be in sync with its answer override, got %#v", status)
+		}
+	}
+
+	if len(report.Drift) != 1 {
+		t.Fatalf("expected drift on one hostname, got %#v", report.Drift)
+	}
+	drift := report.Drift[0]
+	if drift.Hostname != "stale.example.test" || drift.Service != "adguard" {
+		t.Fatalf("unexpected drift: %#v", drift)
+	}
+	if drift.States["adguard"] != "10.0.0.9" || drift.States["adguard:secondary"] != ReplicaMissing {
+		t.Fatalf("unexpected drift states: %#v", drift.States)
+	}
+}
+
+func TestLoadEntriesPlansFromCaddyfileSourceWithoutAdminAPI(t *testing.T) {
+	caddyfile := filepath.Join(t.TempDir(), "Caddyfile")
+	if err := os.WriteFile(caddyfile, []byte(`*.example.test {
+	@app host app.example.test
+	handle @app {
+		reverse_proxy http://10.0.0.5:8080
+	}
+
+	@new host new.example.test
+	handle @new {
+		reverse_proxy http://10.0.0.6:8080
+	}
+}
+`), 0o644); err != nil {
+		t.Fatalf("write Caddyfile: %v", err)
+	}
+	source := caddyeditor.NewCaddyfileSource(caddyfile)
+	source.CaddyBinary = filepath.Join(t.TempDir(), "missing-caddy")
+
+	adguard := httptest.NewServer(fixtureHandler(t, map[string]string{
+		"/control/rewrite/list": "testdata/adguard_rewrites.json",
+	}))
+	defer adguard.Close()
+
+	entries, report, err := LoadEntries(context.Background(), app.ClientSet{
+		// Nothing listens here; the source must be used instead.
+		Caddy:       api.NewCaddyClient("127.0.0.1", 1),
+		CaddySource: source,
+		Adguard:     api.NewAdguardClient(api.AdguardConfig{BaseURL: adguard.URL, Username: "user", Password: "pass"}),
+	}, Options{CaddyServerIP: "10.0.0.1"})
+	if err != nil {
+		t.Fatalf("LoadEntries failed: %v", err)
+	}
+	if got := report.Services[ServiceCaddy]; got.Status != ServiceLoaded || got.Count != 2 {
+		t.Fatalf("expected two Caddyfile hostnames, got %#v", got)
+	}
+
+	plan := syncplan.BuildPlan(entries, syncplan.Options{Service: "adguard", CaddyServerIP: "10.0.0.1"})
+	var got []string
+	for _, action := range plan.Actions {
+		got = append(got, action.Type+" "+action.Hostname)
+	}
+	sort.Strings(got)
+	want := []string{"add new.example.test", "delete stale.example.test"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected AdGuard plan from Caddyfile: got %v, want %v", got, want)
+	}
+}
+
+func TestLoadEntriesMergesCaddyServersAndFlagsConflicts(t *testing.T) {
+	caddy := httptest.NewServer(fixtureHandler(t, map[string]string{
+		"/config/": "testdata/caddy_config.json",
+	}))
+	defer caddy.Close()
+
+	host, port := splitServerHostPort(t, caddy.URL)
+	dmz := staticCaddySource{
+		"dmz.example.test": {Upstream: "10.50.0.20:8080"},
+		"app.example.test": {Upstream: "10.50.0.21:8080"},
+	}
+	entries, report, err := LoadEntries(context.Background(), app.ClientSet{
+		Caddy:        api.NewCaddyClient(host, port),
+		CaddyServers: []app.CaddyServer{{Name: "dmz", ServeIP: "10.50.0.15", Source: dmz}},
+	}, Options{CaddyServerIP: "10.0.0.1"})
+	if err != nil {
+		t.Fatalf("LoadEntries failed: %v", err)
+	}
+
+	byHostname := make(map[string]*models.Entry)
+	for _, entry := range entries {
+		byHostname[entry.Hostname] = entry
+	}
+	if e := byHostname["dmz.example.test"]; e == nil || e.CaddyServer != "dmz" || e.CaddyServerIP != "10.50.0.15" {
+		t.Fatalf("expected dmz.example.test to be owned by the DMZ Caddy, got %#v", e)
+	}
+	primary := byHostname["app.example.test"]
+	if primary.CaddyServer != "default" || primary.CaddyServerIP != "10.0.0.1" || primary.CaddyUpstream != "10.0.0.5:8080" {
+		t.Fatalf("expected the main Caddy to own app.example.test, got %#v", primary)
+	}
+	if want := []string{"default", "dmz"}; !reflect.DeepEqual(report.Conflicts, []CaddyConflict{{Hostname: "app.example.test", Servers: want}}) {
+		t.Fatalf("unexpected conflicts: %#v", report.Conflicts)
+	}
+
+	plan := syncplan.BuildPlan(entries, syncplan.Options{
+		Service:       "unbound",
+		CaddyServerIP: "10.0.0.1",
+	})
+	var dmzAction *syncplan.Action
+	for i, action := range plan.Actions {
+		if action.Hostname == "dmz.example.test" {
+			dmzAction = &plan.Actions[i]
+		}
+	}
+	if dmzAction == nil || dmzAction.NewIP != "10.50.0.15" {
+		t.Fatalf("expected dmz.example.test to point at the DMZ Caddy, got %#v", plan.Actions)
+	}
+
+	failing := staticCaddySource(nil)
+	_, _, err = LoadEntries(context.Background(), app.ClientSet{
+		Caddy:        api.NewCaddyClient(host, port),
+		CaddyServers: []app.CaddyServer{{Name: "dmz", Source: failing}},
+	}, Options{CaddyServerIP: "10.0.0.1"})
+	if err == nil {
+		t.Fatal("expected an unreachable Caddy server to fail the load")
+	}
+}
+
+func TestLoadEntriesAddsDockerLabelHostnames(t *testing.T) {
+	caddy := httptest.NewServer(fixtureHandler(t, map[string]string{
+		"/config/": "testdata/caddy_config.json",
+	}))
+	defer caddy.Close()
+
+	socket := filepath.Join(t.TempDir(), "docker.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("listen on unix socket: %v", err)
+	}
+	docker := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"Id":"c1","Names":["/app"],"Labels":{"caddy":"app.example.test","caddy.reverse_proxy":"{{upstreams 80}}"},
+			 "NetworkSettings":{"Networks":{"proxy":{"IPAddress":"172.18.0.4"}}}},
+			{"Id":"c2","Names":["/wiki"],"Labels":{"caddy":"wiki.example.test","caddy.reverse_proxy":"{{upstreams 3000}}"},
+			 "NetworkSettings":{"Networks":{"proxy":{"IPAddress":"172.18.0.5"}}}}
+		]`)
+	}))
+	docker.Listener = listener
+	docker.Start()
+	defer docker.Close()
+
+	dockerClient, err := api.NewDockerClient(api.DockerConfig{Host: "unix://" + socket})
+	if err != nil {
+		t.Fatalf("NewDockerClient failed: %v", err)
+	}
+	host, port := splitServerHostPort(t, caddy.URL)
+	entries, report, err := LoadEntries(context.Background(), app.ClientSet{
+		Caddy:  api.NewCaddyClient(host, port),
+		Docker: dockerClient,
+	}, Options{CaddyServerIP: "10.0.0.1"})
+	if err != nil {
+		t.Fatalf("LoadEntries failed: %v", err)
+	}
+	if got := report.Services[ServiceDocker]; got.Status != ServiceLoaded || got.Count != 2 {
+		t.Fatalf("unexpected docker report: %#v", got)
+	}
+
+	byHostname := make(map[string]*models.Entry)
+	for _, entry := range entries {
+		byHostname[entry.Hostname] = entry
+	}
+	if e := byHostname["app.example.test"]; e.DataSource != "Caddy" || e.CaddyUpstream != "10.0.0.5:8080" {
+		t.Fatalf("expected Caddy to win for app.example.test, got %#v", e)
+	}
+	wiki := byHostname["wiki.example.test"]
+	if wiki == nil || wiki.DataSource != "docker" || wiki.CaddyUpstream != "172.18.0.5:3000" || wiki.CaddyIP != "172.18.0.5" {
+		t.Fatalf("expected a docker entry for wiki.example.test, got %#v", wiki)
+	}
+
+	plan := syncplan.BuildPlan(entries, syncplan.Options{Service: "unbound", CaddyServerIP: "10.0.0.1"})
+	planned := false
+	for _, action := range plan.Actions {
+		if action.Hostname == "wiki.example.test" && action.Type == "add" && action.NewIP == "10.0.0.1" {
+			planned = true
+		}
+	}
+	if !planned {
+		t.Fatalf("expected an add action for the docker hostname, got %#v", plan.Actions)
+	}
+
+	docker.Close()
+	if _, _, err := LoadEntries(context.Background(), app.ClientSet{
+		Caddy:  api.NewCaddyClient(host, port),
+		Docker: dockerClient,
+	}, Options{CaddyServerIP: "10.0.0.1"}); err == nil {
+		t.Fatal("expected an unreachable Docker host to fail the load")
+	}
+}
+
+func TestLoadEntriesAddsKubernetesIngressHostnames(t *testing.T) {
+	caddy := httptest.NewServer(fixtureHandler(t, map[string]string{
+		"/config/": "testdata/caddy_config.json",
+	}))
+	defer caddy.Close()
+
+	cluster := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/apis/networking.k8s.io/v1/ingresses":
+			fmt.Fprint(w, `{"metadata":{},"items":[
+				{"metadata":{"name":"wiki","namespace":"apps"},
+				 "spec":{"rules":[{"host":"wiki.example.test","http":{"paths":[{"backend":{"service":{"name":"wiki","port":{"number":80}}}}]}}]},
+				 "status":{"loadBalancer":{"ingress":[{"ip":"10.0.0.40"}]}}}
+			]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer cluster.Close()
+
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(kubeconfig, []byte(`current-context: k3s
+clusters: [{name: k3s, cluster: {server: "`+cluster.URL+`", insecure-skip-tls-verify: true}}]
+users: [{name: k3s, user: {token: fixture}}]
+contexts: [{name: k3s, context: {cluster: k3s, user: k3s}}]
+`), 0o600); err != nil {
+		t.Fatalf("write kubeconfig: %v", err)
+	}
+	k8s, err := api.NewKubernetesClient(api.KubernetesConfig{Kubeconfig: kubeconfig})
+	if err != nil {
+		t.Fatalf("NewKubernetesClient failed: %v", err)
+	}
+
+	host, port := splitServerHostPort(t, caddy.URL)
+	entries, report, err := LoadEntries(context.Background(), app.ClientSet{
+		Caddy:      api.NewCaddyClient(host, port),
+		Kubernetes: k8s,
+	}, Options{CaddyServerIP: "10.0.0.1"})
+	if err != nil {
+		t.Fatalf("LoadEntries failed: %v", err)
+	}
+	if got := report.Services[ServiceKubernetes]; got.Status != ServiceLoaded || got.Count != 1 {
+		t.Fatalf("unexpected kubernetes report: %#v", got)
+	}
+
+	var wiki *models.Entry
+	for _, entry := range entries {
+		if entry.Hostname == "wiki.example.test" {
+			wiki = entry
+		}
+	}
+	if wiki == nil || wiki.DataSource != "kubernetes" || wiki.CaddyServerIP != "10.0.0.40" || wiki.CaddyUpstream != "wiki.apps.svc:80" {
+		t.Fatalf("expected a kubernetes entry served by the ingress LoadBalancer, got %#v", wiki)
+	}
+
+	plan := syncplan.BuildPlan(entries, syncplan.Options{Service: "unbound", CaddyServerIP: "10.0.0.1"})
+	planned := false
+	for _, action := range plan.Actions {
+		if action.Hostname == "wiki.example.test" && action.Type == "add" && action.NewIP == "10.0.0.40" {
+			planned = true
+		}
+	}
+	if !planned {
+		t.Fatalf("expected an add action pointing at the LoadBalancer IP, got %#v", plan.Actions)
+	}
+}
+
+func TestLoadEntriesPlansManifestHostsAndKeepsThemOutOfCleanup(t *testing.T) {
+	caddy := httptest.NewServer(fixtureHandler(t, map[string]string{
+		"/config/": "testdata/caddy_config.json",
+	}))
+	defer caddy.Close()
+
+	opnsense := httptest.NewTLSServer(fixtureHandler(t, map[string]string{
+		"/api/unbound/settings/searchHostOverride": "testdata/unbound_overrides.json",
+		"/api/unbound/settings/searchHostAlias":    "testdata/unbound_aliases.json",
+		"/api/dnsmasq/leases/search":               "testdata/dhcp_leases.json",
+		"/api/dnsmasq/settings/searchHost":         "testdata/dnsmasq_hosts.json",
+	}))
+	defer opnsense.Close()
+	opnsenseConfig := api.Config{
+		APIKey:    "fixture-key",
+		APISecret: "fixture-secret",
+		BaseURL:   opnsense.URL,
+		Insecure:  true,
+	}
+
+	host, port := splitServerHostPort(t, caddy.URL)
+	entries, _, err := LoadEntries(context.Background(), app.ClientSet{
+		Caddy:   api.NewCaddyClient(host, port),
+		Unbound: api.NewClient(opnsenseConfig),
+		DNSMasq: api.NewDNSMasqClient(opnsenseConfig),
+		Manifest: []config.ManifestHost{
+			{Hostname: "old.example.test", IP: "10.0.0.1"},
+			{Hostname: "printer.example.test", MAC: "66:55:44:33:22:11"},
+		},
+	}, Options{CaddyServerIP: "10.0.0.1"})
+	if err != nil {
+		t.Fatalf("LoadEntries failed: %v", err)
+	}
+
+	byHostname := make(map[string]*models.Entry, len(entries))
+	for _, entry := range entries {
+		byHostname[entry.Hostname] = entry
+	}
+	printer := byHostname["printer.example.test"]
+	if printer == nil || !printer.Static || printer.DataSource != "manifest" || printer.StaticIP != "10.0.0.6" {
+		t.Fatalf("expected the printer to follow its DHCP lease, got %#v", printer)
+	}
+	if old := byHostname["old.example.test"]; old == nil || !old.Static || old.StaticIP != "10.0.0.1" {
+		t.Fatalf("expected the manifest to claim the existing override, got %#v", old)
+	}
+
+	plan := syncplan.BuildPlan(entries, syncplan.Options{Service: "unbound", CaddyServerIP: "10.0.0.1"})
+	addedPrinter := false
+	for _, action := range plan.Actions {
+		if action.Hostname == "old.example.test" {
+			t.Fatalf("expected the manifest host to be kept, got %#v", action)
+		}
+		if action.Hostname == "printer.example.test" && action.Type == "add" && action.NewIP == "10.0.0.6" {
+			addedPrinter = true
+		}
+	}
+	if !addedPrinter {
+		t.Fatalf("expected an add action at the leased IP, got %#v", plan.Actions)
+	}
+}
+
+func TestLoadEntriesAppliesAnswerRules(t *testing.T) {
+	caddy := httptest.NewServer(fixtureHandler(t, map[string]string{
+		"/config/": "testdata/caddy_config.json",
+	}))
+	defer caddy.Close()
+
+	opnsense := httptest.NewTLSServer(fixtureHandler(t, map[string]string{
+		"/api/unbound/settings/searchHostOverride": "testdata/unbound_overrides.json",
+		"/api/unbound/settings/searchHostAlias":    "testdata/unbound_aliases.json",
+	}))
+	defer opnsense.Close()
+
+	host, port := splitServerHostPort(t, caddy.URL)
+	entries, _, err := LoadEntries(context.Background(), app.ClientSet{
+		Caddy: api.NewCaddyClient(host, port),
+		Unbound: api.NewClient(api.Config{
+			APIKey:    "fixture-key",
+			APISecret: "fixture-secret",
+			BaseURL:   opnsense.URL,
+			Insecure:  true,
+		}),
+		AnswerRules: []config.AnswerRule{
+			{Hostname: "app.example.test", Answer: config.AnswerUpstream, Targets: []string{"unbound"}},
+			{Hostname: "stale.*", Handler: "reverse_proxy", Answer: "10.0.0.99"},
+		},
+	}, Options{CaddyServerIP: "10.0.0.1"})
+	if err != nil {
+		t.Fatalf("LoadEntries failed: %v", err)
+	}
+
+	byHostname := make(map[string]*models.Entry, len(entries))
+	for _, entry := range entries {
+		byHostname[entry.Hostname] = entry
+	}
+	app := byHostname["app.example.test"]
+	if app == nil || app.UnboundStatus.InSync || app.DNSAnswer != nil || app.AnswerFor("unbound", models.Answer{}).IPv4 != "10.0.0.5" {
+		t.Fatalf("expected app to need its upstream IP in Unbound only, got %#v", app)
+	}
+	if stale := byHostname["stale.example.test"]; stale == nil || stale.ExpectedIP() != "10.0.0.99" {
+		t.Fatalf("expected the literal answer for stale, got %#v", stale)
+	}
+
+	plan := syncplan.BuildPlan(entries, syncplan.Options{Service: "unbound", CaddyServerIP: "10.0.0.1"})
+	answers := make(map[string]string)
+	for _, action := range plan.Actions {
+		answers[action.Type+" "+action.Hostname] = action.NewIP
+	}
+	if answers["update app.example.test"] != "10.0.0.5" || answers["add stale.example.test"] != "10.0.0.99" {
+		t.Fatalf("expected the plan to use rule answers, got %#v", plan.Actions)
+	}
+}
+
+func TestLoadEntriesComparesAAAARecords(t *testing.T) {
+	caddy := httptest.NewServer(fixtureHandler(t, map[string]string{
+		"/config/": "testdata/caddy_config.json",
+	}))
+	defer caddy.Close()
+
+	opnsense := httptest.NewTLSServer(fixtureHandler(t, map[string]string{
+		"/api/unbound/settings/searchHostOverride": "testdata/unbound_overrides_aaaa.json",
+		"/api/unbound/settings/searchHostAlias":    "testdata/unbound_aliases.json",
+	}))
+	defer opnsense.Close()
+
+	host, port := splitServerHostPort(t, caddy.URL)
+	entries, _, err := LoadEntries(context.Background(), app.ClientSet{
+		Caddy: api.NewCaddyClient(host, port),
+		Unbound: api.NewClient(api.Config{
+			APIKey:    "fixture-key",
+			APISecret: "fixture-secret",
+			BaseURL:   opnsense.URL,
+			Insecure:  true,
+		}),
+	}, Options{CaddyServerIP: "10.0.0.1", CaddyServerIPv6: "fd00::1"})
+	if err != nil {
+		t.Fatalf("LoadEntries failed: %v", err)
+	}
+
+	byHostname := make(map[string]*models.Entry, len(entries))
+	for _, entry := range entries {
+		byHostname[entry.Hostname] = entry
+	}
+	app := byHostname["app.example.test"]
+	if app == nil || !app.UnboundStatus.Matches() || app.UnboundStatus.IPv6 != "fd00::1" {
+		t.Fatalf("expected app to match in both families, got %#v", app)
+	}
+	if stale := byHostname["stale.example.test"]; stale == nil || !stale.UnboundStatus.IPv6Mismatch {
+		t.Fatalf("expected stale to be missing its AAAA record, got %#v", stale)
+	}
+	if old := byHostname["old.example.test"]; old == nil || !old.UnboundStatus.Configured || old.UnboundStatus.IP != "" {
+		t.Fatalf("expected old to have only an AAAA record, got %#v", old)
+	}
+
+	plan := syncplan.BuildPlan(entries, syncplan.Options{Service: "unbound", CaddyServerIP: "10.0.0.1"})
+	got := make([]string, 0, len(plan.Actions))
+	for _, action := range plan.Actions {
+		got = append(got, fmt.Sprintf("%s %s %s %s", action.Type, action.RecordType, action.Hostname, action.NewIP))
+	}
+	sort.Strings(got)
+	want := []string{
+		"add  stale.example.test 10.0.0.1",
+		"add AAAA stale.example.test fd00::1",
+		"delete AAAA old.example.test ",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected AAAA plan: got %q, want %q", got, want)
+	}
+}
+
+func TestLoadEntriesExpectsAliasesInAliasMode(t *testing.T) {
+	caddy := httptest.NewServer(fixtureHandler(t, map[string]string{
+		"/config/": "testdata/caddy_config.json",
+	}))
+	defer caddy.Close()
+
+	opnsense := httptest.NewTLSServer(fixtureHandler(t, map[string]string{
+		"/api/unbound/settings/searchHostOverride": "testdata/unbound_overrides_alias.json",
+		"/api/unbound/settings/searchHostAlias":    "testdata/unbound_aliases_canonical.json",
+	}))
+	defer opnsense.Close()
+
+	host, port := splitServerHostPort(t, caddy.URL)
+	entries, _, err := LoadEntries(context.Background(), app.ClientSet{
+		Caddy: api.NewCaddyClient(host, port),
+		Unbound: api.NewClient(api.Config{
+			APIKey:    "fixture-key",
+			APISecret: "fixture-secret",
+			BaseURL:   opnsense.URL,
+			Insecure:  true,
+		}),
+		AliasCanonical: "caddy.example.test",
+	}, Options{CaddyServerIP: "10.0.0.1"})
+	if err != nil {
+		t.Fatalf("LoadEntries failed: %v", err)
+	}
+
+	byHostname := make(map[string]*models.Entry, len(entries))
+	for _, entry := range entries {
+		byHostname[entry.Hostname] = entry
+	}
+	canonical := byHostname["caddy.example.test"]
+	if canonical == nil || !canonical.Static || canonical.Alias != "" || !canonical.UnboundStatus.InSync {
+		t.Fatalf("expected the canonical record to hold the Caddy IP, got %#v", canonical)
+	}
+	if stale := byHostname["stale.example.test"]; stale == nil || stale.Alias != "caddy.example.test" || !stale.UnboundStatus.InSync {
+		t.Fatalf("expected the existing alias to be in sync, got %#v", stale)
+	}
+	app := byHostname["app.example.test"]
+	if app == nil || app.UnboundStatus.InSync || app.ExpectedIP() != "10.0.0.1" {
+		t.Fatalf("expected app's A record to need converting, got %#v", app)
+	}
+
+	plan := syncplan.BuildPlan(entries, syncplan.Options{Service: "unbound", CaddyServerIP: "10.0.0.1"})
+	if len(plan.Actions) != 1 {
+		t.Fatalf("expected one action, got %#v", plan.Actions)
+	}
+	if action := plan.Actions[0]; action.Type != "update" || action.Hostname != "app.example.test" || action.OldIP != "10.0.0.1" || action.NewIP != "caddy.example.test" {
+		t.Fatalf("expected app to become an alias, got %#v", action)
+	}
+}
+
+func TestLoadEntriesTreatsExistingAliasesAsConfigured(t *testing.T) {
+	caddy := httptest.NewServer(fixtureHandler(t, map[string]string{
+		"/config/": "testdata/caddy_config.json",
+	}))
+	defer caddy.Close()
+
+	opnsense := httptest.NewTLSServer(fixtureHandler(t, map[string]string{
+		"/api/unbound/settings/searchHostOverride": "testdata/unbound_overrides_alias.json",
+		"/api/unbound/settings/searchHostAlias":    "testdata/unbound_aliases_canonical.json",
+	}))
+	defer opnsense.Close()
+
+	host, port := splitServerHostPort(t, caddy.URL)
+	entries, _, err := LoadEntries(context.Background(), app.ClientSet{
+		Caddy: api.NewCaddyClient(host, port),
+		Unbound: api.NewClient(api.Config{
+			APIKey:    "fixture-key",
+			APISecret: "fixture-secret",
+			BaseURL:   opnsense.URL,
+			Insecure:  true,
+		}),
+	}, Options{CaddyServerIP: "10.0.0.1"})
+	if err != nil {
+		t.Fatalf("LoadEntries failed: %v", err)
+	}
+
+	var stale *models.Entry
+	for _, entry := range entries {
+		if entry.Hostname == "stale.example.test" {
+			stale = entry
+		}
+	}
+	if stale == nil {
+		t.Fatal("expected stale.example.test entry")
+	}
+	status := stale.UnboundStatus
+	if !status.Configured || !status.InSync || !status.IsAlias() || status.IP != "caddy.example.test" {
+		t.Fatalf("expected the alias of the canonical override to be in sync, got %#v", status)
+	}
+	if stale.OverallStatus == models.CaddyOnly {
+		t.Fatalf("expected an aliased hostname not to be reported as Caddy Only")
+	}
+
+	plan := syncplan.BuildPlan(entries, syncplan.Options{Service: "unbound", CaddyServerIP: "10.0.0.1"})
+	for _, action := range plan.Actions {
+		if action.Hostname == "stale.example.test" {
+			t.Fatalf("expected no action for the aliased hostname, got %#v", action)
+		}
+	}
+}
+
+// staticCaddySource is an api.HostnameSource with fixed routes; nil fails.
+type staticCaddySource map[string]models.CaddyRouteInfo
+
+func (s staticCaddySource) GetHostnameDetails() (map[string]models.CaddyRouteInfo, error) {
+	if s == nil {
+		return nil, fmt.Errorf("connection refused")
+	}
+	routes := make(map[string]models.CaddyRouteInfo, len(s))
+	for hostname, route := range s {
+		routes[hostname] = route
+	}
+	return routes, nil
+}
+
+type ownershipLister struct {
+	records []syncplan.Record
+}
+
+func (l *ownershipLister) Name() string          { return "zone" }
+func (l *ownershipLister) Label() string         { return "Zone" }
+func (l *ownershipLister) Available() bool       { return true }
+func (l *ownershipLister) TracksOwnership() bool { return true }
+func (l *ownershipLister) Diff(*models.Entry, syncplan.Options) syncplan.Action {
+	return syncplan.Action{}
+}
+func (l *ownershipLister) Apply(context.Context, syncplan.Action) error { return nil }
+func (l *ownershipLister) Commit(context.Context) (string, error)       { return "", nil }
+
+func (l *ownershipLister) Records(context.Context) ([]syncplan.Record, error) {
+	return l.records, nil
+}
+
 func splitServerHostPort(t *testing.T, rawURL string) (string, int) {
 	t.Helper()
 	parsed, err := url.Parse(rawURL)
@@ -306,6 +1093,10 @@ type fixtureApplyAdguard struct {
 	deleted []api.Rewrite
 }
 
+func (f *fixtureApplyAdguard) ListRewrites() ([]api.Rewrite, error) {
+	return f.added, nil
+}
+
 func (f *fixtureApplyAdguard) AddRewrite(domain, answer string) error {
 	f.added = append(f.added, api.Rewrite{Domain: domain, Answer: answer})
 	return nil
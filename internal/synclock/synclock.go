@@ -0,0 +1,82 @@
+// Package synclock provides the file lock that keeps syncs from overlapping,
+// whether they run from the CLI, the watch daemon or a web webhook.
+package synclock
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// DefaultTimeout is how long AcquireWithWait callers usually wait for a
+// running sync to finish.
+const DefaultTimeout = 30 * time.Second
+
+// Acquire acquires an exclusive file lock to prevent concurrent syncs.
+// Returns a cleanup function that releases the lock.
+func Acquire() (func(), error) {
+	lockDir := Dir()
+	if err := os.MkdirAll(lockDir, 0o700); err != nil {
+		return nil, fmt.Errorf("creating lock directory: %w", err)
+	}
+	lockPath := filepath.Join(lockDir, "sync.lock")
+
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("opening lock file: %w", err)
+	}
+
+	// Non-blocking lock attempt.
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		// Read the PID from the existing lock file for a helpful message.
+		data, _ := os.ReadFile(lockPath)
+		pid := string(data)
+		f.Close()
+		if pid != "" {
+			return nil, fmt.Errorf("another sync is already running (PID %s) — remove %s if stale", pid, lockPath)
+		}
+		return nil, fmt.Errorf("another sync is already running — remove %s if stale", lockPath)
+	}
+
+	// Write our PID.
+	if err := f.Truncate(0); err != nil {
+		return nil, fmt.Errorf("truncate lock file: %w", err)
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		return nil, fmt.Errorf("seek lock file: %w", err)
+	}
+	if _, err := fmt.Fprintf(f, "%d\n", os.Getpid()); err != nil {
+		return nil, fmt.Errorf("write PID to lock file: %w", err)
+	}
+
+	cleanup := func() {
+		_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		_ = f.Close()
+	}
+	return cleanup, nil
+}
+
+// Dir returns the directory for the sync lock file.
+func Dir() string {
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".local", "share", "caddy-dns-sync")
+	}
+	return filepath.Join(os.TempDir(), "caddy-dns-sync")
+}
+
+// AcquireWithWait tries to acquire the sync lock, waiting up to timeout.
+func AcquireWithWait(timeout time.Duration) (func(), error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		cleanup, err := Acquire()
+		if err == nil {
+			return cleanup, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, err
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
@@ -0,0 +1,53 @@
+package syncplan
+
+import (
+	"net"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/config"
+	"github.com/jeeftor/caddy-dns-sync/internal/models"
+)
+
+// AnswerRules decides which address a hostname's DNS records point at. Rules
+// are tried in order and the first one matching the entry and target wins.
+type AnswerRules []config.AnswerRule
+
+// Answer returns the addresses target should answer with for entry, where
+// caddy holds the Caddy addresses that target would use without rules. An
+// empty target matches only rules that name no targets. Upstream and literal
+// answers carry a single address, so the other family gets no record. ok is
+// false when no rule applies, including for hostnames Caddy does not serve
+// and for "upstream" rules whose upstream is not an IP address.
+func (r AnswerRules) Answer(entry *models.Entry, target string, caddy models.Answer) (answer models.Answer, ok bool) {
+	if entry == nil || !entry.IsConfiguredInCaddy() || entry.Static {
+		return models.Answer{}, false
+	}
+	hostname := strings.ToLower(strings.TrimSuffix(entry.Hostname, "."))
+	for _, rule := range r {
+		if len(rule.Targets) > 0 && !slices.Contains(rule.Targets, target) {
+			continue
+		}
+		if rule.Hostname != "" {
+			if matched, _ := path.Match(strings.ToLower(rule.Hostname), hostname); !matched {
+				continue
+			}
+		}
+		if rule.Handler != "" && !slices.Contains(entry.CaddyRoute.HandlerChain, rule.Handler) {
+			continue
+		}
+		switch rule.Answer {
+		case config.AnswerCaddy:
+			return caddy, true
+		case config.AnswerUpstream:
+			if net.ParseIP(entry.CaddyIP) == nil {
+				continue
+			}
+			return models.Answer{}.With(entry.CaddyIP), true
+		default:
+			return models.Answer{}.With(rule.Answer), true
+		}
+	}
+	return models.Answer{}, false
+}
@@ -0,0 +1,57 @@
+package syncplan
+
+import (
+	"testing"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/config"
+	"github.com/jeeftor/caddy-dns-sync/internal/models"
+)
+
+func TestAnswerRulesPickFirstMatchingRule(t *testing.T) {
+	rules := AnswerRules{
+		{Hostname: "*.games.example.com", Answer: config.AnswerUpstream},
+		{Handler: "layer4", Answer: config.AnswerUpstream},
+		{Hostname: "nas.example.com", Answer: "192.168.20.5", Targets: []string{"unbound:iot"}},
+		{Hostname: "nas.example.com", Answer: config.AnswerCaddy},
+	}
+	entry := func(hostname, upstream string, handlers ...string) *models.Entry {
+		return &models.Entry{
+			Hostname:      hostname,
+			CaddyUpstream: upstream + ":443",
+			CaddyIP:       upstream,
+			CaddyRoute:    models.CaddyRouteInfo{HandlerChain: handlers},
+		}
+	}
+
+	caddy := models.Answer{IPv4: "10.0.0.15", IPv6: "fd00::15"}
+
+	tests := []struct {
+		name   string
+		entry  *models.Entry
+		target string
+		want   models.Answer
+		ok     bool
+	}{
+		{"hostname glob", entry("Minecraft.Games.example.com", "10.0.0.40", "reverse_proxy"), "unbound", models.Answer{IPv4: "10.0.0.40"}, true},
+		{"handler type", entry("mqtt.example.com", "10.0.0.41", "layer4"), "adguard", models.Answer{IPv4: "10.0.0.41"}, true},
+		{"target scoped", entry("nas.example.com", "10.0.0.42", "reverse_proxy"), "unbound:iot", models.Answer{IPv4: "192.168.20.5"}, true},
+		{"target scoped elsewhere", entry("nas.example.com", "10.0.0.42", "reverse_proxy"), "unbound", caddy, true},
+		{"untargeted lookup skips scoped rules", entry("nas.example.com", "10.0.0.42", "reverse_proxy"), "", caddy, true},
+		{"upstream without an IP", entry("lobby.games.example.com", "lobby", "reverse_proxy"), "unbound", models.Answer{}, false},
+		{"upstream over IPv6", entry("voice.games.example.com", "fd00::41", "reverse_proxy"), "unbound", models.Answer{IPv6: "fd00::41"}, true},
+		{"no rule", entry("app.example.com", "10.0.0.43", "reverse_proxy"), "unbound", models.Answer{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := rules.Answer(tt.entry, tt.target, caddy)
+			if got != tt.want || ok != tt.ok {
+				t.Fatalf("Answer() = %+v, %t; want %+v, %t", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+
+	static := &models.Entry{Hostname: "printer.games.example.com", Static: true, StaticIP: "10.0.0.50"}
+	if _, ok := rules.Answer(static, "unbound", caddy); ok {
+		t.Fatal("expected manifest hosts to keep their own answer")
+	}
+}
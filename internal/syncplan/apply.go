@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/jeeftor/caddy-dns-sync/internal/api"
+	"github.com/jeeftor/caddy-dns-sync/internal/app"
 )
 
 type UnboundClient interface {
@@ -16,12 +18,52 @@ type UnboundClient interface {
 	ApplyChanges() error
 }
 
+// UnboundAliasClient is implemented by Unbound clients that manage host
+// aliases, which alias mode creates instead of per-hostname overrides.
+type UnboundAliasClient interface {
+	GetHostAliases() ([]api.HostAlias, error)
+	AddHostAlias(api.HostAlias) (string, error)
+	DeleteHostAlias(uuid string) error
+}
+
+type DNSMasqClient interface {
+	GetHosts() ([]api.DNSMasqHost, error)
+	AddHost(api.DNSMasqHost) (string, error)
+	UpdateHost(api.DNSMasqHost) error
+	DeleteHost(uuid string) error
+	Reconfigure() error
+}
+
+// DHCPClient creates static DHCP reservations. It is implemented by both the
+// Dnsmasq and Kea clients.
+type DHCPClient interface {
+	AddReservation(api.DHCPReservation) error
+	Reconfigure() error
+}
+
 type AdguardClient interface {
+	ListRewrites() ([]api.Rewrite, error)
 	AddRewrite(domain, answer string) error
 	UpdateRewrite(target, update api.Rewrite) error
 	DeleteRewrite(domain, answer string) error
 }
 
+type PiholeClient interface {
+	ListHosts() ([]api.PiholeHost, error)
+	AddHost(hostname, ip string) error
+	UpdateHost(hostname, oldIP, newIP string) error
+	DeleteHost(hostname, ip string) error
+	ListCNAMEs() ([]api.PiholeCNAME, error)
+	DeleteCNAME(record api.PiholeCNAME) error
+}
+
+type RFC2136Client interface {
+	ListRecords() ([]api.RFC2136Record, error)
+	AddHost(hostname, ip string) error
+	UpdateHost(hostname, oldIP, newIP string) error
+	DeleteHost(hostname, ip string) error
+}
+
 type CloudflareClient interface {
 	UpdateTunnelRule(api.IngressRuleSpec) error
 	DeleteTunnelRule(hostname string) error
@@ -30,15 +72,132 @@ type CloudflareClient interface {
 }
 
 // Clients contains service clients used to apply a sync plan.
+// Unbound, Dnsmasq, AdGuard, Pi-hole, RFC 2136, DHCP and Cloudflare back the
+// built-in targets; Targets holds any additional registered backends.
 type Clients struct {
 	Unbound    UnboundClient
+	DNSMasq    DNSMasqClient
 	Adguard    AdguardClient
+	Pihole     PiholeClient
+	RFC2136    RFC2136Client
+	DHCP       DHCPClient
 	Cloudflare CloudflareClient
+	Targets    []Target
+	// AdguardAnswer, when set, replaces the Caddy server IP in the primary
+	// AdGuard target's rewrites.
+	AdguardAnswer string
+}
+
+// NewClients builds Clients from a runtime client set, leaving the interface
+// fields nil (rather than holding a typed nil pointer) for missing clients.
+func NewClients(set app.ClientSet) Clients {
+	var clients Clients
+	if set.Unbound != nil {
+		clients.Unbound = set.Unbound
+	}
+	if set.DNSMasq != nil && set.DNSMasqHosts {
+		clients.DNSMasq = set.DNSMasq
+	}
+	if set.Adguard != nil {
+		clients.Adguard = set.Adguard
+		clients.AdguardAnswer = set.AdguardAnswerOverride
+	}
+	if set.Pihole != nil {
+		clients.Pihole = set.Pihole
+	}
+	if set.RFC2136 != nil {
+		clients.RFC2136 = set.RFC2136
+	}
+	switch {
+	case set.Kea != nil:
+		clients.DHCP = set.Kea
+	case set.DNSMasq != nil:
+		clients.DHCP = set.DNSMasq
+	}
+	if set.Cloudflare != nil {
+		clients.Cloudflare = set.Cloudflare
+	}
+	for _, instance := range set.UnboundInstances {
+		if instance.Client != nil {
+			clients.Targets = append(clients.Targets, NewUnboundInstanceTarget(instance.Name, instance.TargetIP, instance.Client))
+		}
+	}
+	for _, instance := range set.AdguardInstances {
+		if instance.Client != nil {
+			clients.Targets = append(clients.Targets, NewAdguardInstanceTarget(instance.Name, instance.AnswerOverride, instance.Client))
+		}
+	}
+	return clients
+}
+
+// Registry returns the built-in targets backed by these clients followed by
+// any additional Targets.
+func (c Clients) Registry() *Registry {
+	registry := NewRegistry(
+		NewUnboundTarget(c.Unbound),
+		NewAdguardInstanceTarget("", c.AdguardAnswer, c.Adguard),
+		NewDNSMasqTarget(c.DNSMasq),
+		NewPiholeTarget(c.Pihole),
+		NewRFC2136Target(c.RFC2136),
+		NewDHCPTarget(c.DHCP),
+		NewCloudflareTarget(c.Cloudflare),
+		NewUnboundDomainTarget(c.unboundDomains()),
+	)
+	for _, target := range c.Targets {
+		registry.Register(target)
+	}
+	return registry
+}
+
+// unboundDomains returns the Unbound client when it manages domain
+// overrides, or nil.
+func (c Clients) unboundDomains() UnboundDomainClient {
+	if domains, ok := c.Unbound.(UnboundDomainClient); ok {
+		return domains
+	}
+	return nil
+}
+
+// AtomicScope selects which actions are reverted together when one fails.
+type AtomicScope string
+
+const (
+	// AtomicOff applies every action independently.
+	AtomicOff AtomicScope = ""
+	// AtomicHostname reverts the applied actions of a hostname when one of
+	// its actions fails and skips its remaining actions.
+	AtomicHostname AtomicScope = "hostname"
+	// AtomicRun reverts every applied action when any action fails and
+	// skips the rest of the plan.
+	AtomicRun AtomicScope = "run"
+)
+
+// ParseAtomicScope validates a scope name; "", "off" and "none" disable
+// atomic apply.
+func ParseAtomicScope(value string) (AtomicScope, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "off", "none":
+		return AtomicOff, nil
+	case "hostname", "host":
+		return AtomicHostname, nil
+	case "run", "all":
+		return AtomicRun, nil
+	default:
+		return AtomicOff, fmt.Errorf("invalid atomic scope %q (want hostname or run)", value)
+	}
 }
 
 // ApplyOptions controls sync plan application.
 type ApplyOptions struct {
 	DryRun bool
+	// Journal, when set, records each action and the state it replaces
+	// before the action is applied. Dry runs are not journaled.
+	Journal *Journal
+	// UndoOf is the ID of the run this apply reverts, if any.
+	UndoOf string
+	// Atomic reverts already-applied actions when a later one in the same
+	// scope fails. It has no effect on dry runs.
+	Atomic AtomicScope
 }
 
 type ActionError string
@@ -47,16 +206,47 @@ func (e ActionError) Error() string {
 	return string(e)
 }
 
+// applier holds the state of one Apply call.
+type applier struct {
+	registry *Registry
+	options  ApplyOptions
+	result   *Result
+	run      *Run
+	changed  map[string]bool
+}
+
 // Apply executes enabled plan actions and returns aggregate and per-action results.
 func Apply(ctx context.Context, clients Clients, plan Plan, options ApplyOptions) *Result {
 	actions := plan.Actions
-	result := &Result{
-		Success:       true,
-		ActionResults: make([]ActionResult, 0, len(actions)),
+	a := &applier{
+		registry: clients.Registry(),
+		options:  options,
+		result: &Result{
+			Success:       true,
+			ActionResults: make([]ActionResult, 0, len(actions)),
+		},
+		changed: make(map[string]bool),
+	}
+	result := a.result
+	if options.DryRun {
+		a.options.Atomic = AtomicOff
+	}
+
+	if options.Journal != nil && !options.DryRun {
+		var err error
+		if a.run, err = options.Journal.Begin(options.UndoOf); err != nil {
+			result.Success = false
+			result.Errors = append(result.Errors, err.Error())
+			result.Message = "Nothing applied: the run journal could not be started"
+			return result
+		}
 	}
 
-	unboundChanged := false
-	adguardChanged := false
+	// applied holds, per atomic scope, the indexes in result.ActionResults
+	// of actions that would be reverted together; failed marks scopes that
+	// have been rolled back.
+	applied := make(map[string][]int)
+	failed := make(map[string]bool)
 
 	for _, action := range actions {
 		actionResult := ActionResult{Action: action}
@@ -66,187 +256,174 @@ func Apply(ctx context.Context, clients Clients, plan Plan, options ApplyOptions
 			continue
 		}
 
-		if err := ctx.Err(); err != nil {
-			recordActionError(result, actionResult, fmt.Errorf("context cancelled: %w", err))
+		scope := a.scopeKey(action)
+		if failed[scope] {
+			actionResult.Skipped = true
+			actionResult.Error = "not applied: an earlier action in the same atomic group failed"
+			result.ActionResults = append(result.ActionResults, actionResult)
 			continue
 		}
 
 		var err error
-		if !options.DryRun {
-			err = applyAction(clients, action)
+		if err = ctx.Err(); err != nil {
+			err = fmt.Errorf("context cancelled: %w", err)
+		} else if target, ok := a.registry.Lookup(action.Service); !ok {
+			err = fmt.Errorf("unknown service: %s", action.Service)
+		} else {
+			err = a.apply(ctx, target, action)
 		}
 		if err != nil {
 			recordActionError(result, actionResult, err)
+			if a.options.Atomic != AtomicOff {
+				failed[scope] = true
+				a.rollback(context.WithoutCancel(ctx), scope, applied[scope], result.Errors[len(result.Errors)-1])
+				delete(applied, scope)
+			}
 			continue
 		}
 
 		actionResult.Success = true
 		result.ActionResults = append(result.ActionResults, actionResult)
-		switch action.Service {
-		case "unbound":
-			unboundChanged = true
-		case "adguard":
-			adguardChanged = true
-		}
 		incrementResultCounts(result, action)
+		if a.options.Atomic != AtomicOff {
+			applied[scope] = append(applied[scope], len(result.ActionResults)-1)
+		}
 	}
 
-	if !options.DryRun && unboundChanged && clients.Unbound != nil {
-		if err := clients.Unbound.ApplyChanges(); err != nil {
-			result.Errors = append(result.Errors, fmt.Sprintf("Failed to restart Unbound service: %v", err))
+	var notes []string
+	if !options.DryRun {
+		for _, target := range a.registry.Targets() {
+			if !a.changed[target.Name()] {
+				continue
+			}
+			note, err := target.Commit(ctx)
+			if err != nil {
+				result.Errors = append(result.Errors, err.Error())
+				continue
+			}
+			if note != "" {
+				notes = append(notes, note)
+			}
 		}
 	}
 
-	if adguardChanged {
-		// AdGuard rewrites are applied immediately.
+	if run := a.run; run != nil && len(run.Steps) > 0 {
+		finished := time.Now().UTC()
+		run.FinishedAt = &finished
+		if err := options.Journal.Save(run); err != nil {
+			result.Errors = append(result.Errors, err.Error())
+		}
+		result.RunID = run.ID
 	}
 
 	result.Success = len(result.Errors) == 0
 	if result.Success {
 		result.Message = "All operations completed successfully"
-		if unboundChanged && !options.DryRun {
-			result.Message += " (Unbound restarted)"
+		if len(notes) > 0 {
+			result.Message += " (" + strings.Join(notes, ", ") + ")"
 		}
 	} else {
 		result.Message = fmt.Sprintf("Completed with %d error(s)", len(result.Errors))
+		if reverted := countRolledBack(result); reverted > 0 {
+			result.Message += fmt.Sprintf("; rolled back %d change(s)", reverted)
+		}
 	}
 
 	return result
 }
 
-// ApplyActions executes an action list through a temporary plan.
-func ApplyActions(ctx context.Context, clients Clients, actions []Action, options ApplyOptions) *Result {
-	return Apply(ctx, clients, Plan{Actions: actions}, options)
-}
-
-func applyAction(clients Clients, action Action) error {
-	switch action.Service {
-	case "unbound":
-		return applyUnboundAction(clients.Unbound, action)
-	case "adguard":
-		return applyAdguardAction(clients.Adguard, action)
-	case "cloudflare":
-		return applyCloudflareAction(clients.Cloudflare, action)
-	case "dhcp":
-		return fmt.Errorf("DHCP sync not yet implemented")
-	default:
-		return fmt.Errorf("unknown service: %s", action.Service)
+// apply journals action, when a journal is set, and applies it to target.
+func (a *applier) apply(ctx context.Context, target Target, action Action) error {
+	run := a.run
+	if run != nil {
+		run.Steps = append(run.Steps, JournalStep{Action: action, Prior: PriorStateOf(action)})
+		if err := a.options.Journal.Save(run); err != nil {
+			run.Steps = run.Steps[:len(run.Steps)-1]
+			return fmt.Errorf("not applied: %w", err)
+		}
 	}
-}
-
-func applyUnboundAction(client UnboundClient, action Action) error {
-	if client == nil {
-		return fmt.Errorf("Unbound client not available")
+	var err error
+	if !a.options.DryRun {
+		err = target.Apply(ctx, action)
 	}
-
-	switch action.Type {
-	case "add":
-		host, domain := SplitHostname(action.Hostname)
-		_, err := client.AddOverride(api.DNSOverride{
-			Enabled:     "1",
-			Host:        host,
-			Domain:      domain,
-			Server:      action.NewIP,
-			Description: "Managed by caddy-dns-sync",
-		})
-		return err
-	case "update":
-		uuid, err := findUnboundOverrideUUID(client, action.Hostname)
+	if run != nil {
+		step := &run.Steps[len(run.Steps)-1]
+		step.Applied = err == nil
 		if err != nil {
-			return err
+			step.Error = err.Error()
 		}
-		host, domain := SplitHostname(action.Hostname)
-		return client.UpdateOverride(api.DNSOverride{
-			UUID:        uuid,
-			Enabled:     "1",
-			Host:        host,
-			Domain:      domain,
-			Server:      action.NewIP,
-			Description: "Managed by caddy-dns-sync",
-		})
-	case "delete":
-		uuid, err := findUnboundOverrideUUID(client, action.Hostname)
-		if err != nil {
-			return err
+		if saveErr := a.options.Journal.Save(run); saveErr != nil {
+			a.result.Errors = append(a.result.Errors, saveErr.Error())
 		}
-		return client.DeleteOverride(uuid)
-	default:
-		return fmt.Errorf("unknown action type: %s", action.Type)
 	}
-}
-
-func applyAdguardAction(client AdguardClient, action Action) error {
-	if client == nil {
-		return fmt.Errorf("AdGuard client not available")
+	if err == nil && !a.options.DryRun {
+		a.changed[action.Service] = true
 	}
+	return err
+}
 
-	switch action.Type {
-	case "add":
-		return client.AddRewrite(action.Hostname, action.NewIP)
-	case "update":
-		return client.UpdateRewrite(
-			api.Rewrite{Domain: action.Hostname, Answer: action.OldIP},
-			api.Rewrite{Domain: action.Hostname, Answer: action.NewIP},
-		)
-	case "delete":
-		return client.DeleteRewrite(action.Hostname, action.OldIP)
-	default:
-		return fmt.Errorf("unknown action type: %s", action.Type)
+// scopeKey returns the atomic group action belongs to.
+func (a *applier) scopeKey(action Action) string {
+	if a.options.Atomic == AtomicHostname {
+		return action.Hostname
 	}
+	return ""
 }
 
-func applyCloudflareAction(client CloudflareClient, action Action) error {
-	if client == nil {
-		return fmt.Errorf("Cloudflare client not available")
+// rollback reverts the actions at indexes, last first, and reports the
+// outcome in the result.
+func (a *applier) rollback(ctx context.Context, scope string, indexes []int, cause string) {
+	rollback := Rollback{Hostname: scope, Cause: cause, Success: true}
+	for i := len(indexes) - 1; i >= 0; i-- {
+		original := &a.result.ActionResults[indexes[i]]
+		inverse, err := InverseAction(JournalStep{Action: original.Action, Prior: PriorStateOf(original.Action), Applied: true})
+		if err == nil {
+			target, ok := a.registry.Lookup(inverse.Service)
+			if !ok {
+				err = fmt.Errorf("unknown service: %s", inverse.Service)
+			} else {
+				err = a.apply(ctx, target, inverse)
+			}
+		}
+		if err != nil {
+			if inverse.Type == "" {
+				inverse = original.Action
+			}
+			rollback.Success = false
+			rollback.Actions = append(rollback.Actions, ActionResult{Action: inverse, Error: err.Error()})
+			a.result.Errors = append(a.result.Errors, fmt.Sprintf("rollback of %s %s for %s: %v",
+				original.Action.Type, original.Action.Service, original.Action.Hostname, err))
+			continue
+		}
+		rollback.Actions = append(rollback.Actions, ActionResult{Action: inverse, Success: true})
+		original.RolledBack = true
+		decrementResultCounts(a.result, original.Action)
 	}
+	a.result.Rollbacks = append(a.result.Rollbacks, rollback)
+}
 
-	switch action.Type {
-	case "add":
-		if err := client.UpdateTunnelRule(api.IngressRuleSpec{
-			Hostname:                  action.Hostname,
-			Service:                   action.NewService,
-			HTTPHostHeader:            action.NewHTTPHostHeader,
-			OriginServerName:          action.OriginServerName,
-			SetOriginServerName:       action.OriginServerName != "",
-			NoTLSVerify:               action.NoTLSVerify,
-			SetNoTLSVerify:            action.NoTLSVerify,
-			DisableChunkedEncoding:    action.DisableChunkedEncoding,
-			SetDisableChunkedEncoding: action.DisableChunkedEncoding,
-			TunnelID:                  action.TunnelID,
-		}); err != nil {
-			return err
-		}
-		return client.EnsureDNSRecord(action.Hostname)
-	case "update":
-		return client.UpdateTunnelRule(api.IngressRuleSpec{
-			Hostname:                  action.Hostname,
-			Service:                   action.NewService,
-			HTTPHostHeader:            action.NewHTTPHostHeader,
-			OriginServerName:          action.OriginServerName,
-			SetOriginServerName:       true, // always write on update
-			NoTLSVerify:               action.NoTLSVerify,
-			SetNoTLSVerify:            true,
-			DisableChunkedEncoding:    action.DisableChunkedEncoding,
-			SetDisableChunkedEncoding: true,
-			TunnelID:                  action.TunnelID,
-		})
-	case "delete":
-		if err := client.DeleteTunnelRule(action.Hostname); err != nil {
-			return err
+func countRolledBack(result *Result) int {
+	count := 0
+	for _, actionResult := range result.ActionResults {
+		if actionResult.RolledBack {
+			count++
 		}
-		return client.DeleteDNSRecord(action.Hostname)
-	default:
-		return fmt.Errorf("unknown action type: %s", action.Type)
 	}
+	return count
 }
 
-func findUnboundOverrideUUID(client UnboundClient, hostname string) (string, error) {
+// ApplyActions executes an action list through a temporary plan.
+func ApplyActions(ctx context.Context, clients Clients, actions []Action, options ApplyOptions) *Result {
+	return Apply(ctx, clients, Plan{Actions: actions}, options)
+}
+
+func findUnboundOverrideUUID(client UnboundClient, hostname string, aaaa bool) (string, error) {
 	overrides, err := client.GetOverrides()
 	if err != nil {
 		return "", fmt.Errorf("failed to get overrides: %w", err)
 	}
 	for _, override := range overrides {
-		if joinHostname(override.Host, override.Domain) == hostname {
+		if joinHostname(override.Host, override.Domain) == hostname && override.IsAAAA() == aaaa {
 			return override.UUID, nil
 		}
 	}
@@ -281,6 +458,17 @@ func recordActionError(result *Result, actionResult ActionResult, err error) {
 	result.Errors = append(result.Errors, errMsg)
 }
 
+func decrementResultCounts(result *Result, action Action) {
+	switch action.Type {
+	case "add":
+		result.ItemsAdded--
+	case "update":
+		result.ItemsUpdated--
+	case "delete":
+		result.ItemsDeleted--
+	}
+}
+
 func incrementResultCounts(result *Result, action Action) {
 	switch action.Type {
 	case "add":
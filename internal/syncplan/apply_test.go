@@ -3,6 +3,7 @@ package syncplan
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/jeeftor/caddy-dns-sync/internal/api"
@@ -46,6 +47,54 @@ func TestApplyUpdatesUnboundByFullHostnameAndRestartsOnce(t *testing.T) {
 	}
 }
 
+func TestApplyKeepsUnboundAAndAAAAOverridesApart(t *testing.T) {
+	unbound := &fakeUnboundClient{
+		overrides: []api.DNSOverride{
+			{UUID: "uuid-a", Host: "app", Domain: "example.com", Server: "10.0.0.15"},
+			{UUID: "uuid-aaaa", Host: "app", Domain: "example.com", RR: "AAAA", Server: "fd00::99"},
+		},
+	}
+
+	result := Apply(context.Background(), Clients{Unbound: unbound}, Plan{Actions: []Action{
+		{Type: "update", Service: "unbound", Hostname: "app.example.com", OldIP: "fd00::99", NewIP: "fd00::15", RecordType: RecordTypeAAAA, Enabled: true},
+		{Type: "add", Service: "unbound", Hostname: "new.example.com", NewIP: "fd00::16", RecordType: RecordTypeAAAA, Enabled: true},
+	}}, ApplyOptions{})
+
+	if !result.Success {
+		t.Fatalf("expected success, got errors: %#v", result.Errors)
+	}
+	if len(unbound.updated) != 1 || unbound.updated[0].UUID != "uuid-aaaa" || unbound.updated[0].RR != "AAAA" {
+		t.Fatalf("expected the AAAA override to be updated, got %#v", unbound.updated)
+	}
+	if len(unbound.added) != 1 || unbound.added[0].RR != "AAAA" || unbound.added[0].Server != "fd00::16" {
+		t.Fatalf("expected an AAAA override to be added, got %#v", unbound.added)
+	}
+}
+
+func TestApplyTurnsUnboundOverrideIntoAlias(t *testing.T) {
+	unbound := &fakeAliasUnboundClient{fakeUnboundClient: fakeUnboundClient{
+		overrides: []api.DNSOverride{
+			{UUID: "uuid-caddy", Host: "caddy", Domain: "example.com", Server: "10.0.0.15"},
+			{UUID: "uuid-app", Host: "app", Domain: "example.com", Server: "10.0.0.15"},
+		},
+	}}
+
+	result := Apply(context.Background(), Clients{Unbound: unbound}, Plan{Actions: []Action{
+		{Type: "update", Service: "unbound", Hostname: "app.example.com", OldIP: "10.0.0.15", NewIP: "caddy.example.com", Enabled: true},
+		{Type: "add", Service: "unbound", Hostname: "orphan.example.com", NewIP: "missing.example.com", Enabled: true},
+	}}, ApplyOptions{})
+
+	if result.Success || len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "must exist before aliasing") {
+		t.Fatalf("expected only the alias without a canonical record to fail, got %#v", result.Errors)
+	}
+	if len(unbound.deleted) != 1 || unbound.deleted[0] != "uuid-app" {
+		t.Fatalf("expected app's override to be deleted, got %#v", unbound.deleted)
+	}
+	if len(unbound.addedAliases) != 1 || unbound.addedAliases[0].Host != "uuid-caddy" || unbound.addedAliases[0].Hostname != "app" {
+		t.Fatalf("expected app to be aliased to the canonical override, got %#v", unbound.addedAliases)
+	}
+}
+
 func TestApplyRecordsPerActionFailures(t *testing.T) {
 	result := Apply(context.Background(), Clients{}, Plan{Actions: []Action{
 		{
@@ -253,6 +302,7 @@ type fakeUnboundClient struct {
 	deleted    []string
 	applyCalls int
 	applyErr   error
+	addErr     error
 }
 
 func (f *fakeUnboundClient) GetOverrides() ([]api.DNSOverride, error) {
@@ -260,6 +310,9 @@ func (f *fakeUnboundClient) GetOverrides() ([]api.DNSOverride, error) {
 }
 
 func (f *fakeUnboundClient) AddOverride(override api.DNSOverride) (string, error) {
+	if f.addErr != nil {
+		return "", f.addErr
+	}
 	f.added = append(f.added, override)
 	return "new-uuid", nil
 }
@@ -279,6 +332,27 @@ func (f *fakeUnboundClient) ApplyChanges() error {
 	return f.applyErr
 }
 
+type fakeAliasUnboundClient struct {
+	fakeUnboundClient
+	aliases        []api.HostAlias
+	addedAliases   []api.HostAlias
+	deletedAliases []string
+}
+
+func (f *fakeAliasUnboundClient) GetHostAliases() ([]api.HostAlias, error) {
+	return f.aliases, nil
+}
+
+func (f *fakeAliasUnboundClient) AddHostAlias(alias api.HostAlias) (string, error) {
+	f.addedAliases = append(f.addedAliases, alias)
+	return "new-alias-uuid", nil
+}
+
+func (f *fakeAliasUnboundClient) DeleteHostAlias(uuid string) error {
+	f.deletedAliases = append(f.deletedAliases, uuid)
+	return nil
+}
+
 type fakeAdguardUpdate struct {
 	target api.Rewrite
 	update api.Rewrite
@@ -290,6 +364,10 @@ type fakeAdguardClient struct {
 	deleted []api.Rewrite
 }
 
+func (f *fakeAdguardClient) ListRewrites() ([]api.Rewrite, error) {
+	return f.added, nil
+}
+
 func (f *fakeAdguardClient) AddRewrite(domain, answer string) error {
 	f.added = append(f.added, api.Rewrite{Domain: domain, Answer: answer})
 	return nil
@@ -331,3 +409,76 @@ func (f *fakeCloudflareClient) DeleteDNSRecord(hostname string) error {
 	f.deletedDNS = append(f.deletedDNS, hostname)
 	return nil
 }
+
+func TestApplyAtomicHostnameRollsBackOnlyTheFailedHostname(t *testing.T) {
+	adguard := &fakeAdguardClient{}
+	unbound := &fakeUnboundClient{addErr: errors.New("unbound unavailable")}
+
+	result := Apply(context.Background(), Clients{Adguard: adguard, Unbound: unbound}, Plan{Actions: []Action{
+		{Type: "add", Service: "adguard", Hostname: "a.example.com", NewIP: "10.0.0.15", Enabled: true},
+		{Type: "add", Service: "adguard", Hostname: "b.example.com", NewIP: "10.0.0.15", Enabled: true},
+		{Type: "add", Service: "unbound", Hostname: "a.example.com", NewIP: "10.0.0.15", Enabled: true},
+		{Type: "update", Service: "adguard", Hostname: "a.example.com", OldIP: "10.0.0.1", NewIP: "10.0.0.15", Enabled: true},
+	}}, ApplyOptions{Atomic: AtomicHostname})
+
+	if result.Success {
+		t.Fatal("expected the failed Unbound add to be reported")
+	}
+	if len(result.Rollbacks) != 1 || result.Rollbacks[0].Hostname != "a.example.com" || !result.Rollbacks[0].Success {
+		t.Fatalf("expected one successful rollback of a.example.com, got %#v", result.Rollbacks)
+	}
+	if len(adguard.deleted) != 1 || adguard.deleted[0] != (api.Rewrite{Domain: "a.example.com", Answer: "10.0.0.15"}) {
+		t.Fatalf("expected the a.example.com rewrite to be removed, got %#v", adguard.deleted)
+	}
+	if len(adguard.updated) != 0 {
+		t.Fatalf("expected the later a.example.com update to be skipped, got %#v", adguard.updated)
+	}
+	if !result.ActionResults[0].RolledBack || result.ActionResults[1].RolledBack || !result.ActionResults[3].Skipped {
+		t.Fatalf("unexpected action results: %#v", result.ActionResults)
+	}
+	if result.ItemsAdded != 1 {
+		t.Fatalf("expected only b.example.com to stay added, got %d", result.ItemsAdded)
+	}
+}
+
+func TestApplyAtomicRunRevertsEverythingInReverseOrder(t *testing.T) {
+	adguard := &fakeAdguardClient{}
+	unbound := &fakeUnboundClient{addErr: errors.New("unbound unavailable")}
+	journal := NewJournal(t.TempDir())
+
+	result := Apply(context.Background(), Clients{Adguard: adguard, Unbound: unbound}, Plan{Actions: []Action{
+		{Type: "add", Service: "adguard", Hostname: "a.example.com", NewIP: "10.0.0.15", Enabled: true},
+		{Type: "update", Service: "adguard", Hostname: "b.example.com", OldIP: "10.0.0.1", NewIP: "10.0.0.15", Enabled: true},
+		{Type: "add", Service: "unbound", Hostname: "c.example.com", NewIP: "10.0.0.15", Enabled: true},
+		{Type: "add", Service: "adguard", Hostname: "d.example.com", NewIP: "10.0.0.15", Enabled: true},
+	}}, ApplyOptions{Atomic: AtomicRun, Journal: journal})
+
+	if len(result.Rollbacks) != 1 || result.Rollbacks[0].Hostname != "" || len(result.Rollbacks[0].Actions) != 2 {
+		t.Fatalf("expected one run rollback of two actions, got %#v", result.Rollbacks)
+	}
+	if first := result.Rollbacks[0].Actions[0].Action; first.Type != "update" || first.Hostname != "b.example.com" || first.NewIP != "10.0.0.1" {
+		t.Fatalf("expected the update to be reverted first, got %#v", first)
+	}
+	if len(adguard.added) != 1 || len(adguard.updated) != 2 || len(adguard.deleted) != 1 {
+		t.Fatalf("expected d.example.com to be skipped and a/b reverted, got %#v", adguard)
+	}
+	if result.ItemsAdded != 0 || result.ItemsUpdated != 0 {
+		t.Fatalf("expected no net changes, got %d added %d updated", result.ItemsAdded, result.ItemsUpdated)
+	}
+
+	run, err := journal.Read(result.RunID)
+	if err != nil || len(run.Steps) != 5 {
+		t.Fatalf("expected the rollback to be journaled, got %#v, %v", run, err)
+	}
+}
+
+func TestParseAtomicScope(t *testing.T) {
+	for value, want := range map[string]AtomicScope{"": AtomicOff, "off": AtomicOff, "hostname": AtomicHostname, "RUN": AtomicRun} {
+		if got, err := ParseAtomicScope(value); err != nil || got != want {
+			t.Errorf("ParseAtomicScope(%q) = %q, %v; want %q", value, got, err, want)
+		}
+	}
+	if _, err := ParseAtomicScope("zone"); err == nil {
+		t.Error("expected an unknown scope to be rejected")
+	}
+}
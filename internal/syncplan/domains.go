@@ -0,0 +1,179 @@
+package syncplan
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/api"
+	"github.com/jeeftor/caddy-dns-sync/internal/app"
+	"github.com/jeeftor/caddy-dns-sync/internal/config"
+	"github.com/jeeftor/caddy-dns-sync/internal/models"
+)
+
+// UnboundDomainsService is the Action.Service of Unbound domain override
+// actions. Their Hostname is the forwarded domain and OldIP/NewIP the
+// server it is forwarded to.
+const UnboundDomainsService = "unbound-domains"
+
+// UnboundDomainClient is implemented by Unbound clients that manage domain
+// overrides (query forwarders).
+type UnboundDomainClient interface {
+	GetDomainOverrides() ([]api.DomainOverride, error)
+	AddDomainOverride(api.DomainOverride) (string, error)
+	UpdateDomainOverride(api.DomainOverride) error
+	DeleteDomainOverride(uuid string) error
+	ApplyChanges() error
+}
+
+// PlanDomainOverrides returns the actions that make Unbound forward every
+// configured domain to each of its servers. Domain overrides that carry
+// caddy-dns-sync's description but are no longer configured are deleted;
+// others are left alone.
+func PlanDomainOverrides(desired []config.DomainOverride, existing []api.DomainOverride) Plan {
+	present := make(map[string]bool, len(existing))
+	for _, override := range forwarders(existing) {
+		present[domainServerKey(override.Domain, override.Server)] = true
+	}
+
+	var actions []Action
+	wanted := make(map[string]bool)
+	for _, override := range desired {
+		for _, server := range override.Servers {
+			key := domainServerKey(override.Domain, server)
+			wanted[key] = true
+			if present[key] {
+				continue
+			}
+			present[key] = true
+			actions = append(actions, Action{
+				Type:     "add",
+				Service:  UnboundDomainsService,
+				Hostname: override.Domain,
+				NewIP:    server,
+				Details:  fmt.Sprintf("forward %s to %s", override.Domain, server),
+				Enabled:  true,
+			})
+		}
+	}
+	for _, override := range forwarders(existing) {
+		if !isManagedUnboundDescription(override.Description) || wanted[domainServerKey(override.Domain, override.Server)] {
+			continue
+		}
+		actions = append(actions, Action{
+			Type:     "delete",
+			Service:  UnboundDomainsService,
+			Hostname: strings.ToLower(override.Domain),
+			OldIP:    override.Server,
+			Details:  "no longer in domain_overrides",
+			Enabled:  true,
+		})
+	}
+	return Plan{Actions: actions}
+}
+
+// OwnedDomainOverrides counts the plain forwarders carrying caddy-dns-sync's
+// description, for Policy.Enforce.
+func OwnedDomainOverrides(existing []api.DomainOverride) int {
+	owned := 0
+	for _, override := range forwarders(existing) {
+		if isManagedUnboundDescription(override.Description) {
+			owned++
+		}
+	}
+	return owned
+}
+
+// forwarders drops DNS-over-TLS entries, which sync does not manage.
+func forwarders(overrides []api.DomainOverride) []api.DomainOverride {
+	plain := make([]api.DomainOverride, 0, len(overrides))
+	for _, override := range overrides {
+		if override.Type == "" || override.Type == api.DomainOverrideTypeForward {
+			plain = append(plain, override)
+		}
+	}
+	return plain
+}
+
+func domainServerKey(domain, server string) string {
+	return strings.ToLower(strings.TrimSuffix(domain, ".")) + "|" + server
+}
+
+// ─── Unbound domain overrides ───────────────────────────────────────────────
+
+type unboundDomainTarget struct {
+	client UnboundDomainClient
+}
+
+// NewUnboundDomainTarget creates the target applying UnboundDomainsService
+// actions. It plans nothing from entries; see PlanDomainOverrides.
+func NewUnboundDomainTarget(client UnboundDomainClient) Target {
+	return &unboundDomainTarget{client: client}
+}
+
+func (t *unboundDomainTarget) Name() string    { return UnboundDomainsService }
+func (t *unboundDomainTarget) Label() string   { return "Unbound domain overrides" }
+func (t *unboundDomainTarget) Available() bool { return t.client != nil }
+
+// IncludeInAll keeps domain overrides out of hostname plans.
+func (t *unboundDomainTarget) IncludeInAll(Options) bool { return false }
+
+func (t *unboundDomainTarget) Diff(*models.Entry, Options) Action { return Action{} }
+
+func (t *unboundDomainTarget) Apply(_ context.Context, action Action) error {
+	if t.client == nil {
+		return errClientUnavailable(t.Label())
+	}
+
+	switch action.Type {
+	case "add":
+		_, err := t.client.AddDomainOverride(api.DomainOverride{
+			Enabled:     "1",
+			Type:        api.DomainOverrideTypeForward,
+			Domain:      action.Hostname,
+			Server:      action.NewIP,
+			Description: app.CurrentUnboundDescription,
+		})
+		return err
+	case "update":
+		override, err := t.find(action.Hostname, action.OldIP)
+		if err != nil {
+			return err
+		}
+		override.Server = action.NewIP
+		override.Description = app.CurrentUnboundDescription
+		return t.client.UpdateDomainOverride(override)
+	case "delete":
+		override, err := t.find(action.Hostname, action.OldIP)
+		if err != nil {
+			return err
+		}
+		return t.client.DeleteDomainOverride(override.UUID)
+	default:
+		return fmt.Errorf("unknown action type: %s", action.Type)
+	}
+}
+
+func (t *unboundDomainTarget) find(domain, server string) (api.DomainOverride, error) {
+	overrides, err := t.client.GetDomainOverrides()
+	if err != nil {
+		return api.DomainOverride{}, err
+	}
+	key := domainServerKey(domain, server)
+	for _, override := range forwarders(overrides) {
+		if domainServerKey(override.Domain, override.Server) == key {
+			return override, nil
+		}
+	}
+	return api.DomainOverride{}, fmt.Errorf("domain override for %s via %s not found", domain, server)
+}
+
+func (t *unboundDomainTarget) Commit(_ context.Context) (string, error) {
+	if t.client == nil {
+		return "", nil
+	}
+	if err := t.client.ApplyChanges(); err != nil {
+		return "", fmt.Errorf("Failed to restart Unbound service: %v", err)
+	}
+	return "Unbound restarted", nil
+}
@@ -0,0 +1,85 @@
+package syncplan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/api"
+	"github.com/jeeftor/caddy-dns-sync/internal/app"
+	"github.com/jeeftor/caddy-dns-sync/internal/config"
+)
+
+func TestPlanDomainOverridesAddsMissingAndDeletesOwnedLeftovers(t *testing.T) {
+	existing := []api.DomainOverride{
+		{UUID: "uuid-dc1", Type: "forward", Domain: "corp.example.com", Server: "10.1.0.10", Description: "added by hand"},
+		{UUID: "uuid-old", Type: "forward", Domain: "old.example.com", Server: "10.1.0.20", Description: app.CurrentUnboundDescription},
+		{UUID: "uuid-manual", Type: "forward", Domain: "lab.example.com", Server: "10.1.0.30", Description: "added by hand"},
+		{UUID: "uuid-dot", Type: "dot", Domain: "dot.example.com", Server: "9.9.9.9", Description: app.CurrentUnboundDescription},
+	}
+	desired := []config.DomainOverride{
+		{Domain: "corp.example.com", Servers: []string{"10.1.0.10", "10.1.0.11"}},
+	}
+
+	plan := PlanDomainOverrides(desired, existing)
+	if len(plan.Actions) != 2 {
+		t.Fatalf("expected one add and one delete, got %#v", plan.Actions)
+	}
+	if add := plan.Actions[0]; add.Type != "add" || add.Service != UnboundDomainsService || add.Hostname != "corp.example.com" || add.NewIP != "10.1.0.11" {
+		t.Fatalf("expected the second domain controller to be added, got %#v", add)
+	}
+	if del := plan.Actions[1]; del.Type != "delete" || del.Hostname != "old.example.com" || del.OldIP != "10.1.0.20" {
+		t.Fatalf("expected the unlisted managed forwarder to be deleted, got %#v", del)
+	}
+	if owned := OwnedDomainOverrides(existing); owned != 1 {
+		t.Fatalf("expected one owned forwarder, got %d", owned)
+	}
+}
+
+func TestApplyDomainOverrideActions(t *testing.T) {
+	unbound := &fakeDomainUnboundClient{domains: []api.DomainOverride{
+		{UUID: "uuid-old", Type: "forward", Domain: "old.example.com", Server: "10.1.0.20", Description: app.CurrentUnboundDescription},
+	}}
+
+	result := Apply(context.Background(), Clients{Unbound: unbound}, Plan{Actions: []Action{
+		{Type: "add", Service: UnboundDomainsService, Hostname: "corp.example.com", NewIP: "10.1.0.11", Enabled: true},
+		{Type: "delete", Service: UnboundDomainsService, Hostname: "old.example.com", OldIP: "10.1.0.20", Enabled: true},
+	}}, ApplyOptions{})
+
+	if !result.Success {
+		t.Fatalf("expected success, got errors: %#v", result.Errors)
+	}
+	if len(unbound.addedDomains) != 1 || unbound.addedDomains[0].Server != "10.1.0.11" || unbound.addedDomains[0].Description != app.CurrentUnboundDescription {
+		t.Fatalf("expected a managed forwarder to be added, got %#v", unbound.addedDomains)
+	}
+	if len(unbound.deletedDomains) != 1 || unbound.deletedDomains[0] != "uuid-old" {
+		t.Fatalf("expected uuid-old to be deleted, got %#v", unbound.deletedDomains)
+	}
+	if unbound.applyCalls != 1 {
+		t.Fatalf("expected Unbound to be restarted once, got %d", unbound.applyCalls)
+	}
+}
+
+type fakeDomainUnboundClient struct {
+	fakeUnboundClient
+	domains        []api.DomainOverride
+	addedDomains   []api.DomainOverride
+	deletedDomains []string
+}
+
+func (f *fakeDomainUnboundClient) GetDomainOverrides() ([]api.DomainOverride, error) {
+	return f.domains, nil
+}
+
+func (f *fakeDomainUnboundClient) AddDomainOverride(override api.DomainOverride) (string, error) {
+	f.addedDomains = append(f.addedDomains, override)
+	return "new-domain-uuid", nil
+}
+
+func (f *fakeDomainUnboundClient) UpdateDomainOverride(override api.DomainOverride) error {
+	return nil
+}
+
+func (f *fakeDomainUnboundClient) DeleteDomainOverride(uuid string) error {
+	f.deletedDomains = append(f.deletedDomains, uuid)
+	return nil
+}
@@ -0,0 +1,357 @@
+package syncplan
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/models"
+)
+
+// ErrRunNotFound is returned when no journal exists for a run ID.
+var ErrRunNotFound = errors.New("run not found")
+
+var runIDPattern = regexp.MustCompile(`^[0-9]{8}T[0-9]{6}Z-[0-9a-f]{8}$`)
+
+// Journal stores one JSON file per applied run so that the changes it made
+// can be listed and reverted later.
+type Journal struct {
+	Dir string
+}
+
+// NewJournal returns a journal that keeps runs in dir.
+func NewJournal(dir string) *Journal {
+	return &Journal{Dir: dir}
+}
+
+// DefaultJournalDir returns the directory runs are journaled to, next to the
+// sync lock.
+func DefaultJournalDir() string {
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".local", "share", "caddy-dns-sync", "runs")
+	}
+	return filepath.Join(os.TempDir(), "caddy-dns-sync", "runs")
+}
+
+// Run is the journal of one Apply call. Steps are written before each action
+// is applied, so a run interrupted part-way still shows what was attempted.
+type Run struct {
+	ID         string        `json:"id"`
+	StartedAt  time.Time     `json:"started_at"`
+	FinishedAt *time.Time    `json:"finished_at,omitempty"`
+	UndoOf     string        `json:"undo_of,omitempty"`
+	UndoneBy   string        `json:"undone_by,omitempty"`
+	Steps      []JournalStep `json:"steps"`
+}
+
+// JournalStep is one action of a run and the state it replaced.
+type JournalStep struct {
+	Action  Action     `json:"action"`
+	Prior   PriorState `json:"prior"`
+	Applied bool       `json:"applied"`
+	Error   string     `json:"error,omitempty"`
+}
+
+// PriorState is the record an action replaces or removes. Exists is false
+// for adds, which had nothing to replace.
+type PriorState struct {
+	Exists           bool   `json:"exists"`
+	IP               string `json:"ip,omitempty"`
+	Service          string `json:"service,omitempty"`
+	HTTPHostHeader   string `json:"http_host_header,omitempty"`
+	OriginServerName string `json:"origin_server_name,omitempty"`
+	NoTLSVerify      bool   `json:"no_tls_verify,omitempty"`
+}
+
+// PriorStateOf returns the state action replaces, as observed when the plan
+// was built.
+func PriorStateOf(action Action) PriorState {
+	if action.Type == "add" {
+		return PriorState{}
+	}
+	if action.Service == "cloudflare" {
+		return PriorState{
+			Exists:           true,
+			Service:          action.OldService,
+			HTTPHostHeader:   action.OldHTTPHostHeader,
+			OriginServerName: action.OldOriginServerName,
+			NoTLSVerify:      action.OldNoTLSVerify,
+		}
+	}
+	return PriorState{Exists: true, IP: action.OldIP}
+}
+
+// Begin starts a run in memory. Nothing is written until its first step is
+// recorded, so runs without enabled actions leave no journal.
+func (j *Journal) Begin(undoOf string) (*Run, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, fmt.Errorf("error generating run ID: %w", err)
+	}
+	now := time.Now().UTC()
+	return &Run{
+		ID:        now.Format("20060102T150405Z") + "-" + hex.EncodeToString(suffix),
+		StartedAt: now,
+		UndoOf:    undoOf,
+		Steps:     []JournalStep{},
+	}, nil
+}
+
+// Save writes run, replacing any earlier copy.
+func (j *Journal) Save(run *Run) error {
+	if err := os.MkdirAll(j.Dir, 0o700); err != nil {
+		return fmt.Errorf("error creating journal directory: %w", err)
+	}
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding run %s: %w", run.ID, err)
+	}
+	path := j.path(run.ID)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("error writing run %s: %w", run.ID, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("error writing run %s: %w", run.ID, err)
+	}
+	return nil
+}
+
+// Read loads the run with the given ID.
+func (j *Journal) Read(id string) (Run, error) {
+	var run Run
+	if !runIDPattern.MatchString(id) {
+		return run, fmt.Errorf("invalid run ID %q", id)
+	}
+	data, err := os.ReadFile(j.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return run, fmt.Errorf("%w: %s", ErrRunNotFound, id)
+	}
+	if err != nil {
+		return run, fmt.Errorf("error reading run %s: %w", id, err)
+	}
+	if err := json.Unmarshal(data, &run); err != nil {
+		return run, fmt.Errorf("error parsing run %s: %w", id, err)
+	}
+	return run, nil
+}
+
+// List returns every journaled run, newest first.
+func (j *Journal) List() ([]Run, error) {
+	files, err := os.ReadDir(j.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading journal directory: %w", err)
+	}
+	runs := make([]Run, 0, len(files))
+	for _, file := range files {
+		id, ok := strings.CutSuffix(file.Name(), ".json")
+		if !ok || !runIDPattern.MatchString(id) {
+			continue
+		}
+		run, err := j.Read(id)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	sort.Slice(runs, func(a, b int) bool { return runs[a].ID > runs[b].ID })
+	return runs, nil
+}
+
+// MarkUndone records that run id was reverted by run undoneBy.
+func (j *Journal) MarkUndone(id, undoneBy string) error {
+	run, err := j.Read(id)
+	if err != nil {
+		return err
+	}
+	run.UndoneBy = undoneBy
+	return j.Save(&run)
+}
+
+func (j *Journal) path(id string) string {
+	return filepath.Join(j.Dir, id+".json")
+}
+
+// UndoPlan returns the inverse of every applied step in run, last step
+// first. Steps that cannot be reverted are left out and described in
+// warnings.
+func UndoPlan(run Run) (Plan, []string, error) {
+	if run.UndoneBy != "" {
+		return Plan{}, nil, fmt.Errorf("run %s was already undone by %s", run.ID, run.UndoneBy)
+	}
+	var plan Plan
+	var warnings []string
+	for i := len(run.Steps) - 1; i >= 0; i-- {
+		step := run.Steps[i]
+		if !step.Applied {
+			continue
+		}
+		inverse, err := InverseAction(step)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s %s for %s: %v", step.Action.Type, step.Action.Service, step.Action.Hostname, err))
+			continue
+		}
+		plan.Actions = append(plan.Actions, inverse)
+	}
+	if len(plan.Actions) == 0 && len(warnings) == 0 {
+		return plan, nil, fmt.Errorf("run %s has no applied changes to undo", run.ID)
+	}
+	return plan, warnings, nil
+}
+
+// CheckUndo keeps the actions of an undo plan whose record still holds what
+// the undone run left behind: the answer an update or delete replaces, or no
+// record where an add restores one. Records changed since the run are left
+// alone and described in warnings, so an undo never overwrites a later edit
+// and can be retried after a partial failure. Targets that cannot list their
+// records are not checked.
+func CheckUndo(ctx context.Context, clients Clients, plan Plan) (Plan, []string, error) {
+	registry := clients.Registry()
+	listed := make(map[string][]Record)
+	var kept Plan
+	var warnings []string
+	for _, action := range plan.Actions {
+		target, ok := registry.Lookup(action.Service)
+		lister, canList := target.(RecordLister)
+		if !ok || !canList || !lister.Available() {
+			kept.Actions = append(kept.Actions, action)
+			continue
+		}
+		records, fetched := listed[action.Service]
+		if !fetched {
+			var err error
+			if records, err = lister.Records(ctx); err != nil {
+				return Plan{}, nil, fmt.Errorf("error listing %s records: %w", action.Service, err)
+			}
+			listed[action.Service] = records
+		}
+
+		current := currentAnswers(records, action.Hostname, action.IsAAAA())
+		switch {
+		case action.Type == "add" && len(current) > 0:
+			warnings = append(warnings, fmt.Sprintf("%s for %s: now answers %s, not left removed by the run", action.Service, action.Hostname, strings.Join(current, ", ")))
+		case action.Type != "add" && !slices.Contains(current, action.OldIP):
+			now := "no record"
+			if len(current) > 0 {
+				now = strings.Join(current, ", ")
+			}
+			warnings = append(warnings, fmt.Sprintf("%s for %s: now %s, not %s as the run left it", action.Service, action.Hostname, now, action.OldIP))
+		default:
+			kept.Actions = append(kept.Actions, action)
+		}
+	}
+	return kept, warnings, nil
+}
+
+// currentAnswers returns the answers records hold for hostname in one
+// family: IPv6 addresses for AAAA, otherwise IPv4 addresses and aliases.
+func currentAnswers(records []Record, hostname string, aaaa bool) []string {
+	var answers []string
+	for _, record := range records {
+		if !strings.EqualFold(record.Hostname, hostname) {
+			continue
+		}
+		ip := net.ParseIP(record.Answer)
+		if (ip != nil && ip.To4() == nil) == aaaa {
+			answers = append(answers, record.Answer)
+		}
+	}
+	return answers
+}
+
+// InverseAction returns the action that restores the prior state of step.
+func InverseAction(step JournalStep) (Action, error) {
+	action := step.Action
+	prior := step.Prior
+	inverse := Action{
+		Hostname:   action.Hostname,
+		Service:    action.Service,
+		MAC:        action.MAC,
+		RecordType: action.RecordType,
+		Details:    fmt.Sprintf("undo %s", action.Type),
+		Enabled:    true,
+	}
+
+	if action.Service == "dhcp" {
+		return Action{}, fmt.Errorf("DHCP reservations cannot be removed automatically")
+	}
+
+	if action.Service == "cloudflare" {
+		inverse.TunnelID = action.TunnelID
+		inverse.TunnelName = action.TunnelName
+		inverse.Path = action.Path
+		inverse.Http2Origin = action.Http2Origin
+		inverse.HasAccessPolicy = action.HasAccessPolicy
+		inverse.DisableChunkedEncoding = action.DisableChunkedEncoding
+		switch action.Type {
+		case "add":
+			inverse.Type = "delete"
+			inverse.OldService = action.NewService
+			inverse.OldHTTPHostHeader = action.NewHTTPHostHeader
+			inverse.OldOriginServerName = action.OriginServerName
+			inverse.OldNoTLSVerify = action.NoTLSVerify
+		case "update", "delete":
+			if prior.Service == "" {
+				return Action{}, fmt.Errorf("prior ingress rule was not recorded")
+			}
+			inverse.Type = "add"
+			if action.Type == "update" {
+				inverse.Type = "update"
+				inverse.OldService = action.NewService
+				inverse.OldHTTPHostHeader = action.NewHTTPHostHeader
+				inverse.OldOriginServerName = action.OriginServerName
+				inverse.OldNoTLSVerify = action.NoTLSVerify
+			}
+			inverse.NewService = prior.Service
+			inverse.NewHTTPHostHeader = prior.HTTPHostHeader
+			inverse.OriginServerName = prior.OriginServerName
+			inverse.NoTLSVerify = prior.NoTLSVerify
+		default:
+			return Action{}, fmt.Errorf("unknown action type: %s", action.Type)
+		}
+		return inverse, nil
+	}
+
+	switch action.Type {
+	case "add":
+		inverse.Type = "delete"
+		inverse.OldIP = action.NewIP
+	case "update", "delete":
+		// A prior alias or CNAME is restored by pointing the record back at
+		// its old target, which only alias-capable targets can write.
+		if net.ParseIP(prior.IP) == nil && !(models.IsAlias(prior.IP) && restoresAliases(action.Service)) {
+			return Action{}, fmt.Errorf("prior record %q is not an address and %s cannot restore it as an alias", prior.IP, action.Service)
+		}
+		inverse.Type = "add"
+		if action.Type == "update" {
+			inverse.Type = "update"
+			inverse.OldIP = action.NewIP
+		}
+		inverse.NewIP = prior.IP
+	default:
+		return Action{}, fmt.Errorf("unknown action type: %s", action.Type)
+	}
+	return inverse, nil
+}
+
+// restoresAliases reports whether the named target can write a record that
+// answers with a hostname: Unbound host aliases and AdGuard rewrites, for
+// the primary servers and their replicas alike.
+func restoresAliases(service string) bool {
+	return service == "unbound" || service == "adguard" ||
+		strings.HasPrefix(service, UnboundInstancePrefix) || strings.HasPrefix(service, AdguardInstancePrefix)
+}
@@ -0,0 +1,204 @@
+package syncplan
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/api"
+)
+
+func TestApplyJournalsRunAndUndoRestoresPriorState(t *testing.T) {
+	journal := NewJournal(t.TempDir())
+	adguard := &fakeAdguardClient{}
+	cloudflare := &fakeCloudflareClient{}
+	clients := Clients{Adguard: adguard, Cloudflare: cloudflare}
+
+	result := Apply(context.Background(), clients, Plan{Actions: []Action{
+		{Type: "add", Service: "adguard", Hostname: "new.example.com", NewIP: "10.0.0.15", Enabled: true},
+		{Type: "update", Service: "adguard", Hostname: "app.example.com", OldIP: "10.0.0.99", NewIP: "10.0.0.15", Enabled: true},
+		{Type: "delete", Service: "adguard", Hostname: "old.example.com", OldIP: "10.0.0.20", Enabled: true},
+		{
+			Type: "update", Service: "cloudflare", Hostname: "app.example.com", TunnelID: "tunnel-1",
+			OldService: "http://10.0.0.5:8080", NewService: "https://10.0.0.15",
+			OldHTTPHostHeader: "", NewHTTPHostHeader: "app.example.com",
+			OldOriginServerName: "", OriginServerName: "app.example.com",
+			Enabled: true,
+		},
+		{Type: "delete", Service: "adguard", Hostname: "skipped.example.com", OldIP: "10.0.0.21"},
+	}}, ApplyOptions{Journal: journal})
+	if !result.Success || result.RunID == "" {
+		t.Fatalf("expected a journaled successful apply, got %#v", result)
+	}
+
+	runs, err := journal.List()
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("expected one journaled run, got %#v, %v", runs, err)
+	}
+	run := runs[0]
+	if run.ID != result.RunID || run.FinishedAt == nil || len(run.Steps) != 4 {
+		t.Fatalf("unexpected run: %#v", run)
+	}
+	if !run.Steps[2].Applied || !run.Steps[2].Prior.Exists || run.Steps[2].Prior.IP != "10.0.0.20" {
+		t.Fatalf("expected the deleted record to be journaled, got %#v", run.Steps[2])
+	}
+
+	plan, warnings, err := UndoPlan(run)
+	if err != nil || len(warnings) != 0 {
+		t.Fatalf("UndoPlan failed: %v %v", err, warnings)
+	}
+	want := []struct{ typ, service, hostname, oldIP, newIP string }{
+		{"update", "cloudflare", "app.example.com", "", ""},
+		{"add", "adguard", "old.example.com", "", "10.0.0.20"},
+		{"update", "adguard", "app.example.com", "10.0.0.15", "10.0.0.99"},
+		{"delete", "adguard", "new.example.com", "10.0.0.15", ""},
+	}
+	if len(plan.Actions) != len(want) {
+		t.Fatalf("expected %d inverse actions, got %#v", len(want), plan.Actions)
+	}
+	for i, w := range want {
+		got := plan.Actions[i]
+		if got.Type != w.typ || got.Service != w.service || got.Hostname != w.hostname || got.OldIP != w.oldIP || got.NewIP != w.newIP {
+			t.Errorf("inverse action %d = %#v, want %+v", i, got, w)
+		}
+	}
+	if cf := plan.Actions[0]; cf.NewService != "http://10.0.0.5:8080" || cf.NewHTTPHostHeader != "" || cf.OriginServerName != "" || cf.TunnelID != "tunnel-1" {
+		t.Fatalf("expected the prior ingress rule to be restored, got %#v", cf)
+	}
+
+	undo := Apply(context.Background(), clients, plan, ApplyOptions{Journal: journal, UndoOf: run.ID})
+	if !undo.Success {
+		t.Fatalf("undo failed: %#v", undo.Errors)
+	}
+	if err := journal.MarkUndone(run.ID, undo.RunID); err != nil {
+		t.Fatalf("MarkUndone failed: %v", err)
+	}
+	if len(adguard.deleted) != 2 || adguard.deleted[1].Domain != "new.example.com" {
+		t.Fatalf("expected the added rewrite to be removed, got %#v", adguard.deleted)
+	}
+	if last := cloudflare.updatedRules[len(cloudflare.updatedRules)-1]; last.Service != "http://10.0.0.5:8080" {
+		t.Fatalf("expected the Cloudflare rule to be restored, got %#v", last)
+	}
+
+	undone, err := journal.Read(run.ID)
+	if err != nil || undone.UndoneBy != undo.RunID {
+		t.Fatalf("expected run to be marked undone by %s, got %#v, %v", undo.RunID, undone, err)
+	}
+	if _, _, err := UndoPlan(undone); err == nil {
+		t.Fatal("expected a second undo of the same run to be refused")
+	}
+	undoRun, err := journal.Read(undo.RunID)
+	if err != nil || undoRun.UndoOf != run.ID {
+		t.Fatalf("expected the undo run to reference %s, got %#v, %v", run.ID, undoRun, err)
+	}
+}
+
+func TestApplyDoesNotJournalDryRuns(t *testing.T) {
+	journal := NewJournal(t.TempDir())
+	result := Apply(context.Background(), Clients{Adguard: &fakeAdguardClient{}}, Plan{Actions: []Action{
+		{Type: "add", Service: "adguard", Hostname: "new.example.com", NewIP: "10.0.0.15", Enabled: true},
+	}}, ApplyOptions{DryRun: true, Journal: journal})
+	if !result.Success || result.RunID != "" {
+		t.Fatalf("expected an unjournaled dry run, got %#v", result)
+	}
+	if runs, err := journal.List(); err != nil || len(runs) != 0 {
+		t.Fatalf("expected no runs, got %#v, %v", runs, err)
+	}
+}
+
+func TestUndoPlanSkipsIrreversibleSteps(t *testing.T) {
+	run := Run{ID: "20260101T000000Z-00000000", Steps: []JournalStep{
+		{Action: Action{Type: "add", Service: "dhcp", Hostname: "nas.example.com", NewIP: "10.0.0.7"}, Applied: true},
+		{Action: Action{Type: "delete", Service: "pihole", Hostname: "www.example.com", OldIP: "app.example.com"}, Prior: PriorState{Exists: true, IP: "app.example.com"}, Applied: true},
+		{Action: Action{Type: "add", Service: "unbound", Hostname: "app.example.com", NewIP: "10.0.0.15"}, Applied: true},
+		{Action: Action{Type: "add", Service: "unbound", Hostname: "failed.example.com", NewIP: "10.0.0.15"}, Error: "boom"},
+	}}
+
+	plan, warnings, err := UndoPlan(run)
+	if err != nil {
+		t.Fatalf("UndoPlan failed: %v", err)
+	}
+	if len(plan.Actions) != 1 || plan.Actions[0].Hostname != "app.example.com" || plan.Actions[0].Type != "delete" {
+		t.Fatalf("expected only the Unbound add to be reverted, got %#v", plan.Actions)
+	}
+	if len(warnings) != 2 {
+		t.Fatalf("expected DHCP and Pi-hole CNAME warnings, got %#v", warnings)
+	}
+}
+
+func TestUndoPlanRestoresPriorAliases(t *testing.T) {
+	run := Run{ID: "20260101T000000Z-00000000", Steps: []JournalStep{
+		{Action: Action{Type: "update", Service: "unbound", Hostname: "www.example.com", OldIP: "app.example.com", NewIP: "10.0.0.15"}, Prior: PriorState{Exists: true, IP: "app.example.com"}, Applied: true},
+		{Action: Action{Type: "delete", Service: "adguard:iot", Hostname: "tv.example.com", OldIP: "media.example.com"}, Prior: PriorState{Exists: true, IP: "media.example.com"}, Applied: true},
+		{Action: Action{Type: "update", Service: "pihole", Hostname: "nas.example.com", OldIP: "storage.example.com", NewIP: "10.0.0.15"}, Prior: PriorState{Exists: true, IP: "storage.example.com"}, Applied: true},
+	}}
+
+	plan, warnings, err := UndoPlan(run)
+	if err != nil {
+		t.Fatalf("UndoPlan failed: %v", err)
+	}
+	want := []Action{
+		{Type: "add", Service: "adguard:iot", Hostname: "tv.example.com", NewIP: "media.example.com"},
+		{Type: "update", Service: "unbound", Hostname: "www.example.com", OldIP: "10.0.0.15", NewIP: "app.example.com"},
+	}
+	if len(plan.Actions) != len(want) {
+		t.Fatalf("expected %d inverse actions, got %#v", len(want), plan.Actions)
+	}
+	for i, action := range plan.Actions {
+		if action.Type != want[i].Type || action.Service != want[i].Service || action.Hostname != want[i].Hostname ||
+			action.OldIP != want[i].OldIP || action.NewIP != want[i].NewIP {
+			t.Errorf("inverse %d = %#v, want %#v", i, action, want[i])
+		}
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "cannot restore it as an alias") {
+		t.Fatalf("expected the Pi-hole CNAME to be refused, got %#v", warnings)
+	}
+}
+
+func TestCheckUndoSkipsRecordsChangedSinceTheRun(t *testing.T) {
+	pihole := &fakePiholeClient{hosts: []api.PiholeHost{
+		{IP: "10.0.0.15", Hostname: "app.example.com"},
+		{IP: "10.0.0.99", Hostname: "nas.example.com"},
+		{IP: "10.0.0.9", Hostname: "old.example.com"},
+	}}
+	clients := Clients{Pihole: pihole, Targets: []Target{&fakeTarget{name: "extra"}}}
+	run := Run{ID: "20260101T000000Z-00000000", Steps: []JournalStep{
+		{Action: Action{Type: "add", Service: "pihole", Hostname: "app.example.com", NewIP: "10.0.0.15"}, Applied: true},
+		{Action: Action{Type: "update", Service: "pihole", Hostname: "nas.example.com", OldIP: "10.0.0.7", NewIP: "10.0.0.15"}, Prior: PriorState{Exists: true, IP: "10.0.0.7"}, Applied: true},
+		{Action: Action{Type: "delete", Service: "pihole", Hostname: "old.example.com", OldIP: "10.0.0.8"}, Prior: PriorState{Exists: true, IP: "10.0.0.8"}, Applied: true},
+		{Action: Action{Type: "delete", Service: "pihole", Hostname: "gone.example.com", OldIP: "10.0.0.6"}, Prior: PriorState{Exists: true, IP: "10.0.0.6"}, Applied: true},
+		{Action: Action{Type: "add", Service: "extra", Hostname: "app.example.com", NewIP: "10.0.0.15"}, Applied: true},
+	}}
+	plan, _, err := UndoPlan(run)
+	if err != nil {
+		t.Fatalf("UndoPlan failed: %v", err)
+	}
+
+	checked, skipped, err := CheckUndo(context.Background(), clients, plan)
+	if err != nil {
+		t.Fatalf("CheckUndo failed: %v", err)
+	}
+	var kept []string
+	for _, action := range checked.Actions {
+		kept = append(kept, action.Type+" "+action.Service+" "+action.Hostname)
+	}
+	want := []string{"delete extra app.example.com", "add pihole gone.example.com", "delete pihole app.example.com"}
+	if !reflect.DeepEqual(kept, want) {
+		t.Fatalf("kept %v, want %v", kept, want)
+	}
+	if len(skipped) != 2 || !strings.Contains(skipped[0], "old.example.com") || !strings.Contains(skipped[1], "nas.example.com") {
+		t.Fatalf("expected the edited nas and re-added old records to be skipped, got %#v", skipped)
+	}
+}
+
+func TestJournalReadRejectsUnknownRuns(t *testing.T) {
+	journal := NewJournal(t.TempDir())
+	if _, err := journal.Read("../../etc/passwd"); err == nil {
+		t.Fatal("expected an invalid run ID to be rejected")
+	}
+	if _, err := journal.Read("20260101T000000Z-00000000"); !errors.Is(err, ErrRunNotFound) {
+		t.Fatalf("expected ErrRunNotFound, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package syncplan
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/jeeftor/caddy-dns-sync/internal/models"
@@ -14,6 +15,9 @@ type Action struct {
 	Service                string `json:"service"` // "unbound", "adguard", "dhcp", "cloudflare"
 	OldIP                  string `json:"old_ip"`
 	NewIP                  string `json:"new_ip"`
+	RecordType             string `json:"record_type,omitempty"` // "" for A (or a Pi-hole CNAME), RecordTypeAAAA
+	MAC                    string `json:"mac,omitempty"`
+	LeaseHostname          string `json:"lease_hostname,omitempty"` // device name from the DHCP lease, for "dhcp" actions
 	OldService             string `json:"old_service,omitempty"`
 	NewService             string `json:"new_service,omitempty"`
 	OldHTTPHostHeader      string `json:"old_http_host_header,omitempty"`
@@ -24,6 +28,8 @@ type Action struct {
 	NoTLSVerify            bool   `json:"no_tls_verify,omitempty"`
 	Http2Origin            bool   `json:"http2_origin,omitempty"`
 	OriginServerName       string `json:"origin_server_name,omitempty"`
+	OldOriginServerName    string `json:"old_origin_server_name,omitempty"`
+	OldNoTLSVerify         bool   `json:"old_no_tls_verify,omitempty"`
 	DisableChunkedEncoding bool   `json:"disable_chunked_encoding,omitempty"`
 	HasAccessPolicy        bool   `json:"has_access_policy,omitempty"`
 	ManagedFields          string `json:"managed_fields,omitempty"`
@@ -32,6 +38,14 @@ type Action struct {
 	Enabled                bool   `json:"enabled"`
 }
 
+// RecordTypeAAAA marks actions on IPv6 (AAAA) records.
+const RecordTypeAAAA = "AAAA"
+
+// IsAAAA reports whether the action changes an AAAA record.
+func (a Action) IsAAAA() bool {
+	return a.RecordType == RecordTypeAAAA
+}
+
 // Plan contains the actions selected for one sync operation.
 type Plan struct {
 	Actions []Action `json:"actions"`
@@ -46,6 +60,10 @@ type Result struct {
 	Errors        []string       `json:"errors"`
 	Message       string         `json:"message"`
 	ActionResults []ActionResult `json:"action_results"`
+	// RunID names the journal entry for this apply, when one was written.
+	RunID string `json:"run_id,omitempty"`
+	// Rollbacks reports each atomic group reverted after a failure.
+	Rollbacks []Rollback `json:"rollbacks,omitempty"`
 }
 
 // ActionResult records the outcome of one planned action.
@@ -54,12 +72,26 @@ type ActionResult struct {
 	Success bool   `json:"success"`
 	Skipped bool   `json:"skipped"`
 	Error   string `json:"error"`
+	// RolledBack is set when the action was applied and then reverted.
+	RolledBack bool `json:"rolled_back,omitempty"`
+}
+
+// Rollback reports how the applied actions of a failed atomic group were
+// reverted. Actions holds the inverse actions, last applied first.
+type Rollback struct {
+	Hostname string         `json:"hostname,omitempty"` // empty when the whole run was rolled back
+	Cause    string         `json:"cause"`
+	Success  bool           `json:"success"`
+	Actions  []ActionResult `json:"actions"`
 }
 
 // Options controls sync action planning.
 type Options struct {
-	Service           string
-	CaddyServerIP     string
+	Service       string
+	CaddyServerIP string
+	// CaddyServerIPv6 is the AAAA answer; BuildPlan takes it from each
+	// entry, so it only matters when diffing entries directly.
+	CaddyServerIPv6   string
 	CaddyServiceURL   string
 	IncludeCloudflare bool
 	// Unsync, when true, generates delete actions for entries that are currently
@@ -82,81 +114,63 @@ type Options struct {
 	// OverrideTunnelID writes the rule to a specific tunnel instead of the
 	// configured default. Empty means use the configured default.
 	OverrideTunnelID string
+
+	// Targets is the registry of sync targets to plan against. Nil means the
+	// built-in targets with no clients attached.
+	Targets *Registry
 }
 
 // BuildPlan creates a sync plan from entries for one service or all services.
+// Each selected target diffs every unique entry; targets are visited in
+// registry order so plans stay stable across runs.
 func BuildPlan(entries []*models.Entry, options Options) Plan {
-	services := servicesFor(options.Service, options.IncludeCloudflare)
+	registry := options.Targets
+	if registry == nil {
+		registry = Clients{}.Registry()
+	}
+	targets := registry.ForService(options)
 	uniqueEntries := uniqueEntriesByHostname(entries)
 	actions := make([]Action, 0)
 
 	for _, entry := range uniqueEntries {
-		for _, svc := range services {
-			var status models.ServiceStatus
-			var needsSync bool
-			var needsRemoval bool
-			var dhcpAction bool
-
-			switch svc {
-			case "unbound":
-				status = entry.UnboundStatus
-				if options.Unsync {
-					needsRemoval = status.Configured
-				} else {
-					needsSync = entry.NeedsSyncToUnbound()
-					needsRemoval = entry.NeedsRemovalFromUnbound()
-				}
-			case "adguard":
-				status = entry.AdguardStatus
-				if options.Unsync {
-					needsRemoval = status.Configured
-				} else {
-					needsSync = entry.NeedsSyncToAdguard()
-					needsRemoval = entry.NeedsRemovalFromAdguard()
-				}
-			case "dhcp":
-				needsSync = entry.NeedsDHCPStaticEntry()
-				dhcpAction = true
-			case "cloudflare":
-				action := buildCloudflareAction(entry, options)
-				if action.Type != "" {
-					actions = append(actions, action)
-				}
-				continue
-			default:
-				continue
-			}
-
-			if !needsSync && !needsRemoval {
-				continue
-			}
-
-			action := buildAction(entry, svc, status, needsRemoval, dhcpAction, options.CaddyServerIP, options.Unsync)
+		// Hostnames served by another Caddy point at that server instead.
+		entryOptions := options
+		if entry.CaddyServerIP != "" {
+			entryOptions.CaddyServerIP = entry.CaddyServerIP
+		}
+		entryOptions.CaddyServerIPv6 = entry.CaddyServerIPv6
+		for _, target := range targets {
+			action := target.Diff(entry, entryOptions)
 			if action.Type != "" {
 				actions = append(actions, action)
 			}
+			if aaaa, ok := target.(AAAATarget); ok {
+				if action := aaaa.DiffAAAA(entry, entryOptions); action.Type != "" {
+					actions = append(actions, action)
+				}
+			}
 		}
 	}
 
+	// Aliases need their canonical record, so address records go first.
+	sort.SliceStable(actions, func(i, j int) bool {
+		return !actions[i].pointsAtAlias() && actions[j].pointsAtAlias()
+	})
+
 	return Plan{Actions: actions}
 }
 
+// pointsAtAlias reports whether the action creates or changes a record that
+// answers with another hostname.
+func (a Action) pointsAtAlias() bool {
+	return a.Type != "delete" && models.IsAlias(a.NewIP)
+}
+
 // PlanFromEntries creates sync actions from entries for one service or all services.
 func PlanFromEntries(entries []*models.Entry, options Options) []Action {
 	return BuildPlan(entries, options).Actions
 }
 
-func servicesFor(service string, includeCloudflare bool) []string {
-	if service == "" || service == "all" {
-		services := []string{"unbound", "adguard"}
-		if includeCloudflare {
-			services = append(services, "cloudflare")
-		}
-		return services
-	}
-	return []string{service}
-}
-
 func uniqueEntriesByHostname(entries []*models.Entry) []*models.Entry {
 	seen := make(map[string]bool)
 	unique := make([]*models.Entry, 0, len(entries))
@@ -170,13 +184,28 @@ func uniqueEntriesByHostname(entries []*models.Entry) []*models.Entry {
 	return unique
 }
 
+// caddyAnswer returns the Caddy addresses options plans against.
+func (o Options) caddyAnswer() models.Answer {
+	return models.Answer{IPv4: o.CaddyServerIP, IPv6: o.CaddyServerIPv6}
+}
+
+// withTargetIP returns options with ip replacing the Caddy address of its
+// family, for targets that reach Caddy at another address.
+func (o Options) withTargetIP(ip string) Options {
+	answer := o.caddyAnswer().With(ip)
+	o.CaddyServerIP, o.CaddyServerIPv6 = answer.IPv4, answer.IPv6
+	return o
+}
+
+// buildAction plans one record for service. status describes the record of
+// the family being planned and answer is the address it should hold.
 func buildAction(
 	entry *models.Entry,
 	service string,
 	status models.ServiceStatus,
 	needsRemoval bool,
 	dhcpAction bool,
-	caddyServerIP string,
+	answer string,
 	unsync bool,
 ) Action {
 	action := Action{
@@ -197,14 +226,16 @@ func buildAction(
 	case dhcpAction:
 		action.Type = "add"
 		action.NewIP = entry.DHCPStatus.IP
+		action.MAC = entry.DHCPStatus.MAC
+		action.LeaseHostname = entry.DHCPStatus.Hostname
 		action.Details = fmt.Sprintf("static lease (MAC: %s)", entry.DHCPStatus.MAC)
-	case !status.Configured:
+	case !status.Configured || status.IP == "":
 		action.Type = "add"
-		action.NewIP = caddyServerIP
+		action.NewIP = answer
 	case !status.InSync:
 		action.Type = "update"
 		action.OldIP = status.IP
-		action.NewIP = caddyServerIP
+		action.NewIP = answer
 	}
 
 	return action
@@ -282,6 +313,8 @@ func buildCloudflareAction(entry *models.Entry, options Options) Action {
 			base.NewService = desiredService
 			base.OldHTTPHostHeader = cf.HTTPHostHeader
 			base.NewHTTPHostHeader = desiredHostHeader
+			base.OldOriginServerName = cf.OriginServerName
+			base.OldNoTLSVerify = cf.NoTLSVerify
 			if base.TunnelID == "" {
 				base.TunnelID = cf.TunnelID
 			}
@@ -308,6 +341,8 @@ func buildCloudflareAction(entry *models.Entry, options Options) Action {
 		base.Type = "delete"
 		base.OldService = cf.Service
 		base.OldHTTPHostHeader = cf.HTTPHostHeader
+		base.OldOriginServerName = cf.OriginServerName
+		base.OldNoTLSVerify = cf.NoTLSVerify
 		if base.TunnelID == "" {
 			base.TunnelID = cf.TunnelID
 		}
@@ -120,6 +120,73 @@ func TestPlanFromEntriesCreatesStaleDeleteActions(t *testing.T) {
 	})
 }
 
+func TestPlanFromEntriesPlansAAAARecordsNextToARecords(t *testing.T) {
+	missingAAAA := models.Synced("10.0.0.15")
+	missingAAAA.IPv6Mismatch = true
+	wrongAAAA := models.Synced("10.0.0.15")
+	wrongAAAA.IPv6, wrongAAAA.IPv6Mismatch = "fd00::99", true
+	stale := models.Synced("10.0.0.15")
+	stale.IPv6 = "fd00::15"
+
+	actions := PlanFromEntries([]*models.Entry{
+		{
+			Hostname:        "app.example.com",
+			CaddyUpstream:   "10.0.0.5:8080",
+			CaddyServerIPv6: "fd00::15",
+			UnboundStatus:   missingAAAA,
+			AdguardStatus:   wrongAAAA,
+			DHCPStatus:      models.NoDHCP(),
+		},
+		{
+			Hostname:      "stale.example.com",
+			UnboundStatus: stale,
+			AdguardStatus: models.NotConfigured(),
+			DHCPStatus:    models.NoDHCP(),
+		},
+	}, Options{
+		Service:       "all",
+		CaddyServerIP: "10.0.0.15",
+	})
+
+	if len(actions) != 4 {
+		t.Fatalf("expected 4 actions, got %d: %#v", len(actions), actions)
+	}
+	assertAction(t, actions[0], Action{
+		Type:       "add",
+		Service:    "unbound",
+		Hostname:   "app.example.com",
+		NewIP:      "fd00::15",
+		RecordType: RecordTypeAAAA,
+		Enabled:    true,
+	})
+	assertAction(t, actions[1], Action{
+		Type:       "update",
+		Service:    "adguard",
+		Hostname:   "app.example.com",
+		OldIP:      "fd00::99",
+		NewIP:      "fd00::15",
+		RecordType: RecordTypeAAAA,
+		Enabled:    true,
+	})
+	assertAction(t, actions[2], Action{
+		Type:     "delete",
+		Service:  "unbound",
+		Hostname: "stale.example.com",
+		OldIP:    "10.0.0.15",
+		Details:  "no longer in Caddy",
+		Enabled:  true,
+	})
+	assertAction(t, actions[3], Action{
+		Type:       "delete",
+		Service:    "unbound",
+		Hostname:   "stale.example.com",
+		OldIP:      "fd00::15",
+		Details:    "no longer in Caddy",
+		RecordType: RecordTypeAAAA,
+		Enabled:    true,
+	})
+}
+
 func TestPlanFromEntriesCreatesCloudflareAddUpdateAndDeleteActions(t *testing.T) {
 	actions := PlanFromEntries([]*models.Entry{
 		{
@@ -199,6 +266,7 @@ func TestPlanFromEntriesCreatesCloudflareAddUpdateAndDeleteActions(t *testing.T)
 		TunnelName:           "default",
 		Path:                 "/api/*",
 		NoTLSVerify:          false,
+		OldNoTLSVerify:       true,
 		OriginServerName:     "wrong.example.com",
 		HasAccessPolicy:      true,
 		Details:              "service and host header differ from Caddy",
@@ -258,12 +326,14 @@ func TestPlanFromEntriesCreatesDHCPStaticLeaseActions(t *testing.T) {
 		t.Fatalf("expected one DHCP action, got %d: %#v", len(actions), actions)
 	}
 	assertAction(t, actions[0], Action{
-		Type:     "add",
-		Service:  "dhcp",
-		Hostname: "device.example.com",
-		NewIP:    "10.0.0.5",
-		Details:  "static lease (MAC: aa:bb:cc:dd:ee:ff)",
-		Enabled:  true,
+		Type:          "add",
+		Service:       "dhcp",
+		Hostname:      "device.example.com",
+		NewIP:         "10.0.0.5",
+		MAC:           "aa:bb:cc:dd:ee:ff",
+		LeaseHostname: "device",
+		Details:       "static lease (MAC: aa:bb:cc:dd:ee:ff)",
+		Enabled:       true,
 	})
 }
 
@@ -282,7 +352,7 @@ func TestPlanFromEntriesExcludesDHCPFromDefaultAll(t *testing.T) {
 	})
 
 	if len(actions) != 0 {
-		t.Fatalf("expected default all plan to exclude unimplemented DHCP actions, got %#v", actions)
+		t.Fatalf("expected default all plan to exclude DHCP actions, got %#v", actions)
 	}
 }
 
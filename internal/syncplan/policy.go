@@ -0,0 +1,162 @@
+package syncplan
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/config"
+	"github.com/jeeftor/caddy-dns-sync/internal/models"
+)
+
+const (
+	// DefaultMaxDeletes is the number of deletes one run may make without
+	// AllowMassDelete.
+	DefaultMaxDeletes = 10
+	// DefaultMaxDeletePercent is the share of a target's records one run may
+	// delete without AllowMassDelete.
+	DefaultMaxDeletePercent = 50
+)
+
+// ErrMassDelete is returned when a run would delete more records than the
+// policy allows.
+var ErrMassDelete = errors.New("refusing mass delete")
+
+// Policy limits what one sync run may change. It guards against a Caddy
+// outage or parse error that leaves few or no hostnames, which would
+// otherwise plan deleting every record the sync owns.
+type Policy struct {
+	// MaxDeletes caps deletes per run across all targets; 0 disables it.
+	MaxDeletes int
+	// MaxDeletePercent caps deletes per target as a percentage of the
+	// records it holds; 0 disables it. A single delete is always allowed.
+	MaxDeletePercent int
+	// Protected holds hostname globs that are never updated or deleted.
+	Protected []string
+	// AllowMassDelete lifts both delete limits for this run.
+	AllowMassDelete bool
+}
+
+// NewPolicy builds a policy from configuration, filling in the default
+// limits for zero values and disabling limits set negative.
+func NewPolicy(cfg config.SyncPolicyConfig, allowMassDelete bool) Policy {
+	policy := Policy{
+		MaxDeletes:       limitOrDefault(cfg.MaxDeletes, DefaultMaxDeletes),
+		MaxDeletePercent: limitOrDefault(cfg.MaxDeletePercent, DefaultMaxDeletePercent),
+		AllowMassDelete:  allowMassDelete,
+	}
+	for _, pattern := range cfg.Protected {
+		policy.Protected = append(policy.Protected, strings.ToLower(pattern))
+	}
+	return policy
+}
+
+func limitOrDefault(value, fallback int) int {
+	switch {
+	case value < 0:
+		return 0
+	case value == 0:
+		return fallback
+	default:
+		return value
+	}
+}
+
+// IsProtected reports whether hostname matches a protected glob.
+func (p Policy) IsProtected(hostname string) bool {
+	hostname = strings.ToLower(strings.TrimSuffix(hostname, "."))
+	for _, pattern := range p.Protected {
+		if matched, _ := path.Match(pattern, hostname); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Unprotected splits hostnames into those sync may modify and those it must
+// leave alone.
+func (p Policy) Unprotected(hostnames []string) (allowed, protected []string) {
+	for _, hostname := range hostnames {
+		if p.IsProtected(hostname) {
+			protected = append(protected, hostname)
+			continue
+		}
+		allowed = append(allowed, hostname)
+	}
+	return allowed, protected
+}
+
+// CheckDeletes returns ErrMassDelete when deleting deletes of the owned
+// records in target exceeds either limit.
+func (p Policy) CheckDeletes(target string, deletes, owned int) error {
+	if p.AllowMassDelete || deletes == 0 {
+		return nil
+	}
+	if p.MaxDeletes > 0 && deletes > p.MaxDeletes {
+		return fmt.Errorf("%w: %d %s deletes exceed the limit of %d per run; re-run with --allow-mass-delete if this is intended",
+			ErrMassDelete, deletes, target, p.MaxDeletes)
+	}
+	if p.MaxDeletePercent > 0 && deletes > 1 && owned > 0 && deletes*100 > p.MaxDeletePercent*owned {
+		return fmt.Errorf("%w: %d of %d %s records (%d%%) exceed the limit of %d%% per run; re-run with --allow-mass-delete if this is intended",
+			ErrMassDelete, deletes, owned, target, deletes*100/owned, p.MaxDeletePercent)
+	}
+	return nil
+}
+
+// Enforce drops enabled updates and deletes of protected hostnames from
+// actions, returning them separately, and then checks the remaining deletes
+// against the limits. existing counts the records each target holds (see
+// ExistingRecords).
+func (p Policy) Enforce(actions []Action, existing map[string]int) (allowed, protected []Action, err error) {
+	deletes := make(map[string]int)
+	total := 0
+	for _, action := range actions {
+		if action.Enabled && action.Type != "add" && p.IsProtected(action.Hostname) {
+			protected = append(protected, action)
+			continue
+		}
+		allowed = append(allowed, action)
+		if action.Enabled && action.Type == "delete" {
+			deletes[action.Service]++
+			total++
+		}
+	}
+
+	if p.AllowMassDelete {
+		return allowed, protected, nil
+	}
+	if p.MaxDeletes > 0 && total > p.MaxDeletes {
+		return allowed, protected, fmt.Errorf("%w: %d deletes exceed the limit of %d per run; re-run with --allow-mass-delete if this is intended",
+			ErrMassDelete, total, p.MaxDeletes)
+	}
+	services := make([]string, 0, len(deletes))
+	for service := range deletes {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+	for _, service := range services {
+		if err := p.CheckDeletes(service, deletes[service], existing[service]); err != nil {
+			return allowed, protected, err
+		}
+	}
+	return allowed, protected, nil
+}
+
+// ExistingRecords counts, per target name, the entries that currently have
+// a record the sync manages there.
+func ExistingRecords(entries []*models.Entry) map[string]int {
+	existing := make(map[string]int)
+	for _, entry := range uniqueEntriesByHostname(entries) {
+		for target, status := range entry.DNSStatuses() {
+			if status.Configured && !status.Foreign {
+				existing[target]++
+			}
+		}
+		if entry.CloudflareStatus.Configured {
+			existing["cloudflare"]++
+		}
+	}
+	return existing
+}
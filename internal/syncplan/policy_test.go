@@ -0,0 +1,83 @@
+package syncplan
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/config"
+)
+
+func deleteActions(service string, n int) []Action {
+	actions := make([]Action, 0, n)
+	for i := 0; i < n; i++ {
+		actions = append(actions, Action{
+			Type: "delete", Service: service, Hostname: string(rune('a'+i)) + ".example.com",
+			OldIP: "10.0.0.5", Enabled: true,
+		})
+	}
+	return actions
+}
+
+func TestNewPolicyDefaultsAndDisabledLimits(t *testing.T) {
+	policy := NewPolicy(config.SyncPolicyConfig{Protected: []string{"*.PROD.example.com"}}, false)
+	if policy.MaxDeletes != DefaultMaxDeletes || policy.MaxDeletePercent != DefaultMaxDeletePercent {
+		t.Fatalf("expected default limits, got %#v", policy)
+	}
+	if !policy.IsProtected("api.prod.example.com.") || policy.IsProtected("api.dev.example.com") {
+		t.Fatalf("unexpected protection for %#v", policy.Protected)
+	}
+
+	disabled := NewPolicy(config.SyncPolicyConfig{MaxDeletes: -1, MaxDeletePercent: -1}, false)
+	if err := disabled.CheckDeletes("adguard", 100, 100); err != nil {
+		t.Fatalf("expected disabled limits to allow any delete, got %v", err)
+	}
+}
+
+func TestPolicyEnforceDropsProtectedHostnames(t *testing.T) {
+	policy := NewPolicy(config.SyncPolicyConfig{Protected: []string{"*.prod.example.com"}}, false)
+	allowed, protected, err := policy.Enforce([]Action{
+		{Type: "add", Service: "adguard", Hostname: "new.prod.example.com", NewIP: "10.0.0.5", Enabled: true},
+		{Type: "update", Service: "adguard", Hostname: "api.prod.example.com", OldIP: "10.0.0.4", NewIP: "10.0.0.5", Enabled: true},
+		{Type: "delete", Service: "adguard", Hostname: "db.prod.example.com", OldIP: "10.0.0.6", Enabled: true},
+		{Type: "delete", Service: "adguard", Hostname: "old.example.com", OldIP: "10.0.0.7", Enabled: true},
+	}, map[string]int{"adguard": 10})
+	if err != nil {
+		t.Fatalf("Enforce failed: %v", err)
+	}
+	if len(allowed) != 2 || allowed[0].Hostname != "new.prod.example.com" || allowed[1].Hostname != "old.example.com" {
+		t.Fatalf("expected the add and the unprotected delete to remain, got %#v", allowed)
+	}
+	if len(protected) != 2 {
+		t.Fatalf("expected two protected actions, got %#v", protected)
+	}
+}
+
+func TestPolicyEnforceRefusesMassDeletes(t *testing.T) {
+	policy := NewPolicy(config.SyncPolicyConfig{MaxDeletes: 3}, false)
+	if _, _, err := policy.Enforce(deleteActions("adguard", 4), map[string]int{"adguard": 100}); !errors.Is(err, ErrMassDelete) {
+		t.Fatalf("expected the count limit to refuse 4 deletes, got %v", err)
+	}
+
+	policy = NewPolicy(config.SyncPolicyConfig{MaxDeletePercent: 50}, false)
+	if _, _, err := policy.Enforce(deleteActions("unbound", 3), map[string]int{"unbound": 4}); !errors.Is(err, ErrMassDelete) {
+		t.Fatalf("expected the percentage limit to refuse 3 of 4 deletes, got %v", err)
+	}
+	if _, _, err := policy.Enforce(deleteActions("unbound", 2), map[string]int{"unbound": 4}); err != nil {
+		t.Fatalf("expected 2 of 4 deletes to be allowed, got %v", err)
+	}
+	if _, _, err := policy.Enforce(deleteActions("unbound", 1), map[string]int{"unbound": 1}); err != nil {
+		t.Fatalf("expected a single delete to be allowed, got %v", err)
+	}
+
+	disabled := deleteActions("unbound", 3)
+	disabled[0].Enabled = false
+	disabled[1].Enabled = false
+	if _, _, err := policy.Enforce(disabled, map[string]int{"unbound": 4}); err != nil {
+		t.Fatalf("expected disabled deletes not to count, got %v", err)
+	}
+
+	policy.AllowMassDelete = true
+	if _, _, err := policy.Enforce(deleteActions("unbound", 4), map[string]int{"unbound": 4}); err != nil {
+		t.Fatalf("expected AllowMassDelete to lift the limits, got %v", err)
+	}
+}
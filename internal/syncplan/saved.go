@@ -0,0 +1,158 @@
+package syncplan
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/models"
+)
+
+// SavedPlanVersion is the format version written to saved plan files.
+const SavedPlanVersion = 1
+
+// ErrStalePlan is returned when live state no longer matches the state a
+// saved plan was built from.
+var ErrStalePlan = errors.New("observed state has changed since the plan was saved")
+
+// SavedPlan is a reviewed plan written to disk. Fingerprint covers the state
+// of Caddy and of the sync targets the plan was built for, so it is applied
+// only if nothing it depends on has changed in between.
+type SavedPlan struct {
+	Version       int       `json:"version"`
+	CreatedAt     time.Time `json:"created_at"`
+	Service       string    `json:"service"`
+	CaddyServerIP string    `json:"caddy_server_ip"`
+	// Targets names the sync targets the plan was built for; Fingerprint
+	// covers only their records.
+	Targets     []string `json:"targets"`
+	Fingerprint string   `json:"fingerprint"`
+	Plan        Plan     `json:"plan"`
+}
+
+// NewSavedPlan wraps plan with the fingerprint of the entries it was built
+// from, limited to the targets options selects.
+func NewSavedPlan(plan Plan, entries []*models.Entry, options Options) SavedPlan {
+	registry := options.Targets
+	if registry == nil {
+		registry = Clients{}.Registry()
+	}
+	selected := registry.ForService(options)
+	targets := make([]string, 0, len(selected))
+	for _, target := range selected {
+		targets = append(targets, target.Name())
+	}
+	return SavedPlan{
+		Version:       SavedPlanVersion,
+		CreatedAt:     time.Now().UTC(),
+		Service:       options.Service,
+		CaddyServerIP: options.CaddyServerIP,
+		Targets:       targets,
+		Fingerprint:   Fingerprint(entries, targets),
+		Plan:          plan,
+	}
+}
+
+// Verify returns ErrStalePlan when entries, freshly loaded, no longer match
+// the saved fingerprint.
+func (s SavedPlan) Verify(entries []*models.Entry) error {
+	if current := Fingerprint(entries, s.Targets); current != s.Fingerprint {
+		return fmt.Errorf("%w (saved %.12s, now %.12s)", ErrStalePlan, s.Fingerprint, current)
+	}
+	return nil
+}
+
+// WriteSavedPlan writes a saved plan as indented JSON.
+func WriteSavedPlan(path string, saved SavedPlan) error {
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding plan: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("error writing plan: %w", err)
+	}
+	return nil
+}
+
+// ReadSavedPlan reads a plan written by WriteSavedPlan.
+func ReadSavedPlan(path string) (SavedPlan, error) {
+	var saved SavedPlan
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return saved, fmt.Errorf("error reading plan: %w", err)
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return saved, fmt.Errorf("error parsing plan %s: %w", path, err)
+	}
+	if saved.Version != SavedPlanVersion {
+		return saved, fmt.Errorf("plan %s has unsupported version %d (want %d)", path, saved.Version, SavedPlanVersion)
+	}
+	if saved.Fingerprint == "" {
+		return saved, fmt.Errorf("plan %s has no state fingerprint", path)
+	}
+	if len(saved.Targets) == 0 {
+		return saved, fmt.Errorf("plan %s names no sync targets", path)
+	}
+	return saved, nil
+}
+
+// Fingerprint hashes the observed state behind entries: each hostname's
+// Caddy route and answer, and its record in each of the named sync targets,
+// including its DHCP lease and Cloudflare rule when those are named. Other
+// targets are left out so a plan for one target does not go stale when
+// another drifts, as are DNS resolution results, which change without
+// anything being edited.
+func Fingerprint(entries []*models.Entry, names []string) string {
+	selected := make(map[string]bool, len(names))
+	for _, name := range names {
+		selected[name] = true
+	}
+	include := func(name string) bool { return selected[name] }
+
+	lines := make([]string, 0, len(entries))
+	for _, entry := range uniqueEntriesByHostname(entries) {
+		var b strings.Builder
+		fmt.Fprintf(&b, "%s|caddy=%s,%s,%s|static=%t,%s", entry.Hostname,
+			entry.CaddyUpstream, entry.CaddyServerIP, entry.CaddyServer, entry.Static, entry.StaticIP)
+		if entry.CaddyServerIPv6 != "" {
+			fmt.Fprintf(&b, ",v6=%s", entry.CaddyServerIPv6)
+		}
+
+		statuses := entry.DNSStatuses()
+		targets := make([]string, 0, len(statuses))
+		for target := range statuses {
+			if include(target) {
+				targets = append(targets, target)
+			}
+		}
+		sort.Strings(targets)
+		for _, target := range targets {
+			status := statuses[target]
+			fmt.Fprintf(&b, "|%s=%t,%s,%t,%t", target, status.Configured, status.IP, status.InSync, status.Foreign)
+			if status.IPv6 != "" || status.IPv6Mismatch {
+				fmt.Fprintf(&b, ",v6=%s,%t", status.IPv6, status.IPv6Mismatch)
+			}
+		}
+
+		if include("dhcp") {
+			dhcp := entry.DHCPStatus
+			fmt.Fprintf(&b, "|dhcp=%t,%s,%s,%s", dhcp.Configured, dhcp.Type, dhcp.IP, dhcp.MAC)
+		}
+		if include("cloudflare") {
+			cf := entry.CloudflareStatus
+			fmt.Fprintf(&b, "|cloudflare=%t,%s,%s,%s,%s,%s,%t,%t,%t", cf.Configured, cf.TunnelID, cf.Service,
+				cf.Path, cf.HTTPHostHeader, cf.OriginServerName, cf.NoTLSVerify, cf.Http2Origin, cf.HasDNSRecord)
+		}
+		lines = append(lines, b.String())
+	}
+	sort.Strings(lines)
+
+	sum := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+	return hex.EncodeToString(sum[:])
+}
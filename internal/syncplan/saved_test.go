@@ -0,0 +1,91 @@
+package syncplan
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/models"
+)
+
+func savedPlanEntries() []*models.Entry {
+	return []*models.Entry{
+		{
+			Hostname:      "app.example.com",
+			CaddyUpstream: "10.0.0.5:8080",
+			UnboundStatus: models.NotConfigured(),
+			AdguardStatus: models.Synced("10.0.0.15"),
+			DHCPStatus:    models.NoDHCP(),
+		},
+		{
+			Hostname:      "old.example.com",
+			UnboundStatus: models.Synced("10.0.0.15"),
+			AdguardStatus: models.NotConfigured(),
+			DHCPStatus:    models.NoDHCP(),
+		},
+	}
+}
+
+func TestSavedPlanRoundTripAndVerify(t *testing.T) {
+	entries := savedPlanEntries()
+	options := Options{Service: "unbound", CaddyServerIP: "10.0.0.15"}
+	plan := BuildPlan(entries, options)
+	if len(plan.Actions) != 2 {
+		t.Fatalf("expected an add and a delete, got %#v", plan.Actions)
+	}
+
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := WriteSavedPlan(path, NewSavedPlan(plan, entries, options)); err != nil {
+		t.Fatalf("WriteSavedPlan failed: %v", err)
+	}
+	saved, err := ReadSavedPlan(path)
+	if err != nil {
+		t.Fatalf("ReadSavedPlan failed: %v", err)
+	}
+	if saved.Service != "unbound" || saved.CaddyServerIP != "10.0.0.15" || len(saved.Plan.Actions) != 2 {
+		t.Fatalf("unexpected saved plan: %#v", saved)
+	}
+
+	// Entry order and DNS resolution do not affect the fingerprint.
+	reloaded := savedPlanEntries()
+	reloaded[0], reloaded[1] = reloaded[1], reloaded[0]
+	reloaded[0].DNSResolved = "10.0.0.15"
+	if err := saved.Verify(reloaded); err != nil {
+		t.Fatalf("expected unchanged state to verify, got %v", err)
+	}
+
+	// The plan only covers Unbound, so AdGuard drifting leaves it valid.
+	reloaded[1].AdguardStatus = models.NotInSync("10.0.0.99")
+	if err := saved.Verify(reloaded); err != nil {
+		t.Fatalf("expected AdGuard drift not to affect an Unbound plan, got %v", err)
+	}
+
+	reloaded[1].UnboundStatus = models.NotInSync("10.0.0.99")
+	if err := saved.Verify(reloaded); !errors.Is(err, ErrStalePlan) {
+		t.Fatalf("expected ErrStalePlan after Unbound drifted, got %v", err)
+	}
+}
+
+func TestReadSavedPlanRequiresTargets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	saved := NewSavedPlan(Plan{}, savedPlanEntries(), Options{Service: "unbound"})
+	saved.Targets = nil
+	if err := WriteSavedPlan(path, saved); err != nil {
+		t.Fatalf("WriteSavedPlan failed: %v", err)
+	}
+	if _, err := ReadSavedPlan(path); err == nil {
+		t.Fatal("expected an error for a plan without targets")
+	}
+}
+
+func TestReadSavedPlanRejectsUnknownVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	saved := NewSavedPlan(Plan{}, nil, Options{})
+	saved.Version = SavedPlanVersion + 1
+	if err := WriteSavedPlan(path, saved); err != nil {
+		t.Fatalf("WriteSavedPlan failed: %v", err)
+	}
+	if _, err := ReadSavedPlan(path); err == nil {
+		t.Fatal("expected an error for an unsupported plan version")
+	}
+}
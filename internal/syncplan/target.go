@@ -0,0 +1,176 @@
+package syncplan
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/models"
+)
+
+// Target is a sync backend that plans are built and applied against.
+// Built-in targets cover Unbound, AdGuard Home, Cloudflare tunnels and DHCP;
+// additional DNS backends implement this interface and are added to a Registry
+// without touching BuildPlan, Apply or the status loader.
+type Target interface {
+	// Name is the service identifier carried in Action.Service (e.g. "unbound").
+	Name() string
+	// Label is the human-readable name shown in UIs and logs (e.g. "Unbound").
+	Label() string
+	// Available reports whether a client is configured for this target.
+	Available() bool
+	// Diff returns the action needed to reconcile entry with this target, or
+	// an Action with an empty Type when nothing needs to change.
+	Diff(entry *models.Entry, options Options) Action
+	// Apply performs one add, update or delete action.
+	Apply(ctx context.Context, action Action) error
+	// Commit finalizes a batch of applied actions (e.g. restarting Unbound).
+	// It is only called when at least one action succeeded and the run is not
+	// a dry run. The returned note, when non-empty, is appended to the result
+	// message.
+	Commit(ctx context.Context) (string, error)
+}
+
+// Record is one DNS answer a target currently holds for a hostname.
+type Record struct {
+	Hostname string
+	Answer   string
+	// AnswerIPv6 is the hostname's AAAA answer. Targets list each family as
+	// its own Record; the status loader merges them into one.
+	AnswerIPv6 string
+	// Owned is true when the record carries caddy-dns-sync's ownership marker.
+	Owned bool
+}
+
+// RecordLister is implemented by targets that keep per-hostname DNS records.
+// The status loader lists every available RecordLister and records the
+// result on each entry via models.Entry.SetStatusFor.
+type RecordLister interface {
+	Target
+	Records(ctx context.Context) ([]Record, error)
+}
+
+// OwnershipTracker is implemented by record listers whose records carry an
+// ownership marker. The status loader flags unmarked records as foreign
+// (models.ServiceStatus.Foreign) so the target can leave them alone.
+type OwnershipTracker interface {
+	TracksOwnership() bool
+}
+
+// AAAATarget is implemented by record listers that keep IPv6 (AAAA) records
+// next to their A records. Records lists both families; DiffAAAA plans the
+// AAAA record the way Diff plans the A record, returning an action with
+// RecordType RecordTypeAAAA.
+type AAAATarget interface {
+	DiffAAAA(entry *models.Entry, options Options) Action
+}
+
+// AliasTarget is implemented by targets whose records can answer with a
+// hostname instead of an address: an Unbound host alias or an AdGuard
+// CNAME-style rewrite. In alias mode the status loader expects such records,
+// pointing at the canonical hostname, for every hostname on the main Caddy
+// server; other targets keep per-hostname A records.
+type AliasTarget interface {
+	SupportsAliases() bool
+}
+
+// TargetIPProvider is implemented by targets whose records should answer with
+// an address other than the Caddy server IP. An empty TargetIP means the
+// default. The status loader uses it to decide whether a record is in sync.
+type TargetIPProvider interface {
+	TargetIP() string
+}
+
+// defaultPlanner lets a target decide whether it takes part in "all" plans.
+// Targets that do not implement it are always included.
+type defaultPlanner interface {
+	IncludeInAll(options Options) bool
+}
+
+// Registry is an ordered set of sync targets keyed by name.
+type Registry struct {
+	targets []Target
+	byName  map[string]Target
+}
+
+// NewRegistry creates a registry holding the given targets in order.
+// Later targets with a duplicate name replace earlier ones.
+func NewRegistry(targets ...Target) *Registry {
+	registry := &Registry{byName: make(map[string]Target)}
+	for _, target := range targets {
+		registry.Register(target)
+	}
+	return registry
+}
+
+// Register adds a target, replacing any existing target with the same name
+// while keeping its original position.
+func (r *Registry) Register(target Target) {
+	if target == nil {
+		return
+	}
+	name := target.Name()
+	if _, exists := r.byName[name]; exists {
+		for i, existing := range r.targets {
+			if existing.Name() == name {
+				r.targets[i] = target
+			}
+		}
+	} else {
+		r.targets = append(r.targets, target)
+	}
+	r.byName[name] = target
+}
+
+// Lookup returns the target registered under name.
+func (r *Registry) Lookup(name string) (Target, bool) {
+	target, ok := r.byName[name]
+	return target, ok
+}
+
+// Targets returns the registered targets in registration order.
+func (r *Registry) Targets() []Target {
+	return append([]Target(nil), r.targets...)
+}
+
+// Names returns the registered target names in registration order.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.targets))
+	for _, target := range r.targets {
+		names = append(names, target.Name())
+	}
+	return names
+}
+
+// RecordListers returns the registered targets that can list DNS records.
+func (r *Registry) RecordListers() []RecordLister {
+	listers := make([]RecordLister, 0, len(r.targets))
+	for _, target := range r.targets {
+		if lister, ok := target.(RecordLister); ok {
+			listers = append(listers, lister)
+		}
+	}
+	return listers
+}
+
+// ForService returns the targets selected by an Options.Service value.
+// "" and "all" select every target that opts in to default plans.
+func (r *Registry) ForService(options Options) []Target {
+	if options.Service != "" && options.Service != "all" {
+		if target, ok := r.Lookup(options.Service); ok {
+			return []Target{target}
+		}
+		return nil
+	}
+	selected := make([]Target, 0, len(r.targets))
+	for _, target := range r.targets {
+		if planner, ok := target.(defaultPlanner); ok && !planner.IncludeInAll(options) {
+			continue
+		}
+		selected = append(selected, target)
+	}
+	return selected
+}
+
+func errClientUnavailable(label string) error {
+	return fmt.Errorf("%s client not available", label)
+}
@@ -0,0 +1,538 @@
+package syncplan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/api"
+	"github.com/jeeftor/caddy-dns-sync/internal/app"
+	"github.com/jeeftor/caddy-dns-sync/internal/models"
+)
+
+func TestRegisteredTargetIsPlannedAndApplied(t *testing.T) {
+	fake := &fakeTarget{name: "fake"}
+	clients := Clients{Targets: []Target{fake}}
+
+	entry := &models.Entry{
+		Hostname:      "app.example.com",
+		CaddyUpstream: "10.0.0.5:8080",
+	}
+	entry.SetStatusFor("fake", models.NotInSync("10.0.0.99"))
+
+	plan := BuildPlan([]*models.Entry{entry}, Options{
+		Service:       "fake",
+		CaddyServerIP: "10.0.0.15",
+		Targets:       clients.Registry(),
+	})
+	if len(plan.Actions) != 1 {
+		t.Fatalf("expected one action, got %#v", plan.Actions)
+	}
+	if got := plan.Actions[0]; got.Type != "update" || got.Service != "fake" || got.NewIP != "10.0.0.15" {
+		t.Fatalf("unexpected action: %#v", got)
+	}
+
+	result := Apply(context.Background(), clients, plan, ApplyOptions{})
+	if !result.Success {
+		t.Fatalf("expected success, got errors: %#v", result.Errors)
+	}
+	if len(fake.applied) != 1 || fake.commits != 1 {
+		t.Fatalf("expected one apply and one commit, got applied=%#v commits=%d", fake.applied, fake.commits)
+	}
+	if result.Message != "All operations completed successfully (fake committed)" {
+		t.Fatalf("unexpected message: %q", result.Message)
+	}
+}
+
+func TestRegistryRegisterReplacesInPlace(t *testing.T) {
+	registry := Clients{}.Registry()
+	replacement := &fakeTarget{name: "adguard"}
+	registry.Register(replacement)
+	registry.Register(&fakeTarget{name: "extra"})
+
+	want := []string{"unbound", "adguard", "dnsmasq", "pihole", "rfc2136", "dhcp", "cloudflare", "unbound-domains", "extra"}
+	if got := registry.Names(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Names() = %v, want %v", got, want)
+	}
+	if got, _ := registry.Lookup("adguard"); got != replacement {
+		t.Fatalf("expected adguard to be replaced, got %#v", got)
+	}
+	// The replacement adguard target cannot list records.
+	listers := registry.RecordListers()
+	names := make([]string, 0, len(listers))
+	for _, lister := range listers {
+		names = append(names, lister.Name())
+	}
+	if !reflect.DeepEqual(names, []string{"unbound", "dnsmasq", "pihole", "rfc2136"}) {
+		t.Fatalf("RecordListers() = %v, want [unbound dnsmasq pihole rfc2136]", names)
+	}
+}
+
+func TestBuildPlanAllSkipsOptedOutTargets(t *testing.T) {
+	entry := &models.Entry{
+		Hostname:      "app.example.com",
+		CaddyUpstream: "10.0.0.5:8080",
+	}
+
+	plan := BuildPlan([]*models.Entry{entry}, Options{
+		Service:       "all",
+		CaddyServerIP: "10.0.0.15",
+		Targets:       Clients{Targets: []Target{&fakeTarget{name: "fake"}}}.Registry(),
+	})
+
+	services := make([]string, 0, len(plan.Actions))
+	for _, action := range plan.Actions {
+		services = append(services, action.Service)
+	}
+	want := []string{"unbound", "adguard", "fake"}
+	if !reflect.DeepEqual(services, want) {
+		t.Fatalf("planned services = %v, want %v", services, want)
+	}
+}
+
+func TestPiholeTargetSkipsEntriesWithoutPiholeStatus(t *testing.T) {
+	entry := &models.Entry{
+		Hostname:      "app.example.com",
+		CaddyUpstream: "10.0.0.5:8080",
+	}
+
+	plan := BuildPlan([]*models.Entry{entry}, Options{Service: "pihole", CaddyServerIP: "10.0.0.15"})
+	if len(plan.Actions) != 0 {
+		t.Fatalf("expected no Pi-hole actions without Pi-hole data, got %#v", plan.Actions)
+	}
+
+	entry.SetStatusFor("pihole", models.ServiceStatus{})
+	plan = BuildPlan([]*models.Entry{entry}, Options{Service: "pihole", CaddyServerIP: "10.0.0.15"})
+	if len(plan.Actions) != 1 || plan.Actions[0].Type != "add" {
+		t.Fatalf("expected one Pi-hole add, got %#v", plan.Actions)
+	}
+}
+
+func TestPiholeTargetReplacesCNAMEWithHost(t *testing.T) {
+	client := &fakePiholeClient{
+		cnames: []api.PiholeCNAME{{Domain: "app.example.com", Target: "proxy.example.com", TTL: 300}},
+	}
+	target := NewPiholeTarget(client)
+
+	records, err := target.Records(context.Background())
+	if err != nil {
+		t.Fatalf("Records failed: %v", err)
+	}
+	if len(records) != 1 || records[0].Answer != "proxy.example.com" {
+		t.Fatalf("unexpected records: %#v", records)
+	}
+
+	err = target.Apply(context.Background(), Action{
+		Type:     "update",
+		Hostname: "app.example.com",
+		Service:  "pihole",
+		OldIP:    "proxy.example.com",
+		NewIP:    "10.0.0.15",
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if len(client.cnames) != 0 {
+		t.Fatalf("expected CNAME to be removed, got %#v", client.cnames)
+	}
+	if len(client.hosts) != 1 || client.hosts[0] != (api.PiholeHost{IP: "10.0.0.15", Hostname: "app.example.com"}) {
+		t.Fatalf("expected host record, got %#v", client.hosts)
+	}
+}
+
+func TestRFC2136TargetLeavesForeignRecordsAlone(t *testing.T) {
+	// Not in Caddy, but not ours to delete.
+	printer := &models.Entry{Hostname: "printer.example.com"}
+	printer.SetStatusFor("rfc2136", models.ServiceStatus{Configured: true, IP: "10.0.0.50", Foreign: true})
+	// In Caddy with the wrong address, but not ours to update.
+	nas := &models.Entry{Hostname: "nas.example.com", CaddyUpstream: "10.0.0.7:5000"}
+	nas.SetStatusFor("rfc2136", models.ServiceStatus{Configured: true, IP: "10.0.0.7", Foreign: true})
+	// Ours and no longer in Caddy.
+	owned := &models.Entry{Hostname: "app.example.com"}
+	owned.SetStatusFor("rfc2136", models.Synced("10.0.0.15"))
+	// Loaded without an RFC 2136 client.
+	unloaded := &models.Entry{Hostname: "new.example.com", CaddyUpstream: "10.0.0.8:80"}
+
+	plan := BuildPlan([]*models.Entry{printer, nas, owned, unloaded}, Options{
+		Service:       "rfc2136",
+		CaddyServerIP: "10.0.0.15",
+	})
+	if len(plan.Actions) != 1 {
+		t.Fatalf("expected only the owned stale record to be planned, got %#v", plan.Actions)
+	}
+	if got := plan.Actions[0]; got.Type != "delete" || got.Hostname != "app.example.com" {
+		t.Fatalf("unexpected action: %#v", got)
+	}
+}
+
+func TestDNSMasqTargetUpdatesHostAndReconfigures(t *testing.T) {
+	client := &fakeDNSMasqClient{
+		hosts: []api.DNSMasqHost{
+			{UUID: "uuid-nas", Host: "nas", Domain: "example.com", IP: "10.0.0.7", Description: "hand-made"},
+			{UUID: "uuid-tv", Host: "tv", Domain: "example.com", IP: "10.0.0.9", HWAddr: "aa:bb:cc:dd:ee:ff"},
+		},
+	}
+	entry := &models.Entry{Hostname: "nas.example.com", CaddyUpstream: "10.0.0.7:5000"}
+	entry.SetStatusFor("dnsmasq", models.ServiceStatus{Configured: true, IP: "10.0.0.7"})
+	unloaded := &models.Entry{Hostname: "new.example.com", CaddyUpstream: "10.0.0.8:80"}
+
+	// Without a client, i.e. dnsmasq_hosts disabled, Dnsmasq stays out of
+	// "all" plans; with one it joins them.
+	for _, action := range BuildPlan([]*models.Entry{entry, unloaded}, Options{CaddyServerIP: "10.0.0.15"}).Actions {
+		if action.Service == "dnsmasq" {
+			t.Fatalf("expected dnsmasq without a client to be left out of all plans, got %#v", action)
+		}
+	}
+	clients := Clients{DNSMasq: client}
+	allPlan := BuildPlan([]*models.Entry{entry, unloaded}, Options{CaddyServerIP: "10.0.0.15", Targets: clients.Registry()})
+	var dnsmasqActions int
+	for _, action := range allPlan.Actions {
+		if action.Service == "dnsmasq" {
+			dnsmasqActions++
+		}
+	}
+	if dnsmasqActions != 1 {
+		t.Fatalf("expected dnsmasq to join all plans, got %#v", allPlan.Actions)
+	}
+	plan := BuildPlan([]*models.Entry{entry, unloaded}, Options{
+		Service:       "dnsmasq",
+		CaddyServerIP: "10.0.0.15",
+		Targets:       clients.Registry(),
+	})
+	if len(plan.Actions) != 1 || plan.Actions[0].Type != "update" {
+		t.Fatalf("expected one dnsmasq update, got %#v", plan.Actions)
+	}
+
+	result := Apply(context.Background(), clients, plan, ApplyOptions{})
+	if !result.Success {
+		t.Fatalf("expected success, got %#v", result.Errors)
+	}
+	if len(client.updated) != 1 {
+		t.Fatalf("expected one host update, got %#v", client.updated)
+	}
+	got := client.updated[0]
+	if got.UUID != "uuid-nas" || got.IP != "10.0.0.15" || got.Description != app.CurrentUnboundDescription {
+		t.Fatalf("unexpected updated host: %#v", got)
+	}
+	if !client.reconfigured {
+		t.Fatal("expected Dnsmasq to be reconfigured")
+	}
+
+	// Hosts pinned to a MAC address are DHCP reservations and are not touched.
+	target, _ := clients.Registry().Lookup("dnsmasq")
+	err := target.Apply(context.Background(), Action{Type: "delete", Hostname: "tv.example.com", Service: "dnsmasq", OldIP: "10.0.0.9"})
+	if err == nil || !strings.Contains(err.Error(), "DHCP reservation") {
+		t.Fatalf("expected reservation to be refused, got %v", err)
+	}
+	if len(client.deleted) != 0 {
+		t.Fatalf("expected no deletions, got %#v", client.deleted)
+	}
+
+	// Nor are they listed, so they never show up as stale host overrides.
+	records, err := target.(RecordLister).Records(context.Background())
+	if err != nil {
+		t.Fatalf("Records failed: %v", err)
+	}
+	if len(records) != 1 || records[0].Hostname != "nas.example.com" {
+		t.Fatalf("expected only the nas host override, got %#v", records)
+	}
+}
+
+func TestUnboundInstanceTargetsPlanAndApplyIndependently(t *testing.T) {
+	fwB := &fakeUnboundClient{addErr: fmt.Errorf("connection refused")}
+	site2 := &fakeUnboundClient{}
+	clients := NewClients(app.ClientSet{UnboundInstances: []app.UnboundInstance{
+		{Name: "fw-b", Client: nil},
+	}})
+	if len(clients.Targets) != 0 {
+		t.Fatalf("expected instances without a client to be skipped, got %#v", clients.Targets)
+	}
+	clients = Clients{Targets: []Target{
+		NewUnboundInstanceTarget("fw-b", "", fwB),
+		NewUnboundInstanceTarget("site-2", "10.2.0.15", site2),
+	}}
+
+	entry := &models.Entry{Hostname: "app.example.com", CaddyUpstream: "10.0.0.5:8080"}
+	entry.SetStatusFor("unbound:fw-b", models.NotConfigured())
+	entry.SetStatusFor("unbound:site-2", models.NotConfigured())
+
+	plan := BuildPlan([]*models.Entry{entry}, Options{CaddyServerIP: "10.0.0.15", Targets: clients.Registry()})
+	var got []string
+	var actions []Action
+	for _, action := range plan.Actions {
+		if strings.HasPrefix(action.Service, UnboundInstancePrefix) {
+			got = append(got, action.Service+" "+action.Type+" "+action.NewIP)
+			actions = append(actions, action)
+		}
+	}
+	want := []string{"unbound:fw-b add 10.0.0.15", "unbound:site-2 add 10.2.0.15"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected instance actions: got %v, want %v", got, want)
+	}
+	plan.Actions = actions
+
+	result := Apply(context.Background(), clients, plan, ApplyOptions{})
+	if result.Success || len(result.Errors) != 1 {
+		t.Fatalf("expected exactly the fw-b action to fail, got %#v", result)
+	}
+	if len(site2.added) != 1 || site2.added[0].Server != "10.2.0.15" || site2.applyCalls != 1 {
+		t.Fatalf("expected site-2 to be updated and restarted, got added=%#v restarts=%d", site2.added, site2.applyCalls)
+	}
+	if fwB.applyCalls != 0 {
+		t.Fatalf("expected failed fw-b not to be restarted, got %d restarts", fwB.applyCalls)
+	}
+}
+
+func TestAdguardInstanceTargetsUseAnswerOverride(t *testing.T) {
+	primary := &fakeAdguardClient{}
+	iot := &fakeAdguardClient{}
+	clients := Clients{
+		Targets: []Target{NewAdguardInstanceTarget("iot", "10.20.0.15", iot)},
+	}
+
+	entry := &models.Entry{Hostname: "app.example.com", CaddyUpstream: "10.0.0.5:8080"}
+	entry.AdguardStatus = models.ServiceStatus{Configured: true, InSync: false, IP: "10.0.0.99"}
+	entry.SetStatusFor("adguard:iot", models.NotConfigured())
+
+	registry := NewRegistry(NewAdguardInstanceTarget("", "10.0.0.1", primary))
+	registry.Register(clients.Targets[0])
+	plan := BuildPlan([]*models.Entry{entry}, Options{CaddyServerIP: "10.0.0.15", Targets: registry})
+	var got []string
+	for _, action := range plan.Actions {
+		got = append(got, action.Service+" "+action.Type+" "+action.NewIP)
+	}
+	want := []string{"adguard update 10.0.0.1", "adguard:iot add 10.20.0.15"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected AdGuard actions: got %v, want %v", got, want)
+	}
+
+	clients.Adguard = primary
+	clients.AdguardAnswer = "10.0.0.1"
+	result := Apply(context.Background(), clients, plan, ApplyOptions{})
+	if !result.Success {
+		t.Fatalf("expected apply success, got %#v", result)
+	}
+	if len(primary.updated) != 1 || primary.updated[0].update.Answer != "10.0.0.1" {
+		t.Fatalf("expected primary rewrite updated to its override, got %#v", primary.updated)
+	}
+	if len(iot.added) != 1 || iot.added[0].Answer != "10.20.0.15" {
+		t.Fatalf("expected iot rewrite added with its override, got %#v", iot.added)
+	}
+}
+
+func TestDHCPTargetCreatesReservationKeyedByMAC(t *testing.T) {
+	client := &fakeDHCPClient{}
+	entries := []*models.Entry{
+		{
+			Hostname:      "jellyfin.example.com",
+			CaddyUpstream: "10.0.0.5:8096",
+			DHCPStatus:    models.NewDHCPStatus(true, "dynamic", "10.0.0.5", "aa:bb:cc:dd:ee:ff", "media", true),
+		},
+		{
+			Hostname:      "printer.example.com",
+			CaddyUpstream: "10.0.0.6:631",
+			DHCPStatus:    models.NewDHCPStatus(true, "static", "10.0.0.6", "11:22:33:44:55:66", "printer", true),
+		},
+	}
+	clients := Clients{DHCP: client}
+	plan := BuildPlan(entries, Options{Service: "dhcp", CaddyServerIP: "10.0.0.15", Targets: clients.Registry()})
+	if len(plan.Actions) != 1 {
+		t.Fatalf("expected one reservation for the dynamic lease, got %#v", plan.Actions)
+	}
+
+	if result := Apply(context.Background(), clients, plan, ApplyOptions{DryRun: true}); !result.Success || len(client.added) != 0 {
+		t.Fatalf("expected dry run to preview without changes, got result=%#v added=%#v", result, client.added)
+	}
+
+	result := Apply(context.Background(), clients, plan, ApplyOptions{})
+	if !result.Success {
+		t.Fatalf("expected success, got %#v", result.Errors)
+	}
+	want := api.DHCPReservation{MAC: "aa:bb:cc:dd:ee:ff", IP: "10.0.0.5", Hostname: "media", Description: app.CurrentUnboundDescription}
+	if len(client.added) != 1 || client.added[0] != want {
+		t.Fatalf("unexpected reservations: %#v", client.added)
+	}
+	if !client.reconfigured {
+		t.Fatal("expected DHCP service to be reconfigured")
+	}
+}
+
+func TestDNSMasqHostAndDHCPReservationOnTheSameHostname(t *testing.T) {
+	var hosts []api.DNSMasqHost
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/dnsmasq/settings/searchHost":
+			rows, _ := json.Marshal(hosts)
+			fmt.Fprintf(w, `{"rows":%s}`, rows)
+		case "/api/dnsmasq/settings/addHost":
+			var body map[string]api.DNSMasqHost
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Fatalf("invalid addHost body: %v", err)
+			}
+			host := body["host"]
+			host.UUID = fmt.Sprintf("uuid-%d", len(hosts))
+			hosts = append(hosts, host)
+			fmt.Fprintf(w, `{"result":"saved","uuid":%q}`, host.UUID)
+		case "/api/dnsmasq/service/reconfigure", "/api/core/firmware/backup":
+			fmt.Fprint(w, `{"status":"ok"}`)
+		default:
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	client := api.NewDNSMasqClient(api.Config{BaseURL: server.URL, Insecure: true})
+	clients := Clients{DNSMasq: client, DHCP: client}
+
+	entry := &models.Entry{
+		Hostname:      "app.example.com",
+		CaddyUpstream: "10.0.0.5:8080",
+		DHCPStatus:    models.NewDHCPStatus(true, "dynamic", "10.0.0.5", "aa:bb:cc:dd:ee:ff", "media.lan", true),
+	}
+	entry.SetStatusFor("dnsmasq", models.ServiceStatus{})
+	var plan Plan
+	for _, service := range []string{"dnsmasq", "dhcp"} {
+		servicePlan := BuildPlan([]*models.Entry{entry}, Options{Service: service, CaddyServerIP: "10.0.0.15", Targets: clients.Registry()})
+		plan.Actions = append(plan.Actions, servicePlan.Actions...)
+	}
+	if len(plan.Actions) != 2 {
+		t.Fatalf("expected a host override and a reservation, got %#v", plan.Actions)
+	}
+
+	if result := Apply(context.Background(), clients, plan, ApplyOptions{}); !result.Success {
+		t.Fatalf("expected both targets to apply, got %#v", result.Errors)
+	}
+	want := []api.DNSMasqHost{
+		{UUID: "uuid-0", Host: "app", Domain: "example.com", IP: "10.0.0.15", Description: app.CurrentUnboundDescription},
+		{UUID: "uuid-1", Host: "media", IP: "10.0.0.5", HWAddr: "aa:bb:cc:dd:ee:ff", Description: app.CurrentUnboundDescription},
+	}
+	if !reflect.DeepEqual(hosts, want) {
+		t.Fatalf("unexpected Dnsmasq hosts:\n got %#v\nwant %#v", hosts, want)
+	}
+
+	// The host override alone answers for the Caddy hostname, so the next
+	// plan finds it in sync.
+	target, _ := clients.Registry().Lookup("dnsmasq")
+	records, err := target.(RecordLister).Records(context.Background())
+	if err != nil {
+		t.Fatalf("Records failed: %v", err)
+	}
+	if len(records) != 1 || records[0].Hostname != "app.example.com" || records[0].Answer != "10.0.0.15" {
+		t.Fatalf("expected only the app host override, got %#v", records)
+	}
+}
+
+type fakeDHCPClient struct {
+	added        []api.DHCPReservation
+	reconfigured bool
+}
+
+func (f *fakeDHCPClient) AddReservation(reservation api.DHCPReservation) error {
+	f.added = append(f.added, reservation)
+	return nil
+}
+
+func (f *fakeDHCPClient) Reconfigure() error {
+	f.reconfigured = true
+	return nil
+}
+
+type fakeDNSMasqClient struct {
+	hosts        []api.DNSMasqHost
+	added        []api.DNSMasqHost
+	updated      []api.DNSMasqHost
+	deleted      []string
+	reconfigured bool
+}
+
+func (f *fakeDNSMasqClient) GetHosts() ([]api.DNSMasqHost, error) { return f.hosts, nil }
+
+func (f *fakeDNSMasqClient) AddHost(host api.DNSMasqHost) (string, error) {
+	f.added = append(f.added, host)
+	return "uuid-new", nil
+}
+
+func (f *fakeDNSMasqClient) UpdateHost(host api.DNSMasqHost) error {
+	f.updated = append(f.updated, host)
+	return nil
+}
+
+func (f *fakeDNSMasqClient) DeleteHost(uuid string) error {
+	f.deleted = append(f.deleted, uuid)
+	return nil
+}
+
+func (f *fakeDNSMasqClient) Reconfigure() error {
+	f.reconfigured = true
+	return nil
+}
+
+type fakePiholeClient struct {
+	hosts  []api.PiholeHost
+	cnames []api.PiholeCNAME
+}
+
+func (f *fakePiholeClient) ListHosts() ([]api.PiholeHost, error) { return f.hosts, nil }
+
+func (f *fakePiholeClient) AddHost(hostname, ip string) error {
+	f.hosts = append(f.hosts, api.PiholeHost{IP: ip, Hostname: hostname})
+	return nil
+}
+
+func (f *fakePiholeClient) UpdateHost(hostname, oldIP, newIP string) error {
+	if err := f.DeleteHost(hostname, oldIP); err != nil {
+		return err
+	}
+	return f.AddHost(hostname, newIP)
+}
+
+func (f *fakePiholeClient) DeleteHost(hostname, ip string) error {
+	for i, host := range f.hosts {
+		if host.Hostname == hostname && host.IP == ip {
+			f.hosts = append(f.hosts[:i], f.hosts[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("host %s not found", hostname)
+}
+
+func (f *fakePiholeClient) ListCNAMEs() ([]api.PiholeCNAME, error) { return f.cnames, nil }
+
+func (f *fakePiholeClient) DeleteCNAME(record api.PiholeCNAME) error {
+	for i, cname := range f.cnames {
+		if cname == record {
+			f.cnames = append(f.cnames[:i], f.cnames[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("CNAME %s not found", record.Domain)
+}
+
+type fakeTarget struct {
+	name    string
+	applied []Action
+	commits int
+}
+
+func (f *fakeTarget) Name() string    { return f.name }
+func (f *fakeTarget) Label() string   { return f.name }
+func (f *fakeTarget) Available() bool { return true }
+
+func (f *fakeTarget) Diff(entry *models.Entry, options Options) Action {
+	return diffDNSRecord(entry, f.name, options)
+}
+
+func (f *fakeTarget) Apply(_ context.Context, action Action) error {
+	f.applied = append(f.applied, action)
+	return nil
+}
+
+func (f *fakeTarget) Commit(context.Context) (string, error) {
+	f.commits++
+	return f.name + " committed", nil
+}
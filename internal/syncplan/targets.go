@@ -0,0 +1,834 @@
+package syncplan
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/api"
+	"github.com/jeeftor/caddy-dns-sync/internal/app"
+	"github.com/jeeftor/caddy-dns-sync/internal/models"
+)
+
+// ─── Unbound ────────────────────────────────────────────────────────────────
+
+// UnboundInstancePrefix prefixes the target name of each additional named
+// Unbound instance, e.g. "unbound:fw-b".
+const UnboundInstancePrefix = "unbound:"
+
+type unboundTarget struct {
+	client   UnboundClient
+	name     string
+	label    string
+	targetIP string
+}
+
+// NewUnboundTarget creates the built-in OPNsense Unbound host override target.
+func NewUnboundTarget(client UnboundClient) RecordLister {
+	return &unboundTarget{client: client, name: "unbound", label: "Unbound"}
+}
+
+// NewUnboundInstanceTarget creates a host override target for an additional
+// named Unbound endpoint. It is registered as "unbound:<name>" so each
+// firewall gets its own actions, results and Commit. A non-empty targetIP
+// replaces Options.CaddyServerIP in this instance's overrides.
+func NewUnboundInstanceTarget(name, targetIP string, client UnboundClient) RecordLister {
+	return &unboundTarget{
+		client:   client,
+		name:     UnboundInstancePrefix + name,
+		label:    "Unbound (" + name + ")",
+		targetIP: targetIP,
+	}
+}
+
+func (t *unboundTarget) Name() string     { return t.name }
+func (t *unboundTarget) Label() string    { return t.label }
+func (t *unboundTarget) Available() bool  { return t.client != nil }
+func (t *unboundTarget) TargetIP() string { return t.targetIP }
+
+func (t *unboundTarget) Diff(entry *models.Entry, options Options) Action {
+	return diffDNSRecord(entry, t.Name(), t.answerOptions(options))
+}
+
+func (t *unboundTarget) DiffAAAA(entry *models.Entry, options Options) Action {
+	return diffAAAARecord(entry, t.Name(), t.answerOptions(options))
+}
+
+func (t *unboundTarget) answerOptions(options Options) Options {
+	if t.targetIP != "" {
+		return options.withTargetIP(t.targetIP)
+	}
+	return options
+}
+
+// SupportsAliases reports that Unbound can answer for a hostname with a host
+// alias under the canonical host override.
+func (t *unboundTarget) SupportsAliases() bool { return true }
+
+// Records lists host overrides followed by host aliases, whose answer is the
+// hostname of the override they belong to.
+func (t *unboundTarget) Records(ctx context.Context) ([]Record, error) {
+	if t.client == nil {
+		return nil, errClientUnavailable(t.label)
+	}
+	client := t.client
+	if c, ok := client.(*api.Client); ok {
+		client = c.WithContext(ctx)
+	}
+	overrides, err := client.GetOverrides()
+	if err != nil {
+		return nil, err
+	}
+	records := make([]Record, 0, len(overrides))
+	parents := make(map[string]string, len(overrides))
+	for _, override := range overrides {
+		if override.RR != "" && override.RR != "A" && override.RR != RecordTypeAAAA {
+			continue
+		}
+		hostname := joinHostname(override.Host, override.Domain)
+		parents[override.UUID] = hostname
+		records = append(records, Record{
+			Hostname: hostname,
+			Answer:   override.Server,
+			Owned:    isManagedUnboundDescription(override.Description),
+		})
+	}
+
+	aliasClient, ok := client.(UnboundAliasClient)
+	if !ok {
+		return records, nil
+	}
+	aliases, err := aliasClient.GetHostAliases()
+	if err != nil {
+		return nil, err
+	}
+	for _, alias := range aliases {
+		parent := parents[alias.Host]
+		if parent == "" {
+			parent = alias.Host
+		}
+		records = append(records, Record{
+			Hostname: joinHostname(alias.Hostname, alias.Domain),
+			Answer:   parent,
+			Owned:    isManagedUnboundDescription(alias.Description),
+		})
+	}
+	return records, nil
+}
+
+// Apply changes a host override, or a host alias when the old or new answer
+// is a hostname. Switching between the two deletes the old record first.
+func (t *unboundTarget) Apply(_ context.Context, action Action) error {
+	if t.client == nil {
+		return errClientUnavailable(t.label)
+	}
+
+	switch action.Type {
+	case "add":
+		return t.addRecord(action.Hostname, action.NewIP, action.IsAAAA())
+	case "update":
+		if models.IsAlias(action.OldIP) || models.IsAlias(action.NewIP) {
+			if err := t.deleteRecord(action.Hostname, action.OldIP, action.IsAAAA()); err != nil {
+				return err
+			}
+			return t.addRecord(action.Hostname, action.NewIP, action.IsAAAA())
+		}
+		uuid, err := findUnboundOverrideUUID(t.client, action.Hostname, action.IsAAAA())
+		if err != nil {
+			return err
+		}
+		host, domain := SplitHostname(action.Hostname)
+		return t.client.UpdateOverride(api.DNSOverride{
+			UUID:        uuid,
+			Enabled:     "1",
+			Host:        host,
+			Domain:      domain,
+			RR:          overrideRR(action.IsAAAA()),
+			Server:      action.NewIP,
+			Description: app.CurrentUnboundDescription,
+		})
+	case "delete":
+		return t.deleteRecord(action.Hostname, action.OldIP, action.IsAAAA())
+	default:
+		return fmt.Errorf("unknown action type: %s", action.Type)
+	}
+}
+
+func (t *unboundTarget) addRecord(hostname, answer string, aaaa bool) error {
+	host, domain := SplitHostname(hostname)
+	if !models.IsAlias(answer) {
+		_, err := t.client.AddOverride(api.DNSOverride{
+			Enabled:     "1",
+			Host:        host,
+			Domain:      domain,
+			RR:          overrideRR(aaaa),
+			Server:      answer,
+			Description: app.CurrentUnboundDescription,
+		})
+		return err
+	}
+
+	aliases, err := t.aliasClient()
+	if err != nil {
+		return err
+	}
+	parent, err := findUnboundOverrideUUID(t.client, answer, false)
+	if err != nil {
+		return fmt.Errorf("canonical record %s must exist before aliasing %s: %w", answer, hostname, err)
+	}
+	_, err = aliases.AddHostAlias(api.HostAlias{
+		Enabled:     "1",
+		Host:        parent,
+		Hostname:    host,
+		Domain:      domain,
+		Description: app.CurrentUnboundDescription,
+	})
+	return err
+}
+
+func (t *unboundTarget) deleteRecord(hostname, answer string, aaaa bool) error {
+	if !models.IsAlias(answer) {
+		uuid, err := findUnboundOverrideUUID(t.client, hostname, aaaa)
+		if err != nil {
+			return err
+		}
+		return t.client.DeleteOverride(uuid)
+	}
+
+	aliases, err := t.aliasClient()
+	if err != nil {
+		return err
+	}
+	existing, err := aliases.GetHostAliases()
+	if err != nil {
+		return fmt.Errorf("failed to get host aliases: %w", err)
+	}
+	for _, alias := range existing {
+		if joinHostname(alias.Hostname, alias.Domain) == hostname {
+			return aliases.DeleteHostAlias(alias.UUID)
+		}
+	}
+	return fmt.Errorf("host alias not found for %s", hostname)
+}
+
+func (t *unboundTarget) aliasClient() (UnboundAliasClient, error) {
+	aliases, ok := t.client.(UnboundAliasClient)
+	if !ok {
+		return nil, fmt.Errorf("%s client does not support host aliases", t.label)
+	}
+	return aliases, nil
+}
+
+// overrideRR returns the override record type for an A or AAAA action.
+func overrideRR(aaaa bool) string {
+	if aaaa {
+		return RecordTypeAAAA
+	}
+	return ""
+}
+
+func (t *unboundTarget) Commit(_ context.Context) (string, error) {
+	if t.client == nil {
+		return "", nil
+	}
+	if err := t.client.ApplyChanges(); err != nil {
+		return "", fmt.Errorf("Failed to restart %s service: %v", t.label, err)
+	}
+	return t.label + " restarted", nil
+}
+
+func isManagedUnboundDescription(description string) bool {
+	if description == app.CurrentUnboundDescription {
+		return true
+	}
+	for _, legacy := range app.LegacyUnboundDescriptions {
+		if description == legacy {
+			return true
+		}
+	}
+	return false
+}
+
+// ─── AdGuard Home ───────────────────────────────────────────────────────────
+
+// AdguardInstancePrefix prefixes the target name of each additional named
+// AdGuard Home replica, e.g. "adguard:secondary".
+const AdguardInstancePrefix = "adguard:"
+
+type adguardTarget struct {
+	client AdguardClient
+	name   string
+	label  string
+	answer string
+}
+
+// NewAdguardTarget creates the built-in AdGuard Home DNS rewrite target.
+func NewAdguardTarget(client AdguardClient) RecordLister {
+	return NewAdguardInstanceTarget("", "", client)
+}
+
+// NewAdguardInstanceTarget creates a rewrite target for a named AdGuard Home
+// replica, registered as "adguard:<name>"; an empty name is the primary
+// "adguard" target. A non-empty answerOverride replaces
+// Options.CaddyServerIP in the replica's rewrites.
+func NewAdguardInstanceTarget(name, answerOverride string, client AdguardClient) RecordLister {
+	target := &adguardTarget{client: client, name: "adguard", label: "AdGuard", answer: answerOverride}
+	if name != "" {
+		target.name = AdguardInstancePrefix + name
+		target.label = "AdGuard (" + name + ")"
+	}
+	return target
+}
+
+func (t *adguardTarget) Name() string     { return t.name }
+func (t *adguardTarget) Label() string    { return t.label }
+func (t *adguardTarget) Available() bool  { return t.client != nil }
+func (t *adguardTarget) TargetIP() string { return t.answer }
+
+func (t *adguardTarget) Diff(entry *models.Entry, options Options) Action {
+	return diffDNSRecord(entry, t.Name(), t.answerOptions(options))
+}
+
+func (t *adguardTarget) DiffAAAA(entry *models.Entry, options Options) Action {
+	return diffAAAARecord(entry, t.Name(), t.answerOptions(options))
+}
+
+func (t *adguardTarget) answerOptions(options Options) Options {
+	if t.answer != "" {
+		return options.withTargetIP(t.answer)
+	}
+	return options
+}
+
+// SupportsAliases reports that AdGuard Home rewrites can answer with a
+// hostname, which it serves as a CNAME.
+func (t *adguardTarget) SupportsAliases() bool { return true }
+
+// Records lists AdGuard rewrites. Rewrites carry no ownership marker, so every
+// record is reported as unowned and the planner infers ownership from Caddy.
+func (t *adguardTarget) Records(ctx context.Context) ([]Record, error) {
+	if t.client == nil {
+		return nil, errClientUnavailable(t.label)
+	}
+	client := t.client
+	if c, ok := client.(*api.AdguardClient); ok {
+		client = c.WithContext(ctx)
+	}
+	rewrites, err := client.ListRewrites()
+	if err != nil {
+		return nil, err
+	}
+	records := make([]Record, 0, len(rewrites))
+	for _, rewrite := range rewrites {
+		records = append(records, Record{Hostname: rewrite.Domain, Answer: rewrite.Answer})
+	}
+	return records, nil
+}
+
+func (t *adguardTarget) Apply(_ context.Context, action Action) error {
+	if t.client == nil {
+		return errClientUnavailable(t.label)
+	}
+
+	switch action.Type {
+	case "add":
+		return t.client.AddRewrite(action.Hostname, action.NewIP)
+	case "update":
+		return t.client.UpdateRewrite(
+			api.Rewrite{Domain: action.Hostname, Answer: action.OldIP},
+			api.Rewrite{Domain: action.Hostname, Answer: action.NewIP},
+		)
+	case "delete":
+		return t.client.DeleteRewrite(action.Hostname, action.OldIP)
+	default:
+		return fmt.Errorf("unknown action type: %s", action.Type)
+	}
+}
+
+// Commit is a no-op: AdGuard rewrites are applied immediately.
+func (t *adguardTarget) Commit(_ context.Context) (string, error) {
+	return "", nil
+}
+
+// ─── Dnsmasq ────────────────────────────────────────────────────────────────
+
+type dnsmasqTarget struct {
+	client DNSMasqClient
+}
+
+// NewDNSMasqTarget creates the built-in OPNsense Dnsmasq host override target.
+// It only joins "all" plans when it has a client. The Dnsmasq client shares
+// the OPNsense credentials used for Unbound, so NewClients only hands it over
+// when dnsmasq_hosts is enabled, to avoid mirroring every hostname into both
+// resolvers unasked.
+func NewDNSMasqTarget(client DNSMasqClient) RecordLister {
+	return &dnsmasqTarget{client: client}
+}
+
+func (t *dnsmasqTarget) Name() string    { return "dnsmasq" }
+func (t *dnsmasqTarget) Label() string   { return "Dnsmasq" }
+func (t *dnsmasqTarget) Available() bool { return t.client != nil }
+
+func (t *dnsmasqTarget) IncludeInAll(Options) bool { return t.Available() }
+
+// Diff skips entries without Dnsmasq data so plans built without a Dnsmasq
+// client never propose adding every hostname to it.
+func (t *dnsmasqTarget) Diff(entry *models.Entry, options Options) Action {
+	if _, ok := entry.TargetStatus[t.Name()]; !ok {
+		return Action{}
+	}
+	return diffDNSRecord(entry, t.Name(), options)
+}
+
+// Records lists Dnsmasq host overrides. Hosts with a MAC address are DHCP
+// reservations rather than DNS records and are skipped, as are entries
+// without an IP.
+func (t *dnsmasqTarget) Records(ctx context.Context) ([]Record, error) {
+	if t.client == nil {
+		return nil, errClientUnavailable("Dnsmasq")
+	}
+	client := t.client
+	if c, ok := client.(*api.DNSMasqClient); ok {
+		client = c.WithContext(ctx)
+	}
+	hosts, err := client.GetHosts()
+	if err != nil {
+		return nil, err
+	}
+	records := make([]Record, 0, len(hosts))
+	for _, host := range hosts {
+		if host.IP == "" || host.HWAddr != "" {
+			continue
+		}
+		records = append(records, Record{
+			Hostname: host.Hostname(),
+			Answer:   host.IP,
+			Owned:    isManagedUnboundDescription(host.Description),
+		})
+	}
+	return records, nil
+}
+
+func (t *dnsmasqTarget) Apply(_ context.Context, action Action) error {
+	if t.client == nil {
+		return errClientUnavailable("Dnsmasq")
+	}
+
+	switch action.Type {
+	case "add":
+		host, domain := SplitHostname(action.Hostname)
+		_, err := t.client.AddHost(api.DNSMasqHost{
+			Host:        host,
+			Domain:      domain,
+			IP:          action.NewIP,
+			Description: app.CurrentUnboundDescription,
+		})
+		return err
+	case "update":
+		existing, err := t.findHost(action.Hostname)
+		if err != nil {
+			return err
+		}
+		existing.IP = action.NewIP
+		existing.Description = app.CurrentUnboundDescription
+		return t.client.UpdateHost(existing)
+	case "delete":
+		existing, err := t.findHost(action.Hostname)
+		if err != nil {
+			return err
+		}
+		return t.client.DeleteHost(existing.UUID)
+	default:
+		return fmt.Errorf("unknown action type: %s", action.Type)
+	}
+}
+
+func (t *dnsmasqTarget) findHost(hostname string) (api.DNSMasqHost, error) {
+	hosts, err := t.client.GetHosts()
+	if err != nil {
+		return api.DNSMasqHost{}, fmt.Errorf("failed to get Dnsmasq hosts: %w", err)
+	}
+	for _, host := range hosts {
+		if !strings.EqualFold(host.Hostname(), hostname) {
+			continue
+		}
+		// Hosts with a MAC address double as DHCP reservations; changing
+		// their address would move the client's lease too.
+		if host.HWAddr != "" {
+			return api.DNSMasqHost{}, fmt.Errorf("Dnsmasq host %s is a DHCP reservation for %s; leaving it unchanged", hostname, host.HWAddr)
+		}
+		return host, nil
+	}
+	return api.DNSMasqHost{}, fmt.Errorf("Dnsmasq host not found for %s", hostname)
+}
+
+func (t *dnsmasqTarget) Commit(_ context.Context) (string, error) {
+	if t.client == nil {
+		return "", nil
+	}
+	if err := t.client.Reconfigure(); err != nil {
+		return "", fmt.Errorf("Failed to reconfigure Dnsmasq service: %v", err)
+	}
+	return "Dnsmasq reconfigured", nil
+}
+
+// ─── Pi-hole ────────────────────────────────────────────────────────────────
+
+type piholeTarget struct {
+	client PiholeClient
+}
+
+// NewPiholeTarget creates the built-in Pi-hole v6 local DNS record target.
+func NewPiholeTarget(client PiholeClient) RecordLister {
+	return &piholeTarget{client: client}
+}
+
+func (t *piholeTarget) Name() string    { return "pihole" }
+func (t *piholeTarget) Label() string   { return "Pi-hole" }
+func (t *piholeTarget) Available() bool { return t.client != nil }
+
+// Diff skips entries without Pi-hole data so plans built without a Pi-hole
+// client never propose adding every hostname to it.
+func (t *piholeTarget) Diff(entry *models.Entry, options Options) Action {
+	if _, ok := entry.TargetStatus[t.Name()]; !ok {
+		return Action{}
+	}
+	return diffDNSRecord(entry, t.Name(), options)
+}
+
+// Records lists Pi-hole local DNS host records followed by CNAME records,
+// whose answer is the CNAME target. Like AdGuard, Pi-hole records carry no
+// ownership marker.
+func (t *piholeTarget) Records(ctx context.Context) ([]Record, error) {
+	if t.client == nil {
+		return nil, errClientUnavailable("Pi-hole")
+	}
+	client := t.client
+	if c, ok := client.(*api.PiholeClient); ok {
+		client = c.WithContext(ctx)
+	}
+	hosts, err := client.ListHosts()
+	if err != nil {
+		return nil, err
+	}
+	cnames, err := client.ListCNAMEs()
+	if err != nil {
+		return nil, err
+	}
+	records := make([]Record, 0, len(hosts)+len(cnames))
+	for _, host := range hosts {
+		records = append(records, Record{Hostname: host.Hostname, Answer: host.IP})
+	}
+	for _, cname := range cnames {
+		records = append(records, Record{Hostname: cname.Domain, Answer: cname.Target})
+	}
+	return records, nil
+}
+
+func (t *piholeTarget) Apply(_ context.Context, action Action) error {
+	if t.client == nil {
+		return errClientUnavailable("Pi-hole")
+	}
+
+	switch action.Type {
+	case "add":
+		return t.client.AddHost(action.Hostname, action.NewIP)
+	case "update":
+		if net.ParseIP(action.OldIP) != nil {
+			return t.client.UpdateHost(action.Hostname, action.OldIP, action.NewIP)
+		}
+		// The hostname is currently a CNAME; replace it with a host record.
+		if err := t.deleteCNAME(action.Hostname, action.OldIP); err != nil {
+			return err
+		}
+		return t.client.AddHost(action.Hostname, action.NewIP)
+	case "delete":
+		if net.ParseIP(action.OldIP) != nil {
+			return t.client.DeleteHost(action.Hostname, action.OldIP)
+		}
+		return t.deleteCNAME(action.Hostname, action.OldIP)
+	default:
+		return fmt.Errorf("unknown action type: %s", action.Type)
+	}
+}
+
+// deleteCNAME removes the CNAME for hostname. Pi-hole deletes by exact value,
+// so the record is looked up first to recover any TTL suffix.
+func (t *piholeTarget) deleteCNAME(hostname, target string) error {
+	cnames, err := t.client.ListCNAMEs()
+	if err != nil {
+		return fmt.Errorf("failed to get CNAME records: %w", err)
+	}
+	for _, cname := range cnames {
+		if cname.Domain == hostname && cname.Target == target {
+			return t.client.DeleteCNAME(cname)
+		}
+	}
+	return fmt.Errorf("CNAME record not found for %s", hostname)
+}
+
+// Commit is a no-op: Pi-hole applies configuration changes immediately.
+func (t *piholeTarget) Commit(_ context.Context) (string, error) {
+	return "", nil
+}
+
+// ─── RFC 2136 ───────────────────────────────────────────────────────────────
+
+type rfc2136Target struct {
+	client RFC2136Client
+}
+
+// NewRFC2136Target creates the built-in RFC 2136 dynamic update target for
+// authoritative servers such as BIND, Knot, PowerDNS and Technitium.
+func NewRFC2136Target(client RFC2136Client) RecordLister {
+	return &rfc2136Target{client: client}
+}
+
+func (t *rfc2136Target) Name() string          { return "rfc2136" }
+func (t *rfc2136Target) Label() string         { return "RFC 2136" }
+func (t *rfc2136Target) Available() bool       { return t.client != nil }
+func (t *rfc2136Target) TracksOwnership() bool { return true }
+
+// Diff skips entries without RFC 2136 data and names owned by someone else:
+// a zone usually holds far more than the hostnames Caddy serves.
+func (t *rfc2136Target) Diff(entry *models.Entry, options Options) Action {
+	status, ok := entry.TargetStatus[t.Name()]
+	if !ok || status.Foreign {
+		return Action{}
+	}
+	return diffDNSRecord(entry, t.Name(), options)
+}
+
+// Records lists the zone's A records; Owned is set for names that carry the
+// ownership TXT marker.
+func (t *rfc2136Target) Records(ctx context.Context) ([]Record, error) {
+	if t.client == nil {
+		return nil, errClientUnavailable("RFC 2136")
+	}
+	client := t.client
+	if c, ok := client.(*api.RFC2136Client); ok {
+		client = c.WithContext(ctx)
+	}
+	zoneRecords, err := client.ListRecords()
+	if err != nil {
+		return nil, err
+	}
+	records := make([]Record, 0, len(zoneRecords))
+	for _, record := range zoneRecords {
+		records = append(records, Record{Hostname: record.Hostname, Answer: record.IP, Owned: record.Owned})
+	}
+	return records, nil
+}
+
+func (t *rfc2136Target) Apply(ctx context.Context, action Action) error {
+	if t.client == nil {
+		return errClientUnavailable("RFC 2136")
+	}
+	client := t.client
+	if c, ok := client.(*api.RFC2136Client); ok {
+		client = c.WithContext(ctx)
+	}
+
+	switch action.Type {
+	case "add":
+		return client.AddHost(action.Hostname, action.NewIP)
+	case "update":
+		return client.UpdateHost(action.Hostname, action.OldIP, action.NewIP)
+	case "delete":
+		return client.DeleteHost(action.Hostname, action.OldIP)
+	default:
+		return fmt.Errorf("unknown action type: %s", action.Type)
+	}
+}
+
+// Commit is a no-op: the primary applies each UPDATE message atomically.
+func (t *rfc2136Target) Commit(_ context.Context) (string, error) {
+	return "", nil
+}
+
+// ─── Cloudflare ─────────────────────────────────────────────────────────────
+
+type cloudflareTarget struct {
+	client CloudflareClient
+}
+
+// NewCloudflareTarget creates the built-in Cloudflare tunnel ingress target.
+// It only joins "all" plans when Options.IncludeCloudflare is set.
+func NewCloudflareTarget(client CloudflareClient) Target {
+	return &cloudflareTarget{client: client}
+}
+
+func (t *cloudflareTarget) Name() string    { return "cloudflare" }
+func (t *cloudflareTarget) Label() string   { return "Cloudflare" }
+func (t *cloudflareTarget) Available() bool { return t.client != nil }
+
+func (t *cloudflareTarget) IncludeInAll(options Options) bool {
+	return options.IncludeCloudflare
+}
+
+func (t *cloudflareTarget) Diff(entry *models.Entry, options Options) Action {
+	return buildCloudflareAction(entry, options)
+}
+
+func (t *cloudflareTarget) Apply(_ context.Context, action Action) error {
+	if t.client == nil {
+		return errClientUnavailable("Cloudflare")
+	}
+
+	switch action.Type {
+	case "add":
+		if err := t.client.UpdateTunnelRule(api.IngressRuleSpec{
+			Hostname:                  action.Hostname,
+			Service:                   action.NewService,
+			HTTPHostHeader:            action.NewHTTPHostHeader,
+			OriginServerName:          action.OriginServerName,
+			SetOriginServerName:       action.OriginServerName != "",
+			NoTLSVerify:               action.NoTLSVerify,
+			SetNoTLSVerify:            action.NoTLSVerify,
+			DisableChunkedEncoding:    action.DisableChunkedEncoding,
+			SetDisableChunkedEncoding: action.DisableChunkedEncoding,
+			TunnelID:                  action.TunnelID,
+		}); err != nil {
+			return err
+		}
+		return t.client.EnsureDNSRecord(action.Hostname)
+	case "update":
+		return t.client.UpdateTunnelRule(api.IngressRuleSpec{
+			Hostname:                  action.Hostname,
+			Service:                   action.NewService,
+			HTTPHostHeader:            action.NewHTTPHostHeader,
+			OriginServerName:          action.OriginServerName,
+			SetOriginServerName:       true, // always write on update
+			NoTLSVerify:               action.NoTLSVerify,
+			SetNoTLSVerify:            true,
+			DisableChunkedEncoding:    action.DisableChunkedEncoding,
+			SetDisableChunkedEncoding: true,
+			TunnelID:                  action.TunnelID,
+		})
+	case "delete":
+		if err := t.client.DeleteTunnelRule(action.Hostname); err != nil {
+			return err
+		}
+		return t.client.DeleteDNSRecord(action.Hostname)
+	default:
+		return fmt.Errorf("unknown action type: %s", action.Type)
+	}
+}
+
+// Commit is a no-op: tunnel configuration changes are applied per action.
+func (t *cloudflareTarget) Commit(_ context.Context) (string, error) {
+	return "", nil
+}
+
+// ─── DHCP ───────────────────────────────────────────────────────────────────
+
+type dhcpTarget struct {
+	client DHCPClient
+}
+
+// NewDHCPTarget creates the built-in DHCP static reservation target. It is
+// only planned when requested explicitly, never as part of "all".
+func NewDHCPTarget(client DHCPClient) Target {
+	return &dhcpTarget{client: client}
+}
+
+func (t *dhcpTarget) Name() string    { return "dhcp" }
+func (t *dhcpTarget) Label() string   { return "DHCP" }
+func (t *dhcpTarget) Available() bool { return t.client != nil }
+
+func (t *dhcpTarget) IncludeInAll(Options) bool { return false }
+
+func (t *dhcpTarget) Diff(entry *models.Entry, options Options) Action {
+	if !entry.NeedsDHCPStaticEntry() || entry.DHCPStatus.MAC == "" {
+		return Action{}
+	}
+	return buildAction(entry, t.Name(), models.ServiceStatus{}, false, true, "", options.Unsync)
+}
+
+// Apply pins the dynamic lease behind a Caddy upstream to its current
+// address, keyed by MAC. The reservation is named after the device's lease
+// hostname without its domain, falling back to the first label of the Caddy
+// hostname. Naming it after the Caddy hostname would make Dnsmasq answer that
+// name with the upstream's address instead of Caddy's.
+func (t *dhcpTarget) Apply(_ context.Context, action Action) error {
+	if t.client == nil {
+		return errClientUnavailable("DHCP")
+	}
+	if action.Type != "add" {
+		return fmt.Errorf("unsupported DHCP action type: %s", action.Type)
+	}
+	if action.MAC == "" {
+		return fmt.Errorf("no MAC address for %s", action.Hostname)
+	}
+	name := action.LeaseHostname
+	if name == "" {
+		name = action.Hostname
+	}
+	host, _ := SplitHostname(name)
+	return t.client.AddReservation(api.DHCPReservation{
+		MAC:         action.MAC,
+		IP:          action.NewIP,
+		Hostname:    host,
+		Description: app.CurrentUnboundDescription,
+	})
+}
+
+func (t *dhcpTarget) Commit(_ context.Context) (string, error) {
+	if t.client == nil {
+		return "", nil
+	}
+	if err := t.client.Reconfigure(); err != nil {
+		return "", fmt.Errorf("Failed to reconfigure DHCP service: %v", err)
+	}
+	return "DHCP reconfigured", nil
+}
+
+// diffDNSRecord plans the add/update/delete of the A record for a target whose
+// per-entry state is a models.ServiceStatus holding a single DNS answer.
+func diffDNSRecord(entry *models.Entry, target string, options Options) Action {
+	status := entry.StatusFor(target)
+	answer := entry.AnswerFor(target, options.caddyAnswer())
+	return diffRecord(entry, target, status, answer.IPv4, entry.NeedsSyncTo(target), options)
+}
+
+// diffAAAARecord plans the AAAA record counterpart of diffDNSRecord.
+func diffAAAARecord(entry *models.Entry, target string, options Options) Action {
+	status := entry.StatusFor(target)
+	answer := entry.AnswerFor(target, options.caddyAnswer())
+	aaaa := models.ServiceStatus{
+		Configured: status.IPv6 != "",
+		IP:         status.IPv6,
+		InSync:     !status.IPv6Mismatch,
+	}
+	action := diffRecord(entry, target, aaaa, answer.IPv6, entry.IsDeclared() && status.IPv6Mismatch, options)
+	if action.Type != "" {
+		action.RecordType = RecordTypeAAAA
+	}
+	return action
+}
+
+// diffRecord plans one address family. status.IP is the record's current
+// address and answer the address it should hold; an empty answer means the
+// record should not exist.
+func diffRecord(entry *models.Entry, target string, status models.ServiceStatus, answer string, needsSync bool, options Options) Action {
+	var needsRemoval bool
+	if options.Unsync {
+		needsSync, needsRemoval = false, status.IP != ""
+	} else {
+		needsRemoval = status.IP != "" && !entry.IsDeclared()
+	}
+	if needsSync && answer == "" {
+		// An answer rule leaves this family without an address.
+		if status.IP == "" {
+			return Action{}
+		}
+		action := buildAction(entry, target, status, true, false, "", false)
+		action.Details = "no address of this family in the answer"
+		return action
+	}
+	if !needsSync && !needsRemoval {
+		return Action{}
+	}
+	return buildAction(entry, target, status, needsRemoval, false, answer, options.Unsync)
+}
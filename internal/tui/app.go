@@ -10,6 +10,7 @@ import (
 	"github.com/jeeftor/caddy-dns-sync/internal/api"
 	"github.com/jeeftor/caddy-dns-sync/internal/models"
 	"github.com/jeeftor/caddy-dns-sync/internal/status"
+	"github.com/jeeftor/caddy-dns-sync/internal/syncplan"
 	"github.com/jeeftor/caddy-dns-sync/internal/widgets"
 )
 
@@ -36,6 +37,7 @@ type AppModel struct {
 	caddyClient   *api.CaddyClient
 	unboundClient *api.Client
 	adguardClient *api.AdguardClient
+	piholeClient  *api.PiholeClient
 	dnsmasqClient *api.DNSMasqClient
 	cfClient      *api.CloudflareClient
 
@@ -56,6 +58,10 @@ type AppModel struct {
 	entries       []*models.Entry
 	caddyServerIP string
 
+	// policy, when set, guards sync dialog applies against mass deletes
+	// and changes to protected hostnames.
+	policy *syncplan.Policy
+
 	// State
 	currentView ViewMode
 	loading     bool
@@ -97,6 +103,29 @@ func NewAppModel(
 	}
 }
 
+// WithPiholeClient sets the optional Pi-hole client used for loading and syncing.
+func (m *AppModel) WithPiholeClient(piholeClient *api.PiholeClient) *AppModel {
+	m.piholeClient = piholeClient
+	return m
+}
+
+// WithSyncPolicy enforces policy on every sync started from the TUI.
+func (m *AppModel) WithSyncPolicy(policy syncplan.Policy) *AppModel {
+	m.policy = &policy
+	return m
+}
+
+// newSyncExecutor builds the executor behind the sync dialog.
+func (m *AppModel) newSyncExecutor() *TUISyncExecutor {
+	executor := NewTUISyncExecutor(m.unboundClient, m.adguardClient, m.dnsmasqClient, m.cfClient).
+		WithPiholeClient(m.piholeClient).
+		WithJournal(syncplan.NewJournal(syncplan.DefaultJournalDir()))
+	if m.policy != nil {
+		executor.WithPolicy(*m.policy, syncplan.ExistingRecords(m.entries))
+	}
+	return executor
+}
+
 // Init initializes the application
 func (m *AppModel) Init() tea.Cmd {
 	return tea.Batch(
@@ -604,7 +633,7 @@ func (m *AppModel) updateLayout() {
 // showSyncDialog prepares and shows the sync dialog for selected entries or all entries
 func (m *AppModel) showSyncDialog() {
 	// Create sync executor with API clients
-	executor := NewTUISyncExecutor(m.unboundClient, m.adguardClient, m.dnsmasqClient, m.cfClient)
+	executor := m.newSyncExecutor()
 
 	// Inject sync executor into dialog
 	m.syncDialog.SetSyncExecutor(executor.ExecuteSyncActions)
@@ -631,7 +660,7 @@ func (m *AppModel) showSingleEntrySync() {
 	}
 
 	// Create sync executor with API clients
-	executor := NewTUISyncExecutor(m.unboundClient, m.adguardClient, m.dnsmasqClient, m.cfClient)
+	executor := m.newSyncExecutor()
 
 	// Inject sync executor into dialog
 	m.syncDialog.SetSyncExecutor(executor.ExecuteSyncActions)
@@ -751,6 +780,7 @@ func (m *AppModel) updateServiceStatus() {
 		Caddy:      m.caddyClient != nil,
 		Unbound:    m.unboundClient != nil,
 		AdGuard:    m.adguardClient != nil,
+		Pihole:     m.piholeClient != nil,
 		DHCP:       m.dnsmasqClient != nil,
 		Cloudflare: m.cfClient != nil,
 		Complete:   !m.loading,
@@ -808,6 +838,7 @@ func (m *AppModel) loadData() tea.Cmd {
 			m.caddyServerIP,
 		)
 		loader.WithCloudflareClient(m.cfClient)
+		loader.WithPiholeClient(m.piholeClient)
 
 		// Load data
 		entries, err := loader.LoadData()
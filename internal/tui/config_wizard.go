@@ -495,6 +495,9 @@ func buildExtendedConfig(vals map[string]string, existing config.ExtendedConfig)
 		Username: vals["adguard_username"],
 		Password: vals["adguard_password"],
 		Insecure: vals["adguard_insecure"] == "true",
+		// Replicas and answer overrides are only edited in the config file.
+		AnswerOverride: existing.Adguard.AnswerOverride,
+		Instances:      existing.Adguard.Instances,
 	}
 	cfg.Cloudflare = config.CloudflareConfig{
 		Enabled:         vals["cf_enabled"] == "true",
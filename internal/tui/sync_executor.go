@@ -12,6 +12,11 @@ type TUISyncExecutor struct {
 	clients syncplan.Clients
 	dryRun  bool
 	ctx     context.Context
+	journal *syncplan.Journal
+	// policy, when set, is enforced before every apply; existing holds the
+	// per-target record counts its delete percentage is measured against.
+	policy   *syncplan.Policy
+	existing map[string]int
 }
 
 // WithContext sets the context for sync operations (enables Ctrl+C cancellation).
@@ -41,6 +46,28 @@ func NewTUISyncExecutor(
 	return executor
 }
 
+// WithPiholeClient adds the optional Pi-hole target.
+func (e *TUISyncExecutor) WithPiholeClient(piholeClient *api.PiholeClient) *TUISyncExecutor {
+	if piholeClient != nil {
+		e.clients.Pihole = piholeClient
+	}
+	return e
+}
+
+// WithJournal records applied actions in journal so they can be undone.
+func (e *TUISyncExecutor) WithJournal(journal *syncplan.Journal) *TUISyncExecutor {
+	e.journal = journal
+	return e
+}
+
+// WithPolicy enforces policy on every apply. existing counts the records
+// each target holds (see syncplan.ExistingRecords).
+func (e *TUISyncExecutor) WithPolicy(policy syncplan.Policy, existing map[string]int) *TUISyncExecutor {
+	e.policy = &policy
+	e.existing = existing
+	return e
+}
+
 // SetDryRun sets dry run mode.
 func (e *TUISyncExecutor) SetDryRun(dryRun bool) {
 	e.dryRun = dryRun
@@ -49,13 +76,7 @@ func (e *TUISyncExecutor) SetDryRun(dryRun bool) {
 // ExecuteSyncAction executes a single sync action.
 func (e *TUISyncExecutor) ExecuteSyncAction(action syncplan.Action) error {
 	action.Enabled = true
-	ctx := e.ctx
-	if ctx == nil {
-		ctx = context.Background()
-	}
-	result := syncplan.Apply(ctx, e.clients, syncplan.Plan{Actions: []syncplan.Action{action}}, syncplan.ApplyOptions{
-		DryRun: e.dryRun,
-	})
+	result := e.ExecuteSyncActions([]syncplan.Action{action})
 	if result.Success {
 		return nil
 	}
@@ -74,7 +95,28 @@ func (e *TUISyncExecutor) ExecuteSyncActions(actions []syncplan.Action) *syncpla
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	return syncplan.Apply(ctx, e.clients, syncplan.Plan{Actions: actions}, syncplan.ApplyOptions{
-		DryRun: e.dryRun,
+	var protected []syncplan.Action
+	if e.policy != nil {
+		var err error
+		actions, protected, err = e.policy.Enforce(actions, e.existing)
+		if err != nil {
+			return &syncplan.Result{
+				Success: false,
+				Errors:  []string{err.Error()},
+				Message: "Nothing applied: " + err.Error(),
+			}
+		}
+	}
+	result := syncplan.Apply(ctx, e.clients, syncplan.Plan{Actions: actions}, syncplan.ApplyOptions{
+		DryRun:  e.dryRun,
+		Journal: e.journal,
 	})
+	for _, action := range protected {
+		result.ActionResults = append(result.ActionResults, syncplan.ActionResult{
+			Action:  action,
+			Skipped: true,
+			Error:   "hostname is protected by sync_policy",
+		})
+	}
+	return result
 }
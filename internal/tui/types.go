@@ -37,6 +37,7 @@ type SyncStatus struct {
 	DataSource    string
 	CaddyIP       string
 	DNSResolvedIP string
+	DNSResolvedV6 string
 	UpstreamIP    string
 	DHCPLeaseIP   string
 	DHCPLeaseType string
@@ -206,6 +207,7 @@ func syncStatusFromEntry(entry *models.Entry) SyncStatus {
 		DataSource:    entry.DataSource,
 		CaddyIP:       entry.CaddyIP,
 		DNSResolvedIP: entry.DNSResolved,
+		DNSResolvedV6: entry.DNSResolvedV6,
 		UpstreamIP:    entry.CaddyUpstream,
 		DHCPLeaseIP:   entry.DHCPStatus.IP,
 		DHCPLeaseType: entry.DHCPStatus.Type,
@@ -219,8 +221,8 @@ func syncStatusFromEntry(entry *models.Entry) SyncStatus {
 func serviceStatusFromModel(status models.ServiceStatus) ServiceStatus {
 	return ServiceStatus{
 		Present: status.Configured,
-		InSync:  status.InSync,
-		IP:      status.IP,
+		InSync:  status.Matches(),
+		IP:      status.Addresses(),
 	}
 }
 
@@ -453,6 +455,9 @@ func (r *SyncStatusRenderer) RenderDashboard() string {
 		} else if s.DNSResolvedIP == "FAIL" || s.DNSResolvedIP == "NONE" {
 			dnsText = outStyle.Render(s.DNSResolvedIP)
 		}
+		if s.DNSResolvedV6 != "" {
+			dnsText += " " + dimStyle.Render(s.DNSResolvedV6)
+		}
 
 		// Upstream
 		upstream := dimStyle.Render("—")
@@ -0,0 +1,152 @@
+// Package watch keeps DNS in sync continuously: it polls the Caddy admin
+// config for changes and re-plans only when Caddy changed or a periodic full
+// resync is due, backing off after failed runs.
+package watch
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/logging"
+)
+
+const (
+	// DefaultPollInterval is how often the Caddy config version is checked.
+	DefaultPollInterval = 30 * time.Second
+	// DefaultResyncInterval is how often a full resync runs even when Caddy
+	// did not change, catching edits made directly in a DNS service.
+	DefaultResyncInterval = time.Hour
+	// DefaultMaxBackoff caps the wait between retries of a failing run.
+	DefaultMaxBackoff = 5 * time.Minute
+	// resyncJitter spreads full resyncs by up to this share of the interval
+	// either way, so several watchers do not hit the DNS services together.
+	resyncJitter = 0.1
+)
+
+// Options controls the watch loop. Zero durations select the defaults.
+type Options struct {
+	PollInterval   time.Duration
+	ResyncInterval time.Duration
+	MaxBackoff     time.Duration
+}
+
+func (o Options) withDefaults() Options {
+	if o.PollInterval <= 0 {
+		o.PollInterval = DefaultPollInterval
+	}
+	if o.ResyncInterval <= 0 {
+		o.ResyncInterval = DefaultResyncInterval
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = DefaultMaxBackoff
+	}
+	if o.MaxBackoff < o.PollInterval {
+		o.MaxBackoff = o.PollInterval
+	}
+	return o
+}
+
+// Reason says why a sync run was started.
+type Reason string
+
+const (
+	// ReasonStartup is the first run after the watcher starts.
+	ReasonStartup Reason = "startup"
+	// ReasonChanged is a run after the Caddy config version changed.
+	ReasonChanged Reason = "caddy config changed"
+	// ReasonResync is a periodic full resync.
+	ReasonResync Reason = "periodic resync"
+	// ReasonRetry retries a run that failed.
+	ReasonRetry Reason = "retry"
+)
+
+// Watcher runs Sync whenever Version reports a new Caddy config version and
+// at jittered ResyncInterval intervals.
+type Watcher struct {
+	// Version returns a value that changes whenever Caddy's config does.
+	Version func(ctx context.Context) (string, error)
+	// Sync plans and applies one run.
+	Sync    func(ctx context.Context, reason Reason) error
+	Options Options
+
+	// now and after are replaced in tests.
+	now   func() time.Time
+	after func(time.Duration) <-chan time.Time
+}
+
+// Run polls until ctx is cancelled, which it returns as nil.
+func (w *Watcher) Run(ctx context.Context) error {
+	options := w.Options.withDefaults()
+	now, after := w.now, w.after
+	if now == nil {
+		now = time.Now
+	}
+	if after == nil {
+		after = time.After
+	}
+
+	var synced string
+	var nextResync time.Time
+	failures := 0
+	reason := ReasonStartup
+	for ctx.Err() == nil {
+		version, err := w.Version(ctx)
+		if err != nil {
+			logging.Warn("Failed to read Caddy config version", "error", err)
+		}
+
+		switch {
+		case err != nil:
+			failures++
+		case reason == ReasonStartup || reason == ReasonRetry:
+		case version != synced:
+			reason = ReasonChanged
+		case !now().Before(nextResync):
+			reason = ReasonResync
+		default:
+			reason = ""
+		}
+
+		if err == nil && reason != "" {
+			logging.Info("Starting sync", "reason", string(reason))
+			if err := w.Sync(ctx, reason); err != nil {
+				failures++
+				logging.Error("Sync failed", "reason", string(reason), "error", err, "failures", failures)
+			} else {
+				failures = 0
+				synced = version
+				nextResync = now().Add(jitter(options.ResyncInterval))
+			}
+		}
+
+		wait := options.PollInterval
+		reason = ""
+		if failures > 0 {
+			wait = backoff(options.PollInterval, options.MaxBackoff, failures)
+			reason = ReasonRetry
+			logging.Info("Retrying after backoff", "wait", wait.String())
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-after(wait):
+		}
+	}
+	return nil
+}
+
+// backoff doubles base for every failure after the first, up to limit.
+func backoff(base, limit time.Duration, failures int) time.Duration {
+	wait := base
+	for i := 1; i < failures && wait < limit; i++ {
+		wait *= 2
+	}
+	return min(wait, limit)
+}
+
+// jitter returns interval moved by up to resyncJitter of itself either way.
+func jitter(interval time.Duration) time.Duration {
+	spread := float64(interval) * resyncJitter
+	return interval + time.Duration((rand.Float64()*2-1)*spread)
+}
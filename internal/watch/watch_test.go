@@ -0,0 +1,112 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeClock advances by every wait the watcher asks for.
+type fakeClock struct {
+	now   time.Time
+	waits []time.Duration
+	polls int
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.waits = append(c.waits, d)
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+func runWatcher(t *testing.T, polls int, versions []string, syncErrs []error, options Options) ([]Reason, *fakeClock) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	var reasons []Reason
+	w := &Watcher{
+		Version: func(context.Context) (string, error) {
+			clock.polls++
+			if clock.polls >= polls {
+				cancel()
+			}
+			return versions[min(clock.polls, len(versions))-1], nil
+		},
+		Sync: func(_ context.Context, reason Reason) error {
+			reasons = append(reasons, reason)
+			if len(syncErrs) > 0 {
+				err := syncErrs[0]
+				syncErrs = syncErrs[1:]
+				return err
+			}
+			return nil
+		},
+		Options: options,
+		now:     clock.Now,
+		after:   clock.After,
+	}
+	if err := w.Run(ctx); err != nil {
+		t.Fatalf("Run returned %v", err)
+	}
+	return reasons, clock
+}
+
+func TestWatcherSyncsOnStartupAndWhenCaddyChanges(t *testing.T) {
+	reasons, _ := runWatcher(t, 5, []string{"a", "a", "b", "b", "b"}, nil, Options{PollInterval: time.Minute})
+
+	want := []Reason{ReasonStartup, ReasonChanged}
+	if !reflect.DeepEqual(reasons, want) {
+		t.Fatalf("reasons = %v, want %v", reasons, want)
+	}
+}
+
+func TestWatcherBacksOffAfterFailuresAndRetries(t *testing.T) {
+	failed := errors.New("unbound unreachable")
+	reasons, clock := runWatcher(t, 5, []string{"a"}, []error{failed, failed, failed}, Options{
+		PollInterval: time.Minute,
+		MaxBackoff:   3 * time.Minute,
+	})
+
+	want := []Reason{ReasonStartup, ReasonRetry, ReasonRetry, ReasonRetry}
+	if !reflect.DeepEqual(reasons, want) {
+		t.Fatalf("reasons = %v, want %v", reasons, want)
+	}
+	wantWaits := []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, time.Minute, time.Minute}
+	if !reflect.DeepEqual(clock.waits, wantWaits) {
+		t.Fatalf("waits = %v, want %v", clock.waits, wantWaits)
+	}
+}
+
+func TestWatcherRunsJitteredFullResyncs(t *testing.T) {
+	reasons, _ := runWatcher(t, 13, []string{"a"}, nil, Options{
+		PollInterval:   time.Minute,
+		ResyncInterval: 10 * time.Minute,
+	})
+
+	// The resync falls 9 to 11 minutes after startup, within 12 polls.
+	want := []Reason{ReasonStartup, ReasonResync}
+	if !reflect.DeepEqual(reasons, want) {
+		t.Fatalf("reasons = %v, want %v", reasons, want)
+	}
+}
+
+func TestBackoffIsCapped(t *testing.T) {
+	for failures, want := range map[int]time.Duration{
+		1: 30 * time.Second,
+		2: time.Minute,
+		3: 2 * time.Minute,
+		9: 5 * time.Minute,
+	} {
+		if got := backoff(30*time.Second, 5*time.Minute, failures); got != want {
+			t.Errorf("backoff after %d failures = %v, want %v", failures, got, want)
+		}
+	}
+}
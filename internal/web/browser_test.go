@@ -68,7 +68,7 @@ func TestBrowserSmokeWithFakeData(t *testing.T) {
 	opnsense := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		switch r.URL.Path {
-		case "/api/unbound/settings/searchHostOverride":
+		case "/api/unbound/settings/searchHostOverride", "/api/unbound/settings/searchHostAlias":
 			fmt.Fprint(w, `{"rows":[]}`)
 		case "/api/unbound/settings/addHostOverride":
 			fmt.Fprint(w, `{"result":"saved","uuid":"new-uuid"}`)
@@ -475,10 +475,12 @@ func (s *Server) handleCaddyDeploy(w http.ResponseWriter, r *http.Request) {
 
 	pr := &sseWriter{w: w, flusher: flusher, canFlush: canFlush}
 
+	s.deployMu.Lock()
 	result := caddyeditor.DeployPipeline(r.Context(), cfg, caddyeditor.DeployPipelineOptions{
 		SkipValidate:  opts.SkipValidate,
 		CommitMessage: opts.CommitMessage,
 	}, pr)
+	s.deployMu.Unlock()
 
 	// Send final done event.
 	status := "ok"
@@ -33,6 +33,7 @@ type Options struct {
 	BoundHost       string
 	EnableTestHooks bool
 	ConfigPath      string
+	JournalDir      string // run journal directory; defaults to syncplan.DefaultJournalDir()
 	Version         string // build version, e.g. "v1.2.3" or "dev"
 	Commit          string // git commit hash
 	BuildDate       string // build timestamp
@@ -45,6 +46,19 @@ type Server struct {
 	runtimeMu sync.RWMutex
 	planMu    sync.Mutex
 	plans     map[string]storedPlan
+	journal   *syncplan.Journal
+	undoMu    sync.Mutex
+	// deployMu serializes Caddy editor deploys from the UI and webhooks, so
+	// two pipelines never pull, validate or reload the repo at once.
+	deployMu sync.Mutex
+
+	// Webhook runs — hookMu serializes runs, hookRunsMu guards the record of
+	// recent deliveries and the bodies already accepted, kept to reject
+	// replays.
+	hookMu     sync.Mutex
+	hookRunsMu sync.Mutex
+	hookRuns   []HookRun
+	hookSeen   map[string]time.Time
 
 	// Auth inventory cache — populated at startup and after mutations.
 	authMu    sync.RWMutex
@@ -88,11 +102,16 @@ func NewServerWithOptions(runtime *app.Runtime, options Options) *Server {
 	// Capture log lines into the ring buffer so the web UI can stream them.
 	logging.EnableBuffer()
 	ctx, cancel := context.WithCancel(context.Background())
+	journalDir := options.JournalDir
+	if journalDir == "" {
+		journalDir = syncplan.DefaultJournalDir()
+	}
 	server := &Server{
 		runtime: runtime,
 		options: options,
 		mux:     http.NewServeMux(),
 		plans:   make(map[string]storedPlan),
+		journal: syncplan.NewJournal(journalDir),
 		ctx:     ctx,
 		cancel:  cancel,
 	}
@@ -244,6 +263,10 @@ func (s *Server) routes() {
 	s.mux.HandleFunc("/api/sync/plan", s.handlePlan)
 	s.mux.HandleFunc("/api/sync/apply", s.handleApply)
 	s.mux.HandleFunc("/api/sync/remove", s.handleSyncRemove)
+	s.mux.HandleFunc("/api/history", s.handleHistory)
+	s.mux.HandleFunc("/api/history/", s.handleHistoryRun)
+	s.mux.HandleFunc("/api/hooks", s.handleHooks)
+	s.mux.HandleFunc("/api/hooks/", s.handleHook)
 	// Caddy Editor routes
 	s.mux.HandleFunc("/api/caddy/entries", s.handleCaddyEntries)
 	s.mux.HandleFunc("/api/caddy/entries/", s.handleCaddyEntry)
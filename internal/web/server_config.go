@@ -14,6 +14,7 @@ import (
 	"github.com/jeeftor/caddy-dns-sync/internal/caddyeditor"
 	"github.com/jeeftor/caddy-dns-sync/internal/config"
 	"github.com/jeeftor/caddy-dns-sync/internal/logging"
+	"github.com/jeeftor/caddy-dns-sync/internal/syncplan"
 	"github.com/spf13/viper"
 )
 
@@ -38,6 +39,7 @@ type ConfigSummary struct {
 	Caddy      ConfigServiceSummary `json:"caddy"`
 	Unbound    ConfigServiceSummary `json:"unbound"`
 	Adguard    ConfigServiceSummary `json:"adguard"`
+	Pihole     ConfigServiceSummary `json:"pihole"`
 	DHCP       ConfigServiceSummary `json:"dhcp"`
 	Cloudflare ConfigServiceSummary `json:"cloudflare"`
 }
@@ -121,6 +123,7 @@ type sourceProbe string
 const (
 	sourceProbeUnbound    sourceProbe = "unbound"
 	sourceProbeAdguard    sourceProbe = "adguard"
+	sourceProbePihole     sourceProbe = "pihole"
 	sourceProbeCloudflare sourceProbe = "cloudflare"
 )
 
@@ -252,18 +255,28 @@ func (s *Server) configResponse() (ConfigResponse, error) {
 		return ConfigResponse{}, err
 	}
 	runtime := s.runtimeSnapshot()
+	enabled := map[string]bool{
+		"caddy":      runtime.Clients.Caddy != nil,
+		"unbound":    runtime.Clients.Unbound != nil,
+		"dnsmasq":    runtime.Clients.DNSMasq != nil && runtime.Clients.DNSMasqHosts,
+		"adguard":    runtime.Clients.Adguard != nil,
+		"pihole":     runtime.Clients.Pihole != nil,
+		"rfc2136":    runtime.Clients.RFC2136 != nil,
+		"dhcp":       runtime.Clients.DNSMasq != nil,
+		"cloudflare": runtime.Clients.Cloudflare != nil,
+	}
+	for _, instance := range runtime.Clients.UnboundInstances {
+		enabled[syncplan.UnboundInstancePrefix+instance.Name] = instance.Client != nil
+	}
+	for _, instance := range runtime.Clients.AdguardInstances {
+		enabled[syncplan.AdguardInstancePrefix+instance.Name] = instance.Client != nil
+	}
 	return ConfigResponse{
 		Caddy: CaddyConfigResponse{
 			ServerIP:   runtime.CaddyEndpoint.ServerIP,
 			ServerPort: runtime.CaddyEndpoint.ServerPort,
 		},
-		Enabled: map[string]bool{
-			"caddy":      runtime.Clients.Caddy != nil,
-			"unbound":    runtime.Clients.Unbound != nil,
-			"adguard":    runtime.Clients.Adguard != nil,
-			"dhcp":       runtime.Clients.DNSMasq != nil,
-			"cloudflare": runtime.Clients.Cloudflare != nil,
-		},
+		Enabled:         enabled,
 		MutationEnabled: s.mutationsEnabled(),
 		SaveTarget:      saveTarget,
 		Summary:         s.configSummary(&runtime),
@@ -306,7 +319,7 @@ func (s *Server) testConfigService(service string) ConfigTestResponse {
 			Success: true,
 			Message: "Connected to Caddy admin API.",
 			Details: map[string]string{
-				"endpoint": fmt.Sprintf("%s:%d", runtime.CaddyEndpoint.ServerIP, runtime.CaddyEndpoint.ServerPort),
+				"endpoint": runtime.CaddyEndpoint.String(),
 				"sections": fmt.Sprintf("%d", len(cfg)),
 			},
 		}
@@ -338,6 +351,20 @@ func (s *Server) testConfigService(service string) ConfigTestResponse {
 			Message: "Connected to AdGuard rewrite API.",
 			Details: map[string]string{"rewrites": fmt.Sprintf("%d", len(rewrites))},
 		}
+	case "pihole":
+		if runtime.Clients.Pihole == nil {
+			return failedConfigTest(service, "Pi-hole is not configured.")
+		}
+		hosts, err := runtime.Clients.Pihole.ListHosts()
+		if err != nil {
+			return failedConfigTest(service, fmt.Sprintf("Pi-hole test failed: %v", err))
+		}
+		return ConfigTestResponse{
+			Service: service,
+			Success: true,
+			Message: "Connected to Pi-hole local DNS API.",
+			Details: map[string]string{"hosts": fmt.Sprintf("%d", len(hosts))},
+		}
 	case "cloudflare":
 		if runtime.Clients.Cloudflare == nil {
 			return failedConfigTest(service, "Cloudflare is not configured.")
@@ -374,6 +401,10 @@ func (s *Server) configSummary(runtime *app.Runtime) ConfigSummary {
 		"Username": runtime.AdguardConfig.Username != "",
 		"Password": runtime.AdguardConfig.Password != "",
 	})
+	piholeMissing := missingFields(map[string]bool{
+		"Enabled":  runtime.PiholeConfig.Enabled,
+		"Base URL": runtime.PiholeConfig.BaseURL != "",
+	})
 	cloudflareMissing := []string{}
 	if runtime.CloudflareConfig.Enabled {
 		cloudflareMissing = missingFields(map[string]bool{
@@ -383,7 +414,7 @@ func (s *Server) configSummary(runtime *app.Runtime) ConfigSummary {
 			"Tunnel ID":  runtime.CloudflareConfig.TunnelID != "",
 		})
 	}
-	caddyEndpoint := fmt.Sprintf("%s:%d", runtime.CaddyEndpoint.ServerIP, runtime.CaddyEndpoint.ServerPort)
+	caddyEndpoint := runtime.CaddyEndpoint.String()
 	unboundEndpoint := sanitizeEndpoint(runtime.UnboundConfig.BaseURL)
 	adguardEndpoint := sanitizeEndpoint(runtime.AdguardConfig.BaseURL)
 	caddyServiceURL := sanitizeEndpoint(runtime.CaddyServiceURL)
@@ -423,6 +454,19 @@ func (s *Server) configSummary(runtime *app.Runtime) ConfigSummary {
 			},
 			Missing: adguardMissing,
 		},
+		Pihole: ConfigServiceSummary{
+			Label:       "Pi-hole",
+			Enabled:     runtime.PiholeConfig.Enabled,
+			ClientReady: runtime.Clients.Pihole != nil,
+			Source:      s.configSource(configPath, sourceProbePihole),
+			Endpoint:    sanitizeEndpoint(runtime.PiholeConfig.BaseURL),
+			Insecure:    runtime.PiholeConfig.Insecure,
+			Fields: map[string]bool{
+				"password_set": runtime.PiholeConfig.Password != "",
+				"base_url_set": runtime.PiholeConfig.BaseURL != "",
+			},
+			Missing: piholeMissing,
+		},
 		DHCP: ConfigServiceSummary{
 			Label:       "DHCP / DNSMasq",
 			Enabled:     runtime.Clients.DNSMasq != nil,
@@ -472,6 +516,16 @@ func (s *Server) configSource(configPath string, probe sourceProbe) ConfigSource
 			}
 			return ConfigSource{Kind: "cli", Label: "Viper/CLI values"}
 		}
+	case sourceProbePihole:
+		if os.Getenv(config.EnvPiholeEnabled) != "" {
+			return ConfigSource{Kind: "env", Label: "Environment variables"}
+		}
+		if viper.IsSet("pihole") {
+			if used := viper.ConfigFileUsed(); used != "" {
+				return ConfigSource{Kind: "config-file", Label: "Viper config file", Path: used}
+			}
+			return ConfigSource{Kind: "cli", Label: "Viper/CLI values"}
+		}
 	case sourceProbeCloudflare:
 		if os.Getenv(config.EnvCFEnabled) != "" {
 			return ConfigSource{Kind: "env", Label: "Environment variables"}
@@ -505,6 +559,8 @@ func (s *Server) configFileHasService(configPath string, probe sourceProbe) bool
 		return cfg.APIKey != "" || cfg.APISecret != "" || cfg.BaseURL != ""
 	case sourceProbeAdguard:
 		return cfg.Adguard.Enabled || cfg.Adguard.BaseURL != "" || cfg.Adguard.Username != "" || cfg.Adguard.Password != ""
+	case sourceProbePihole:
+		return cfg.Pihole.Enabled || cfg.Pihole.BaseURL != "" || cfg.Pihole.Password != ""
 	case sourceProbeCloudflare:
 		return cfg.Cloudflare.Enabled ||
 			cfg.Cloudflare.APIToken != "" ||
@@ -567,7 +623,7 @@ func (s *Server) loadWritableConfig(configPath string) (config.ExtendedConfig, e
 	runtime := s.runtimeSnapshot()
 	return config.ExtendedConfig{
 		Config:     runtime.UnboundConfig,
-		Caddy:      config.CaddyConfig{ServerIP: runtime.CaddyEndpoint.ServerIP, ServerPort: runtime.CaddyEndpoint.ServerPort},
+		Caddy:      config.CaddyConfig{ServerIP: runtime.CaddyEndpoint.ServerIP, ServerPort: runtime.CaddyEndpoint.ServerPort, Admin: runtime.CaddyEndpoint.Admin},
 		Adguard:    runtime.AdguardConfig,
 		Cloudflare: runtime.CloudflareConfig,
 	}, nil
@@ -668,18 +724,33 @@ func applyCaddyEditorConfigUpdate(cfg *caddyeditor.EditorConfig, update *CaddyEd
 
 func (s *Server) reloadRuntimeFromConfig(cfg config.ExtendedConfig) error {
 	current := s.runtimeSnapshot()
-	nextRuntime, err := app.NewRuntimeFromConfigs(cfg.Config, cfg.Adguard, cfg.Cloudflare, cfg.Authentik, app.RuntimeOptions{
+	nextRuntime, err := app.NewRuntimeFromConfigs(cfg.Config, cfg.Adguard, cfg.Pihole, cfg.RFC2136, cfg.Cloudflare, cfg.Authentik, app.RuntimeOptions{
 		CaddyServerIP:     current.CaddyEndpoint.ServerIP,
 		CaddyServerPort:   current.CaddyEndpoint.ServerPort,
+		CaddyAdmin:        current.CaddyEndpoint.Admin,
+		CaddyServerIPv6:   current.CaddyEndpoint.ServerIPv6,
 		IncludeUnbound:    true,
 		IncludeDNSMasq:    current.Clients.DNSMasq != nil,
 		IncludeAdguard:    true,
+		IncludePihole:     true,
+		IncludeRFC2136:    true,
 		IncludeCloudflare: true,
 		IncludeAuthentik:  true,
 	})
 	if err != nil {
 		return fmt.Errorf("error refreshing runtime from saved config: %w", err)
 	}
+	if err := config.ValidateUnboundInstances(cfg.UnboundInstances); err != nil {
+		return fmt.Errorf("error refreshing runtime from saved config: %w", err)
+	}
+	nextRuntime.AddUnboundInstances(cfg.UnboundInstances)
+	nextRuntime.Clients.CaddySource = current.Clients.CaddySource
+	nextRuntime.Clients.CaddyServers = current.Clients.CaddyServers
+	nextRuntime.Clients.Docker = current.Clients.Docker
+	nextRuntime.Clients.Kubernetes = current.Clients.Kubernetes
+	nextRuntime.Clients.Manifest = current.Clients.Manifest
+	nextRuntime.Clients.AnswerRules = current.Clients.AnswerRules
+	nextRuntime.Clients.AliasCanonical = current.Clients.AliasCanonical
 	s.runtimeMu.Lock()
 	s.runtime = nextRuntime
 	s.runtimeMu.Unlock()
@@ -5,12 +5,15 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/jeeftor/caddy-dns-sync/internal/api"
 	"github.com/jeeftor/caddy-dns-sync/internal/app"
+	"github.com/jeeftor/caddy-dns-sync/internal/config"
 	"github.com/jeeftor/caddy-dns-sync/internal/logging"
 	"github.com/jeeftor/caddy-dns-sync/internal/models"
 	"github.com/jeeftor/caddy-dns-sync/internal/status"
@@ -27,7 +30,11 @@ type EntriesResponse struct {
 type ServiceStatusResponse struct {
 	Configured bool   `json:"configured"`
 	IP         string `json:"ip"`
-	InSync     bool   `json:"in_sync"`
+	IPv6       string `json:"ipv6,omitempty"`
+	// Alias is set when IP holds the hostname of the record this one
+	// aliases rather than an address.
+	Alias  bool `json:"alias,omitempty"`
+	InSync bool `json:"in_sync"`
 }
 
 type DHCPStatusResponse struct {
@@ -55,22 +62,31 @@ type CloudflareStatusResponse struct {
 }
 
 type EntryResponse struct {
-	Hostname                   string                   `json:"hostname"`
-	CaddyUpstream              string                   `json:"caddy_upstream"`
-	CaddyIP                    string                   `json:"caddy_ip"`
-	CaddyPort                  string                   `json:"caddy_port"`
-	UnboundStatus              ServiceStatusResponse    `json:"unbound_status"`
-	AdguardStatus              ServiceStatusResponse    `json:"adguard_status"`
-	DHCPStatus                 DHCPStatusResponse       `json:"dhcp_status"`
-	DNSResolved                string                   `json:"dns_resolved"`
-	CloudflareStatus           CloudflareStatusResponse `json:"cloudflare_status"`
-	OverallStatus              models.SyncStatus        `json:"overall_status"`
-	StatusLabel                string                   `json:"status_label"`
-	DataSource                 string                   `json:"data_source"`
-	HasForwardAuth             bool                     `json:"has_forward_auth"`
-	HasConditionalForwardAuth  bool                     `json:"has_conditional_forward_auth,omitempty"`
-	HasAuthBypass              bool                     `json:"has_auth_bypass_risk"`
-	MissingRequiredForwardAuth bool                     `json:"missing_required_forward_auth,omitempty"`
+	Hostname      string `json:"hostname"`
+	CaddyUpstream string `json:"caddy_upstream"`
+	CaddyIP       string `json:"caddy_ip"`
+	CaddyPort     string `json:"caddy_port"`
+	// CaddyServer names the Caddy server owning the hostname; CaddyConflicts
+	// lists every server claiming it when several do.
+	CaddyServer    string                 `json:"caddy_server,omitempty"`
+	CaddyConflicts []string               `json:"caddy_conflicts,omitempty"`
+	UnboundStatus  ServiceStatusResponse  `json:"unbound_status"`
+	AdguardStatus  ServiceStatusResponse  `json:"adguard_status"`
+	PiholeStatus   *ServiceStatusResponse `json:"pihole_status,omitempty"`
+	// TargetStatus holds the status of targets without a dedicated field
+	// (e.g. "rfc2136", "dnsmasq", "unbound:fw-b"), keyed by target name.
+	TargetStatus               map[string]ServiceStatusResponse `json:"target_status,omitempty"`
+	DHCPStatus                 DHCPStatusResponse               `json:"dhcp_status"`
+	DNSResolved                string                           `json:"dns_resolved"`
+	DNSResolvedV6              string                           `json:"dns_resolved_v6,omitempty"`
+	CloudflareStatus           CloudflareStatusResponse         `json:"cloudflare_status"`
+	OverallStatus              models.SyncStatus                `json:"overall_status"`
+	StatusLabel                string                           `json:"status_label"`
+	DataSource                 string                           `json:"data_source"`
+	HasForwardAuth             bool                             `json:"has_forward_auth"`
+	HasConditionalForwardAuth  bool                             `json:"has_conditional_forward_auth,omitempty"`
+	HasAuthBypass              bool                             `json:"has_auth_bypass_risk"`
+	MissingRequiredForwardAuth bool                             `json:"missing_required_forward_auth,omitempty"`
 }
 
 type PlanResponse struct {
@@ -85,6 +101,11 @@ type ApplyRequest struct {
 	ActionIDs []string          `json:"action_ids"`
 	DryRun    bool              `json:"dry_run"`
 	Actions   []syncplan.Action `json:"actions"`
+	// Atomic is "hostname" or "run" to roll back applied actions when a
+	// later one in the same group fails.
+	Atomic string `json:"atomic,omitempty"`
+	// AllowMassDelete lifts the sync_policy delete limits for this apply.
+	AllowMassDelete bool `json:"allow_mass_delete,omitempty"`
 }
 
 type ApplyResponse struct {
@@ -143,7 +164,23 @@ func (s *Server) handleEntriesStream(w http.ResponseWriter, r *http.Request) {
 		runtime.Clients.DNSMasq,
 		runtime.CaddyEndpoint.ServerIP,
 	)
+	loader.WithCaddyServerIPv6(runtime.CaddyEndpoint.ServerIPv6)
+	loader.WithCaddySource(runtime.Clients.CaddySource)
+	loader.WithCaddyServers(runtime.Clients.CaddyServers)
+	loader.WithDockerClient(runtime.Clients.Docker)
+	loader.WithKubernetesClient(runtime.Clients.Kubernetes)
+	loader.WithManifest(runtime.Clients.Manifest)
+	loader.WithAnswerRules(runtime.Clients.AnswerRules)
+	loader.WithAliasCanonical(runtime.Clients.AliasCanonical)
 	loader.WithCloudflareClient(runtime.Clients.Cloudflare)
+	loader.WithKeaClient(runtime.Clients.Kea)
+	if runtime.Clients.DNSMasqHosts {
+		loader.WithDNSMasqHosts(runtime.Clients.DNSMasq)
+	}
+	loader.WithPiholeClient(runtime.Clients.Pihole)
+	loader.WithRFC2136Client(runtime.Clients.RFC2136)
+	loader.WithUnboundInstances(runtime.Clients.UnboundInstances)
+	loader.WithAdguardInstances(runtime.Clients.AdguardAnswerOverride, runtime.Clients.AdguardInstances)
 	loader.WithContext(ctx)
 	loader.WithProgress(func(ev status.ProgressEvent) {
 		data, err := json.Marshal(ev)
@@ -254,6 +291,11 @@ func (s *Server) handleApply(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid apply request: %w", err))
 		return
 	}
+	atomic, err := syncplan.ParseAtomicScope(request.Atomic)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
 	if !request.DryRun {
 		if err := s.allowMutation(r); err != nil {
 			writeError(w, http.StatusForbidden, err)
@@ -276,7 +318,23 @@ func (s *Server) handleApply(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusBadRequest, err)
 			return
 		}
-		result := s.applyActions(r.Context(), actions, false)
+		actions, protected, err := s.enforceSyncPolicy(r.Context(), actions, request.AllowMassDelete)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, syncplan.ErrMassDelete) {
+				status = http.StatusConflict
+			}
+			writeError(w, status, err)
+			return
+		}
+		result := s.applyActions(r.Context(), actions, false, atomic)
+		for _, action := range protected {
+			result.ActionResults = append(result.ActionResults, syncplan.ActionResult{
+				Action:  action,
+				Skipped: true,
+				Error:   "hostname is protected by sync_policy",
+			})
+		}
 		writeJSON(w, http.StatusOK, ApplyResponse{Result: result})
 		// Refresh auth cache — entries may have changed.
 		s.invalidateEntriesCache()
@@ -287,7 +345,7 @@ func (s *Server) handleApply(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, err)
 		return
 	}
-	result := s.applyActions(r.Context(), request.Actions, request.DryRun)
+	result := s.applyActions(r.Context(), request.Actions, request.DryRun, atomic)
 	writeJSON(w, http.StatusOK, ApplyResponse{Result: result})
 	if !request.DryRun {
 		s.invalidateEntriesCache()
@@ -295,17 +353,33 @@ func (s *Server) handleApply(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (s *Server) applyActions(ctx context.Context, actions []syncplan.Action, dryRun bool) *syncplan.Result {
+// enforceSyncPolicy drops actions on protected hostnames and refuses a mass
+// delete unless allowMassDelete is set.
+func (s *Server) enforceSyncPolicy(ctx context.Context, actions []syncplan.Action, allowMassDelete bool) (allowed, protected []syncplan.Action, err error) {
+	cfg, err := config.LoadSyncPolicyConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	entries, _, err := s.loadEntries(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	policy := syncplan.NewPolicy(cfg, allowMassDelete)
+	return policy.Enforce(actions, syncplan.ExistingRecords(entries))
+}
+
+func (s *Server) applyActions(ctx context.Context, actions []syncplan.Action, dryRun bool, atomic syncplan.AtomicScope) *syncplan.Result {
 	runtime := s.runtimeSnapshot()
-	return syncplan.Apply(ctx, syncplan.Clients{
-		Unbound:    runtime.Clients.Unbound,
-		Adguard:    runtime.Clients.Adguard,
-		Cloudflare: runtime.Clients.Cloudflare,
-	}, syncplan.Plan{Actions: actions}, syncplan.ApplyOptions{DryRun: dryRun})
+	clients := syncplan.NewClients(runtime.Clients)
+	return syncplan.Apply(ctx, clients, syncplan.Plan{Actions: actions}, syncplan.ApplyOptions{
+		DryRun:  dryRun,
+		Journal: s.journal,
+		Atomic:  atomic,
+	})
 }
 
 // handleSyncRemove deletes DNS entries for a specific hostname.
-// Body: {"hostname":"foo.example.com","service":"all"|"unbound"|"adguard"}
+// Body: {"hostname":"foo.example.com","service":"all"|"unbound"|"unbound:<name>"|"adguard"|"adguard:<name>"|"pihole"}
 // service defaults to "all" when omitted.
 func (s *Server) handleSyncRemove(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -319,7 +393,7 @@ func (s *Server) handleSyncRemove(w http.ResponseWriter, r *http.Request) {
 	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
 	var req struct {
 		Hostname string `json:"hostname"`
-		Service  string `json:"service"` // "all", "unbound", "adguard" — defaults to "all"
+		Service  string `json:"service"` // "all", "unbound", "dnsmasq", "adguard", "pihole" — defaults to "all"
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Hostname == "" {
 		writeError(w, http.StatusBadRequest, fmt.Errorf("hostname required"))
@@ -339,44 +413,86 @@ func (s *Server) handleSyncRemove(w http.ResponseWriter, r *http.Request) {
 
 	// Remove from Unbound
 	if (req.Service == "all" || req.Service == "unbound") && runtime.Clients.Unbound != nil {
-		parts := strings.SplitN(req.Hostname, ".", 2)
-		if len(parts) == 2 {
-			overrides, err := runtime.Clients.Unbound.GetOverrides()
-			if err == nil {
-				unboundRemoved := 0
-				for _, o := range overrides {
-					if strings.EqualFold(o.Host, parts[0]) && strings.EqualFold(o.Domain, parts[1]) {
-						if delErr := runtime.Clients.Unbound.DeleteOverride(o.UUID); delErr == nil {
-							unboundRemoved++
-							removed++
-						}
+		if n := removeUnboundOverrides(runtime.Clients.Unbound, req.Hostname); n > 0 {
+			removed += n
+			msgs = append(msgs, fmt.Sprintf("removed %d Unbound override(s)", n))
+		}
+	}
+	for _, instance := range runtime.Clients.UnboundInstances {
+		if req.Service != "all" && req.Service != syncplan.UnboundInstancePrefix+instance.Name {
+			continue
+		}
+		if n := removeUnboundOverrides(instance.Client, req.Hostname); n > 0 {
+			removed += n
+			msgs = append(msgs, fmt.Sprintf("removed %d Unbound (%s) override(s)", n, instance.Name))
+		}
+	}
+
+	// Remove from Dnsmasq
+	if (req.Service == "all" || req.Service == "dnsmasq") && runtime.Clients.DNSMasq != nil && runtime.Clients.DNSMasqHosts {
+		hosts, err := runtime.Clients.DNSMasq.GetHosts()
+		if err == nil {
+			n := 0
+			for _, host := range hosts {
+				if strings.EqualFold(host.Hostname(), req.Hostname) && host.HWAddr == "" {
+					if delErr := runtime.Clients.DNSMasq.DeleteHost(host.UUID); delErr == nil {
+						n++
+						removed++
 					}
 				}
-				if unboundRemoved > 0 {
-					if err := runtime.Clients.Unbound.ApplyChanges(); err != nil {
-						logging.Warn("Failed to apply Unbound changes after override removal", "error", err)
-					}
-					msgs = append(msgs, fmt.Sprintf("removed %d Unbound override(s)", unboundRemoved))
+			}
+			if n > 0 {
+				if err := runtime.Clients.DNSMasq.Reconfigure(); err != nil {
+					logging.Warn("Failed to apply Dnsmasq changes after host removal", "error", err)
 				}
+				msgs = append(msgs, fmt.Sprintf("removed %d Dnsmasq host(s)", n))
 			}
 		}
 	}
 
 	// Remove from AdGuard
 	if (req.Service == "all" || req.Service == "adguard") && runtime.Clients.Adguard != nil {
-		rewrites, err := runtime.Clients.Adguard.GetRewritesForDomain(req.Hostname)
-		if err == nil {
-			n := 0
-			for _, rw := range rewrites {
-				if delErr := runtime.Clients.Adguard.DeleteRewrite(rw.Domain, rw.Answer); delErr == nil {
-					n++
-					removed++
+		if n := removeAdguardRewrites(runtime.Clients.Adguard, req.Hostname); n > 0 {
+			removed += n
+			msgs = append(msgs, fmt.Sprintf("removed %d AdGuard rewrite(s)", n))
+		}
+	}
+	for _, instance := range runtime.Clients.AdguardInstances {
+		if req.Service != "all" && req.Service != syncplan.AdguardInstancePrefix+instance.Name {
+			continue
+		}
+		if n := removeAdguardRewrites(instance.Client, req.Hostname); n > 0 {
+			removed += n
+			msgs = append(msgs, fmt.Sprintf("removed %d AdGuard (%s) rewrite(s)", n, instance.Name))
+		}
+	}
+
+	// Remove from Pi-hole
+	if (req.Service == "all" || req.Service == "pihole") && runtime.Clients.Pihole != nil {
+		n := 0
+		if hosts, err := runtime.Clients.Pihole.ListHosts(); err == nil {
+			for _, host := range hosts {
+				if strings.EqualFold(host.Hostname, req.Hostname) {
+					if delErr := runtime.Clients.Pihole.RemoveHost(host); delErr == nil {
+						n++
+						removed++
+					}
 				}
 			}
-			if n > 0 {
-				msgs = append(msgs, fmt.Sprintf("removed %d AdGuard rewrite(s)", n))
+		}
+		if cnames, err := runtime.Clients.Pihole.ListCNAMEs(); err == nil {
+			for _, cname := range cnames {
+				if strings.EqualFold(cname.Domain, req.Hostname) {
+					if delErr := runtime.Clients.Pihole.DeleteCNAME(cname); delErr == nil {
+						n++
+						removed++
+					}
+				}
 			}
 		}
+		if n > 0 {
+			msgs = append(msgs, fmt.Sprintf("removed %d Pi-hole record(s)", n))
+		}
 	}
 
 	msg := fmt.Sprintf("Removed DNS entries for %s", req.Hostname)
@@ -394,6 +510,58 @@ func (s *Server) handleSyncRemove(w http.ResponseWriter, r *http.Request) {
 	go s.refreshAuthCache()
 }
 
+// removeAdguardRewrites deletes every rewrite for hostname from one AdGuard
+// Home instance and returns how many were removed.
+func removeAdguardRewrites(client *api.AdguardClient, hostname string) int {
+	if client == nil {
+		return 0
+	}
+	rewrites, err := client.GetRewritesForDomain(hostname)
+	if err != nil {
+		logging.Warn("Failed to list AdGuard rewrites for removal", "error", err)
+		return 0
+	}
+	removed := 0
+	for _, rw := range rewrites {
+		if delErr := client.DeleteRewrite(rw.Domain, rw.Answer); delErr == nil {
+			removed++
+		}
+	}
+	return removed
+}
+
+// removeUnboundOverrides deletes every override for hostname from one Unbound
+// instance and restarts it when anything was removed. Errors are logged and
+// reported as zero removals so other services are still cleaned up.
+func removeUnboundOverrides(client *api.Client, hostname string) int {
+	if client == nil {
+		return 0
+	}
+	parts := strings.SplitN(hostname, ".", 2)
+	if len(parts) != 2 {
+		return 0
+	}
+	overrides, err := client.GetOverrides()
+	if err != nil {
+		logging.Warn("Failed to list Unbound overrides for removal", "error", err)
+		return 0
+	}
+	removed := 0
+	for _, o := range overrides {
+		if strings.EqualFold(o.Host, parts[0]) && strings.EqualFold(o.Domain, parts[1]) {
+			if delErr := client.DeleteOverride(o.UUID); delErr == nil {
+				removed++
+			}
+		}
+	}
+	if removed > 0 {
+		if err := client.ApplyChanges(); err != nil {
+			logging.Warn("Failed to apply Unbound changes after override removal", "error", err)
+		}
+	}
+	return removed
+}
+
 // ─── Entry/Plan Helpers ─────────────────────────────────────────────────────
 
 const entriesCacheTTL = 30 * time.Second
@@ -414,7 +582,8 @@ func (s *Server) loadEntries(ctx context.Context) ([]*models.Entry, status.LoadR
 
 	runtime := s.runtimeSnapshot()
 	entries, report, err := status.LoadEntries(ctx, runtime.Clients, status.Options{
-		CaddyServerIP: runtime.CaddyEndpoint.ServerIP,
+		CaddyServerIP:   runtime.CaddyEndpoint.ServerIP,
+		CaddyServerIPv6: runtime.CaddyEndpoint.ServerIPv6,
 	})
 	if err != nil {
 		return nil, report, err
@@ -447,12 +616,16 @@ func entryResponses(entries []*models.Entry) []EntryResponse {
 			continue
 		}
 		out = append(out, EntryResponse{
-			Hostname:      entry.Hostname,
-			CaddyUpstream: entry.CaddyUpstream,
-			CaddyIP:       entry.CaddyIP,
-			CaddyPort:     entry.CaddyPort,
-			UnboundStatus: serviceStatusResponse(entry.UnboundStatus),
-			AdguardStatus: serviceStatusResponse(entry.AdguardStatus),
+			Hostname:       entry.Hostname,
+			CaddyUpstream:  entry.CaddyUpstream,
+			CaddyIP:        entry.CaddyIP,
+			CaddyPort:      entry.CaddyPort,
+			CaddyServer:    entry.CaddyServer,
+			CaddyConflicts: entry.CaddyConflicts,
+			UnboundStatus:  serviceStatusResponse(entry.UnboundStatus),
+			AdguardStatus:  serviceStatusResponse(entry.AdguardStatus),
+			PiholeStatus:   optionalServiceStatusResponse(entry.PiholeStatus()),
+			TargetStatus:   targetStatusResponses(entry.TargetStatus),
 			DHCPStatus: DHCPStatusResponse{
 				Configured: entry.DHCPStatus.Configured,
 				Type:       entry.DHCPStatus.Type,
@@ -461,7 +634,8 @@ func entryResponses(entries []*models.Entry) []EntryResponse {
 				Hostname:   entry.DHCPStatus.Hostname,
 				InSync:     entry.DHCPStatus.InSync,
 			},
-			DNSResolved: entry.DNSResolved,
+			DNSResolved:   entry.DNSResolved,
+			DNSResolvedV6: entry.DNSResolvedV6,
 			CloudflareStatus: CloudflareStatusResponse{
 				Configured:       entry.CloudflareStatus.Configured,
 				TunnelName:       entry.CloudflareStatus.TunnelName,
@@ -488,17 +662,48 @@ func entryResponses(entries []*models.Entry) []EntryResponse {
 	return out
 }
 
+// optionalServiceStatusResponse returns nil for services that are not
+// configured at all, so the UI can hide their column.
+func optionalServiceStatusResponse(serviceStatus *models.ServiceStatus) *ServiceStatusResponse {
+	if serviceStatus == nil {
+		return nil
+	}
+	response := serviceStatusResponse(*serviceStatus)
+	return &response
+}
+
+// targetStatusResponses converts Entry.TargetStatus, leaving out Pi-hole,
+// which has its own field, and returning nil when nothing is left so the
+// field is omitted.
+func targetStatusResponses(statuses map[string]models.ServiceStatus) map[string]ServiceStatusResponse {
+	out := make(map[string]ServiceStatusResponse, len(statuses))
+	for name, serviceStatus := range statuses {
+		if name != "pihole" {
+			out[name] = serviceStatusResponse(serviceStatus)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
 func serviceStatusResponse(serviceStatus models.ServiceStatus) ServiceStatusResponse {
 	return ServiceStatusResponse{
 		Configured: serviceStatus.Configured,
 		IP:         serviceStatus.IP,
-		InSync:     serviceStatus.InSync,
+		IPv6:       serviceStatus.IPv6,
+		Alias:      serviceStatus.IsAlias(),
+		InSync:     serviceStatus.Matches(),
 	}
 }
 
 func validPlanService(service string) bool {
+	if isInstanceService(service) {
+		return true
+	}
 	switch service {
-	case "", "all", "unbound", "adguard", "dhcp", "cloudflare":
+	case "", "all", "unbound", "dnsmasq", "adguard", "pihole", "rfc2136", "dhcp", "cloudflare":
 		return true
 	default:
 		return false
@@ -507,11 +712,12 @@ func validPlanService(service string) bool {
 
 func validateApplyActions(actions []syncplan.Action) error {
 	for _, action := range actions {
+		if isInstanceService(action.Service) {
+			continue
+		}
 		switch action.Service {
-		case "unbound", "adguard", "cloudflare":
+		case "unbound", "dnsmasq", "adguard", "pihole", "rfc2136", "dhcp", "cloudflare":
 			continue
-		case "dhcp":
-			return fmt.Errorf("DHCP apply is not implemented")
 		default:
 			return fmt.Errorf("invalid sync service %q", action.Service)
 		}
@@ -583,12 +789,43 @@ func filterPlanActionsByHostname(actions []syncplan.Action, hostname string) []s
 	return out
 }
 
+// isInstanceService reports whether service names an additional Unbound or
+// AdGuard Home instance target.
+func isInstanceService(service string) bool {
+	return strings.HasPrefix(service, syncplan.UnboundInstancePrefix) ||
+		strings.HasPrefix(service, syncplan.AdguardInstancePrefix)
+}
+
 func serviceEnabled(runtime *app.Runtime, service string) bool {
+	if name, ok := strings.CutPrefix(service, syncplan.UnboundInstancePrefix); ok {
+		for _, instance := range runtime.Clients.UnboundInstances {
+			if instance.Name == name {
+				return instance.Client != nil
+			}
+		}
+		return false
+	}
+	if name, ok := strings.CutPrefix(service, syncplan.AdguardInstancePrefix); ok {
+		for _, instance := range runtime.Clients.AdguardInstances {
+			if instance.Name == name {
+				return instance.Client != nil
+			}
+		}
+		return false
+	}
 	switch service {
 	case "unbound":
 		return runtime.Clients.Unbound != nil
+	case "dnsmasq":
+		return runtime.Clients.DNSMasq != nil && runtime.Clients.DNSMasqHosts
+	case "dhcp":
+		return runtime.Clients.DNSMasq != nil || runtime.Clients.Kea != nil
 	case "adguard":
 		return runtime.Clients.Adguard != nil
+	case "pihole":
+		return runtime.Clients.Pihole != nil
+	case "rfc2136":
+		return runtime.Clients.RFC2136 != nil
 	case "cloudflare":
 		return runtime.Clients.Cloudflare != nil
 	default:
@@ -0,0 +1,122 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/synclock"
+	"github.com/jeeftor/caddy-dns-sync/internal/syncplan"
+)
+
+type HistoryResponse struct {
+	Runs []syncplan.Run `json:"runs"`
+}
+
+type UndoResponse struct {
+	Result *syncplan.Result `json:"result"`
+	// Warnings lists applied changes that could not be reverted.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ─── History Handlers ───────────────────────────────────────────────────────
+
+// handleHistory lists journaled sync runs, newest first.
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+	runs, err := s.journal.List()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if runs == nil {
+		runs = []syncplan.Run{}
+	}
+	writeJSON(w, http.StatusOK, HistoryResponse{Runs: runs})
+}
+
+// handleHistoryRun serves GET /api/history/{id} and POST /api/history/{id}/undo.
+func (s *Server) handleHistoryRun(w http.ResponseWriter, r *http.Request) {
+	id, undo := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/api/history/"), "/undo")
+	if !undo {
+		if r.Method != http.MethodGet {
+			writeMethodNotAllowed(w)
+			return
+		}
+		run, err := s.journal.Read(id)
+		if err != nil {
+			writeError(w, historyErrorStatus(err), err)
+			return
+		}
+		writeJSON(w, http.StatusOK, run)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w)
+		return
+	}
+	if err := s.allowMutation(r); err != nil {
+		writeError(w, http.StatusForbidden, err)
+		return
+	}
+
+	s.undoMu.Lock()
+	defer s.undoMu.Unlock()
+	run, err := s.journal.Read(id)
+	if err != nil {
+		writeError(w, historyErrorStatus(err), err)
+		return
+	}
+	plan, warnings, err := syncplan.UndoPlan(run)
+	if err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+
+	releaseLock, err := synclock.AcquireWithWait(syncLockTimeout)
+	if err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	defer releaseLock()
+
+	runtime := s.runtimeSnapshot()
+	clients := syncplan.NewClients(runtime.Clients)
+	plan, skipped, err := syncplan.CheckUndo(r.Context(), clients, plan)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	for _, warning := range skipped {
+		warnings = append(warnings, "skipped "+warning)
+	}
+	result := &syncplan.Result{Success: true}
+	if len(plan.Actions) > 0 {
+		result = syncplan.Apply(r.Context(), clients, plan, syncplan.ApplyOptions{
+			Journal: s.journal,
+			UndoOf:  run.ID,
+		})
+	}
+	// A partial undo leaves the run undoable so the rest can be retried.
+	if result.Success && result.RunID != "" {
+		if err := s.journal.MarkUndone(run.ID, result.RunID); err != nil {
+			result.Success = false
+			result.Errors = append(result.Errors, fmt.Sprintf("recording undo of %s: %v", run.ID, err))
+		}
+	}
+	writeJSON(w, http.StatusOK, UndoResponse{Result: result, Warnings: warnings})
+	s.invalidateEntriesCache()
+	go s.refreshAuthCache()
+}
+
+func historyErrorStatus(err error) int {
+	if errors.Is(err, syncplan.ErrRunNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,486 @@
+package web
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jeeftor/caddy-dns-sync/internal/caddyeditor"
+	"github.com/jeeftor/caddy-dns-sync/internal/config"
+	"github.com/jeeftor/caddy-dns-sync/internal/logging"
+	"github.com/jeeftor/caddy-dns-sync/internal/synclock"
+	"github.com/jeeftor/caddy-dns-sync/internal/syncplan"
+)
+
+// maxHookRuns caps how many webhook deliveries are kept for GET /api/hooks.
+const maxHookRuns = 50
+
+// maxHookOutput caps the output kept with each recorded run; older output is
+// dropped first.
+const maxHookOutput = 64 << 10
+
+// hookMaxAge is how far the time a delivery was sent may lie from now, either
+// way. Older deliveries are rejected as replays; within the window a body is
+// accepted only once.
+const hookMaxAge = 5 * time.Minute
+
+// syncLockTimeout is how long a webhook sync or a web undo waits for the
+// sync lock held by a CLI or watch run.
+var syncLockTimeout = synclock.DefaultTimeout
+
+// Hook delivery sources.
+const (
+	hookSourceGitHub  = "github"
+	hookSourceGitea   = "gitea"
+	hookSourceGeneric = "generic"
+)
+
+// HookRun records one accepted webhook delivery and its outcome.
+type HookRun struct {
+	ID         string                    `json:"id"`
+	Hook       string                    `json:"hook"`
+	Source     string                    `json:"source"`
+	Event      string                    `json:"event,omitempty"`
+	Ref        string                    `json:"ref,omitempty"`
+	Commit     string                    `json:"commit,omitempty"`
+	Status     string                    `json:"status"` // running, ok, error
+	Error      string                    `json:"error,omitempty"`
+	StartedAt  time.Time                 `json:"started_at"`
+	FinishedAt *time.Time                `json:"finished_at,omitempty"`
+	Deploy     *caddyeditor.DeployResult `json:"deploy,omitempty"`
+	Sync       *syncplan.Result          `json:"sync,omitempty"`
+	// Output is the progress the run wrote, as streamed to SSE clients.
+	Output string `json:"output,omitempty"`
+}
+
+type HooksResponse struct {
+	Runs []HookRun `json:"runs"`
+}
+
+// hookDelivery is what a webhook payload says about the push behind it.
+type hookDelivery struct {
+	Source string
+	Event  string
+	Ref    string
+	Commit string
+	SentAt time.Time // zero when the payload carries no timestamp
+}
+
+// ─── Webhook Handlers ───────────────────────────────────────────────────────
+
+// handleHooks lists recent webhook deliveries, newest first. Their output
+// can include deploy logs, so it requires the session token.
+func (s *Server) handleHooks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+	if err := s.allowMutation(r); err != nil {
+		writeError(w, http.StatusForbidden, err)
+		return
+	}
+	s.hookRunsMu.Lock()
+	runs := make([]HookRun, 0, len(s.hookRuns))
+	for i := len(s.hookRuns) - 1; i >= 0; i-- {
+		runs = append(runs, s.hookRuns[i])
+	}
+	s.hookRunsMu.Unlock()
+	writeJSON(w, http.StatusOK, HooksResponse{Runs: runs})
+}
+
+// handleHook serves POST /api/hooks/{name}. The body must be signed with the
+// hook's secret. Clients that accept text/event-stream get the run's progress
+// streamed like a Caddy deploy; others (GitHub, Gitea) get 202 right away
+// while the run continues in the background.
+func (s *Server) handleHook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w)
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, "/api/hooks/")
+	if !s.options.AllowMutations {
+		writeError(w, http.StatusForbidden, fmt.Errorf("web mutations are disabled"))
+		return
+	}
+	hooks, err := config.LoadWebhooks()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	var hook *config.Webhook
+	for i := range hooks {
+		if hooks[i].Name == name {
+			hook = &hooks[i]
+		}
+	}
+	if hook == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown webhook %q", name))
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid webhook body: %w", err))
+		return
+	}
+	if !verifyHookSignature(r.Header, body, hook.Secret) {
+		logging.Warn("Rejected webhook delivery with a bad signature", "hook", hook.Name, "remote", r.RemoteAddr)
+		writeError(w, http.StatusUnauthorized, fmt.Errorf("invalid webhook signature"))
+		return
+	}
+
+	delivery, err := parseHookDelivery(r.Header, body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	switch {
+	case delivery.Event == "ping":
+		writeJSON(w, http.StatusOK, map[string]string{"status": "pong"})
+		return
+	case delivery.Source != hookSourceGeneric && delivery.Event != "push":
+		writeJSON(w, http.StatusAccepted, map[string]string{"status": "ignored", "reason": fmt.Sprintf("%s event", delivery.Event)})
+		return
+	case hook.Branch != "" && delivery.Ref != "" && delivery.Ref != "refs/heads/"+hook.Branch:
+		writeJSON(w, http.StatusAccepted, map[string]string{"status": "ignored", "reason": fmt.Sprintf("push to %s", delivery.Ref)})
+		return
+	}
+	if delivery.SentAt.IsZero() {
+		writeError(w, http.StatusBadRequest, errors.New("webhook payload carries no timestamp"))
+		return
+	}
+	if err := s.checkHookReplay(hook.Name, body, delivery.SentAt, time.Now()); err != nil {
+		logging.Warn("Rejected webhook delivery", "hook", hook.Name, "remote", r.RemoteAddr, "error", err)
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+
+	started := time.Now().UTC()
+	run := HookRun{
+		ID:        fmt.Sprintf("%s-%s", hook.Name, started.Format("20060102T150405.000000")),
+		Hook:      hook.Name,
+		Source:    delivery.Source,
+		Event:     delivery.Event,
+		Ref:       delivery.Ref,
+		Commit:    delivery.Commit,
+		Status:    "running",
+		StartedAt: started,
+	}
+	s.recordHookRun(run)
+	logging.Info("Webhook delivery accepted", "hook", hook.Name, "source", delivery.Source, "ref", delivery.Ref, "commit", delivery.Commit)
+
+	// The run outlives the request: a sender that hangs up must not cancel a
+	// deploy halfway through.
+	ctx := s.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer logging.Recover("server: webhook " + hook.Name)
+			s.runHook(ctx, *hook, run, io.Discard)
+		}()
+		writeJSON(w, http.StatusAccepted, run)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	flusher, canFlush := w.(http.Flusher)
+	run = s.runHook(ctx, *hook, run, &sseWriter{w: w, flusher: flusher, canFlush: canFlush})
+	_, _ = fmt.Fprintf(w, "data: {\"done\":true,\"status\":%q,\"run_id\":%q}\n\n", run.Status, run.ID)
+	if canFlush {
+		flusher.Flush()
+	}
+}
+
+// runHook pulls and deploys the Caddy editor repo and/or syncs DNS, writing
+// progress to w, and records the outcome along with that progress. Runs are
+// serialized so two pushes in quick succession do not sync over each other.
+func (s *Server) runHook(ctx context.Context, hook config.Webhook, run HookRun, w io.Writer) HookRun {
+	s.hookMu.Lock()
+	defer s.hookMu.Unlock()
+
+	output := &hookOutput{}
+	w = io.MultiWriter(w, output)
+	writeLine := func(line string) {
+		_, _ = fmt.Fprintln(w, line)
+		logging.Info("webhook " + hook.Name + ": " + line)
+	}
+
+	err := func() error {
+		if hook.Deploy {
+			result, err := s.hookDeploy(ctx, hook, w, writeLine)
+			run.Deploy = result
+			if err != nil {
+				return err
+			}
+		}
+		if hook.Sync {
+			result, err := s.hookSync(ctx, hook.Service, writeLine)
+			run.Sync = result
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}()
+
+	finished := time.Now().UTC()
+	run.FinishedAt = &finished
+	run.Status = "ok"
+	if err != nil {
+		run.Status = "error"
+		run.Error = err.Error()
+		writeLine("FAILED: " + err.Error())
+		logging.Error("Webhook run failed", "hook", hook.Name, "run", run.ID, "error", err)
+	} else {
+		logging.Info("Webhook run completed", "hook", hook.Name, "run", run.ID)
+	}
+	run.Output = output.String()
+	s.recordHookRun(run)
+	return run
+}
+
+// hookDeploy pulls the Caddy editor repo and runs its deploy pipeline.
+func (s *Server) hookDeploy(ctx context.Context, hook config.Webhook, w io.Writer, writeLine func(string)) (*caddyeditor.DeployResult, error) {
+	cfg, err := s.caddyEditorConfig()
+	if err != nil {
+		return nil, err
+	}
+	if !cfg.Enabled || cfg.RepoPath == "" {
+		return nil, errors.New("caddy_editor is not configured")
+	}
+	s.deployMu.Lock()
+	defer s.deployMu.Unlock()
+
+	writeLine(fmt.Sprintf("Pulling %s...", cfg.RepoPath))
+	out, err := caddyeditor.GitPull(cfg)
+	if out != "" {
+		writeLine(out)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("git pull: %w", err)
+	}
+
+	result := caddyeditor.DeployPipeline(ctx, cfg, caddyeditor.DeployPipelineOptions{
+		CommitMessage: fmt.Sprintf("caddy: deploy from webhook %s", hook.Name),
+	}, w)
+	s.invalidateEntriesCache()
+	go s.refreshAuthCache()
+	if !result.OK {
+		return &result, fmt.Errorf("deploy failed: %s", result.Output)
+	}
+	return &result, nil
+}
+
+// hookSync plans and applies a sync for service against fresh entries,
+// honouring the sync policy. It holds the same sync lock as the CLI and watch
+// runs, and the applied run is journaled like any web apply.
+func (s *Server) hookSync(ctx context.Context, service string, writeLine func(string)) (*syncplan.Result, error) {
+	runtime := s.runtimeSnapshot()
+	if !validPlanService(service) || (service != "all" && !serviceEnabled(&runtime, service)) {
+		return nil, fmt.Errorf("%s is unavailable in this web session", service)
+	}
+	releaseLock, err := synclock.AcquireWithWait(syncLockTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseLock()
+
+	writeLine("Loading DNS state...")
+	s.invalidateEntriesCache()
+	entries, _, err := s.loadEntries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("loading entries: %w", err)
+	}
+	plan := syncplan.BuildPlan(entries, syncplan.Options{
+		Service:           service,
+		CaddyServerIP:     runtime.CaddyEndpoint.ServerIP,
+		CaddyServiceURL:   runtime.CaddyServiceURL,
+		IncludeCloudflare: runtime.Clients.Cloudflare != nil,
+	})
+	actions, protected, err := s.enforceSyncPolicy(ctx, s.webPlanActions(&runtime, service, plan.Actions), false)
+	for _, action := range protected {
+		writeLine(fmt.Sprintf("Skipped %s %s for %s: hostname is protected", action.Type, action.Service, action.Hostname))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	writeLine(fmt.Sprintf("%d hostnames, %d changes", len(entries), len(actions)))
+	if len(actions) == 0 {
+		return &syncplan.Result{Success: true}, nil
+	}
+	for _, action := range actions {
+		writeLine(fmt.Sprintf("%s %s %s", action.Service, action.Type, action.Hostname))
+	}
+	result := s.applyActions(ctx, actions, false, syncplan.AtomicOff)
+	s.invalidateEntriesCache()
+	go s.refreshAuthCache()
+	if !result.Success {
+		return result, fmt.Errorf("sync finished with %d error(s)", len(result.Errors))
+	}
+	writeLine(fmt.Sprintf("Sync applied (run %s)", result.RunID))
+	return result, nil
+}
+
+// recordHookRun adds run, or replaces the earlier record with its ID.
+func (s *Server) recordHookRun(run HookRun) {
+	s.hookRunsMu.Lock()
+	defer s.hookRunsMu.Unlock()
+	for i := range s.hookRuns {
+		if s.hookRuns[i].ID == run.ID {
+			s.hookRuns[i] = run
+			return
+		}
+	}
+	s.hookRuns = append(s.hookRuns, run)
+	if len(s.hookRuns) > maxHookRuns {
+		s.hookRuns = s.hookRuns[len(s.hookRuns)-maxHookRuns:]
+	}
+}
+
+// checkHookReplay rejects a delivery sent more than hookMaxAge from now, or
+// with a body already accepted for hook, and otherwise remembers the body.
+// The send time is read from the signed payload, so neither can be forged.
+func (s *Server) checkHookReplay(hook string, body []byte, sentAt, now time.Time) error {
+	if age := now.Sub(sentAt); age > hookMaxAge || age < -hookMaxAge {
+		return fmt.Errorf("webhook delivery sent at %s is outside the %s window", sentAt.UTC().Format(time.RFC3339), hookMaxAge)
+	}
+
+	sum := sha256.Sum256(body)
+	key := hook + ":" + hex.EncodeToString(sum[:])
+	s.hookRunsMu.Lock()
+	defer s.hookRunsMu.Unlock()
+	for seen, at := range s.hookSeen {
+		if now.Sub(at) > 2*hookMaxAge {
+			delete(s.hookSeen, seen)
+		}
+	}
+	if _, ok := s.hookSeen[key]; ok {
+		return errors.New("webhook delivery was already accepted")
+	}
+	if s.hookSeen == nil {
+		s.hookSeen = make(map[string]time.Time)
+	}
+	s.hookSeen[key] = now
+	return nil
+}
+
+// hookOutput keeps the last maxHookOutput bytes written to it.
+type hookOutput struct {
+	buf []byte
+}
+
+func (o *hookOutput) Write(p []byte) (int, error) {
+	o.buf = append(o.buf, p...)
+	if len(o.buf) > maxHookOutput {
+		o.buf = o.buf[len(o.buf)-maxHookOutput:]
+	}
+	return len(p), nil
+}
+
+func (o *hookOutput) String() string {
+	return string(o.buf)
+}
+
+// verifyHookSignature checks the HMAC-SHA256 of body as GitHub sends it
+// (X-Hub-Signature-256: sha256=<hex>) or Gitea does (X-Gitea-Signature:
+// <hex>). Generic senders use either header.
+func verifyHookSignature(header http.Header, body []byte, secret string) bool {
+	signature := header.Get("X-Hub-Signature-256")
+	if signature == "" {
+		signature = header.Get("X-Gitea-Signature")
+	}
+	got, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil || len(got) == 0 {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// parseHookDelivery reads the sender, event, pushed ref and send time from a
+// webhook. Gitea also sends GitHub's headers, so it is checked first. The send
+// time is GitHub's repository.pushed_at, Gitea's repository.updated_at, or a
+// generic payload's "timestamp" (Unix seconds or RFC 3339). Generic payloads
+// are otherwise free-form; "ref" and "after" are read like a push.
+func parseHookDelivery(header http.Header, body []byte) (hookDelivery, error) {
+	delivery := hookDelivery{Source: hookSourceGeneric}
+	switch {
+	case header.Get("X-Gitea-Event") != "":
+		delivery.Source, delivery.Event = hookSourceGitea, header.Get("X-Gitea-Event")
+	case header.Get("X-GitHub-Event") != "":
+		delivery.Source, delivery.Event = hookSourceGitHub, header.Get("X-GitHub-Event")
+	}
+
+	// GitHub can deliver the JSON form-encoded as payload=...
+	if strings.HasPrefix(header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return delivery, fmt.Errorf("invalid webhook form: %w", err)
+		}
+		body = []byte(form.Get("payload"))
+	}
+
+	var payload struct {
+		Ref        string          `json:"ref"`
+		After      string          `json:"after"`
+		Timestamp  json.RawMessage `json:"timestamp"`
+		Repository struct {
+			PushedAt  json.RawMessage `json:"pushed_at"`
+			UpdatedAt json.RawMessage `json:"updated_at"`
+		} `json:"repository"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil && delivery.Source != hookSourceGeneric && delivery.Event == "push" {
+		return delivery, fmt.Errorf("invalid %s push payload: %w", delivery.Source, err)
+	}
+	delivery.Ref, delivery.Commit = payload.Ref, payload.After
+
+	sentAt := payload.Timestamp
+	switch delivery.Source {
+	case hookSourceGitHub:
+		sentAt = payload.Repository.PushedAt
+	case hookSourceGitea:
+		sentAt = payload.Repository.UpdatedAt
+	}
+	delivery.SentAt = parseHookTime(sentAt)
+	return delivery, nil
+}
+
+// parseHookTime reads a JSON timestamp given as Unix seconds, as a number or
+// a string, or as an RFC 3339 string. Anything else yields the zero time.
+func parseHookTime(raw json.RawMessage) time.Time {
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return time.Time{}
+	}
+	switch v := value.(type) {
+	case float64:
+		return time.Unix(int64(v), 0)
+	case string:
+		if seconds, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return time.Unix(seconds, 0)
+		}
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
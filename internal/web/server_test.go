@@ -3,6 +3,9 @@ package web
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net"
@@ -14,10 +17,12 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/jeeftor/caddy-dns-sync/internal/api"
 	"github.com/jeeftor/caddy-dns-sync/internal/app"
 	"github.com/jeeftor/caddy-dns-sync/internal/config"
+	"github.com/jeeftor/caddy-dns-sync/internal/synclock"
 	"github.com/jeeftor/caddy-dns-sync/internal/syncplan"
 )
 
@@ -52,6 +57,10 @@ func TestAPIRoutesUseSharedServicesWithoutLAN(t *testing.T) {
 	defer caddy.Close()
 
 	opnsense := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/unbound/settings/searchHostAlias" {
+			fmt.Fprint(w, `{"rows":[]}`)
+			return
+		}
 		if r.URL.Path != "/api/unbound/settings/searchHostOverride" {
 			t.Fatalf("unexpected OPNSense path %s", r.URL.Path)
 		}
@@ -424,6 +433,10 @@ func TestConfigTestRouteChecksConfiguredServices(t *testing.T) {
 	defer caddy.Close()
 
 	opnsense := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/unbound/settings/searchHostAlias" {
+			fmt.Fprint(w, `{"rows":[]}`)
+			return
+		}
 		if r.URL.Path != "/api/unbound/settings/searchHostOverride" {
 			t.Fatalf("unexpected OPNSense path %s", r.URL.Path)
 		}
@@ -751,6 +764,10 @@ func TestPlanRouteFiltersSelectedHostname(t *testing.T) {
 	defer caddy.Close()
 
 	opnsense := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/unbound/settings/searchHostAlias" {
+			fmt.Fprint(w, `{"rows":[]}`)
+			return
+		}
 		if r.URL.Path != "/api/unbound/settings/searchHostOverride" {
 			t.Fatalf("unexpected OPNSense path %s", r.URL.Path)
 		}
@@ -793,6 +810,10 @@ func TestAllPlanFiltersUnavailableWebServices(t *testing.T) {
 	defer caddy.Close()
 
 	opnsense := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/unbound/settings/searchHostAlias" {
+			fmt.Fprint(w, `{"rows":[]}`)
+			return
+		}
 		if r.URL.Path != "/api/unbound/settings/searchHostOverride" {
 			t.Fatalf("unexpected OPNSense path %s", r.URL.Path)
 		}
@@ -910,10 +931,10 @@ func TestApplyRouteAllowsDryRunOnly(t *testing.T) {
 	}
 }
 
-func TestApplyRouteRejectsUnsupportedDHCPDryRun(t *testing.T) {
+func TestApplyRoutePreviewsDHCPReservationDryRun(t *testing.T) {
 	server := NewServer(&app.Runtime{})
 	action := syncplan.Action{
-		Type: "add", Service: "dhcp", Hostname: "dhcp.example.test", NewIP: "10.0.0.55", Enabled: true,
+		Type: "add", Service: "dhcp", Hostname: "dhcp.example.test", NewIP: "10.0.0.55", MAC: "aa:bb:cc:dd:ee:ff", Enabled: true,
 	}
 	body, err := json.Marshal(ApplyRequest{DryRun: true, Actions: []syncplan.Action{action}})
 	if err != nil {
@@ -923,8 +944,15 @@ func TestApplyRouteRejectsUnsupportedDHCPDryRun(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	rec := httptest.NewRecorder()
 	server.ServeHTTP(rec, req)
-	if rec.Code != http.StatusBadRequest {
-		t.Fatalf("expected 400 for unsupported DHCP apply, got %d: %s", rec.Code, rec.Body.String())
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 for DHCP dry run, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var response ApplyResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Result == nil || !response.Result.Success || response.Result.ItemsAdded != 1 {
+		t.Fatalf("expected previewed DHCP reservation, got %#v", response.Result)
 	}
 }
 
@@ -1037,7 +1065,7 @@ func TestMutatingApplyUsesServerIssuedPlanActions(t *testing.T) {
 	opnsense := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		switch r.URL.Path {
-		case "/api/unbound/settings/searchHostOverride":
+		case "/api/unbound/settings/searchHostOverride", "/api/unbound/settings/searchHostAlias":
 			fmt.Fprint(w, `{"rows":[]}`)
 		case "/api/unbound/settings/addHostOverride":
 			added = true
@@ -1070,6 +1098,7 @@ func TestMutatingApplyUsesServerIssuedPlanActions(t *testing.T) {
 		AllowMutations: true,
 		AllowedOrigin:  "http://127.0.0.1:8080",
 		BoundHost:      "127.0.0.1",
+		JournalDir:     t.TempDir(),
 	})
 
 	configResp := getJSON[ConfigResponse](t, server, "/api/config")
@@ -1108,6 +1137,278 @@ func TestMutatingApplyUsesServerIssuedPlanActions(t *testing.T) {
 	if !added || !reconfigured {
 		t.Fatalf("expected add and reconfigure calls, added=%t reconfigured=%t", added, reconfigured)
 	}
+	history := getJSON[HistoryResponse](t, server, "/api/history")
+	if len(history.Runs) != 1 || history.Runs[0].ID != applyResp.Result.RunID {
+		t.Fatalf("expected the apply to be journaled as run %q, got %#v", applyResp.Result.RunID, history.Runs)
+	}
+}
+
+func TestSignedWebhookSyncsDNSAndRecordsOutcome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	hooks := `{"webhooks":[{"name":"gitea","secret":"hook-secret","sync":true,"service":"unbound","branch":"main"}]}`
+	if err := os.WriteFile(filepath.Join(home, config.DefaultConfigFileName), []byte(hooks), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	caddy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"apps":{"http":{"servers":{"srv0":{"routes":[{"match":[{"host":["hook.example.test"]}],"handle":[{"handler":"reverse_proxy","upstreams":[{"dial":"10.0.0.5:8080"}]}]}]}}}}}`)
+	}))
+	defer caddy.Close()
+
+	var added bool
+	opnsense := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/unbound/settings/searchHostOverride", "/api/unbound/settings/searchHostAlias":
+			fmt.Fprint(w, `{"rows":[]}`)
+		case "/api/unbound/settings/addHostOverride":
+			added = true
+			fmt.Fprint(w, `{"result":"saved","uuid":"new-uuid"}`)
+		case "/api/unbound/service/reconfigure", "/api/core/firmware/backup":
+			fmt.Fprint(w, `{"status":"ok"}`)
+		default:
+			t.Errorf("unexpected OPNSense path %s", r.URL.Path)
+		}
+	}))
+	defer opnsense.Close()
+
+	host, port := splitWebTestServerHostPort(t, caddy.URL)
+	server := NewServerWithOptions(&app.Runtime{
+		CaddyEndpoint: app.CaddyEndpoint{ServerIP: host, ServerPort: port},
+		Clients: app.ClientSet{
+			Caddy: api.NewCaddyClient(host, port),
+			Unbound: api.NewClient(api.Config{
+				APIKey:    "fixture-key",
+				APISecret: "fixture-secret",
+				BaseURL:   opnsense.URL,
+				Insecure:  true,
+			}),
+		},
+	}, Options{ApplyToken: "test-token", AllowMutations: true, BoundHost: "127.0.0.1", JournalDir: t.TempDir()})
+	defer server.Shutdown()
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	deliver := func(payload, secret string) *httptest.ResponseRecorder {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(payload))
+		req := httptest.NewRequest(http.MethodPost, "/api/hooks/gitea", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "text/event-stream")
+		req.Header.Set("X-Gitea-Event", "push")
+		req.Header.Set("X-Gitea-Signature", hex.EncodeToString(mac.Sum(nil)))
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := deliver(`{"ref":"refs/heads/main","repository":{"updated_at":"`+now+`"}}`, "wrong-secret"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected a bad signature to be rejected with 401, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := deliver(`{"ref":"refs/heads/feature","repository":{"updated_at":"`+now+`"}}`, "hook-secret"); rec.Code != http.StatusAccepted || !strings.Contains(rec.Body.String(), "ignored") {
+		t.Fatalf("expected a push to another branch to be ignored, got %d: %s", rec.Code, rec.Body.String())
+	}
+	stale := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	if rec := deliver(`{"ref":"refs/heads/main","repository":{"updated_at":"`+stale+`"}}`, "hook-secret"); rec.Code != http.StatusConflict {
+		t.Fatalf("expected a stale delivery to be rejected with 409, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := deliver(`{"ref":"refs/heads/main"}`, "hook-secret"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected a delivery without a timestamp to be rejected with 400, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if added {
+		t.Fatal("expected no sync before a valid delivery")
+	}
+
+	payload := `{"ref":"refs/heads/main","after":"abc123","repository":{"updated_at":"` + now + `"}}`
+	rec := deliver(payload, "hook-secret")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"done":true,"status":"ok"`) {
+		t.Fatalf("expected a streamed successful run, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !added {
+		t.Fatal("expected the webhook to add the missing host override")
+	}
+	if rec := deliver(payload, "hook-secret"); rec.Code != http.StatusConflict {
+		t.Fatalf("expected a replayed delivery to be rejected with 409, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/hooks", nil)
+	unauthorized := httptest.NewRecorder()
+	server.ServeHTTP(unauthorized, req)
+	if unauthorized.Code != http.StatusForbidden {
+		t.Fatalf("expected hook history without a token to be forbidden, got %d: %s", unauthorized.Code, unauthorized.Body.String())
+	}
+	runs := getHookRuns(t, server)
+	if len(runs) != 1 {
+		t.Fatalf("expected one recorded delivery, got %#v", runs)
+	}
+	run := runs[0]
+	if run.Status != "ok" || run.Source != "gitea" || run.Commit != "abc123" || run.Sync == nil || run.Sync.ItemsAdded != 1 {
+		t.Fatalf("expected the successful sync to be recorded, got %#v", run)
+	}
+	if !strings.Contains(run.Output, "1 hostnames, 1 changes") {
+		t.Fatalf("expected the run output to be recorded, got %q", run.Output)
+	}
+	history := getJSON[HistoryResponse](t, server, "/api/history")
+	if len(history.Runs) != 1 || history.Runs[0].ID != run.Sync.RunID {
+		t.Fatalf("expected the webhook sync to be journaled as run %q, got %#v", run.Sync.RunID, history.Runs)
+	}
+}
+
+func TestBackgroundWebhookRecordsOutputAndHonoursSyncLock(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	hooks := `{"webhooks":[{"name":"generic","secret":"hook-secret","sync":true,"service":"unbound"}]}`
+	if err := os.WriteFile(filepath.Join(home, config.DefaultConfigFileName), []byte(hooks), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	previousTimeout := syncLockTimeout
+	syncLockTimeout = 0
+	t.Cleanup(func() { syncLockTimeout = previousTimeout })
+
+	// A CLI or watch sync holds the lock for the whole delivery.
+	releaseLock, err := synclock.Acquire()
+	if err != nil {
+		t.Fatalf("failed to take the sync lock: %v", err)
+	}
+	defer releaseLock()
+
+	server := NewServerWithOptions(&app.Runtime{
+		Clients: app.ClientSet{
+			Unbound: api.NewClient(api.Config{BaseURL: "https://127.0.0.1:1", APIKey: "key", APISecret: "secret"}),
+		},
+	}, Options{ApplyToken: "test-token", AllowMutations: true, BoundHost: "127.0.0.1", JournalDir: t.TempDir()})
+
+	payload := fmt.Sprintf(`{"timestamp":%d}`, time.Now().Unix())
+	mac := hmac.New(sha256.New, []byte("hook-secret"))
+	mac.Write([]byte(payload))
+	req := httptest.NewRequest(http.MethodPost, "/api/hooks/generic", strings.NewReader(payload))
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected the delivery to be accepted, got %d: %s", rec.Code, rec.Body.String())
+	}
+	server.Shutdown()
+
+	runs := getHookRuns(t, server)
+	if len(runs) != 1 || runs[0].Status != "error" || !strings.Contains(runs[0].Error, "another sync is already running") {
+		t.Fatalf("expected the run to fail on the held sync lock, got %#v", runs)
+	}
+	if !strings.Contains(runs[0].Output, "FAILED: another sync is already running") {
+		t.Fatalf("expected the background run's output to be recorded, got %q", runs[0].Output)
+	}
+}
+
+// getHookRuns fetches GET /api/hooks with the session token.
+func getHookRuns(t *testing.T, handler http.Handler) []HookRun {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/hooks", nil)
+	req.Header.Set("X-UnboundCLI-Token", "test-token")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/hooks: expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var out HooksResponse
+	if err := json.NewDecoder(rec.Body).Decode(&out); err != nil {
+		t.Fatalf("GET /api/hooks: failed to decode JSON: %v", err)
+	}
+	return out.Runs
+}
+
+func TestHistoryUndoRevertsJournaledRun(t *testing.T) {
+	var deleted []string
+	adguard := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/control/rewrite/list":
+			if len(deleted) > 0 {
+				fmt.Fprint(w, `[]`)
+				return
+			}
+			fmt.Fprint(w, `[{"domain":"new.example.test","answer":"10.0.0.15"},{"domain":"moved.example.test","answer":"10.0.0.99"}]`)
+			return
+		case "/control/rewrite/delete":
+		default:
+			t.Fatalf("unexpected AdGuard path %s", r.URL.Path)
+		}
+		var rewrite api.Rewrite
+		if err := json.NewDecoder(r.Body).Decode(&rewrite); err != nil {
+			t.Fatalf("failed to decode rewrite: %v", err)
+		}
+		deleted = append(deleted, rewrite.Domain+"="+rewrite.Answer)
+	}))
+	defer adguard.Close()
+
+	journal := syncplan.NewJournal(t.TempDir())
+	run, err := journal.Begin("")
+	if err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+	run.Steps = append(run.Steps,
+		syncplan.JournalStep{
+			Action:  syncplan.Action{Type: "add", Service: "adguard", Hostname: "new.example.test", NewIP: "10.0.0.15", Enabled: true},
+			Applied: true,
+		},
+		// Changed by hand since the run, so undo must leave it alone.
+		syncplan.JournalStep{
+			Action:  syncplan.Action{Type: "add", Service: "adguard", Hostname: "moved.example.test", NewIP: "10.0.0.16", Enabled: true},
+			Applied: true,
+		},
+	)
+	if err := journal.Save(run); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	t.Setenv("HOME", t.TempDir())
+	server := NewServerWithOptions(&app.Runtime{
+		Clients: app.ClientSet{
+			Adguard: api.NewAdguardClient(api.AdguardConfig{BaseURL: adguard.URL}),
+		},
+	}, Options{
+		ApplyToken:     "test-token",
+		AllowMutations: true,
+		AllowedOrigin:  "http://127.0.0.1:8080",
+		BoundHost:      "127.0.0.1",
+		JournalDir:     journal.Dir,
+	})
+
+	undo := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/history/"+run.ID+"/undo", nil)
+		req.Header.Set("X-UnboundCLI-Token", token)
+		req.Header.Set("Origin", "http://127.0.0.1:8080")
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec
+	}
+	if rec := undo(""); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected undo without token to be forbidden, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec := undo("test-token")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var undoResp UndoResponse
+	if err := json.NewDecoder(rec.Body).Decode(&undoResp); err != nil {
+		t.Fatalf("failed to decode undo response: %v", err)
+	}
+	if !undoResp.Result.Success || undoResp.Result.ItemsDeleted != 1 {
+		t.Fatalf("expected the added rewrite to be deleted, got %#v", undoResp.Result)
+	}
+	if len(deleted) != 1 || deleted[0] != "new.example.test=10.0.0.15" {
+		t.Fatalf("unexpected AdGuard deletes: %#v", deleted)
+	}
+	if len(undoResp.Warnings) != 1 || !strings.Contains(undoResp.Warnings[0], "moved.example.test") {
+		t.Fatalf("expected the changed rewrite to be reported as skipped, got %#v", undoResp.Warnings)
+	}
+
+	original := getJSON[syncplan.Run](t, server, "/api/history/"+run.ID)
+	if original.UndoneBy != undoResp.Result.RunID {
+		t.Fatalf("expected run to be marked undone by %q, got %#v", undoResp.Result.RunID, original)
+	}
+	if rec := undo("test-token"); rec.Code != http.StatusConflict {
+		t.Fatalf("expected a second undo to conflict, got %d: %s", rec.Code, rec.Body.String())
+	}
 }
 
 func TestMutatingApplyRejectsUnknownActionID(t *testing.T) {
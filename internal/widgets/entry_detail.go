@@ -136,14 +136,17 @@ func RenderEntryDetail(entry *models.Entry, theme *Theme) string {
 	default:
 		lines = append(lines, lbl("Resolved:")+val(entry.DNSResolved))
 	}
+	if entry.DNSResolvedV6 != "" {
+		lines = append(lines, lbl("Resolved v6:")+val(entry.DNSResolvedV6))
+	}
 	lines = append(lines, "")
 
 	// ── UnboundDNS ─────────────────────────────────────────────────────────
 	lines = append(lines, section("UnboundDNS"))
 	lines = append(lines, lbl("Configured:")+yesno(entry.UnboundStatus.Configured))
 	if entry.UnboundStatus.Configured {
-		lines = append(lines, lbl("IP:")+val(entry.UnboundStatus.IP))
-		lines = append(lines, lbl("In Sync:")+yesno(entry.UnboundStatus.InSync))
+		lines = append(lines, lbl("IP:")+val(entry.UnboundStatus.Addresses()))
+		lines = append(lines, lbl("In Sync:")+yesno(entry.UnboundStatus.Matches()))
 	}
 	lines = append(lines, "")
 
@@ -151,8 +154,8 @@ func RenderEntryDetail(entry *models.Entry, theme *Theme) string {
 	lines = append(lines, section("AdGuard Home"))
 	lines = append(lines, lbl("Configured:")+yesno(entry.AdguardStatus.Configured))
 	if entry.AdguardStatus.Configured {
-		lines = append(lines, lbl("IP:")+val(entry.AdguardStatus.IP))
-		lines = append(lines, lbl("In Sync:")+yesno(entry.AdguardStatus.InSync))
+		lines = append(lines, lbl("IP:")+val(entry.AdguardStatus.Addresses()))
+		lines = append(lines, lbl("In Sync:")+yesno(entry.AdguardStatus.Matches()))
 	}
 	lines = append(lines, "")
 
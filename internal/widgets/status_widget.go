@@ -16,6 +16,7 @@ type ServiceLoadStatus struct {
 	Caddy      bool
 	Unbound    bool
 	AdGuard    bool
+	Pihole     bool
 	DHCP       bool
 	Cloudflare bool
 	Complete   bool
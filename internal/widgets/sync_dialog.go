@@ -567,6 +567,8 @@ func (w *SyncDialog) formatActionInline(action syncplan.Action) string {
 		serviceName = "unb"
 	} else if serviceName == "adguard" {
 		serviceName = "adg"
+	} else if serviceName == "pihole" {
+		serviceName = "pih"
 	}
 	parts = append(parts, w.theme.Info.Render(serviceName))
 
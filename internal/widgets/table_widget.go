@@ -466,7 +466,7 @@ func (w *TableWidget) IsSearching() bool {
 }
 
 // SetServiceStatus updates which services have been loaded.
-// The CF column is only included in the layout when Cloudflare data is available.
+// The Pi-hole and CF columns are only included in the layout when those services are configured.
 func (w *TableWidget) SetServiceStatus(status ServiceLoadStatus) {
 	w.serviceStatus = status
 	w.rebuildColumnConfigs()
@@ -484,6 +484,9 @@ func (w *TableWidget) rebuildColumnConfigs() {
 		{Title: "Unbound", MinWidth: 7, Priority: 1, FlexGrow: 0.1},
 		{Title: "AdGuard", MinWidth: 7, Priority: 1, FlexGrow: 0.1},
 	}
+	if w.serviceStatus.Pihole {
+		configs = append(configs, ColumnConfig{Title: "Pi-hole", MinWidth: 7, Priority: 1, FlexGrow: 0.1})
+	}
 	if w.serviceStatus.Cloudflare {
 		configs = append(configs, ColumnConfig{Title: "CF", MinWidth: 14, Priority: 3, FlexGrow: 0.8})
 	}
@@ -565,12 +568,12 @@ func (w *TableWidget) buildRow(entry *models.Entry) []string {
 			cell = w.truncate(src, w.columnWidths[i])
 
 		case "DNS":
-			if entry.DNSResolved == "" || entry.DNSResolved == "NONE" {
-				cell = "-"
-			} else if entry.DNSResolved == "FAIL" {
+			if entry.DNSResolved == "FAIL" {
 				cell = "FAIL"
+			} else if resolved := entry.ResolvedAddresses(); resolved == "" {
+				cell = "-"
 			} else {
-				cell = w.truncate(entry.DNSResolved, w.columnWidths[i])
+				cell = w.truncate(resolved, w.columnWidths[i])
 			}
 
 		case "Upstream":
@@ -608,6 +611,13 @@ func (w *TableWidget) buildRow(entry *models.Entry) []string {
 				cell = w.formatServiceStatus(entry.AdguardStatus, entry.IsConfiguredInCaddy())
 			}
 
+		case "Pi-hole":
+			if status := entry.PiholeStatus(); status == nil {
+				cell = w.theme.Dimmed.Render("..")
+			} else {
+				cell = w.formatServiceStatus(*status, entry.IsConfiguredInCaddy())
+			}
+
 		case "CF":
 			cell = w.formatCFStatus(entry)
 
@@ -632,7 +642,7 @@ func (w *TableWidget) formatServiceStatus(status models.ServiceStatus, inCaddy b
 	if !status.Configured {
 		return w.theme.Warning.Render("NO")
 	}
-	if status.InSync {
+	if status.Matches() {
 		return w.theme.Success.Render("OK")
 	}
 	return w.theme.Error.Render("!!")
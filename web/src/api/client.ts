@@ -81,7 +81,7 @@ export const api = {
   },
   applySync: (payload: { dry_run: boolean; actions?: SyncAction[]; plan_id?: string; action_ids?: string[] }) =>
     postJSON<ApplyResponse>('/api/sync/apply', payload),
-  removeEntry: (hostname: string, service: 'all' | 'unbound' | 'adguard' = 'all') =>
+  removeEntry: (hostname: string, service: 'all' | 'unbound' | 'adguard' | 'pihole' = 'all') =>
     postJSON<{ removed: number; message: string }>('/api/sync/remove', { hostname, service }),
   saveConfig: (payload: unknown) => postJSON<ConfigResponse>('/api/config', payload),
   testConfig: (service: ServiceKey) => postJSON<ConfigTestResponse>('/api/config/test', { service }),
@@ -23,12 +23,13 @@ const configTabLabels: Record<ConfigTab, string> = {
   caddy:        'Caddy',
   unbound:      'Unbound',
   adguard:      'AdGuard',
+  pihole:       'Pi-hole',
   dhcp:         'DHCP',
   cloudflare:   'Cloudflare',
   'caddy-editor': 'File Editor',
 };
 
-const configTabOrder: ConfigTab[] = ['caddy', 'unbound', 'adguard', 'dhcp', 'cloudflare', 'caddy-editor'];
+const configTabOrder: ConfigTab[] = ['caddy', 'unbound', 'adguard', 'pihole', 'dhcp', 'cloudflare', 'caddy-editor'];
 
 export function ConfigModal({
   open,
@@ -324,6 +325,9 @@ function ConfigEditor({
   if (service === 'caddy') {
     return <div className="config-editor compact" data-config-editor="caddy"><ConfigTestResult service={service} result={testResult} /><button type="button" data-config-test="caddy" disabled={!mutationEnabled} onClick={() => void onTest('caddy')}>Test Caddy</button></div>;
   }
+  if (service === 'pihole') {
+    return <div className="config-editor compact" data-config-editor="pihole"><ConfigTestResult service={service} result={testResult} /><button type="button" data-config-test="pihole" disabled={!mutationEnabled} onClick={() => void onTest('pihole')}>Test Pi-hole</button></div>;
+  }
   if (service === 'dhcp') return null;
   if (service === 'unbound') {
     const dirty = isDirty('unbound');
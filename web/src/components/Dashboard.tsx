@@ -301,7 +301,7 @@ export function AppShell() {
                 onQuickSync={openQuickSync}
                 onOpenModify={openModify}
                 onOpenVisualize={openVisualize}
-                onRemove={(hostname, service) => removeEntry(hostname, service as 'all' | 'unbound' | 'adguard')}
+                onRemove={(hostname, service) => removeEntry(hostname, service as 'all' | 'unbound' | 'adguard' | 'pihole')}
               />
             </main>
           )}
@@ -330,7 +330,7 @@ export function AppShell() {
         onDryRun={() => dryRunSync()}
         onSync={() => syncNow()}
         onRefresh={() => void refreshEntries()}
-        onRemoveEntry={(hostname, service) => removeEntry(hostname, service as 'all' | 'unbound' | 'adguard')}
+        onRemoveEntry={(hostname, service) => removeEntry(hostname, service as 'all' | 'unbound' | 'adguard' | 'pihole')}
       />
       {visualizeOpen && visualizeEntry && (
         <VisualizeModal entry={visualizeEntry} onClose={() => setVisualizeOpen(false)} />
@@ -5,6 +5,7 @@ import { createPortal } from 'react-dom';
 import { getHostnameDecision, suppressionKey } from '../lib/hostnameDecision';
 import {
   dnsResultClass,
+  serviceAddresses,
   statusClassByCode
 } from '../lib/services';
 import type { Entry, ServiceKey } from '../types';
@@ -129,7 +130,7 @@ const EntryRow = memo(function EntryRow({
       <td data-label="Status"><StatusChip entry={entry} /><span className="status-subtext">{statusDetail}</span></td>
       <td data-label="Services"><ServiceBadges entry={entry} /></td>
       <td data-label="Caddy upstream"><span>{entry.caddy_upstream || '-'}{entry.caddy_upstream && <CopyButton value={entry.caddy_upstream} label="upstream" />}</span><span className="subtle">admin {entry.caddy_ip || '-'}</span><span className="protocol-pill">HTTP</span></td>
-      <td data-label="DNS"><span className={`dns-result ${dnsResultClass(entry.dns_resolved)}`}>{entry.dns_resolved || 'FAIL'}</span><span className="status-subtext">{dnsOK ? 'A record' : 'NXDOMAIN'}</span></td>
+      <td data-label="DNS"><span className={`dns-result ${dnsResultClass(entry.dns_resolved)}`}>{entry.dns_resolved || 'FAIL'}</span>{entry.dns_resolved_v6 && <span className="dns-result ok">{entry.dns_resolved_v6}</span>}<span className="status-subtext">{dnsOK ? (entry.dns_resolved_v6 ? 'A + AAAA records' : 'A record') : 'NXDOMAIN'}</span></td>
       <td data-label="Cloudflare route"><CloudflareDetails status={entry.cloudflare_status} hostname={entry.hostname} /></td>
       <td data-label="Actions">
         <div className="row-actions">
@@ -163,20 +164,21 @@ function ServiceBadges({ entry }: { entry: Entry }) {
     <div className="service-stack">
       <ServiceBadge name="Unbound" status={entry.unbound_status} />
       <ServiceBadge name="AdGuard" status={entry.adguard_status} />
+      {entry.pihole_status && <ServiceBadge name="Pi-hole" status={entry.pihole_status} />}
       <ServiceBadge name="DHCP" status={entry.dhcp_status} />
     </div>
   );
 }
 
-const ServiceBadge = memo(function ServiceBadge({ name, status }: { name: string; status: { configured: boolean; in_sync: boolean; ip: string } }) {
+const ServiceBadge = memo(function ServiceBadge({ name, status }: { name: string; status: { configured: boolean; in_sync: boolean; ip: string; ipv6?: string } }) {
   let tone = 'missing';
   let label = 'Missing';
   if (status.configured && status.in_sync) {
     tone = 'ok';
-    label = status.ip || 'In sync';
+    label = serviceAddresses(status) || 'In sync';
   } else if (status.configured) {
     tone = 'bad';
-    label = status.ip || 'Mismatch';
+    label = serviceAddresses(status) || 'Mismatch';
   }
   return <span className={`service-badge ${tone}`}><strong>{name}</strong>{label}</span>;
 });
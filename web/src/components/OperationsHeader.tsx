@@ -1,7 +1,7 @@
 import { SERVICE_META } from '../store';
 import type { ProgressEvent } from '../types';
 
-const SERVICE_ORDER = ['caddy', 'unbound', 'adguard', 'dhcp', 'cloudflare', 'dns'];
+const SERVICE_ORDER = ['caddy', 'unbound', 'adguard', 'pihole', 'dhcp', 'cloudflare', 'dns'];
 
 export function OperationsHeader({
   loading,
@@ -179,6 +179,7 @@ export function SyncModal({
   const serviceRows: Array<{ key: string; label: string; status?: { configured: boolean; in_sync: boolean; ip: string } }> = [
     { key: 'unbound', label: 'Unbound DNS', status: entry?.unbound_status },
     { key: 'adguard', label: 'AdGuard Home', status: entry?.adguard_status },
+    { key: 'pihole', label: 'Pi-hole', status: entry?.pihole_status },
   ].filter(s => enabledServices[s.key as ServiceKey]);
 
   const handleServiceRemove = async (key: string) => {
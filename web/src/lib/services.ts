@@ -2,12 +2,13 @@ import { Activity, Cloud, Database, HardDrive, Network, ShieldCheck } from 'luci
 import type { ComponentType } from 'react';
 import type { ConfigSource, Entry, ServiceKey, SyncAction } from '../types';
 
-export const serviceOrder: ServiceKey[] = ['caddy', 'unbound', 'adguard', 'dhcp', 'cloudflare'];
+export const serviceOrder: ServiceKey[] = ['caddy', 'unbound', 'adguard', 'pihole', 'dhcp', 'cloudflare'];
 
 export const serviceMeta: Record<ServiceKey, { label: string; shortLabel: string; icon: ComponentType<{ size?: number }>; tone: string }> = {
   caddy: { label: 'Caddy', shortLabel: 'Caddy', icon: Network, tone: 'green' },
   unbound: { label: 'OPNSense / Unbound', shortLabel: 'Unbound', icon: Database, tone: 'blue' },
   adguard: { label: 'AdGuard', shortLabel: 'AdGuard', icon: ShieldCheck, tone: 'teal' },
+  pihole: { label: 'Pi-hole', shortLabel: 'Pi-hole', icon: ShieldCheck, tone: 'red' },
   dhcp: { label: 'DHCP / DNSMasq', shortLabel: 'DHCP', icon: HardDrive, tone: 'yellow' },
   cloudflare: { label: 'Cloudflare', shortLabel: 'Cloudflare', icon: Cloud, tone: 'violet' }
 };
@@ -24,6 +25,7 @@ export const serviceFilterOptions: [string, string][] = [
   ['all', 'All services'],
   ['unbound', 'Unbound'],
   ['adguard', 'AdGuard'],
+  ['pihole', 'Pi-hole'],
   ['dhcp', 'DHCP'],
   ['cloudflare', 'Cloudflare']
 ];
@@ -32,6 +34,7 @@ export function serviceEnabled(enabled: Partial<Record<ServiceKey, boolean>>, se
   if (service === 'all') return enabled.unbound !== false || enabled.adguard !== false;
   if (service === 'dhcp') return true;
   if (service === 'unbound' || service === 'adguard' || service === 'cloudflare') return enabled[service] !== false;
+  if (service === 'pihole') return enabled.pihole === true;
   return true;
 }
 
@@ -43,6 +46,7 @@ export function syncOptions(enabled: Partial<Record<ServiceKey, boolean>>): [str
     ['all', allLabel],
     ['unbound', 'Unbound'],
     ['adguard', 'AdGuard'],
+    ['pihole', 'Pi-hole'],
     ['dhcp', 'DHCP preview']
   ];
 }
@@ -52,6 +56,7 @@ export function disabledSyncValues(enabled: Partial<Record<ServiceKey, boolean>>
   if (enabled.unbound === false && enabled.adguard === false) disabled.add('all');
   if (enabled.unbound === false) disabled.add('unbound');
   if (enabled.adguard === false) disabled.add('adguard');
+  if (enabled.pihole !== true) disabled.add('pihole');
   return disabled;
 }
 
@@ -66,10 +71,16 @@ export function dnsResultClass(value: string) {
   return normalized && normalized !== 'fail' ? 'ok' : 'bad';
 }
 
-export function serviceStateText(status: { configured: boolean; in_sync: boolean; ip: string }) {
+export function serviceAddresses(status: { ip: string; ipv6?: string; alias?: boolean }) {
+  if (status.alias) return `alias of ${status.ip}`;
+  return [status.ip, status.ipv6].filter(Boolean).join(', ');
+}
+
+export function serviceStateText(status: { configured: boolean; in_sync: boolean; ip: string; ipv6?: string; alias?: boolean }) {
   if (!status?.configured) return 'Missing';
-  if (status.in_sync) return status.ip ? `In sync (${status.ip})` : 'In sync';
-  return status.ip ? `Mismatch (${status.ip})` : 'Mismatch';
+  const addresses = serviceAddresses(status);
+  if (status.in_sync) return addresses ? `In sync (${addresses})` : 'In sync';
+  return addresses ? `Mismatch (${addresses})` : 'Mismatch';
 }
 
 export function cloudflareStateText(status: Entry['cloudflare_status']) {
@@ -319,6 +319,7 @@ export const SERVICE_META: Record<string, { label: string; icon: string }> = {
   caddy: { label: 'Caddy', icon: '🌐' },
   unbound: { label: 'Unbound', icon: '📋' },
   adguard: { label: 'AdGuard', icon: '🛡️' },
+  pihole: { label: 'Pi-hole', icon: '🕳️' },
   dhcp: { label: 'DHCP', icon: '📡' },
   cloudflare: { label: 'Cloudflare', icon: '☁️' },
   dns: { label: 'DNS Resolve', icon: '🔍' },
@@ -577,7 +578,7 @@ export async function syncNow(): Promise<void> {
   }
 }
 
-export async function removeEntry(hostname: string, service: 'all' | 'unbound' | 'adguard' = 'all'): Promise<void> {
+export async function removeEntry(hostname: string, service: 'all' | 'unbound' | 'adguard' | 'pihole' = 'all'): Promise<void> {
   await api.removeEntry(hostname, service);
   void refreshEntries();
   void useStore.getState().refreshAuth();
@@ -1,4 +1,4 @@
-export type ServiceKey = 'caddy' | 'unbound' | 'adguard' | 'dhcp' | 'cloudflare';
+export type ServiceKey = 'caddy' | 'unbound' | 'adguard' | 'pihole' | 'dhcp' | 'cloudflare';
 
 export type ConfigSource = {
   kind: string;
@@ -34,6 +34,8 @@ export type ConfigResponse = {
 export type ServiceStatus = {
   configured: boolean;
   ip: string;
+  ipv6?: string;
+  alias?: boolean;
   in_sync: boolean;
 };
 
@@ -68,8 +70,10 @@ export type Entry = {
   caddy_port: string;
   unbound_status: ServiceStatus;
   adguard_status: ServiceStatus;
+  pihole_status?: ServiceStatus;
   dhcp_status: DHCPStatus;
   dns_resolved: string;
+  dns_resolved_v6?: string;
   cloudflare_status: CloudflareStatus;
   overall_status: number;
   status_label: string;